@@ -0,0 +1,221 @@
+package access
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// Typed numeric arrays.
+//
+// A homogeneous int16/int32/int64/float32/float64 slice is packed as a single
+// byte-array field: the elements back to back in little endian, followed by
+// one descriptor byte. The descriptor holds the element tag (TypeInteger or
+// TypeFloating) in its low 3 bits and log2 of the element width in bits 3-4,
+// so the count is (len(payload)-1)/width. Unlike AddStringArray there are no
+// per-element headers.
+//
+// The descriptor trails the elements so that the data starts where the field
+// starts; when that address happens to be aligned for the element type on a
+// little-endian host the Get*Slice readers return a view into the buffer
+// instead of a copy. Generic readers see an ordinary byte array, so the
+// reader has to know the field is an array.
+
+// ArrayElem lists the element types of packed numeric arrays.
+type ArrayElem interface {
+	~int16 | ~int32 | ~int64 | ~float32 | ~float64
+}
+
+var hostLittleEndian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+func arrayDescriptor[T ArrayElem]() byte {
+	var zero T
+	tag := typetags.TypeInteger
+	if one := T(1); one/2 != 0 {
+		// only floats keep a fraction, including named float types
+		tag = typetags.TypeFloating
+	}
+	d, _ := ArrayDescriptor(tag, int(unsafe.Sizeof(zero)))
+	return d
+}
+
+// ArrayDescriptor returns the descriptor byte for elements of the given tag
+// and width. Integers may be 2, 4 or 8 bytes wide, floats 4 or 8.
+func ArrayDescriptor(tag typetags.Type, width int) (byte, error) {
+	var log2 byte
+	switch width {
+	case 2:
+		log2 = 1
+	case 4:
+		log2 = 2
+	case 8:
+		log2 = 3
+	default:
+		return 0, fmt.Errorf("unsupported array element width %d", width)
+	}
+	if tag != typetags.TypeInteger && (tag != typetags.TypeFloating || width == 2) {
+		return 0, fmt.Errorf("unsupported array element %v of width %d", tag, width)
+	}
+	return byte(tag) | log2<<3, nil
+}
+
+// ArrayLayout reads the descriptor of a non-empty typed array payload and
+// returns the element tag, width and count.
+func ArrayLayout(payload []byte) (tag typetags.Type, width, count int, err error) {
+	if len(payload) == 0 {
+		return 0, 0, 0, errors.New("empty array payload")
+	}
+	d := payload[len(payload)-1]
+	tag, width = typetags.Type(d&7), 1<<(d>>3&3)
+	if want, err := ArrayDescriptor(tag, width); err != nil || want != d {
+		return 0, 0, 0, fmt.Errorf("invalid array descriptor %#x", d)
+	}
+	if (len(payload)-1)%width != 0 {
+		return 0, 0, 0, fmt.Errorf("array payload of %d bytes is not a multiple of %d", len(payload)-1, width)
+	}
+	return tag, width, (len(payload) - 1) / width, nil
+}
+
+// ArrayValueSize returns the payload size of a packed array of n elements of T.
+func ArrayValueSize[T ArrayElem](n int) int {
+	var zero T
+	return n*int(unsafe.Sizeof(zero)) + 1
+}
+
+// WriteArray writes the payload of vals at pos and returns the new position.
+// buf must have ArrayValueSize[T](len(vals)) bytes available.
+func WriteArray[T ArrayElem](buf []byte, pos int, vals []T) int {
+	var zero T
+	size := int(unsafe.Sizeof(zero))
+	for i := range vals {
+		// copy the raw bits so float and integer elements share one path
+		v := unsafe.Pointer(&vals[i])
+		switch size {
+		case 2:
+			binary.LittleEndian.PutUint16(buf[pos:], *(*uint16)(v))
+		case 4:
+			binary.LittleEndian.PutUint32(buf[pos:], *(*uint32)(v))
+		default:
+			binary.LittleEndian.PutUint64(buf[pos:], *(*uint64)(v))
+		}
+		pos += size
+	}
+	buf[pos] = arrayDescriptor[T]()
+	return pos + 1
+}
+
+// AddArray packs vals as a typed numeric array. A nil slice is packed as a
+// null field; an empty one keeps its descriptor.
+func AddArray[T ArrayElem](p *PutAccess, vals []T) {
+	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeByteArray))
+	if vals != nil {
+		n := len(p.buf)
+		p.buf = append(p.buf, make([]byte, ArrayValueSize[T](len(vals)))...)
+		WriteArray(p.buf, n, vals)
+	}
+	p.advance()
+}
+
+// AddInt16Array packs v as a typed int16 array.
+func (p *PutAccess) AddInt16Array(v []int16) { AddArray(p, v) }
+
+// AddInt32Array packs v as a typed int32 array.
+func (p *PutAccess) AddInt32Array(v []int32) { AddArray(p, v) }
+
+// AddInt64Array packs v as a typed int64 array.
+func (p *PutAccess) AddInt64Array(v []int64) { AddArray(p, v) }
+
+// AddFloat32Array packs v as a typed float32 array.
+func (p *PutAccess) AddFloat32Array(v []float32) { AddArray(p, v) }
+
+// AddFloat64Array packs v as a typed float64 array.
+func (p *PutAccess) AddFloat64Array(v []float64) { AddArray(p, v) }
+
+// DecodeArray reads a typed array payload into a new slice. A nil payload
+// (a null field) yields a nil slice.
+func DecodeArray[T ArrayElem](payload []byte) ([]T, error) {
+	n, err := arrayLen[T](payload)
+	if err != nil || payload == nil {
+		return nil, err
+	}
+	out := make([]T, n)
+	var zero T
+	size := int(unsafe.Sizeof(zero))
+	for i := range out {
+		b := payload[i*size:]
+		v := unsafe.Pointer(&out[i])
+		switch size {
+		case 2:
+			*(*uint16)(v) = binary.LittleEndian.Uint16(b)
+		case 4:
+			*(*uint32)(v) = binary.LittleEndian.Uint32(b)
+		default:
+			*(*uint64)(v) = binary.LittleEndian.Uint64(b)
+		}
+	}
+	return out, nil
+}
+
+// ViewArray returns the elements of a typed array payload. On little-endian
+// hosts, when the data is suitably aligned, the result aliases payload and
+// must not outlive it or be modified while others read it; otherwise it is
+// a copy as from DecodeArray.
+func ViewArray[T ArrayElem](payload []byte) ([]T, error) {
+	n, err := arrayLen[T](payload)
+	if err != nil || payload == nil {
+		return nil, err
+	}
+	if n == 0 {
+		return []T{}, nil
+	}
+	var zero T
+	p := unsafe.Pointer(unsafe.SliceData(payload))
+	if hostLittleEndian && uintptr(p)%unsafe.Alignof(zero) == 0 {
+		return unsafe.Slice((*T)(p), n), nil
+	}
+	return DecodeArray[T](payload)
+}
+
+func arrayLen[T ArrayElem](payload []byte) (int, error) {
+	if payload == nil {
+		return 0, nil
+	}
+	var zero T
+	size := int(unsafe.Sizeof(zero))
+	if len(payload) == 0 || payload[len(payload)-1] != arrayDescriptor[T]() || (len(payload)-1)%size != 0 {
+		return 0, fmt.Errorf("not a %T array payload", zero)
+	}
+	return (len(payload) - 1) / size, nil
+}
+
+func getArray[T ArrayElem](g *GetAccess, pos int) ([]T, error) {
+	tp, start, end := g.rangeAt(pos)
+	if tp != typetags.TypeByteArray || start > end {
+		return nil, fmt.Errorf("no array at pos %d", pos)
+	}
+	if start == end {
+		return nil, nil
+	}
+	return ViewArray[T](g.buf[start:end:end])
+}
+
+// GetInt16Slice returns the int16 array at position pos; see ViewArray.
+func (g *GetAccess) GetInt16Slice(pos int) ([]int16, error) { return getArray[int16](g, pos) }
+
+// GetInt32Slice returns the int32 array at position pos; see ViewArray.
+func (g *GetAccess) GetInt32Slice(pos int) ([]int32, error) { return getArray[int32](g, pos) }
+
+// GetInt64Slice returns the int64 array at position pos; see ViewArray.
+func (g *GetAccess) GetInt64Slice(pos int) ([]int64, error) { return getArray[int64](g, pos) }
+
+// GetFloat32Slice returns the float32 array at position pos; see ViewArray.
+func (g *GetAccess) GetFloat32Slice(pos int) ([]float32, error) { return getArray[float32](g, pos) }
+
+// GetFloat64Slice returns the float64 array at position pos; see ViewArray.
+func (g *GetAccess) GetFloat64Slice(pos int) ([]float64, error) { return getArray[float64](g, pos) }
@@ -0,0 +1,103 @@
+package access
+
+import (
+	"math"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArray_RoundTrip(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt16Array([]int16{-1, 2, math.MaxInt16})
+	put.AddInt32Array([]int32{math.MinInt32, 0, 7})
+	put.AddInt64Array([]int64{1 << 40})
+	put.AddFloat32Array([]float32{1.5, float32(math.Inf(-1))})
+	put.AddFloat64Array([]float64{})
+	put.AddInt32Array(nil)
+	buf := put.Pack()
+
+	get := NewGetAccess(buf)
+	i16, err := get.GetInt16Slice(0)
+	require.NoError(t, err)
+	assert.Equal(t, []int16{-1, 2, math.MaxInt16}, i16)
+	i32, err := get.GetInt32Slice(1)
+	require.NoError(t, err)
+	assert.Equal(t, []int32{math.MinInt32, 0, 7}, i32)
+	i64, err := get.GetInt64Slice(2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1 << 40}, i64)
+	f32, err := get.GetFloat32Slice(3)
+	require.NoError(t, err)
+	assert.Equal(t, []float32{1.5, float32(math.Inf(-1))}, f32)
+	f64, err := get.GetFloat64Slice(4)
+	require.NoError(t, err)
+	assert.NotNil(t, f64)
+	assert.Empty(t, f64)
+	null, err := get.GetInt32Slice(5)
+	require.NoError(t, err)
+	assert.Nil(t, null)
+}
+
+func TestArray_Layout(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt16Array([]int16{1, -2})
+	buf := put.Pack()
+	// header, end header, elements, descriptor: TypeInteger | log2(2)<<3
+	assert.Equal(t, []byte{0x26, 0x00, 0x28, 0x00, 0x01, 0x00, 0xFE, 0xFF, 0x09}, buf)
+}
+
+func TestArray_ElementTypeMismatch(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt32Array([]int32{1, 2})
+	put.AddBytes([]byte{1, 2, 3})
+	get := NewGetAccess(put.Pack())
+
+	_, err := get.GetFloat32Slice(0)
+	assert.Error(t, err, "same width, different element tag")
+	_, err = get.GetInt64Slice(0)
+	assert.Error(t, err)
+	_, err = get.GetInt32Slice(1)
+	assert.Error(t, err)
+}
+
+func TestViewArray_ZeroCopyWhenAligned(t *testing.T) {
+	if !hostLittleEndian {
+		t.Skip("views are only handed out on little-endian hosts")
+	}
+	backing := make([]int64, 3) // 8-byte aligned storage
+	raw := unsafe.Slice((*byte)(unsafe.Pointer(&backing[0])), 24)
+	n := WriteArray(raw, 0, []int32{10, 20, 30, 40})
+	require.Equal(t, 17, n)
+
+	view, err := ViewArray[int32](raw[:n])
+	require.NoError(t, err)
+	assert.Equal(t, []int32{10, 20, 30, 40}, view)
+	raw[0] = 11
+	assert.Equal(t, int32(11), view[0], "aligned payloads are not copied")
+
+	shifted := make([]byte, 18)
+	copy(shifted[1:], raw[:n])
+	copied, err := ViewArray[int32](shifted[1:])
+	require.NoError(t, err)
+	assert.Equal(t, []int32{11, 20, 30, 40}, copied)
+	shifted[1] = 12
+	assert.Equal(t, int32(11), copied[0], "misaligned payloads are copied")
+}
+
+type celsius float64
+
+func TestArray_NamedElementType(t *testing.T) {
+	put := NewPutAccess()
+	AddArray(put, []celsius{-40, 21.5})
+	get := NewGetAccess(put.Pack())
+	_, start, end := get.rangeAt(0)
+	vals, err := DecodeArray[celsius](get.buf[start:end])
+	require.NoError(t, err)
+	assert.Equal(t, []celsius{-40, 21.5}, vals)
+	f64, err := get.GetFloat64Slice(0)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{-40, 21.5}, f64)
+}
@@ -0,0 +1,170 @@
+package access
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// Bitsets are packed as a byte array in a roaring-style layout: the bit
+// length as a uvarint, then one container for every block of BitsetBlockBits
+// bits that has a bit set. A container starts with the number of empty
+// blocks skipped since the previous one and the block's cardinality c, both
+// uvarints. When c > 0 the container is an array of c uvarint gaps between
+// set positions; when c == 0 it is the block's bitmap, least significant bit
+// first, truncated at the bit length. Each block uses whichever form is
+// smaller, so sparse sets cost a few bytes per set bit and dense sets at
+// most one bit per position.
+
+const (
+	// BitsetBlockBits is the number of bits covered by one container.
+	BitsetBlockBits = 4096
+	// MaxBitsetBits bounds the length DecodeBitset accepts, so a short
+	// payload cannot claim a huge allocation.
+	MaxBitsetBits = 1 << 24
+)
+
+var errBitsetPayload = errors.New("invalid bitset payload")
+
+// EncodeBitset returns the packed payload of b.
+func EncodeBitset(b *typetags.Bitset) []byte {
+	n := b.Len()
+	out := binary.AppendUvarint(nil, uint64(n))
+	ones := b.Ones()
+	prevBlock := -1
+	for len(ones) > 0 {
+		block := ones[0] / BitsetBlockBits
+		start := block * BitsetBlockBits
+		end := len(ones)
+		for i, p := range ones {
+			if p >= start+BitsetBlockBits {
+				end = i
+				break
+			}
+		}
+		inBlock := ones[:end]
+		ones = ones[end:]
+
+		out = binary.AppendUvarint(out, uint64(block-prevBlock-1))
+		prevBlock = block
+
+		bitmapSize := (min(BitsetBlockBits, n-start) + 7) / 8
+		arraySize, prev := uvarintLen(uint64(len(inBlock))), start-1
+		for _, p := range inBlock {
+			arraySize += uvarintLen(uint64(p - prev - 1))
+			prev = p
+		}
+		if arraySize < 1+bitmapSize {
+			out = binary.AppendUvarint(out, uint64(len(inBlock)))
+			prev = start - 1
+			for _, p := range inBlock {
+				out = binary.AppendUvarint(out, uint64(p-prev-1))
+				prev = p
+			}
+			continue
+		}
+		out = append(out, 0)
+		bitmap := make([]byte, bitmapSize)
+		for _, p := range inBlock {
+			bitmap[(p-start)/8] |= 1 << ((p - start) % 8)
+		}
+		out = append(out, bitmap...)
+	}
+	return out
+}
+
+func uvarintLen(v uint64) int {
+	n := 1
+	for v >= 0x80 {
+		v >>= 7
+		n++
+	}
+	return n
+}
+
+// DecodeBitset decodes a payload written by EncodeBitset.
+func DecodeBitset(payload []byte) (*typetags.Bitset, error) {
+	next := func() (int, error) {
+		v, k := binary.Uvarint(payload)
+		if k <= 0 || v > MaxBitsetBits {
+			return 0, errBitsetPayload
+		}
+		payload = payload[k:]
+		return int(v), nil
+	}
+	n, err := next()
+	if err != nil {
+		return nil, err
+	}
+	b := typetags.NewBitset(n)
+	block := -1
+	for len(payload) > 0 {
+		skip, err := next()
+		if err != nil {
+			return nil, err
+		}
+		block += skip + 1
+		start := block * BitsetBlockBits
+		if start >= n {
+			return nil, fmt.Errorf("%w: block %d beyond %d bits", errBitsetPayload, block, n)
+		}
+		c, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if c == 0 {
+			size := (min(BitsetBlockBits, n-start) + 7) / 8
+			if len(payload) < size {
+				return nil, fmt.Errorf("%w: short bitmap", errBitsetPayload)
+			}
+			for i, by := range payload[:size] {
+				for ; by != 0; by &= by - 1 {
+					p := start + i*8 + bits.TrailingZeros8(by)
+					if p >= n {
+						return nil, fmt.Errorf("%w: bit %d beyond %d bits", errBitsetPayload, p, n)
+					}
+					b.Set(p)
+				}
+			}
+			payload = payload[size:]
+			continue
+		}
+		p := start - 1
+		for ; c > 0; c-- {
+			gap, err := next()
+			if err != nil {
+				return nil, err
+			}
+			p += gap + 1
+			if p >= n || p >= start+BitsetBlockBits {
+				return nil, fmt.Errorf("%w: bit %d outside its block", errBitsetPayload, p)
+			}
+			b.Set(p)
+		}
+	}
+	return b, nil
+}
+
+// AddBitset packs b as a byte array; a nil b is packed as null.
+func (p *PutAccess) AddBitset(b *typetags.Bitset) {
+	if b == nil {
+		p.AddBytes(nil)
+		return
+	}
+	p.AddBytes(EncodeBitset(b))
+}
+
+// GetBitset decodes the bitset at position pos. A null field yields nil.
+func (g *GetAccess) GetBitset(pos int) (*typetags.Bitset, error) {
+	tp, start, end := g.rangeAt(pos)
+	if tp != typetags.TypeByteArray || start > end {
+		return nil, fmt.Errorf("GetBitset: not a bitset at pos %d", pos)
+	}
+	if start == end {
+		return nil, nil
+	}
+	return DecodeBitset(g.buf[start:end])
+}
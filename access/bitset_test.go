@@ -0,0 +1,70 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitset_RoundTrip(t *testing.T) {
+	dense := typetags.NewBitset(300)
+	for i := 0; i < 300; i += 2 {
+		dense.Set(i)
+	}
+	cases := map[string]*typetags.Bitset{
+		"empty":    typetags.NewBitset(0),
+		"unset":    typetags.NewBitset(10000),
+		"dense":    dense,
+		"sparse":   typetags.BitsetOf(100000, 3, 4095, 4096, 70000, 99999),
+		"boundary": typetags.BitsetOf(8193, 8192),
+	}
+	for name, b := range cases {
+		got, err := DecodeBitset(EncodeBitset(b))
+		require.NoError(t, err, name)
+		assert.True(t, b.Equal(got), name)
+		assert.Equal(t, b.Ones(), got.Ones(), name)
+	}
+}
+
+func TestBitset_CompressesSparseSets(t *testing.T) {
+	sparse := typetags.BitsetOf(50000, 10, 20000, 49999)
+	assert.Less(t, len(EncodeBitset(sparse)), 16)
+
+	full := typetags.NewBitset(4096)
+	for i := 0; i < 4096; i++ {
+		full.Set(i)
+	}
+	// length, skip, marker and the 512-byte bitmap
+	assert.Len(t, EncodeBitset(full), 2+1+1+512)
+}
+
+func TestBitset_PutGet(t *testing.T) {
+	put := NewPutAccess()
+	put.AddBitset(typetags.BitsetOf(5000, 1, 4999))
+	put.AddBitset(nil)
+	get := NewGetAccess(put.Pack())
+
+	b, err := get.GetBitset(0)
+	require.NoError(t, err)
+	assert.Equal(t, 5000, b.Len())
+	assert.True(t, b.Test(4999))
+	assert.False(t, b.Test(2))
+	b, err = get.GetBitset(1)
+	require.NoError(t, err)
+	assert.Nil(t, b)
+}
+
+func TestDecodeBitset_Rejects(t *testing.T) {
+	for name, payload := range map[string][]byte{
+		"truncated length": {0x80},
+		"too long":         {0x80, 0x80, 0x80, 0x80, 0x01},
+		"block past end":   {0x10, 0x01, 0x01, 0x00},
+		"bit past end":     {0x10, 0x00, 0x01, 0x10},
+		"short bitmap":     {0x10, 0x00, 0x00, 0xFF},
+	} {
+		_, err := DecodeBitset(payload)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,412 @@
+package access
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+	"unicode/utf8"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// CBOR bridge (RFC 8949).
+//
+// Tag mapping, PackOS → CBOR:
+//
+//	Integer (fixed or varint) → major 0/1 integer
+//	Integer (12-byte time)    → tag 0 RFC 3339 string
+//	Floating (2/4/8 bytes)    → float32 / float32 / float64
+//	Bool                      → simple true / false
+//	String                    → text string when valid UTF-8, byte string otherwise
+//	Map                       → map with text keys, key order preserved
+//	Tuple                     → array
+//	any zero-width nullable   → null (this includes the empty tuple)
+//
+// CBOR → PackOS uses the reverse mapping. Integers take the smallest width that
+// holds them, half floats widen to float32, null and undefined become a null
+// tuple, and semantic tags are unwrapped to their content.
+// A packed buffer maps to a CBOR sequence (RFC 8742): one item per top-level field.
+
+const (
+	cborUint   = 0 << 5
+	cborNegInt = 1 << 5
+	cborBytes  = 2 << 5
+	cborText   = 3 << 5
+	cborArray  = 4 << 5
+	cborMap    = 5 << 5
+	cborTag    = 6 << 5
+	cborSimple = 7 << 5
+
+	cborFalse     = cborSimple | 20
+	cborTrue      = cborSimple | 21
+	cborNull      = cborSimple | 22
+	cborUndefined = cborSimple | 23
+	cborFloat16   = cborSimple | 25
+	cborFloat32   = cborSimple | 26
+	cborFloat64   = cborSimple | 27
+	cborBreak     = cborSimple | 31
+
+	cborIndefinite  = 31
+	maxNestingDepth = 512
+)
+
+// ToCBOR transcodes a packed buffer into a CBOR sequence.
+func ToCBOR(buf []byte) ([]byte, error) {
+	seq, err := NewSeqGetAccess(buf)
+	if err != nil {
+		return nil, fmt.Errorf("ToCBOR: %w", err)
+	}
+	out := make([]byte, 0, len(buf))
+	for i := 0; i < seq.ArgCount(); i++ {
+		if out, err = appendCBORField(out, seq, 0); err != nil {
+			return nil, fmt.Errorf("ToCBOR: field %d: %w", i, err)
+		}
+	}
+	return out, nil
+}
+
+func appendCBORHead(out []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(out, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(out, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(out, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(out, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(out, major|27), n)
+	}
+}
+
+func appendCBORInt(out []byte, v int64) []byte {
+	if v < 0 {
+		return appendCBORHead(out, cborNegInt, uint64(-(v + 1)))
+	}
+	return appendCBORHead(out, cborUint, uint64(v))
+}
+
+func appendCBORString(out []byte, b []byte) []byte {
+	major := byte(cborText)
+	if !utf8.Valid(b) {
+		major = cborBytes
+	}
+	out = appendCBORHead(out, major, uint64(len(b)))
+	return append(out, b...)
+}
+
+// appendCBORField writes the field at the current position of seq and advances past it.
+func appendCBORField(out []byte, seq *SeqGetAccess, depth int) ([]byte, error) {
+	if depth > maxNestingDepth {
+		return nil, errors.New("nesting too deep")
+	}
+	typ, width, err := seq.PeekTypeWidth()
+	if err != nil {
+		return nil, err
+	}
+
+	if typ.IsMap() || typ == typetags.TypeTuple {
+		switch {
+		case width == 0 && typ.IsMap():
+			out = appendCBORHead(out, cborMap, 0)
+		case width == 0:
+			out = append(out, cborNull)
+		default:
+			nested, err := seq.PeekNestedSeq()
+			if err != nil {
+				return nil, err
+			}
+			if typ.IsMap() {
+				out, err = appendCBORMap(out, nested, depth+1)
+			} else {
+				out = appendCBORHead(out, cborArray, uint64(nested.ArgCount()))
+				for i := 0; i < nested.ArgCount() && err == nil; i++ {
+					out, err = appendCBORField(out, nested, depth+1)
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return out, seq.Advance()
+	}
+
+	payload, typ, err := seq.Next()
+	if err != nil {
+		return nil, err
+	}
+	if typ == typetags.TypeString {
+		return appendCBORString(out, payload), nil
+	}
+	v, err := DecodePrimitive(typ, payload)
+	if err != nil {
+		return nil, err
+	}
+	switch val := v.(type) {
+	case nil:
+		return append(out, cborNull), nil
+	case int8:
+		return appendCBORInt(out, int64(val)), nil
+	case int16:
+		return appendCBORInt(out, int64(val)), nil
+	case int32:
+		return appendCBORInt(out, int64(val)), nil
+	case int64:
+		return appendCBORInt(out, val), nil
+	case float32:
+		return binary.BigEndian.AppendUint32(append(out, cborFloat32), math.Float32bits(val)), nil
+	case float64:
+		return binary.BigEndian.AppendUint64(append(out, cborFloat64), math.Float64bits(val)), nil
+	case bool:
+		if val {
+			return append(out, cborTrue), nil
+		}
+		return append(out, cborFalse), nil
+	case time.Time:
+		// tag 0: RFC 3339 date/time string
+		return appendCBORString(append(out, cborTag), val.AppendFormat(nil, time.RFC3339Nano)), nil
+	default:
+		return nil, fmt.Errorf("unsupported value %T", v)
+	}
+}
+
+func appendCBORMap(out []byte, nested *SeqGetAccess, depth int) ([]byte, error) {
+	if nested.ArgCount()%2 != 0 {
+		return nil, fmt.Errorf("map has odd field count %d", nested.ArgCount())
+	}
+	out = appendCBORHead(out, cborMap, uint64(nested.ArgCount()/2))
+	for i := 0; i < nested.ArgCount(); i += 2 {
+		key, typ, err := nested.Next()
+		if err != nil {
+			return nil, err
+		}
+		if typ != typetags.TypeString {
+			return nil, fmt.Errorf("map key at %d is %v, expected string", i, typ)
+		}
+		out = appendCBORHead(out, cborText, uint64(len(key)))
+		out = append(out, key...)
+		if out, err = appendCBORField(out, nested, depth); err != nil {
+			return nil, fmt.Errorf("map key %q: %w", key, err)
+		}
+	}
+	return out, nil
+}
+
+// FromCBOR transcodes a CBOR sequence into a packed buffer, one top-level field per item.
+func FromCBOR(data []byte) ([]byte, error) {
+	put := NewPutAccessFromPool()
+	defer ReleasePutAccess(put)
+	d := cborDecoder{data: data}
+	for d.pos < len(d.data) {
+		if err := d.decodeInto(put, 0); err != nil {
+			return nil, fmt.Errorf("FromCBOR: offset %d: %w", d.pos, err)
+		}
+	}
+	return put.Pack(), nil
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+var errCBORTruncated = errors.New("unexpected end of CBOR data")
+
+func (d *cborDecoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errCBORTruncated
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// head reads an initial byte and its argument. indefinite is set for additional info 31.
+func (d *cborDecoder) head() (major byte, info byte, arg uint64, indefinite bool, err error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, 0, 0, false, err
+	}
+	major, info = b[0]&0xe0, b[0]&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), false, nil
+	case info == 24:
+		b, err = d.read(1)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+		return major, info, uint64(b[0]), false, nil
+	case info == 25:
+		b, err = d.read(2)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+		return major, info, uint64(binary.BigEndian.Uint16(b)), false, nil
+	case info == 26:
+		b, err = d.read(4)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+		return major, info, uint64(binary.BigEndian.Uint32(b)), false, nil
+	case info == 27:
+		b, err = d.read(8)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+		return major, info, binary.BigEndian.Uint64(b), false, nil
+	case info == cborIndefinite:
+		return major, info, 0, true, nil
+	default:
+		return 0, 0, 0, false, fmt.Errorf("reserved additional info %d", info)
+	}
+}
+
+func (d *cborDecoder) atBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == cborBreak {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+// decodeString reads a definite or indefinite text/byte string of the given major type.
+func (d *cborDecoder) decodeString(major byte, arg uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		return d.read(arg)
+	}
+	var out []byte
+	for !d.atBreak() {
+		m, _, n, ind, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if m != major || ind {
+			return nil, errors.New("invalid indefinite-length string chunk")
+		}
+		chunk, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, chunk...)
+	}
+	return out, nil
+}
+
+func (d *cborDecoder) decodeInto(put *PutAccess, depth int) error {
+	if depth > maxNestingDepth {
+		return errors.New("nesting too deep")
+	}
+	major, info, arg, indefinite, err := d.head()
+	if err != nil {
+		return err
+	}
+
+	switch major {
+	case cborUint:
+		if arg > math.MaxInt64 {
+			return fmt.Errorf("integer %d overflows int64", arg)
+		}
+		put.AddIntegerCompressed(int64(arg))
+	case cborNegInt:
+		if arg > math.MaxInt64 {
+			return fmt.Errorf("integer -1-%d overflows int64", arg)
+		}
+		put.AddIntegerCompressed(-1 - int64(arg))
+	case cborBytes, cborText:
+		b, err := d.decodeString(major, arg, indefinite)
+		if err != nil {
+			return err
+		}
+		put.AddBytes(b)
+	case cborArray:
+		if !indefinite && arg == 0 {
+			put.AddNull(nil)
+			return nil
+		}
+		nested := put.BeginTuple()
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.atBreak() {
+				break
+			}
+			if err := d.decodeInto(nested, depth+1); err != nil {
+				ReleasePutAccess(nested)
+				return fmt.Errorf("array item %d: %w", i, err)
+			}
+		}
+		if len(nested.offsets) == 0 {
+			// indefinite-length empty array
+			ReleasePutAccess(nested)
+			return nil
+		}
+		put.EndNested(nested)
+	case cborMap:
+		nested := put.BeginMap()
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.atBreak() {
+				break
+			}
+			if err := d.decodeMapEntry(nested, depth+1); err != nil {
+				ReleasePutAccess(nested)
+				return err
+			}
+		}
+		if len(nested.offsets) == 0 {
+			ReleasePutAccess(nested)
+			return nil
+		}
+		put.EndNested(nested)
+	case cborTag:
+		if indefinite {
+			return errors.New("indefinite tag")
+		}
+		return d.decodeInto(put, depth+1)
+	default:
+		return d.decodeSimple(put, info, arg)
+	}
+	return nil
+}
+
+func (d *cborDecoder) decodeMapEntry(nested *PutAccess, depth int) error {
+	major, _, arg, indefinite, err := d.head()
+	if err != nil {
+		return err
+	}
+	if major != cborText {
+		return fmt.Errorf("map key has major type %d, expected text string", major>>5)
+	}
+	key, err := d.decodeString(major, arg, indefinite)
+	if err != nil {
+		return err
+	}
+	nested.AddBytes(key)
+	if err := d.decodeInto(nested, depth); err != nil {
+		return fmt.Errorf("map key %q: %w", key, err)
+	}
+	return nil
+}
+
+func (d *cborDecoder) decodeSimple(put *PutAccess, info byte, arg uint64) error {
+	switch cborSimple | info {
+	case cborFalse:
+		put.AddBool(false)
+	case cborTrue:
+		put.AddBool(true)
+	case cborNull, cborUndefined:
+		put.AddNull(nil)
+	case cborFloat16:
+		put.AddFloat32(Float16frombits(uint16(arg)))
+	case cborFloat32:
+		put.AddFloat32(math.Float32frombits(uint32(arg)))
+	case cborFloat64:
+		put.AddFloat64(math.Float64frombits(arg))
+	case cborBreak:
+		return errors.New("unexpected break")
+	default:
+		return fmt.Errorf("unsupported simple value %d", arg)
+	}
+	return nil
+}
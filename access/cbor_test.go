@@ -0,0 +1,80 @@
+package access
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToCBOR_PrimitivesAndNullables(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt8(10)
+	put.AddInt16(-500)
+	put.AddBool(true)
+	put.AddString("a")
+	put.AddBytes([]byte{0xff})
+	put.AddNullableInt32(nil)
+	put.AddFloat64(1.5)
+	put.AddNull(nil)
+
+	out, err := ToCBOR(put.Pack())
+	require.NoError(t, err)
+	assert.Equal(t, "0a"+"3901f3"+"f5"+"6161"+"41ff"+"f6"+"fb3ff8000000000000"+"f6", hex.EncodeToString(out))
+}
+
+func TestToCBOR_MapsAndTuples(t *testing.T) {
+	put := NewPutAccess()
+	err := put.AddMapAnyOrdered(typetags.NewOrderedMapAny(
+		typetags.OPAny("b", int8(1)),
+		typetags.OPAny("a", []any{int8(2), "x"}),
+	), false)
+	require.NoError(t, err)
+	put.AddMapAny(map[string]any{}, false)
+
+	out, err := ToCBOR(put.Pack())
+	require.NoError(t, err)
+	// {"b": 1, "a": [2, "x"]} followed by {}
+	assert.Equal(t, "a2"+"616201"+"6161"+"82"+"02"+"6178"+"a0", hex.EncodeToString(out))
+}
+
+func TestCBOR_RoundTrip(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt64(1 << 40)
+	put.AddFloat32(0.25)
+	err := put.AddMapAnyOrdered(typetags.NewOrderedMapAny(
+		typetags.OPAny("id", int32(70000)),
+		typetags.OPAny("tags", []any{"x", "y"}),
+		typetags.OPAny("inner", typetags.NewOrderedMapAny(typetags.OPAny("ok", false))),
+		typetags.OPAny("none", nil),
+	), false)
+	require.NoError(t, err)
+	buf := put.Pack()
+
+	cbor, err := ToCBOR(buf)
+	require.NoError(t, err)
+	back, err := FromCBOR(cbor)
+	require.NoError(t, err)
+	assert.Equal(t, buf, back)
+}
+
+func TestFromCBOR_DecoderFeatures(t *testing.T) {
+	// tag 1 (epoch) wrapping 1000, half float 1.0, undefined,
+	// indefinite text "ab"+"c", indefinite array [1], empty array
+	data, _ := hex.DecodeString("c11903e8" + "f93c00" + "f7" + "7f6261626163ff" + "9f01ff" + "80")
+	buf, err := FromCBOR(data)
+	require.NoError(t, err)
+
+	decoded, err := Decode(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int16(1000), float32(1), []any(nil), "abc", []any{int8(1)}, []any(nil)}, decoded)
+
+	_, err = FromCBOR([]byte{0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	assert.Error(t, err, "uint64 beyond int64")
+	_, err = FromCBOR([]byte{0xa1, 0x01, 0x02})
+	assert.Error(t, err, "non-text map key")
+	_, err = FromCBOR([]byte{0x62, 0x61})
+	assert.Error(t, err, "truncated")
+}
@@ -0,0 +1,202 @@
+package access
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Compressed containers.
+//
+// BeginCompressedMap and BeginCompressedTuple start a nested container whose
+// packed body is compressed when it is closed with EndNested. The body is
+// replaced by compressedMarker, followed by the compressor ID, the
+// uncompressed length as a uvarint and the compressed bytes:
+//
+//	02 00 | id | len | compressed body
+//
+// The field keeps its Map or Tuple tag, so PeekNestedSeq, and with it
+// DecodeMapAny, Decode, ToJSON and schema decoding, decompress transparently.
+// GetAccess and the other random-access helpers see the compressed bytes.
+// Bodies that do not shrink are written uncompressed.
+
+// Compressor compresses container bodies. Its ID is written after the
+// marker so readers can pick the same compressor from the registry.
+type Compressor interface {
+	ID() byte
+	Compress(src []byte) ([]byte, error)
+	Decompress(src []byte, size int) ([]byte, error)
+}
+
+const (
+	// CompressFlate is the ID of FlateCompressor.
+	CompressFlate byte = 1
+	// CompressZstd and CompressSnappy are reserved for zstd and snappy
+	// compressors registered by the application.
+	CompressZstd   byte = 2
+	CompressSnappy byte = 3
+)
+
+// MaxDecompressedSize bounds the uncompressed length a container may
+// declare, so a short payload cannot claim a huge allocation.
+var MaxDecompressedSize = 8 << 20
+
+// MaxCompressionRatio bounds the declared uncompressed length relative to
+// the compressed bytes. DEFLATE cannot exceed about 1032:1; raise it for a
+// registered compressor that can.
+var MaxCompressionRatio = 1032
+
+// compressedMarker opens a compressed body. A packed list never starts with
+// an offset-0 header, so such headers are escapes told apart by their low
+// three bits: 0 for an interned string dictionary, 2 here. Those bits are
+// not a type tag in this position.
+const compressedMarker uint16 = 0<<3 | 2
+
+var errCompressed = errors.New("invalid compressed container")
+
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[byte]Compressor{CompressFlate: FlateCompressor{}}
+)
+
+// RegisterCompressor makes c available for decompression under c.ID().
+// Panics if the ID is already registered.
+func RegisterCompressor(c Compressor) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	if _, exists := compressors[c.ID()]; exists {
+		panic(fmt.Sprintf("compressor already registered: %d", c.ID()))
+	}
+	compressors[c.ID()] = c
+}
+
+// UnregisterCompressor removes a compressor added with RegisterCompressor.
+func UnregisterCompressor(id byte) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	delete(compressors, id)
+}
+
+func lookupCompressor(id byte) (Compressor, error) {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+	if c, ok := compressors[id]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("%w: unknown compressor %d", errCompressed, id)
+}
+
+// FlateCompressor compresses with DEFLATE at the given level; the zero
+// value uses flate.DefaultCompression.
+type FlateCompressor struct {
+	Level int
+}
+
+func (FlateCompressor) ID() byte { return CompressFlate }
+
+func (f FlateCompressor) Compress(src []byte) ([]byte, error) {
+	level := f.Level
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+	var out bytes.Buffer
+	w, err := flate.NewWriter(&out, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// Decompress grows its output as the stream inflates rather than trusting
+// size up front, and stops one byte past it.
+func (FlateCompressor) Decompress(src []byte, size int) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(src))
+	defer r.Close()
+	var out bytes.Buffer
+	if _, err := out.ReadFrom(io.LimitReader(r, int64(size)+1)); err != nil {
+		return nil, err
+	}
+	// the stream must end exactly at the declared size
+	if out.Len() > size {
+		return nil, errors.New("flate: data beyond declared size")
+	}
+	return out.Bytes(), nil
+}
+
+// BeginCompressedMap starts a map whose body is compressed with c when
+// passed to EndNested.
+func (p *PutAccess) BeginCompressedMap(c Compressor) *PutAccess {
+	nested := p.BeginMap()
+	nested.compressor = c
+	return nested
+}
+
+// BeginCompressedTuple starts a tuple whose body is compressed with c when
+// passed to EndNested.
+func (p *PutAccess) BeginCompressedTuple(c Compressor) *PutAccess {
+	nested := p.BeginTuple()
+	nested.compressor = c
+	return nested
+}
+
+// appendCompressed appends nested's packed body, compressed when that
+// makes it smaller. A compressor error also leaves the body uncompressed.
+func (p *PutAccess) appendCompressed(nested *PutAccess) {
+	body := nested.PackAppend(nil)
+	packed, err := nested.compressor.Compress(body)
+	if err != nil {
+		p.buf = append(p.buf, body...)
+		return
+	}
+	marker := binary.LittleEndian.AppendUint16(nil, compressedMarker)
+	marker = append(marker, nested.compressor.ID())
+	marker = binary.AppendUvarint(marker, uint64(len(body)))
+	if len(marker)+len(packed) >= len(body) {
+		p.buf = append(p.buf, body...)
+		return
+	}
+	p.buf = append(append(p.buf, marker...), packed...)
+}
+
+// IsCompressed reports whether a container body is compressed.
+func IsCompressed(body []byte) bool {
+	return len(body) >= 3 && binary.LittleEndian.Uint16(body) == compressedMarker
+}
+
+// decompressBody returns the packed list stored in a compressed body. A
+// positive limit lowers MaxDecompressedSize.
+func decompressBody(body []byte, limit int) ([]byte, error) {
+	c, err := lookupCompressor(body[2])
+	if err != nil {
+		return nil, err
+	}
+	size, k := binary.Uvarint(body[3:])
+	if k <= 0 || size > uint64(MaxDecompressedSize) {
+		return nil, fmt.Errorf("%w: bad length", errCompressed)
+	}
+	src := body[3+k:]
+	if size > uint64(len(src))*uint64(MaxCompressionRatio) {
+		return nil, fmt.Errorf("%w: %d bytes cannot inflate to %d", errCompressed, len(src), size)
+	}
+	if limit > 0 && size > uint64(limit) {
+		return nil, fmt.Errorf("%w: %d decompressed bytes, at most %d left", ErrDecodeLimit, size, limit)
+	}
+	out, err := c.Decompress(src, int(size))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errCompressed, err)
+	}
+	if len(out) != int(size) {
+		return nil, fmt.Errorf("%w: got %d bytes, want %d", errCompressed, len(out), size)
+	}
+	return out, nil
+}
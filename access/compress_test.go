@@ -0,0 +1,185 @@
+package access
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func packLogs(t *testing.T, put *PutAccess, tup *PutAccess) {
+	for _, rec := range logRecords() {
+		require.NoError(t, tup.AddAny(rec, false))
+	}
+	put.EndNested(tup)
+	put.AddString("tail")
+}
+
+func TestCompressed_TupleRoundTrip(t *testing.T) {
+	plain := NewPutAccess()
+	plain.SetDeterministic(true)
+	packLogs(t, plain, plain.BeginTuple())
+	plainBuf := plain.Pack()
+
+	put := NewPutAccess()
+	put.SetDeterministic(true)
+	packLogs(t, put, put.BeginCompressedTuple(FlateCompressor{}))
+	buf := put.Pack()
+	assert.Less(t, len(buf), len(plainBuf)/2)
+
+	seq, err := NewSeqGetAccess(buf)
+	require.NoError(t, err)
+	_, width, err := seq.PeekTypeWidth()
+	require.NoError(t, err)
+	body, err := seq.GetPayload(width)
+	require.NoError(t, err)
+	assert.True(t, IsCompressed(body))
+
+	want, err := Decode(plainBuf)
+	require.NoError(t, err)
+	got, err := Decode(buf)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestCompressed_MapAndNesting(t *testing.T) {
+	put := NewPutAccess()
+	m := put.BeginCompressedMap(FlateCompressor{Level: 9})
+	for _, k := range []string{"alpha", "beta", "gamma"} {
+		m.AddString(k)
+		inner := m.BeginTuple()
+		for i := 0; i < 30; i++ {
+			inner.AddString("repeated value")
+		}
+		m.EndNested(inner)
+	}
+	put.EndNested(m)
+	buf := put.Pack()
+
+	seq, err := NewSeqGetAccess(buf)
+	require.NoError(t, err)
+	got, err := DecodeMapAny(seq)
+	require.NoError(t, err)
+	assert.Len(t, got, 3)
+	assert.Len(t, got["gamma"], 30)
+	assert.Equal(t, "repeated value", got["beta"].([]any)[29])
+}
+
+func TestCompressed_SmallBodyStaysPlain(t *testing.T) {
+	put := NewPutAccess()
+	tup := put.BeginCompressedTuple(FlateCompressor{})
+	tup.AddInt16(7)
+	put.EndNested(tup)
+	buf := put.Pack()
+
+	plain := NewPutAccess()
+	tup = plain.BeginTuple()
+	tup.AddInt16(7)
+	plain.EndNested(tup)
+	assert.Equal(t, plain.Pack(), buf)
+}
+
+type rleCompressor struct{}
+
+func (rleCompressor) ID() byte { return 200 }
+
+// Compress writes runs of equal bytes as (count, byte) pairs.
+func (rleCompressor) Compress(src []byte) ([]byte, error) {
+	var out []byte
+	for i := 0; i < len(src); {
+		j := i
+		for j < len(src) && src[j] == src[i] && j-i < 255 {
+			j++
+		}
+		out = append(out, byte(j-i), src[i])
+		i = j
+	}
+	return out, nil
+}
+
+func (rleCompressor) Decompress(src []byte, size int) ([]byte, error) {
+	out := make([]byte, 0, size)
+	for i := 0; i+1 < len(src); i += 2 {
+		for n := 0; n < int(src[i]); n++ {
+			out = append(out, src[i+1])
+		}
+	}
+	return out, nil
+}
+
+func TestCompressed_Registry(t *testing.T) {
+	put := NewPutAccess()
+	tup := put.BeginCompressedTuple(rleCompressor{})
+	tup.AddBytes(make([]byte, 500))
+	put.EndNested(tup)
+	buf := put.Pack()
+
+	_, err := Decode(buf)
+	assert.ErrorIs(t, err, errCompressed)
+
+	RegisterCompressor(rleCompressor{})
+	defer UnregisterCompressor(200)
+	assert.Panics(t, func() { RegisterCompressor(rleCompressor{}) })
+
+	got, err := Decode(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []any{string(make([]byte, 500))}, got)
+}
+
+func TestCompressed_RejectsBadLength(t *testing.T) {
+	put := NewPutAccess()
+	tup := put.BeginCompressedTuple(FlateCompressor{})
+	tup.AddBytes(make([]byte, 500))
+	put.EndNested(tup)
+	buf := put.Pack()
+
+	old := MaxDecompressedSize
+	MaxDecompressedSize = 100
+	defer func() { MaxDecompressedSize = old }()
+	_, err := Decode(buf)
+	assert.ErrorIs(t, err, errCompressed)
+}
+
+func TestCompressed_RejectsInflatedLength(t *testing.T) {
+	put := NewPutAccess()
+	tup := put.BeginCompressedTuple(FlateCompressor{})
+	tup.AddBytes(make([]byte, 500))
+	put.EndNested(tup)
+	buf := put.Pack()
+
+	// claim 16000 bytes, more than the few compressed bytes can hold,
+	// without changing the width of the uvarint
+	at := bytes.Index(buf, []byte{byte(compressedMarker), 0, CompressFlate}) + 3
+	require.True(t, buf[at]&0x80 != 0 && buf[at+1]&0x80 == 0)
+	forged := bytes.Clone(buf)
+	forged[at], forged[at+1] = 0x80|(16000&0x7F), 16000>>7
+	_, err := Decode(forged)
+	assert.ErrorIs(t, err, errCompressed)
+
+	// a stream shorter than its claim fails without allocating the claim
+	packed, err := FlateCompressor{}.Compress([]byte("short"))
+	require.NoError(t, err)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	out, err := FlateCompressor{}.Decompress(packed, MaxDecompressedSize)
+	runtime.ReadMemStats(&after)
+	require.NoError(t, err)
+	assert.Equal(t, "short", string(out))
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+
+	_, err = FlateCompressor{}.Decompress(packed, 3)
+	assert.Error(t, err)
+}
+
+func TestIsCompressed_SortedMapFirst(t *testing.T) {
+	put := NewPutAccess()
+	require.NoError(t, put.AddSortedMapAny(map[string]any{"a": int32(1)}, false))
+	buf := put.Pack()
+	assert.Equal(t, byte(compressedMarker), buf[0]&0x07, "first field carries tag 2")
+	assert.False(t, IsCompressed(buf))
+	v, err := Decode(buf)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": int32(1)}, v)
+}
@@ -0,0 +1,132 @@
+package access
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// Decimals are packed as a two-field tuple: the exponent as a compressed
+// integer, then the coefficient as a compressed integer when it fits int64,
+// or as big-endian two's complement bytes when it does not. Generic decoders
+// therefore see [exponent, coefficient].
+
+// AddDecimal packs d as an (exponent, coefficient) tuple.
+func (p *PutAccess) AddDecimal(d typetags.Decimal) {
+	nested := p.BeginTuple()
+	nested.AddIntegerCompressed(int64(d.Exp))
+	switch {
+	case d.Coef == nil:
+		nested.AddInt8(0)
+	case d.Coef.IsInt64():
+		nested.AddIntegerCompressed(d.Coef.Int64())
+	default:
+		nested.AddBytes(bigIntBytes(d.Coef))
+	}
+	p.EndNested(nested)
+}
+
+var errDecimal = errors.New("invalid decimal tuple")
+
+// DecodeDecimal decodes the payload of a decimal tuple as written by AddDecimal.
+func DecodeDecimal(payload []byte) (typetags.Decimal, error) {
+	seq, err := NewSeqGetAccess(payload)
+	if err != nil {
+		return typetags.Decimal{}, err
+	}
+	if seq.ArgCount() != 2 {
+		return typetags.Decimal{}, errDecimal
+	}
+	expPayload, typ, err := seq.Next()
+	if err != nil {
+		return typetags.Decimal{}, err
+	}
+	if typ != typetags.TypeInteger {
+		return typetags.Decimal{}, errDecimal
+	}
+	exp, err := decodeInt64(expPayload)
+	if err != nil || exp < math.MinInt32 || exp > math.MaxInt32 {
+		return typetags.Decimal{}, errDecimal
+	}
+	coefPayload, typ, err := seq.Next()
+	if err != nil {
+		return typetags.Decimal{}, err
+	}
+	var coef *big.Int
+	switch typ {
+	case typetags.TypeInteger:
+		v, err := decodeInt64(coefPayload)
+		if err != nil {
+			return typetags.Decimal{}, errDecimal
+		}
+		coef = big.NewInt(v)
+	case typetags.TypeString:
+		coef = bigIntFromBytes(coefPayload)
+	default:
+		return typetags.Decimal{}, errDecimal
+	}
+	return typetags.Decimal{Coef: coef, Exp: int32(exp)}, nil
+}
+
+// GetDecimal decodes a decimal at position pos
+func (g *GetAccess) GetDecimal(pos int) (typetags.Decimal, error) {
+	tp, start, end := g.rangeAt(pos)
+	if tp != typetags.TypeTuple || end <= start {
+		return typetags.Decimal{}, fmt.Errorf("GetDecimal: %w at pos %d", errDecimal, pos)
+	}
+	d, err := DecodeDecimal(g.buf[start:end])
+	if err != nil {
+		return typetags.Decimal{}, fmt.Errorf("GetDecimal: %w at pos %d", err, pos)
+	}
+	return d, nil
+}
+
+// decodeInt64 decodes an integer payload of any width.
+func decodeInt64(payload []byte) (int64, error) {
+	v, err := DecodePrimitive(typetags.TypeInteger, payload)
+	if err != nil {
+		return 0, err
+	}
+	switch n := v.(type) {
+	case int8:
+		return int64(n), nil
+	case int16:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	}
+	return 0, errDecimal
+}
+
+// bigIntBytes returns the minimal big-endian two's complement form of x.
+func bigIntBytes(x *big.Int) []byte {
+	if x.Sign() >= 0 {
+		b := x.Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			b = append([]byte{0}, b...)
+		}
+		return b
+	}
+	// -x - 1 has the same bits as x, inverted
+	m := new(big.Int).Not(x).Bytes()
+	for i := range m {
+		m[i] = ^m[i]
+	}
+	if len(m) == 0 || m[0]&0x80 == 0 {
+		m = append([]byte{0xff}, m...)
+	}
+	return m
+}
+
+func bigIntFromBytes(b []byte) *big.Int {
+	x := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		x.Sub(x, new(big.Int).Lsh(big.NewInt(1), uint(len(b))*8))
+	}
+	return x
+}
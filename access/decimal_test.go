@@ -0,0 +1,61 @@
+package access
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddDecimal_RoundTrip(t *testing.T) {
+	huge, _ := new(big.Int).SetString("-340282366920938463463374607431768211456", 10)
+	values := []typetags.Decimal{
+		typetags.NewDecimalInt64(1999, -2),
+		typetags.NewDecimalInt64(-5, 3),
+		typetags.NewDecimal(huge, -10),
+		typetags.NewDecimal(new(big.Int).Lsh(big.NewInt(1), 63), 0), // just beyond int64
+		typetags.NewDecimal(nil, 0),
+	}
+	put := NewPutAccess()
+	for _, d := range values {
+		put.AddDecimal(d)
+	}
+	buf := put.Pack()
+
+	get := NewGetAccess(buf)
+	for i, want := range values {
+		got, err := get.GetDecimal(i)
+		require.NoError(t, err)
+		assert.Equal(t, want.String(), got.String())
+		assert.Equal(t, want.Exp, got.Exp)
+	}
+
+	// generic readers see the (exponent, coefficient) tuple
+	decoded, err := Decode(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int8(-2), int16(1999)}, decoded.([]any)[0])
+}
+
+func TestBigIntBytes(t *testing.T) {
+	for _, s := range []string{"0", "127", "128", "-128", "-129", "255", "-256", "9223372036854775808", "-9223372036854775809"} {
+		x, _ := new(big.Int).SetString(s, 10)
+		b := bigIntBytes(x)
+		assert.Equal(t, s, bigIntFromBytes(b).String(), s)
+	}
+	assert.Equal(t, []byte{0x00, 0x80}, bigIntBytes(big.NewInt(128)))
+	assert.Equal(t, []byte{0x80}, bigIntBytes(big.NewInt(-128)))
+	assert.Equal(t, []byte{0xff, 0x7f}, bigIntBytes(big.NewInt(-129)))
+}
+
+func TestGetDecimal_Errors(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt16(1)
+	require.NoError(t, put.AddAnyTuple([]any{"x", int8(1)}, false))
+	get := NewGetAccess(put.Pack())
+	_, err := get.GetDecimal(0)
+	assert.Error(t, err)
+	_, err = get.GetDecimal(1)
+	assert.Error(t, err)
+}
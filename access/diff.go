@@ -0,0 +1,354 @@
+package access
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// PatchOpKind selects what a PatchOp does to the field at its path.
+type PatchOpKind uint8
+
+const (
+	// PatchSet replaces the field at Path with Tag and Value. A missing last
+	// map key is inserted: after the other keys in a TypeMap, at its sorted
+	// position in a TypeSortedMap.
+	PatchSet PatchOpKind = iota + 1
+	// PatchDelete removes the map entry at Path.
+	PatchDelete
+)
+
+// PatchOp is one change of a Patch. Path follows Patcher.FieldPath; the
+// empty path stands for the whole buffer, whose new bytes are then Value.
+type PatchOp struct {
+	Kind  PatchOpKind
+	Path  string
+	Tag   typetags.Type
+	Value []byte
+}
+
+// Patch is an ordered list of changes that turns one packed buffer into
+// another. Values alias the buffer passed to Diff or DecodePatch.
+type Patch []PatchOp
+
+// Diff compares two packed buffers and returns the changes that turn old
+// into new. Fields with equal bytes are skipped and containers of the same
+// type are compared field by field, so the patch only carries the payloads
+// that changed. A tuple whose length changed, or a map whose common keys
+// were reordered, is replaced as a whole. Apply(old, patch) returns new.
+func Diff(old, new []byte) (Patch, error) {
+	if bytes.Equal(old, new) {
+		return nil, nil
+	}
+	var p Patch
+	if IsInterned(old) || IsInterned(new) {
+		return append(p, wholeBuffer(new)), nil
+	}
+	same, err := diffContainer(old, new, typetags.TypeTuple, "", &p, 0)
+	if err != nil {
+		return nil, fmt.Errorf("Diff: %w", err)
+	}
+	if !same {
+		p = append(p[:0], wholeBuffer(new))
+	}
+	return p, nil
+}
+
+func wholeBuffer(buf []byte) PatchOp {
+	return PatchOp{Kind: PatchSet, Tag: typetags.TypeTuple, Value: buf}
+}
+
+// diffContainer appends the ops turning the container body old into new.
+// It returns false, with ops possibly appended, when the container has to
+// be replaced as a whole instead.
+func diffContainer(old, new []byte, tag typetags.Type, prefix string, p *Patch, depth int) (bool, error) {
+	if depth > maxNestingDepth {
+		return false, fmt.Errorf("nesting too deep")
+	}
+	of, err := rawFields(old)
+	if err != nil {
+		return false, err
+	}
+	nf, err := rawFields(new)
+	if err != nil {
+		return false, err
+	}
+	if !tag.IsMap() {
+		if len(of) != len(nf) {
+			return false, nil
+		}
+		for i := range of {
+			if err := diffField(of[i], nf[i], joinPath(prefix, fmt.Sprint(i)), p, depth); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	oldKeys, ok := mapKeys(of)
+	if !ok {
+		return false, nil
+	}
+	newKeys, ok := mapKeys(nf)
+	if !ok {
+		return false, nil
+	}
+	oldPos := make(map[string]int, len(oldKeys))
+	for i, k := range oldKeys {
+		oldPos[k] = i
+	}
+	newPos := make(map[string]int, len(newKeys))
+	for i, k := range newKeys {
+		newPos[k] = i
+	}
+	// Apply keeps the common keys in place and inserts new ones after them,
+	// or in key order for sorted maps; any other layout is not reachable.
+	last, inserted := -1, false
+	for _, k := range newKeys {
+		i, common := oldPos[k]
+		switch {
+		case !common:
+			inserted = true
+		case inserted && tag != typetags.TypeSortedMap, i < last:
+			return false, nil
+		default:
+			last = i
+		}
+	}
+	for _, k := range oldKeys {
+		if _, ok := newPos[k]; !ok {
+			*p = append(*p, PatchOp{Kind: PatchDelete, Path: joinPath(prefix, k)})
+		}
+	}
+	for _, k := range newKeys {
+		nv := nf[2*newPos[k]+1]
+		if i, ok := oldPos[k]; ok {
+			if err := diffField(of[2*i+1], nv, joinPath(prefix, k), p, depth); err != nil {
+				return false, err
+			}
+			continue
+		}
+		*p = append(*p, PatchOp{Kind: PatchSet, Path: joinPath(prefix, k), Tag: nv.tag, Value: nv.value})
+	}
+	return true, nil
+}
+
+// diffField appends the ops turning the field o into n.
+func diffField(o, n rawField, path string, p *Patch, depth int) error {
+	if o.tag == n.tag && bytes.Equal(o.value, n.value) {
+		return nil
+	}
+	if o.tag == n.tag && (n.tag.IsMap() || n.tag == typetags.TypeTuple) &&
+		len(o.value) > 0 && len(n.value) > 0 && !IsCompressed(o.value) && !IsCompressed(n.value) {
+		mark := len(*p)
+		same, err := diffContainer(o.value, n.value, n.tag, path, p, depth+1)
+		if err != nil {
+			return err
+		}
+		if same {
+			return nil
+		}
+		*p = (*p)[:mark]
+	}
+	*p = append(*p, PatchOp{Kind: PatchSet, Path: path, Tag: n.tag, Value: n.value})
+	return nil
+}
+
+// mapKeys returns the keys of a map's fields. It reports false when a key
+// cannot be addressed by a path: not a string, repeated or containing a dot.
+func mapKeys(fields []rawField) ([]string, bool) {
+	if len(fields)%2 != 0 {
+		return nil, false
+	}
+	keys := make([]string, 0, len(fields)/2)
+	seen := make(map[string]struct{}, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		if fields[i].tag != typetags.TypeString {
+			return nil, false
+		}
+		k := string(fields[i].value)
+		if _, dup := seen[k]; dup || k == "" || strings.Contains(k, ".") {
+			return nil, false
+		}
+		seen[k] = struct{}{}
+		keys = append(keys, k)
+	}
+	return keys, true
+}
+
+func joinPath(prefix, seg string) string {
+	if prefix == "" {
+		return seg
+	}
+	return prefix + "." + seg
+}
+
+// Apply returns a copy of old with the changes of p applied in order.
+func Apply(old []byte, p Patch) ([]byte, error) {
+	buf := bytes.Clone(old)
+	for _, op := range p {
+		if op.Kind != PatchSet && op.Kind != PatchDelete {
+			return nil, fmt.Errorf("Apply: path %q: unknown op %d", op.Path, op.Kind)
+		}
+		if op.Path == "" {
+			if op.Kind != PatchSet {
+				return nil, fmt.Errorf("Apply: cannot delete the whole buffer")
+			}
+			buf = bytes.Clone(op.Value)
+			continue
+		}
+		out, err := applyOp(buf, typetags.TypeTuple, strings.Split(op.Path, "."), op, 0)
+		if err != nil {
+			return nil, fmt.Errorf("Apply: path %q: %w", op.Path, err)
+		}
+		buf = out
+	}
+	return buf, nil
+}
+
+// PathValue returns the tag and payload of the field at path, written as in
+// PatchOp, so that the old value of a changed field can be shown. The empty
+// path returns the whole buffer as a tuple.
+func PathValue(buf []byte, path string) (typetags.Type, []byte, error) {
+	if path == "" {
+		return typetags.TypeTuple, buf, nil
+	}
+	g := NewGetAccess(buf)
+	if g == nil {
+		return typetags.TypeInvalid, nil, fmt.Errorf("PathValue: malformed buffer")
+	}
+	tag := typetags.TypeTuple
+	segments := strings.Split(path, ".")
+	for i, seg := range segments {
+		pos, err := segmentPos(g, tag, seg)
+		if err != nil {
+			return typetags.TypeInvalid, nil, fmt.Errorf("PathValue: path %q: %w", path, err)
+		}
+		if i == len(segments)-1 {
+			tp, value := g.GetTypeAndValue(pos)
+			if tp == typetags.TypeInvalid {
+				return tp, nil, fmt.Errorf("PathValue: path %q: no field at %q", path, seg)
+			}
+			return tp, value, nil
+		}
+		nested, tp, err := g.GetNestedGetAccess(pos)
+		if err != nil {
+			return typetags.TypeInvalid, nil, fmt.Errorf("PathValue: path %q at %q: %w", path, seg, err)
+		}
+		if nested == nil {
+			return typetags.TypeInvalid, nil, fmt.Errorf("PathValue: path %q at %q: empty container", path, seg)
+		}
+		g, tag = nested, tp
+	}
+	return typetags.TypeInvalid, nil, nil
+}
+
+func applyOp(buf []byte, tag typetags.Type, segments []string, op PatchOp, depth int) ([]byte, error) {
+	if depth > maxNestingDepth {
+		return nil, fmt.Errorf("nesting too deep")
+	}
+	fields, err := rawFields(buf)
+	if err != nil {
+		return nil, err
+	}
+	seg := segments[0]
+	// pos stays -1 for a key missing from a map, which PatchSet inserts
+	pos := -1
+	if len(fields) > 0 {
+		found, err := segmentPos(NewGetAccess(buf), tag, seg)
+		switch {
+		case err == nil && (found < 0 || found >= len(fields)):
+			return nil, fmt.Errorf("segment %q: position %d out of range [0, %d)", seg, found, len(fields))
+		case err == nil:
+			pos = found
+		case !tag.IsMap() || len(segments) > 1:
+			return nil, err
+		}
+	}
+
+	if len(segments) == 1 {
+		value := rawField{tag: op.Tag, value: op.Value}
+		switch {
+		case op.Kind == PatchDelete && (!tag.IsMap() || pos < 0):
+			return nil, fmt.Errorf("segment %q: only existing map keys can be deleted", seg)
+		case op.Kind == PatchDelete:
+			fields = append(fields[:pos-1], fields[pos+1:]...)
+		case pos >= 0:
+			fields[pos] = value
+		case !tag.IsMap():
+			return nil, fmt.Errorf("segment %q: empty container", seg)
+		default:
+			at := len(fields)
+			if tag == typetags.TypeSortedMap {
+				at = 0
+				for at < len(fields) && string(fields[at].value) < seg {
+					at += 2
+				}
+			}
+			key := rawField{tag: typetags.TypeString, value: []byte(seg)}
+			fields = append(fields[:at], append([]rawField{key, value}, fields[at:]...)...)
+		}
+		return packRawFields(fields), nil
+	}
+
+	if pos < 0 {
+		return nil, fmt.Errorf("segment %q: empty container", seg)
+	}
+	f := fields[pos]
+	if !f.tag.IsMap() && f.tag != typetags.TypeTuple {
+		return nil, fmt.Errorf("segment %q: %v is not a container", seg, f.tag)
+	}
+	nested, err := applyOp(f.value, f.tag, segments[1:], op, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	fields[pos].value = nested
+	return packRawFields(fields), nil
+}
+
+// Encode packs p for sending: three fields per op, the kind as a uint8,
+// the path as a string and the value under its own tag.
+func (p Patch) Encode() []byte {
+	put := NewPutAccessFromPool()
+	defer ReleasePutAccess(put)
+	for _, op := range p {
+		put.AddUint8(uint8(op.Kind))
+		put.AddString(op.Path)
+		if op.Kind == PatchDelete {
+			put.AppendTagAndValue(typetags.TypeByteArray, nil)
+			continue
+		}
+		put.AppendTagAndValue(op.Tag, op.Value)
+	}
+	return put.Pack()
+}
+
+// DecodePatch reads a patch written by Patch.Encode.
+func DecodePatch(buf []byte) (Patch, error) {
+	fields, err := rawFields(buf)
+	if err != nil {
+		return nil, fmt.Errorf("DecodePatch: %w", err)
+	}
+	if len(fields)%3 != 0 {
+		return nil, fmt.Errorf("DecodePatch: %d fields is not a multiple of 3", len(fields))
+	}
+	p := make(Patch, 0, len(fields)/3)
+	for i := 0; i < len(fields); i += 3 {
+		kind, path := fields[i], fields[i+1]
+		if kind.tag != typetags.TypeInteger || len(kind.value) != 1 || path.tag != typetags.TypeString {
+			return nil, fmt.Errorf("DecodePatch: op %d: malformed kind or path", i/3)
+		}
+		op := PatchOp{Kind: PatchOpKind(kind.value[0]), Path: string(path.value)}
+		switch op.Kind {
+		case PatchSet:
+			op.Tag, op.Value = fields[i+2].tag, fields[i+2].value
+		case PatchDelete:
+		default:
+			return nil, fmt.Errorf("DecodePatch: op %d: unknown kind %d", i/3, op.Kind)
+		}
+		p = append(p, op)
+	}
+	return p, nil
+}
@@ -0,0 +1,149 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func packDoc(t *testing.T, id int64, profile map[string]any, tags []any) []byte {
+	put := NewPutAccess()
+	put.SetDeterministic(true)
+	put.AddInt64(id)
+	require.NoError(t, put.AddMapAny(profile, false))
+	require.NoError(t, put.AddAnyTuple(tags, false))
+	return put.Pack()
+}
+
+func TestDiff_NestedChanges(t *testing.T) {
+	old := packDoc(t, 7,
+		map[string]any{"name": "ann", "city": "oslo", "age": int16(30), "addr": map[string]any{"zip": "0150", "street": "main"}},
+		[]any{"a", "b"})
+	cur := packDoc(t, 7,
+		map[string]any{"name": "ann", "city": "bergen", "age": int16(30), "addr": map[string]any{"zip": "5003", "street": "main"}},
+		[]any{"a", "b"})
+
+	p, err := Diff(old, cur)
+	require.NoError(t, err)
+	paths := make([]string, len(p))
+	for i, op := range p {
+		paths[i] = op.Path
+		assert.Equal(t, PatchSet, op.Kind)
+	}
+	assert.ElementsMatch(t, []string{"1.addr.zip", "1.city"}, paths)
+
+	out, err := Apply(old, p)
+	require.NoError(t, err)
+	assert.Equal(t, cur, out)
+
+	none, err := Diff(old, old)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestPathValue(t *testing.T) {
+	buf := packDoc(t, 7, map[string]any{"addr": map[string]any{"zip": "0150"}}, []any{"a", int8(2)})
+
+	tag, value, err := PathValue(buf, "1.addr.zip")
+	require.NoError(t, err)
+	assert.Equal(t, typetags.TypeString, tag)
+	assert.Equal(t, "0150", string(value))
+
+	tag, value, err = PathValue(buf, "2.1")
+	require.NoError(t, err)
+	assert.Equal(t, typetags.TypeInteger, tag)
+	assert.Equal(t, []byte{2}, value)
+
+	_, _, err = PathValue(buf, "1.addr.city")
+	assert.Error(t, err)
+	_, _, err = PathValue(buf, "5")
+	assert.Error(t, err)
+	_, _, err = PathValue(buf, "0.x")
+	assert.Error(t, err)
+}
+
+func TestDiff_MapKeysAndTupleLength(t *testing.T) {
+	old := packDoc(t, 1, map[string]any{"a": "x", "b": "y"}, []any{"a"})
+	cur := packDoc(t, 1, map[string]any{"a": "x", "c": "z"}, []any{"a", "b"})
+
+	p, err := Diff(old, cur)
+	require.NoError(t, err)
+	require.Len(t, p, 3)
+	assert.Equal(t, PatchOp{Kind: PatchDelete, Path: "1.b"}, p[0])
+	assert.Equal(t, PatchSet, p[1].Kind)
+	assert.Equal(t, "1.c", p[1].Path)
+	assert.Equal(t, "2", p[2].Path, "a tuple that grew is replaced")
+	assert.Equal(t, typetags.TypeTuple, p[2].Tag)
+
+	out, err := Apply(old, p)
+	require.NoError(t, err)
+	assert.Equal(t, cur, out)
+}
+
+func TestDiff_SortedMapInsert(t *testing.T) {
+	pack := func(m map[string]any) []byte {
+		put := NewPutAccess()
+		require.NoError(t, put.AddSortedMapAny(m, false))
+		return put.Pack()
+	}
+	old := pack(map[string]any{"a": "1", "c": "3"})
+	cur := pack(map[string]any{"a": "1", "b": "2", "c": "3"})
+
+	p, err := Diff(old, cur)
+	require.NoError(t, err)
+	require.Len(t, p, 1)
+	assert.Equal(t, "0.b", p[0].Path)
+
+	out, err := Apply(old, p)
+	require.NoError(t, err)
+	assert.Equal(t, cur, out)
+}
+
+func TestDiff_WholeBuffer(t *testing.T) {
+	old := packInts(1, 2)
+	cur := packInts(1, 2, 3)
+
+	p, err := Diff(old, cur)
+	require.NoError(t, err)
+	require.Len(t, p, 1)
+	assert.Equal(t, "", p[0].Path)
+
+	out, err := Apply(old, p)
+	require.NoError(t, err)
+	assert.Equal(t, cur, out)
+}
+
+func TestPatch_EncodeDecode(t *testing.T) {
+	old := packDoc(t, 1, map[string]any{"a": "x", "b": "y"}, []any{"a"})
+	cur := packDoc(t, 2, map[string]any{"a": "w", "d": int16(4)}, []any{"a"})
+
+	p, err := Diff(old, cur)
+	require.NoError(t, err)
+	wire := p.Encode()
+	assert.Less(t, len(wire), len(cur)+len(old))
+
+	decoded, err := DecodePatch(wire)
+	require.NoError(t, err)
+	assert.Equal(t, len(p), len(decoded))
+	out, err := Apply(old, decoded)
+	require.NoError(t, err)
+	assert.Equal(t, cur, out)
+
+	_, err = DecodePatch(packInts(1, 2))
+	assert.Error(t, err)
+}
+
+func TestApply_Errors(t *testing.T) {
+	buf := packDoc(t, 1, map[string]any{"a": "x"}, []any{"a"})
+
+	_, err := Apply(buf, Patch{{Kind: PatchDelete, Path: "1.missing"}})
+	assert.Error(t, err)
+	_, err = Apply(buf, Patch{{Kind: PatchDelete, Path: "0"}})
+	assert.Error(t, err)
+	_, err = Apply(buf, Patch{{Kind: PatchSet, Path: "9", Tag: typetags.TypeString, Value: []byte("v")}})
+	assert.Error(t, err)
+	_, err = Apply(buf, Patch{{Kind: 9, Path: "0"}})
+	assert.Error(t, err)
+}
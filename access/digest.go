@@ -0,0 +1,94 @@
+package access
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// Packed digests.
+//
+// With SetDigest, a PutAccess feeds its payload to a hash as fields are
+// added, so PackWithDigest returns the buffer and its digest without reading
+// the payload again. The headers, and the string dictionary of an interned
+// buffer, are only known at Pack, and they precede the payload, so the
+// digest is not the plain hash of the buffer but
+//
+//	H(prefix || H(payload))
+//
+// where prefix is everything before the payload. Digest computes the same
+// value from a packed buffer, for checking a signature or a dedup key.
+
+var errDigestLayout = errors.New("digest: invalid packed buffer")
+
+// SetDigest makes p hash its payload with hashes from newHash, such as
+// sha256.New or an xxhash constructor, or stops hashing when newHash is nil.
+// Enable it before adding fields; bytes already written are hashed at once.
+// Nested containers are hashed when they are closed into p.
+func (p *PutAccess) SetDigest(newHash func() hash.Hash) {
+	p.newHash, p.digest, p.hashed = newHash, nil, 0
+	if newHash != nil {
+		p.digest = newHash()
+		p.feedDigest()
+	}
+}
+
+// advance moves the write position to the end of the payload, hashing the
+// new bytes when p has a digest.
+func (p *PutAccess) advance() {
+	p.position = len(p.buf)
+	if p.digest != nil {
+		p.feedDigest()
+	}
+}
+
+func (p *PutAccess) feedDigest() {
+	p.digest.Write(p.buf[p.hashed:])
+	p.hashed = len(p.buf)
+}
+
+// PackWithDigest packs like Pack and returns the digest of the result, as
+// Digest would compute it. Without SetDigest it hashes with sha256 and the
+// payload is read once more.
+func (p *PutAccess) PackWithDigest() (buf, digest []byte) {
+	if p.digest == nil {
+		p.SetDigest(sha256.New)
+	}
+	p.feedDigest()
+	payloadSum := p.digest.Sum(nil)
+	buf = p.Pack()
+	return buf, digestOf(p.newHash, buf[:len(buf)-len(p.buf)], payloadSum)
+}
+
+// Digest returns the digest PackWithDigest gives for buf with the same hash.
+func Digest(buf []byte, newHash func() hash.Hash) ([]byte, error) {
+	prefix := 0
+	if IsInterned(buf) {
+		n, k := binary.Uvarint(buf[2:])
+		if k <= 0 || n > uint64(len(buf)-2-k) {
+			return nil, errDigestLayout
+		}
+		prefix = 2 + k + int(n)
+	}
+	if len(buf)-prefix < 2 {
+		return nil, errDigestLayout
+	}
+	base, _ := typetags.DecodeHeader(binary.LittleEndian.Uint16(buf[prefix:]))
+	prefix += base
+	if base < 2 || prefix > len(buf) {
+		return nil, errDigestLayout
+	}
+	h := newHash()
+	h.Write(buf[prefix:])
+	return digestOf(newHash, buf[:prefix], h.Sum(nil)), nil
+}
+
+func digestOf(newHash func() hash.Hash, prefix, payloadSum []byte) []byte {
+	h := newHash()
+	h.Write(prefix)
+	h.Write(payloadSum)
+	return h.Sum(nil)
+}
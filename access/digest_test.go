@@ -0,0 +1,77 @@
+package access
+
+import (
+	"crypto/sha256"
+	"hash"
+	"hash/fnv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func digestRecord(p *PutAccess) {
+	p.SetDeterministic(true)
+	p.AddInt32(7)
+	p.AddString("payments-api")
+	nested := p.BeginTuple()
+	nested.AddFloat64(1.5)
+	nested.AddBool(true)
+	p.EndNested(nested)
+	if err := p.AddAnyTuple(logRecords(), false); err != nil {
+		panic(err)
+	}
+}
+
+func TestPackWithDigest(t *testing.T) {
+	p := NewPutAccess()
+	p.SetDigest(sha256.New)
+	digestRecord(p)
+	buf, digest := p.PackWithDigest()
+
+	plain := NewPutAccess()
+	digestRecord(plain)
+	assert.Equal(t, plain.Pack(), buf)
+
+	// H(headers || H(payload))
+	base := len(buf) - len(p.buf)
+	payloadSum := sha256.Sum256(buf[base:])
+	want := sha256.Sum256(append(append([]byte{}, buf[:base]...), payloadSum[:]...))
+	assert.Equal(t, want[:], digest)
+
+	got, err := Digest(buf, sha256.New)
+	require.NoError(t, err)
+	assert.Equal(t, digest, got)
+
+	// without SetDigest the payload is hashed at pack
+	late := NewPutAccess()
+	digestRecord(late)
+	_, lateDigest := late.PackWithDigest()
+	assert.Equal(t, digest, lateDigest)
+
+	changed := NewPutAccess()
+	changed.SetDigest(sha256.New)
+	changed.AddInt32(8)
+	_, other := changed.PackWithDigest()
+	assert.NotEqual(t, digest, other)
+}
+
+func TestPackWithDigest_InternedAndOtherHash(t *testing.T) {
+	newHash := func() hash.Hash { return fnv.New64a() }
+	p := NewPutAccess()
+	p.SetInterning(true)
+	p.SetDigest(newHash)
+	require.NoError(t, p.AddAnyTuple(logRecords(), false))
+	buf, digest := p.PackWithDigest()
+	require.True(t, IsInterned(buf))
+	assert.Len(t, digest, 8)
+
+	got, err := Digest(buf, newHash)
+	require.NoError(t, err)
+	assert.Equal(t, digest, got)
+
+	_, err = Digest([]byte{0, 0, 9}, newHash)
+	assert.Error(t, err)
+	_, err = Digest([]byte{1}, newHash)
+	assert.Error(t, err)
+}
@@ -0,0 +1,117 @@
+package access
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Difference is a mismatched node found by DeepCompare. Path follows
+// Patcher.FieldPath; A and B are the decoded values of the node in each
+// buffer, nil where it is missing.
+type Difference struct {
+	Path   string
+	A, B   any
+	Reason string // "value", "type", "missing in a" or "missing in b"
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %v != %v (%s)", d.Path, d.A, d.B, d.Reason)
+}
+
+// Equal reports whether two packed buffers hold the same values. See
+// DeepCompare.
+func Equal(a, b []byte) bool {
+	return bytes.Equal(a, b) || len(DeepCompare(a, b)) == 0
+}
+
+// DeepCompare compares two packed buffers value by value and lists every
+// node that differs, for test assertions and debugging. Map entries are
+// matched by key, so key order does not matter, and TypeMap and
+// TypeSortedMap compare alike. Interned strings and compressed containers
+// are resolved first. Values keep their packed width: an int16 and an int32
+// holding the same number differ. A buffer that does not decode is
+// reported as a single difference at the empty path.
+func DeepCompare(a, b []byte) []Difference {
+	va, err := decodeRoot(a)
+	if err != nil {
+		return []Difference{{A: a, B: b, Reason: "decode a: " + err.Error()}}
+	}
+	vb, err := decodeRoot(b)
+	if err != nil {
+		return []Difference{{A: a, B: b, Reason: "decode b: " + err.Error()}}
+	}
+	var diffs []Difference
+	compareValues("", va, vb, &diffs)
+	return diffs
+}
+
+// decodeRoot decodes the top-level fields of buf, also when there is only
+// one.
+func decodeRoot(buf []byte) ([]any, error) {
+	seq, err := NewSeqGetAccess(buf)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeTupleGeneric(seq, true, false)
+}
+
+func compareValues(path string, a, b any, diffs *[]Difference) {
+	switch av := a.(type) {
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < max(len(av), len(bv)); i++ {
+			p := joinPath(path, strconv.Itoa(i))
+			switch {
+			case i >= len(av):
+				*diffs = append(*diffs, Difference{Path: p, B: bv[i], Reason: "missing in a"})
+			case i >= len(bv):
+				*diffs = append(*diffs, Difference{Path: p, A: av[i], Reason: "missing in b"})
+			default:
+				compareValues(p, av[i], bv[i], diffs)
+			}
+		}
+		return
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(av)+len(bv))
+		for k := range av {
+			keys = append(keys, k)
+		}
+		for k := range bv {
+			if _, ok := av[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := joinPath(path, k)
+			x, inA := av[k]
+			y, inB := bv[k]
+			switch {
+			case !inA:
+				*diffs = append(*diffs, Difference{Path: p, B: y, Reason: "missing in a"})
+			case !inB:
+				*diffs = append(*diffs, Difference{Path: p, A: x, Reason: "missing in b"})
+			default:
+				compareValues(p, x, y, diffs)
+			}
+		}
+		return
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		*diffs = append(*diffs, Difference{Path: path, A: a, B: b, Reason: "type"})
+		return
+	}
+	if !reflect.DeepEqual(a, b) {
+		*diffs = append(*diffs, Difference{Path: path, A: a, B: b, Reason: "value"})
+	}
+}
@@ -0,0 +1,51 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEqual_MapOrderAndKind(t *testing.T) {
+	a := NewPutAccess()
+	a.AddInt16(1)
+	require.NoError(t, a.AddMapAnyOrdered(typetags.NewOrderedMapAny(
+		typetags.OPAny("x", "1"), typetags.OPAny("y", []any{int8(2), "z"})), false))
+
+	b := NewPutAccess()
+	b.AddInt16(1)
+	require.NoError(t, b.AddSortedMapAny(map[string]any{"y": []any{int8(2), "z"}, "x": "1"}, false))
+
+	bufA, bufB := a.Pack(), b.Pack()
+	assert.NotEqual(t, bufA, bufB)
+	assert.True(t, Equal(bufA, bufB))
+	assert.Empty(t, DeepCompare(bufA, bufB))
+
+	interned := NewPutAccess()
+	interned.SetInterning(true)
+	interned.AddInt16(1)
+	require.NoError(t, interned.AddMapAny(map[string]any{"x": "1", "y": []any{int8(2), "z"}}, false))
+	assert.True(t, Equal(bufA, interned.Pack()))
+}
+
+func TestDeepCompare_Differences(t *testing.T) {
+	a := packDoc(t, 1, map[string]any{"name": "ann", "age": int16(30), "old": "x"}, []any{"a", "b"})
+	b := packDoc(t, 1, map[string]any{"name": "bob", "age": int32(30), "new": "y"}, []any{"a"})
+
+	diffs := DeepCompare(a, b)
+	assert.False(t, Equal(a, b))
+	assert.Equal(t, []Difference{
+		{Path: "1.age", A: int16(30), B: int32(30), Reason: "type"},
+		{Path: "1.name", A: "ann", B: "bob", Reason: "value"},
+		{Path: "1.new", B: "y", Reason: "missing in a"},
+		{Path: "1.old", A: "x", Reason: "missing in b"},
+		{Path: "2.1", A: "b", Reason: "missing in b"},
+	}, diffs)
+	assert.Equal(t, "1.name: ann != bob (value)", diffs[1].String())
+
+	diffs = DeepCompare(a, []byte{1})
+	require.Len(t, diffs, 1)
+	assert.Equal(t, "", diffs[0].Path)
+}
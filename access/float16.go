@@ -0,0 +1,128 @@
+package access
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// Two-byte floats.
+//
+// A floating payload of width 2 holds either an IEEE 754 half-precision float
+// (float16) or a bfloat16, the upper half of a float32. The header cannot tell
+// the two apart, so generic readers such as Decode, ToJSON and ToCBOR treat
+// width 2 as float16; bfloat16 fields must be read with GetBFloat16 or a
+// schema that expects them. Both decode to float32.
+
+// Float16bits converts f to IEEE 754 half precision, rounding to nearest even.
+// Values beyond the half range become infinity.
+func Float16bits(f float32) uint16 {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int32(b>>23) & 0xff
+	frac := b & 0x7fffff
+
+	switch {
+	case exp == 0xff:
+		if frac != 0 {
+			// keep NaN a NaN even when the payload bits are shifted out
+			return sign | 0x7e00 | uint16(frac>>13)
+		}
+		return sign | 0x7c00
+	case exp-127 > 15:
+		return sign | 0x7c00
+	case exp-127 >= -14:
+		// normal: rebias, then round the 13 dropped bits
+		h := uint32(exp-127+15)<<10 | frac>>13
+		return sign | uint16(roundNearestEven(h, frac, 13))
+	case exp-127 >= -25:
+		// subnormal: the implicit leading one becomes explicit
+		m := frac | 0x800000
+		shift := uint32(-exp + 127 - 14 + 13)
+		return sign | uint16(roundNearestEven(m>>shift, m, shift))
+	default:
+		return sign
+	}
+}
+
+// Float16frombits converts IEEE 754 half-precision bits to float32. The
+// conversion is exact.
+func Float16frombits(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h) & 0x3ff
+	switch exp {
+	case 0:
+		// zero or subnormal
+		f := float32(frac) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | frac<<13)
+	default:
+		return math.Float32frombits(sign | (exp+112)<<23 | frac<<13)
+	}
+}
+
+// BFloat16bits converts f to bfloat16, rounding to nearest even.
+func BFloat16bits(f float32) uint16 {
+	b := math.Float32bits(f)
+	if b&0x7fffffff > 0x7f800000 {
+		// NaN: truncating could clear every payload bit
+		return uint16(b>>16) | 0x40
+	}
+	return uint16(roundNearestEven(b>>16, b, 16))
+}
+
+// BFloat16frombits converts bfloat16 bits to float32. The conversion is exact.
+func BFloat16frombits(h uint16) float32 {
+	return math.Float32frombits(uint32(h) << 16)
+}
+
+// roundNearestEven rounds the truncated value v, whose dropped low bits are the
+// low shift bits of src, to nearest with ties to even. A carry out of the
+// mantissa correctly bumps the exponent.
+func roundNearestEven(v, src, shift uint32) uint32 {
+	half := uint32(1) << (shift - 1)
+	rem := src & (half<<1 - 1)
+	if rem > half || rem == half && v&1 == 1 {
+		v++
+	}
+	return v
+}
+
+// AddFloat16 packs v as an IEEE 754 half-precision float.
+func (p *PutAccess) AddFloat16(v float32) {
+	p.buf = binary.LittleEndian.AppendUint16(p.buf, Float16bits(v))
+	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeFloating))
+	p.advance()
+}
+
+// AddBFloat16 packs v as a bfloat16.
+func (p *PutAccess) AddBFloat16(v float32) {
+	p.buf = binary.LittleEndian.AppendUint16(p.buf, BFloat16bits(v))
+	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeFloating))
+	p.advance()
+}
+
+// GetFloat16 decodes a half-precision float at position pos
+func (g *GetAccess) GetFloat16(pos int) (float32, error) {
+	tp, start, end := g.rangeAt(pos)
+	if tp != typetags.TypeFloating || end-start != 2 {
+		return 0, errors.New("decode error")
+	}
+	return Float16frombits(binary.LittleEndian.Uint16(g.buf[start:end])), nil
+}
+
+// GetBFloat16 decodes a bfloat16 at position pos
+func (g *GetAccess) GetBFloat16(pos int) (float32, error) {
+	tp, start, end := g.rangeAt(pos)
+	if tp != typetags.TypeFloating || end-start != 2 {
+		return 0, errors.New("decode error")
+	}
+	return BFloat16frombits(binary.LittleEndian.Uint16(g.buf[start:end])), nil
+}
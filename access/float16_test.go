@@ -0,0 +1,83 @@
+package access
+
+import (
+	"math"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFloat16bits_KnownValues(t *testing.T) {
+	cases := []struct {
+		f    float32
+		bits uint16
+	}{
+		{0, 0x0000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.5, 0x3800},
+		{65504, 0x7bff}, // largest half
+		{65520, 0x7c00}, // rounds up to infinity
+		{float32(math.Inf(-1)), 0xfc00},
+		{6.103515625e-05, 0x0400},       // smallest normal
+		{5.960464477539063e-08, 0x0001}, // smallest subnormal
+		{1 + 1.0/2048, 0x3c00},          // tie rounds to even
+		{1 + 3.0/2048, 0x3c02},          // tie rounds to even
+		{1e-10, 0x0000},                 // underflow
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.bits, Float16bits(c.f), "%v", c.f)
+	}
+	assert.True(t, math.IsNaN(float64(Float16frombits(Float16bits(float32(math.NaN()))))))
+
+	// every finite half survives a round trip through float32
+	for h := 0; h < 1<<16; h++ {
+		if h&0x7c00 == 0x7c00 {
+			continue
+		}
+		require.Equal(t, uint16(h), Float16bits(Float16frombits(uint16(h))), "%#04x", h)
+	}
+}
+
+func TestBFloat16bits(t *testing.T) {
+	assert.Equal(t, uint16(0x3f80), BFloat16bits(1))
+	assert.Equal(t, uint16(0xc040), BFloat16bits(-3))
+	assert.Equal(t, float32(3.140625), BFloat16frombits(BFloat16bits(3.14159)))
+	assert.Equal(t, uint16(0x3f80), BFloat16bits(math.Float32frombits(0x3f808000)), "tie rounds to even")
+	assert.Equal(t, uint16(0x3f82), BFloat16bits(math.Float32frombits(0x3f818000)), "tie rounds to even")
+	assert.True(t, math.IsNaN(float64(BFloat16frombits(BFloat16bits(math.Float32frombits(0x7f800001))))))
+}
+
+func TestPutGetFloat16(t *testing.T) {
+	put := NewPutAccess()
+	put.AddFloat16(1.5)
+	put.AddBFloat16(-0.15625)
+	put.AddFloat32(1.5)
+	buf := put.Pack()
+	assert.Len(t, buf, 4*2+2+2+4)
+
+	get := NewGetAccess(buf)
+	v, err := get.GetFloat16(0)
+	require.NoError(t, err)
+	assert.Equal(t, float32(1.5), v)
+	v, err = get.GetBFloat16(1)
+	require.NoError(t, err)
+	assert.Equal(t, float32(-0.15625), v)
+	_, err = get.GetFloat16(2)
+	assert.Error(t, err, "float32 is not a float16")
+
+	// generic readers see width 2 as IEEE half precision
+	decoded, err := Decode(buf)
+	require.NoError(t, err)
+	assert.Equal(t, float32(1.5), decoded.([]any)[0])
+	f, size, err := get.GetFloating(0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, size)
+	assert.Equal(t, float32(1.5), f)
+
+	typ, payload := get.GetTypeAndValue(1)
+	assert.Equal(t, typetags.TypeFloating, typ)
+	assert.Len(t, payload, 2)
+}
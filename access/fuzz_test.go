@@ -0,0 +1,151 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+)
+
+// fuzzSeeds returns valid buffers covering every container kind, interning
+// and compression, for the fuzz corpora.
+func fuzzSeeds(t testing.TB) [][]byte {
+	var seeds [][]byte
+	put := NewPutAccess()
+	put.AddInt16(42)
+	put.AddBool(true)
+	put.AddString("go")
+	put.AddNullableInt32(nil)
+	seeds = append(seeds, put.Pack())
+
+	put = NewPutAccess()
+	put.SetDeterministic(true)
+	if err := put.AddMapAny(map[string]any{"k": "v", "n": []any{int8(1), 2.5, map[string]any{"x": "y"}}}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := put.AddSortedMapAny(map[string]any{"a": int64(1), "b": "c"}, false); err != nil {
+		t.Fatal(err)
+	}
+	seeds = append(seeds, put.Pack())
+
+	put = NewPutAccess()
+	put.SetInterning(true)
+	put.AddString("repeated")
+	put.AddString("repeated")
+	seeds = append(seeds, put.Pack())
+
+	put = NewPutAccess()
+	c := put.BeginCompressedTuple(FlateCompressor{})
+	for i := 0; i < 20; i++ {
+		c.AddString("compressible")
+	}
+	put.EndNested(c)
+	seeds = append(seeds, put.Pack())
+	return seeds
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, s := range fuzzSeeds(f) {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		seq, err := NewSeqGetAccess(buf)
+		if err != nil {
+			return
+		}
+		_, _ = DecodeTupleGeneric(seq, true, false)
+		_, _ = Decode(buf)
+		_, _ = DecodeOrdered(buf)
+	})
+}
+
+func FuzzDecodeMapAny(f *testing.F) {
+	for _, s := range fuzzSeeds(f) {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		seq, err := NewSeqGetAccess(buf)
+		if err != nil {
+			return
+		}
+		for i := 0; i < seq.ArgCount(); i++ {
+			if _, err := DecodeMapAny(seq); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func FuzzSeqGetAccess(f *testing.F) {
+	for _, s := range fuzzSeeds(f) {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		seq, err := NewSeqGetAccess(buf)
+		if err != nil {
+			return
+		}
+		walkSeq(seq, 0)
+	})
+}
+
+// walkSeq reads every field of seq, descending into containers.
+func walkSeq(seq *SeqGetAccess, depth int) {
+	if depth > maxNestingDepth {
+		return
+	}
+	for i := 0; i < seq.ArgCount(); i++ {
+		typ, width, err := seq.PeekTypeWidth()
+		if err != nil {
+			return
+		}
+		if typ.IsMap() || typ == typetags.TypeTuple {
+			if nested, err := seq.PeekNestedSeq(); err == nil {
+				walkSeq(nested, depth+1)
+			}
+		}
+		if _, err := seq.GetPayload(width); err != nil {
+			return
+		}
+		if err := seq.Advance(); err != nil {
+			return
+		}
+	}
+}
+
+func FuzzGetAccess(f *testing.F) {
+	for _, s := range fuzzSeeds(f) {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		g := NewGetAccess(buf)
+		if g == nil {
+			return
+		}
+		for pos := -1; pos <= g.argCount; pos++ {
+			_, _ = GetAny(g, pos)
+			_, _, _ = g.GetNestedGetAccess(pos)
+			_, _ = g.GetTypeAndValue(pos)
+		}
+	})
+}
+
+func TestAdversarialHeaders(t *testing.T) {
+	cases := map[string][]byte{
+		"odd base":         {0x29, 0x00, 0x00, 0x00, 0x00},
+		"base too small":   {0x10, 0x00, 0x00, 0x00},
+		"backwards offset": {0x31, 0x00, 0x11, 0x00, 0x08, 0x00, 1, 2},
+		"end past buffer":  {0x31, 0x00, 0x11, 0x00, 0x40, 0x00, 1, 2},
+	}
+	for name, buf := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSeqGetAccess(buf)
+			assert.Error(t, err)
+			if g := NewGetAccess(buf); g != nil {
+				for pos := -1; pos <= g.argCount; pos++ {
+					_, _ = GetAny(g, pos)
+				}
+			}
+		})
+	}
+}
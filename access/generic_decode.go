@@ -26,6 +26,18 @@ func DecodePrimitive(typ typetags.Type, buf []byte) (interface{}, error) {
 			return int32(binary.LittleEndian.Uint32(buf)), nil
 		case 8:
 			return int64(binary.LittleEndian.Uint64(buf)), nil
+		case TimeWidth:
+			t, err := DecodeTime(buf)
+			if err != nil {
+				return nil, fmt.Errorf("DecodePrimitive: %w", err)
+			}
+			return t, nil
+		case 3, 5, 6, 7:
+			v, err := DecodeVarint(buf)
+			if err != nil {
+				return nil, fmt.Errorf("DecodePrimitive: %w", err)
+			}
+			return v, nil
 		default:
 			return nil, fmt.Errorf("DecodePrimitive: unsupported integer size %d", size)
 		}
@@ -34,6 +46,8 @@ func DecodePrimitive(typ typetags.Type, buf []byte) (interface{}, error) {
 		switch size {
 		case 0:
 			return nil, nil
+		case 2:
+			return Float16frombits(binary.LittleEndian.Uint16(buf)), nil
 		case 4:
 			bits := binary.LittleEndian.Uint32(buf)
 			return math.Float32frombits(bits), nil
@@ -95,7 +109,7 @@ func DecodeTupleGeneric(seq *SeqGetAccess, root bool, ordered bool) ([]any, erro
 			return nil, fmt.Errorf("DecodeTuple: nested value peek error at %d: %w", i, err)
 		}
 		switch valTyp {
-		case typetags.TypeMap:
+		case typetags.TypeMap, typetags.TypeSortedMap:
 			var v any
 			if ordered {
 				v, err = DecodeOrderedMapAny(nested)
@@ -151,7 +165,7 @@ func DecodeMapAny(seq *SeqGetAccess) (map[string]any, error) {
 	if err != nil {
 		return nil, fmt.Errorf("DecodeMapAny: peek failed at pos %d: %w", pos, err)
 	}
-	if typ != typetags.TypeMap {
+	if !typ.IsMap() {
 		return nil, fmt.Errorf("DecodeMapAny: type mismatch at pos %d — expected %v, got %v", pos, typetags.TypeMap, typ)
 	}
 	if width == 0 {
@@ -185,7 +199,7 @@ func DecodeMapAny(seq *SeqGetAccess) (map[string]any, error) {
 
 		}
 		switch valTyp {
-		case typetags.TypeMap:
+		case typetags.TypeMap, typetags.TypeSortedMap:
 			v, err := DecodeMapAny(nested) // delegate
 			if err != nil {
 				return nil, fmt.Errorf("DecodeMapAny: nested value decode error at %d: %w", i+1, err)
@@ -225,7 +239,7 @@ func DecodeOrderedMapAny(seq *SeqGetAccess) (*typetags.OrderedMapAny, error) {
 	if err != nil {
 		return nil, fmt.Errorf("DecodeOrderedMapAny: peek failed at pos %d: %w", pos, err)
 	}
-	if typ != typetags.TypeMap {
+	if !typ.IsMap() {
 		return nil, fmt.Errorf("DecodeOrderedMapAny: type mismatch at pos %d — expected %v, got %v", pos, typetags.TypeMap, typ)
 	}
 	if width == 0 {
@@ -259,7 +273,7 @@ func DecodeOrderedMapAny(seq *SeqGetAccess) (*typetags.OrderedMapAny, error) {
 		}
 
 		switch valTyp {
-		case typetags.TypeMap:
+		case typetags.TypeMap, typetags.TypeSortedMap:
 			v, err := DecodeOrderedMapAny(nested) // delegate recursively
 			if err != nil {
 				return nil, fmt.Errorf("DecodeOrderedMapAny: nested value decode error at %d: %w", i+1, err)
@@ -328,3 +342,44 @@ func DecodeOrdered(buf []byte) (any, error) {
 	}
 	return vals, nil
 }
+
+// WidenNumbers returns v with every integer narrower than 64 bits converted
+// to int64 and every float32 (float16 and bfloat16 decode to float32) to
+// float64, descending into []any, map[string]any and *typetags.OrderedMapAny.
+// Slices and maps are rewritten in place. Other values are returned as is.
+func WidenNumbers(v any) any {
+	switch x := v.(type) {
+	case int8:
+		return int64(x)
+	case int16:
+		return int64(x)
+	case int32:
+		return int64(x)
+	case int:
+		return int64(x)
+	case uint8:
+		return int64(x)
+	case uint16:
+		return int64(x)
+	case uint32:
+		return int64(x)
+	case float32:
+		return float64(x)
+	case []any:
+		for i, e := range x {
+			x[i] = WidenNumbers(e)
+		}
+	case map[string]any:
+		for k, e := range x {
+			x[k] = WidenNumbers(e)
+		}
+	case *typetags.OrderedMapAny:
+		if x != nil {
+			for _, k := range x.Keys() {
+				e, _ := x.Get(k)
+				x.Set(k, WidenNumbers(e))
+			}
+		}
+	}
+	return v
+}
@@ -11,9 +11,10 @@ import (
 )
 
 type GetAccess struct {
-	buf      []byte // full packed buffer: headers + payload
-	argCount int    // number of headers (excluding TypeEnd)
-	base     int    // absolute offset to payload start
+	buf      []byte         // full packed buffer: headers + payload
+	argCount int            // number of headers (excluding TypeEnd)
+	base     int            // absolute offset to payload start
+	index    map[string]int // field index loaded lazily by Field
 }
 
 func NewGetAccess(buf []byte) *GetAccess {
@@ -26,6 +27,9 @@ func NewGetAccess(buf []byte) *GetAccess {
 	if len(buf) < base {
 		return nil // buffer too short for declared header count
 	}
+	if base < 2 || base%2 != 0 {
+		return nil // headers are two bytes each, the end header included
+	}
 
 	return &GetAccess{
 		buf:      buf,
@@ -37,8 +41,8 @@ func NewGetAccess(buf []byte) *GetAccess {
 // rangeAt returns absolute start and end offsets for field at pos
 func (g *GetAccess) rangeAt(pos int) (tp typetags.Type, start, end int) {
 
-	if pos >= g.argCount {
-		return typetags.TypeEnd, -2, -1
+	if pos < 0 || pos >= g.argCount {
+		return typetags.TypeEnd, 0, -1 // end < start, like a bad range
 	}
 
 	h1 := binary.LittleEndian.Uint16(g.buf[pos*2:])
@@ -140,6 +144,12 @@ func (g *GetAccess) GetInt(pos int) (any, int, error) {
 	case 8:
 		v := int64(binary.LittleEndian.Uint64(g.buf[start:end]))
 		return v, 8, nil
+	case 3, 5, 6, 7:
+		v, err := DecodeVarint(g.buf[start:end])
+		if err != nil {
+			return 0, 0, fmt.Errorf("GetInt decode error at pos %d: %w", pos, err)
+		}
+		return v, size, nil
 	default:
 		return 0, 0, fmt.Errorf("GetInt decode error: unsupported size %d at pos %d", size, pos)
 	}
@@ -156,6 +166,8 @@ func (g *GetAccess) GetFloating(pos int) (any, int, error) {
 	switch size {
 	case 0:
 		return 0, 0, nil // nil value
+	case 2:
+		return Float16frombits(binary.LittleEndian.Uint16(g.buf[start:end])), 2, nil
 	case 4:
 		bits := binary.LittleEndian.Uint32(g.buf[start:end])
 		v := math.Float32frombits(bits)
@@ -365,16 +377,58 @@ func (g *GetAccess) GetString(pos int) (string, error) {
 	return string(g.buf[start:end]), nil
 }
 
+// IsNullTag reports whether the field at pos is the null marker written by
+// AddNullableString and AddNullableBytes.
+func (g *GetAccess) IsNullTag(pos int) bool {
+	tp, start, end := g.rangeAt(pos)
+	return tp == typetags.TypeNull && start == end
+}
+
+// GetNullableBytes decodes a byte slice at position pos. It returns nil for
+// the null marker and an empty non-nil slice for an empty byte array.
+func (g *GetAccess) GetNullableBytes(pos int) ([]byte, error) {
+	if g.IsNullTag(pos) {
+		return nil, nil
+	}
+	b, err := g.GetBytes(pos)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		b = []byte{}
+	}
+	return b, nil
+}
+
+// GetNullableString decodes a string at position pos. It returns nil for
+// the null marker and a pointer to "" for an empty string.
+func (g *GetAccess) GetNullableString(pos int) (*string, error) {
+	if g.IsNullTag(pos) {
+		return nil, nil
+	}
+	v, err := g.GetString(pos)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
 // GetStringUnsafe decodes a string at position pos using unsafe.String
 func (g *GetAccess) GetStringUnsafe(pos int) (string, error) {
 	tp, start, end := g.rangeAt(pos)
 	if tp != typetags.TypeString || end < start {
 		return "", errors.New("decode error")
 	}
+	if start == end {
+		return "", nil
+	}
 	return unsafe.String(&g.buf[start], end-start), nil
 }
 
 func GetAny(g *GetAccess, pos int) (any, error) {
+	if pos < 0 || pos >= g.argCount {
+		return nil, fmt.Errorf("GetAny: position %d out of range", pos)
+	}
 	h := binary.LittleEndian.Uint16(g.buf[pos*2:])
 	_, typ := typetags.DecodeHeader(h)
 
@@ -401,7 +455,7 @@ func GetAny(g *GetAccess, pos int) (any, error) {
 	case typetags.TypeString:
 		return g.GetString(pos)
 
-	case typetags.TypeMap:
+	case typetags.TypeMap, typetags.TypeSortedMap:
 		return g.GetMapAny(pos)
 
 	default:
@@ -411,7 +465,7 @@ func GetAny(g *GetAccess, pos int) (any, error) {
 
 func (g *GetAccess) GetMapAny(pos int) (map[string]any, error) {
 	tp, start, end := g.rangeAt(pos)
-	if end < start || tp != typetags.TypeMap {
+	if end < start || !tp.IsMap() {
 		return nil, errors.New("decode error")
 	}
 	if end == start {
@@ -419,6 +473,9 @@ func (g *GetAccess) GetMapAny(pos int) (map[string]any, error) {
 	}
 
 	nested := NewGetAccess(g.buf[start:end])
+	if nested == nil {
+		return nil, errors.New("decode error: malformed map")
+	}
 	out := make(map[string]any, nested.argCount/2)
 
 	for i := 0; i < nested.argCount; i += 2 {
@@ -439,7 +496,7 @@ func (g *GetAccess) GetMapAny(pos int) (map[string]any, error) {
 // preserving insertion order of keys.
 func (g *GetAccess) GetMapOrderedAny(pos int) (*typetags.OrderedMapAny, error) {
 	tp, start, end := g.rangeAt(pos)
-	if end < start || tp != typetags.TypeMap {
+	if end < start || !tp.IsMap() {
 		return nil, errors.New("decode error")
 	}
 	if end == start {
@@ -447,6 +504,9 @@ func (g *GetAccess) GetMapOrderedAny(pos int) (*typetags.OrderedMapAny, error) {
 	}
 
 	nested := NewGetAccess(g.buf[start:end])
+	if nested == nil {
+		return nil, errors.New("decode error: malformed map")
+	}
 	out := typetags.NewOrderedMapAny()
 
 	for i := 0; i < nested.argCount; i += 2 {
@@ -465,7 +525,7 @@ func (g *GetAccess) GetMapOrderedAny(pos int) (*typetags.OrderedMapAny, error) {
 
 func (g *GetAccess) GetMapStr(pos int) (map[string]string, error) {
 	tp, start, end := g.rangeAt(pos)
-	if end < start || tp != typetags.TypeMap {
+	if end < start || !tp.IsMap() {
 		return nil, errors.New("decode error")
 	}
 	if end == start {
@@ -473,6 +533,9 @@ func (g *GetAccess) GetMapStr(pos int) (map[string]string, error) {
 	}
 
 	nested := NewGetAccess(g.buf[start:end])
+	if nested == nil {
+		return nil, errors.New("decode error: malformed map")
+	}
 	out := make(map[string]string, nested.argCount/2)
 
 	for i := 0; i < nested.argCount; i += 2 {
@@ -489,15 +552,87 @@ func (g *GetAccess) GetMapStr(pos int) (map[string]string, error) {
 	return out, nil
 }
 
+var ErrKeyNotFound = errors.New("key not found")
+
+// LookupKey finds key in the map at pos and returns the value's type and payload.
+// Maps tagged TypeSortedMap are binary-searched over their key headers; plain
+// maps fall back to a linear scan. A nested container value is returned as its
+// payload, whose fields can be read with NewGetAccess.
+func (g *GetAccess) LookupKey(pos int, key string) (typetags.Type, []byte, error) {
+	tp, start, end := g.rangeAt(pos)
+	if end < start || !tp.IsMap() {
+		return typetags.TypeInvalid, nil, errors.New("decode error: it's not a map")
+	}
+	if end == start {
+		return typetags.TypeInvalid, nil, ErrKeyNotFound
+	}
+	nested := NewGetAccess(g.buf[start:end])
+	if nested == nil || nested.argCount%2 != 0 {
+		return typetags.TypeInvalid, nil, errors.New("decode error: malformed map")
+	}
+	pairs := nested.argCount / 2
+	keyAt := func(i int) (string, error) {
+		ktp, ks, ke := nested.rangeAt(i * 2)
+		if ktp != typetags.TypeString || ke < ks {
+			return "", fmt.Errorf("map key decode error at %d", i*2)
+		}
+		return unsafe.String(unsafe.SliceData(nested.buf[ks:ke]), ke-ks), nil
+	}
+
+	found := -1
+	if tp == typetags.TypeSortedMap {
+		lo, hi := 0, pairs
+		for lo < hi {
+			mid := int(uint(lo+hi) >> 1)
+			k, err := keyAt(mid)
+			if err != nil {
+				return typetags.TypeInvalid, nil, err
+			}
+			if k == key {
+				found = mid
+				break
+			}
+			if k < key {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+	} else {
+		for i := 0; i < pairs; i++ {
+			k, err := keyAt(i)
+			if err != nil {
+				return typetags.TypeInvalid, nil, err
+			}
+			if k == key {
+				found = i
+				break
+			}
+		}
+	}
+	if found < 0 {
+		return typetags.TypeInvalid, nil, ErrKeyNotFound
+	}
+	vtp, vs, ve := nested.rangeAt(found*2 + 1)
+	if ve < vs {
+		return typetags.TypeInvalid, nil, errors.New("decode error")
+	}
+	return vtp, nested.buf[vs:ve], nil
+}
+
 func (g *GetAccess) GetNestedGetAccess(pos int) (*GetAccess, typetags.Type, error) {
 	tp, start, end := g.rangeAt(pos)
-	if end < start || (tp != typetags.TypeMap && tp != typetags.TypeTuple) {
+	if end < start || (!tp.IsMap() && tp != typetags.TypeTuple) {
 		return nil, tp, errors.New("decode error: it's not nested type")
 	}
 	if end == start {
 		return nil, tp, nil // nil map
 	}
-	return NewGetAccess(g.buf[start:end]), tp, nil
+	nested := NewGetAccess(g.buf[start:end])
+	if nested == nil {
+		return nil, tp, errors.New("decode error: malformed nested headers")
+	}
+	return nested, tp, nil
 }
 
 // function to get type and value, which can be used for repacking or other purposes
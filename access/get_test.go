@@ -1,6 +1,8 @@
 package access
 
 import (
+	"encoding/binary"
+	"fmt"
 	"testing"
 
 	"github.com/quickwritereader/PackOS/typetags"
@@ -124,3 +126,74 @@ func TestGetAccess_IntThenMapWithInnerMapAndString(t *testing.T) {
 
 	assert.Equal(t, "gopher", m["name"].(string))
 }
+
+func TestGetAccess_LookupKey(t *testing.T) {
+	m := map[string]any{}
+	for i := 0; i < 50; i++ {
+		m[fmt.Sprintf("k%03d", i)] = int32(i)
+	}
+	sorted := NewPutAccess()
+	require.NoError(t, sorted.AddSortedMapAny(m, false))
+	sorted.AddString("not a map")
+	plain := NewPutAccess()
+	require.NoError(t, plain.AddMapAny(m, false))
+	plain.AddSortedMapAny(nil, false)
+
+	for _, buf := range [][]byte{sorted.Pack(), plain.Pack()} {
+		g := NewGetAccess(buf)
+		for _, key := range []string{"k000", "k025", "k049"} {
+			tp, val, err := g.LookupKey(0, key)
+			require.NoError(t, err, key)
+			assert.Equal(t, typetags.TypeInteger, tp)
+			assert.Equal(t, m[key], int32(binary.LittleEndian.Uint32(val)))
+		}
+		_, _, err := g.LookupKey(0, "k050")
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+		_, _, err = g.LookupKey(0, "")
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	}
+
+	g := NewGetAccess(sorted.Pack())
+	_, _, err := g.LookupKey(1, "k000")
+	assert.Error(t, err)
+	decoded, err := g.GetMapAny(0)
+	require.NoError(t, err)
+	assert.Equal(t, m, decoded)
+
+	g = NewGetAccess(plain.Pack())
+	_, _, err = g.LookupKey(1, "k000")
+	assert.ErrorIs(t, err, ErrKeyNotFound, "empty sorted map")
+}
+
+func TestNullableStringAndBytes(t *testing.T) {
+	empty := ""
+	word := "hi"
+	put := NewPutAccess()
+	put.AddNullableString(nil)
+	put.AddNullableString(&empty)
+	put.AddNullableString(&word)
+	put.AddNullableBytes(nil)
+	put.AddNullableBytes([]byte{})
+	g := NewGetAccess(put.Pack())
+
+	assert.True(t, g.IsNullTag(0))
+	assert.False(t, g.IsNullTag(1))
+	v, err := g.GetNullableString(0)
+	require.NoError(t, err)
+	assert.Nil(t, v)
+	v, err = g.GetNullableString(1)
+	require.NoError(t, err)
+	require.NotNil(t, v)
+	assert.Equal(t, "", *v)
+	v, err = g.GetNullableString(2)
+	require.NoError(t, err)
+	assert.Equal(t, "hi", *v)
+
+	b, err := g.GetNullableBytes(3)
+	require.NoError(t, err)
+	assert.Nil(t, b)
+	b, err = g.GetNullableBytes(4)
+	require.NoError(t, err)
+	assert.NotNil(t, b)
+	assert.Empty(t, b)
+}
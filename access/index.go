@@ -0,0 +1,134 @@
+package access
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// indexMagic is the first key of a trailing field index. It sorts before any
+// regular name, so readers only need to check key 0 to recognise an index.
+const indexMagic = "\x00packos.index"
+
+var ErrNoIndex = errors.New("buffer has no field index")
+
+type indexName struct {
+	name string
+	pos  int
+}
+
+// Named records name for the next field added to p. PackWithIndex writes the
+// recorded names into a trailing index. Naming a field twice keeps the last name.
+//
+//	put.Named("id").AddInt32(7)
+func (p *PutAccess) Named(name string) *PutAccess {
+	p.names = append(p.names, indexName{name: name, pos: len(p.offsets) / 2})
+	return p
+}
+
+// PackWithIndex packs like Pack and appends a TypeSortedMap field mapping
+// every name recorded with Named to its field position. The index is an
+// ordinary last field, so generic decoders see one extra map; GetAccess.Field
+// uses it for lookups by name without schema knowledge.
+func (p *PutAccess) PackWithIndex() []byte {
+	entries := slices.Clone(p.names)
+	// stable sort keeps the last duplicate at the end of its run
+	slices.SortStableFunc(entries, func(a, b indexName) int { return strings.Compare(a.name, b.name) })
+
+	nested := NewPutAccessFromPool()
+	nested.AddString(indexMagic)
+	nested.AddInt8(1) // index format version
+	for i, e := range entries {
+		if i+1 < len(entries) && entries[i+1].name == e.name {
+			continue
+		}
+		nested.AddString(e.name)
+		nested.AddIntegerCompressed(int64(e.pos))
+	}
+	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeSortedMap))
+	p.appendAndReleaseNested(nested)
+	return p.Pack()
+}
+
+// HasIndex reports whether the buffer ends with a field index written by PackWithIndex.
+func (g *GetAccess) HasIndex() bool {
+	return g.loadIndex() == nil
+}
+
+// Field returns the position of the field recorded under name, for use with
+// the typed getters. The index is parsed on first use and cached, so later
+// lookups are a single map access. Call HasIndex before sharing g between
+// goroutines, since the first call populates the cache.
+func (g *GetAccess) Field(name string) (int, error) {
+	if err := g.loadIndex(); err != nil {
+		return -1, err
+	}
+	pos, ok := g.index[name]
+	if !ok {
+		return -1, fmt.Errorf("field %q: %w", name, ErrKeyNotFound)
+	}
+	return pos, nil
+}
+
+func (g *GetAccess) loadIndex() error {
+	if g.index != nil {
+		if len(g.index) == 0 {
+			return ErrNoIndex
+		}
+		return nil
+	}
+	g.index = map[string]int{}
+	if g.argCount == 0 {
+		return ErrNoIndex
+	}
+	tp, start, end := g.rangeAt(g.argCount - 1)
+	if tp != typetags.TypeSortedMap || end <= start {
+		return ErrNoIndex
+	}
+	nested := NewGetAccess(g.buf[start:end])
+	if nested == nil || nested.argCount < 2 || nested.argCount%2 != 0 {
+		return ErrNoIndex
+	}
+	if magic, err := nested.GetStringUnsafe(0); err != nil || magic != indexMagic {
+		return ErrNoIndex
+	}
+	index := make(map[string]int, nested.argCount/2-1)
+	for i := 2; i < nested.argCount; i += 2 {
+		name, err := nested.GetString(i)
+		if err != nil {
+			return fmt.Errorf("field index: key at %d: %w", i, err)
+		}
+		v, _, err := nested.GetInt(i + 1)
+		if err != nil {
+			return fmt.Errorf("field index: %q: %w", name, err)
+		}
+		pos, ok := indexPos(v)
+		if !ok || pos < 0 || pos >= g.argCount-1 {
+			return fmt.Errorf("field index: %q: invalid position %v", name, v)
+		}
+		index[name] = pos
+	}
+	if len(index) == 0 {
+		return ErrNoIndex
+	}
+	g.index = index
+	return nil
+}
+
+func indexPos(v any) (int, bool) {
+	switch n := v.(type) {
+	case int8:
+		return int(n), true
+	case int16:
+		return int(n), true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	}
+	return 0, false
+}
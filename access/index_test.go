@@ -0,0 +1,70 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackWithIndex_FieldLookup(t *testing.T) {
+	put := NewPutAccess()
+	put.Named("id").AddInt32(7)
+	put.AddBool(true) // unnamed
+	put.Named("name").AddString("gopher")
+	put.Named("tmp").AddInt8(1)
+	put.Named("tmp").AddFloat64(2.5) // later name wins
+	buf := put.PackWithIndex()
+
+	g := NewGetAccess(buf)
+	require.True(t, g.HasIndex())
+
+	pos, err := g.Field("id")
+	require.NoError(t, err)
+	id, err := g.GetInt32(pos)
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), id)
+
+	pos, err = g.Field("name")
+	require.NoError(t, err)
+	name, err := g.GetString(pos)
+	require.NoError(t, err)
+	assert.Equal(t, "gopher", name)
+
+	pos, err = g.Field("tmp")
+	require.NoError(t, err)
+	assert.Equal(t, 4, pos)
+
+	_, err = g.Field("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// generic decoders see the index as a trailing sorted map
+	decoded, err := Decode(buf)
+	require.NoError(t, err)
+	require.Len(t, decoded, 6)
+	tp, _ := NewGetAccess(buf).GetTypeAndValue(5)
+	assert.Equal(t, typetags.TypeSortedMap, tp)
+}
+
+func TestPackWithIndex_NoIndex(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt32(1)
+	require.NoError(t, put.AddSortedMapAny(map[string]any{"a": int8(1)}, false))
+	g := NewGetAccess(put.Pack())
+	assert.False(t, g.HasIndex())
+	_, err := g.Field("a")
+	assert.ErrorIs(t, err, ErrNoIndex)
+
+	put = NewPutAccessFromPool()
+	put.Named("x").AddInt16(3)
+	buf := put.PackWithIndex()
+	ReleasePutAccess(put)
+	reused := NewPutAccessFromPool()
+	assert.Empty(t, reused.names, "pooled PutAccess starts without names")
+	ReleasePutAccess(reused)
+
+	pos, err := NewGetAccess(buf).Field("x")
+	require.NoError(t, err)
+	assert.Equal(t, 0, pos)
+}
@@ -0,0 +1,151 @@
+package access
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// String interning.
+//
+// With SetInterning, strings added with AddString that repeat are written
+// once into a dictionary and later occurrences become references. A
+// reference is a field tagged TypeEnd, which never appears before the last
+// header of a container otherwise, whose payload is the dictionary index as
+// a uvarint. The packed buffer is prefixed with two zero bytes (a first
+// header can never be zero), the dictionary length as a uvarint and the
+// dictionary itself, an ordinary packed list of strings:
+//
+//	00 00 | len | dictionary | record
+//
+// SeqGetAccess detects the prefix and resolves references, so they read as
+// TypeString fields in nested containers too, and DecodeMapAny, Decode and
+// schema decoding work unchanged. GetAccess, patching and the other
+// random-access helpers do not understand interned buffers.
+//
+// Strings shorter than internMinLen are always written inline, since a
+// reference would not be smaller. A string's first occurrence is written
+// inline as well; it joins the dictionary when it repeats.
+
+const internMinLen = 3
+
+var errInternRef = errors.New("invalid interned string reference")
+
+type internTable struct {
+	seen    map[string]int // dictionary index, or -1 when seen once
+	entries []string
+}
+
+// SetInterning turns string interning on or off for p and the containers
+// nested in it. Enable it before adding fields; only the buffer produced by
+// p itself carries the dictionary.
+func (p *PutAccess) SetInterning(on bool) {
+	if !on {
+		p.intern, p.internRoot = nil, false
+		return
+	}
+	if p.intern == nil {
+		p.intern = &internTable{seen: make(map[string]int)}
+	}
+	p.internRoot = true
+}
+
+// Interning reports whether p interns repeated strings.
+func (p *PutAccess) Interning() bool {
+	return p.intern != nil
+}
+
+// addInterned writes a reference for s when it has been seen before and
+// reports whether it did.
+func (p *PutAccess) addInterned(s string) bool {
+	if len(s) < internMinLen {
+		return false
+	}
+	t := p.intern
+	idx, ok := t.seen[s]
+	if !ok {
+		t.seen[s] = -1
+		return false
+	}
+	if idx < 0 {
+		idx = len(t.entries)
+		t.entries = append(t.entries, s)
+		t.seen[s] = idx
+	}
+	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeEnd))
+	p.buf = binary.AppendUvarint(p.buf, uint64(idx))
+	p.advance()
+	return true
+}
+
+// hasDictionary reports whether p's packed output needs the dictionary prefix.
+func (p *PutAccess) hasDictionary() bool {
+	return p.internRoot && p.intern != nil && len(p.intern.entries) > 0
+}
+
+func (t *internTable) appendDictionary(buf []byte) []byte {
+	dict := NewPutAccessFromPool()
+	defer ReleasePutAccess(dict)
+	for _, e := range t.entries {
+		dict.AddString(e)
+	}
+	dictBuf := dict.Pack()
+	buf = append(buf, 0, 0)
+	buf = binary.AppendUvarint(buf, uint64(len(dictBuf)))
+	return append(buf, dictBuf...)
+}
+
+// IsInterned reports whether buf starts with a string dictionary.
+func IsInterned(buf []byte) bool {
+	return len(buf) >= 2 && buf[0] == 0 && buf[1] == 0
+}
+
+// splitInterned separates the dictionary entries from the record.
+func splitInterned(buf []byte) (dict [][]byte, record []byte, err error) {
+	n, k := binary.Uvarint(buf[2:])
+	if k <= 0 || n > uint64(len(buf)-2-k) {
+		return nil, nil, errors.New("invalid string dictionary length")
+	}
+	dictBuf := buf[2+k : 2+k+int(n)]
+	seq, err := newSeqGetAccess(dictBuf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("string dictionary: %w", err)
+	}
+	dict = make([][]byte, seq.ArgCount())
+	for i := range dict {
+		payload, typ, err := seq.Next()
+		if err != nil {
+			return nil, nil, fmt.Errorf("string dictionary: %w", err)
+		}
+		if typ != typetags.TypeString {
+			return nil, nil, fmt.Errorf("string dictionary entry %d is %v", i, typ)
+		}
+		dict[i] = payload
+	}
+	return dict, buf[2+k+int(n):], nil
+}
+
+// Interned reports whether s reads an interned buffer, whose payloads may
+// hold references into the buffer's own dictionary.
+func (s *SeqGetAccess) Interned() bool {
+	return s.dict != nil
+}
+
+// isRef reports whether the current field is an interned string reference.
+func (s *SeqGetAccess) isRef() bool {
+	return s.dict != nil && s.currentType == typetags.TypeEnd && s.pos < s.count-1
+}
+
+// resolveRef returns the dictionary entry the current field refers to.
+func (s *SeqGetAccess) resolveRef() ([]byte, error) {
+	if s.nextOffset > len(s.buf) || s.currentOffset > s.nextOffset {
+		return nil, errInternRef
+	}
+	idx, k := binary.Uvarint(s.buf[s.currentOffset:s.nextOffset])
+	if k <= 0 || idx >= uint64(len(s.dict)) {
+		return nil, fmt.Errorf("%w at pos %d", errInternRef, s.pos)
+	}
+	return s.dict[idx], nil
+}
@@ -0,0 +1,127 @@
+package access
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func logRecords() []any {
+	var recs []any
+	for i := 0; i < 20; i++ {
+		recs = append(recs, map[string]any{
+			"level":   "warning",
+			"service": "payments-api",
+			"msg":     fmt.Sprintf("retry %d", i),
+			"ok":      i%2 == 0,
+		})
+	}
+	return recs
+}
+
+func TestInterning_RoundTrip(t *testing.T) {
+	plain := NewPutAccess()
+	plain.SetDeterministic(true)
+	require.NoError(t, plain.AddAnyTuple(logRecords(), false))
+	plainBuf := plain.Pack()
+
+	put := NewPutAccess()
+	put.SetDeterministic(true)
+	put.SetInterning(true)
+	require.NoError(t, put.AddAnyTuple(logRecords(), false))
+	buf := put.Pack()
+
+	assert.True(t, IsInterned(buf))
+	assert.False(t, IsInterned(plainBuf))
+	assert.Less(t, len(buf), len(plainBuf)*3/4)
+
+	want, err := Decode(plainBuf)
+	require.NoError(t, err)
+	got, err := Decode(buf)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestInterning_SeqGetAccessResolves(t *testing.T) {
+	put := NewPutAccess()
+	put.SetInterning(true)
+	put.AddString("region")
+	put.AddString("region")
+	put.AddString("eu") // too short to intern
+	put.AddString("eu")
+	m := put.BeginMap()
+	m.AddString("region")
+	m.AddString("us-east")
+	put.EndNested(m)
+	buf := put.Pack()
+
+	seq, err := NewSeqGetAccess(buf)
+	require.NoError(t, err)
+	require.Equal(t, 5, seq.ArgCount())
+	for _, want := range []string{"region", "region", "eu", "eu"} {
+		payload, typ, err := seq.Next()
+		require.NoError(t, err)
+		assert.Equal(t, typetags.TypeString, typ)
+		assert.Equal(t, want, string(payload))
+	}
+	got, err := DecodeMapAny(seq)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"region": "us-east"}, got)
+}
+
+func TestInterning_NoRepeatsIsPlain(t *testing.T) {
+	put := NewPutAccess()
+	put.SetInterning(true)
+	put.AddString("alpha")
+	put.AddString("beta")
+	assert.Equal(t, put.PackSize(), len(put.Pack()))
+
+	plain := NewPutAccess()
+	plain.AddString("alpha")
+	plain.AddString("beta")
+	assert.Equal(t, plain.Pack(), func() []byte {
+		p := NewPutAccess()
+		p.SetInterning(true)
+		p.AddString("alpha")
+		p.AddString("beta")
+		return p.Pack()
+	}())
+}
+
+func TestInterning_PackSizeAndBuff(t *testing.T) {
+	put := NewPutAccess()
+	put.SetInterning(true)
+	put.AddString("repeat")
+	put.AddString("repeat")
+	size := put.PackSize()
+	out := make([]byte, size)
+	n, err := put.PackBuff(out)
+	require.NoError(t, err)
+	assert.Equal(t, size, n)
+
+	got, err := Decode(out)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"repeat", "repeat"}, got)
+}
+
+func TestInterning_BadReference(t *testing.T) {
+	put := NewPutAccess()
+	put.SetInterning(true)
+	put.AddString("abc")
+	put.AddString("abc")
+	buf := put.Pack()
+	buf[len(buf)-1] = 9 // reference past the dictionary
+
+	seq, err := NewSeqGetAccess(buf)
+	require.NoError(t, err)
+	_, _, err = seq.Next()
+	require.NoError(t, err)
+	_, _, err = seq.PeekTypeWidth()
+	assert.Error(t, err)
+
+	_, err = NewSeqGetAccess([]byte{0, 0, 0x7f})
+	assert.Error(t, err)
+}
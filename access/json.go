@@ -0,0 +1,332 @@
+package access
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const jsonFlushSize = 4096
+
+// ToJSON walks a packed buffer and writes it to w as JSON text without building
+// intermediate Go values. Like Decode, a single top-level field is written as is
+// and several fields are written as an array. Map keys keep their wire order,
+// zero-width nullables and empty tuples are written as null.
+func ToJSON(buf []byte, w io.Writer) error {
+	seq, err := NewSeqGetAccess(buf)
+	if err != nil {
+		return fmt.Errorf("ToJSON: %w", err)
+	}
+	e := jsonEmitter{w: w, out: make([]byte, 0, min(len(buf)*2, jsonFlushSize))}
+	if seq.ArgCount() == 1 {
+		err = e.field(seq, 0)
+	} else {
+		err = e.tuple(seq, 0)
+	}
+	if err != nil {
+		return fmt.Errorf("ToJSON: %w", err)
+	}
+	return e.flush()
+}
+
+type jsonEmitter struct {
+	w   io.Writer
+	out []byte
+}
+
+func (e *jsonEmitter) flush() error {
+	if len(e.out) == 0 {
+		return nil
+	}
+	_, err := e.w.Write(e.out)
+	e.out = e.out[:0]
+	return err
+}
+
+func (e *jsonEmitter) maybeFlush() error {
+	if len(e.out) < jsonFlushSize {
+		return nil
+	}
+	return e.flush()
+}
+
+func (e *jsonEmitter) tuple(seq *SeqGetAccess, depth int) error {
+	e.out = append(e.out, '[')
+	for i := 0; i < seq.ArgCount(); i++ {
+		if i > 0 {
+			e.out = append(e.out, ',')
+		}
+		if err := e.field(seq, depth); err != nil {
+			return fmt.Errorf("index %d: %w", i, err)
+		}
+	}
+	e.out = append(e.out, ']')
+	return nil
+}
+
+func (e *jsonEmitter) object(seq *SeqGetAccess, depth int) error {
+	if seq.ArgCount()%2 != 0 {
+		return fmt.Errorf("map has odd field count %d", seq.ArgCount())
+	}
+	e.out = append(e.out, '{')
+	for i := 0; i < seq.ArgCount(); i += 2 {
+		if i > 0 {
+			e.out = append(e.out, ',')
+		}
+		key, typ, err := seq.Next()
+		if err != nil {
+			return err
+		}
+		if typ != typetags.TypeString {
+			return fmt.Errorf("map key at %d is %v, expected string", i, typ)
+		}
+		e.out = appendJSONString(e.out, key)
+		e.out = append(e.out, ':')
+		if err := e.field(seq, depth); err != nil {
+			return fmt.Errorf("key %q: %w", key, err)
+		}
+	}
+	e.out = append(e.out, '}')
+	return nil
+}
+
+// field writes the value at the current position of seq and advances past it.
+func (e *jsonEmitter) field(seq *SeqGetAccess, depth int) error {
+	if depth > maxNestingDepth {
+		return errors.New("nesting too deep")
+	}
+	if err := e.maybeFlush(); err != nil {
+		return err
+	}
+	typ, width, err := seq.PeekTypeWidth()
+	if err != nil {
+		return err
+	}
+
+	if typ.IsMap() || typ == typetags.TypeTuple {
+		switch {
+		case width == 0 && typ.IsMap():
+			e.out = append(e.out, "{}"...)
+		case width == 0:
+			e.out = append(e.out, "null"...)
+		default:
+			nested, err := seq.PeekNestedSeq()
+			if err != nil {
+				return err
+			}
+			if typ.IsMap() {
+				err = e.object(nested, depth+1)
+			} else {
+				err = e.tuple(nested, depth+1)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return seq.Advance()
+	}
+
+	payload, typ, err := seq.Next()
+	if err != nil {
+		return err
+	}
+	if typ == typetags.TypeString {
+		e.out = appendJSONString(e.out, payload)
+		return nil
+	}
+	v, err := DecodePrimitive(typ, payload)
+	if err != nil {
+		return err
+	}
+	switch val := v.(type) {
+	case nil:
+		e.out = append(e.out, "null"...)
+	case int8:
+		e.out = strconv.AppendInt(e.out, int64(val), 10)
+	case int16:
+		e.out = strconv.AppendInt(e.out, int64(val), 10)
+	case int32:
+		e.out = strconv.AppendInt(e.out, int64(val), 10)
+	case int64:
+		e.out = strconv.AppendInt(e.out, val, 10)
+	case float32:
+		return e.float(float64(val), 32)
+	case float64:
+		return e.float(val, 64)
+	case bool:
+		e.out = strconv.AppendBool(e.out, val)
+	case time.Time:
+		e.out = append(e.out, '"')
+		e.out = val.AppendFormat(e.out, time.RFC3339Nano)
+		e.out = append(e.out, '"')
+	default:
+		return fmt.Errorf("unsupported value %T", v)
+	}
+	return nil
+}
+
+// float formats like encoding/json: plain notation unless the exponent is very small or large.
+func (e *jsonEmitter) float(f float64, bits int) error {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return fmt.Errorf("unsupported float value %v", f)
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	e.out = strconv.AppendFloat(e.out, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(e.out)
+		if n >= 4 && e.out[n-4] == 'e' && e.out[n-3] == '-' && e.out[n-2] == '0' {
+			e.out[n-2] = e.out[n-1]
+			e.out = e.out[:n-1]
+		}
+	}
+	return nil
+}
+
+const jsonHex = "0123456789abcdef"
+
+// appendJSONString quotes b as a JSON string; invalid UTF-8 is replaced with U+FFFD.
+func appendJSONString(out []byte, b []byte) []byte {
+	out = append(out, '"')
+	start := 0
+	for i := 0; i < len(b); {
+		c := b[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			out = append(out, b[start:i]...)
+			switch c {
+			case '"', '\\':
+				out = append(out, '\\', c)
+			case '\n':
+				out = append(out, '\\', 'n')
+			case '\r':
+				out = append(out, '\\', 'r')
+			case '\t':
+				out = append(out, '\\', 't')
+			default:
+				out = append(out, '\\', 'u', '0', '0', jsonHex[c>>4], jsonHex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && size == 1 {
+			out = append(out, b[start:i]...)
+			out = append(out, `�`...)
+			i++
+			start = i
+			continue
+		}
+		i += size
+	}
+	out = append(out, b[start:]...)
+	return append(out, '"')
+}
+
+// FromJSON reads JSON values from r and packs their tokens straight into a
+// buffer without building an intermediate object graph. Each top-level value
+// becomes one field. Integers take the smallest fitting width, other numbers
+// are stored as float64, objects keep their key order, and null and empty
+// arrays become a null tuple.
+func FromJSON(r io.Reader) ([]byte, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	put := NewPutAccessFromPool()
+	defer ReleasePutAccess(put)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("FromJSON: %w", err)
+		}
+		if err := packJSONToken(dec, put, tok, 0); err != nil {
+			return nil, fmt.Errorf("FromJSON: offset %d: %w", dec.InputOffset(), err)
+		}
+	}
+	if len(put.offsets) == 0 {
+		return nil, errors.New("FromJSON: no JSON value")
+	}
+	return put.Pack(), nil
+}
+
+func packJSONToken(dec *json.Decoder, put *PutAccess, tok json.Token, depth int) error {
+	if depth > maxNestingDepth {
+		return errors.New("nesting too deep")
+	}
+	switch v := tok.(type) {
+	case nil:
+		put.AddNull(nil)
+	case bool:
+		put.AddBool(v)
+	case string:
+		put.AddString(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			put.AddIntegerCompressed(i)
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return fmt.Errorf("invalid number %q: %w", v, err)
+		}
+		put.AddFloat64(f)
+	case json.Delim:
+		isMap := v == '{'
+		var nested *PutAccess
+		if isMap {
+			nested = put.BeginMap()
+		} else {
+			nested = put.BeginTuple()
+		}
+		for dec.More() {
+			if isMap {
+				key, err := dec.Token()
+				if err != nil {
+					ReleasePutAccess(nested)
+					return err
+				}
+				nested.AddString(key.(string))
+			}
+			next, err := dec.Token()
+			if err == nil {
+				err = packJSONToken(dec, nested, next, depth+1)
+			}
+			if err != nil {
+				ReleasePutAccess(nested)
+				return err
+			}
+		}
+		// consume the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			ReleasePutAccess(nested)
+			return err
+		}
+		if len(nested.offsets) == 0 {
+			// empty object or array: zero-width field
+			ReleasePutAccess(nested)
+			return nil
+		}
+		put.EndNested(nested)
+	default:
+		return fmt.Errorf("unexpected token %v", tok)
+	}
+	return nil
+}
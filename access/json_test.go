@@ -0,0 +1,109 @@
+package access
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToJSON_OrderedDocument(t *testing.T) {
+	put := NewPutAccess()
+	err := put.AddMapAnyOrdered(typetags.NewOrderedMapAny(
+		typetags.OPAny("name", "a \"quoted\"\n\x01 line"),
+		typetags.OPAny("id", int64(-42)),
+		typetags.OPAny("ratio", 0.5),
+		typetags.OPAny("small", float32(1e-7)),
+		typetags.OPAny("ok", true),
+		typetags.OPAny("tags", []any{"x", int8(1), nil}),
+		typetags.OPAny("empty", map[string]any{}),
+		typetags.OPAny("none", []any{}),
+	), false)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, ToJSON(put.Pack(), &out))
+	assert.Equal(t,
+		`{"name":"a \"quoted\"\n\u0001 line","id":-42,"ratio":0.5,"small":1e-7,"ok":true,"tags":["x",1,null],"empty":{},"none":null}`,
+		out.String())
+	assert.True(t, json.Valid(out.Bytes()))
+}
+
+func TestToJSON_TopLevelFields(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt16(1)
+	put.AddNullableFloat64(nil)
+	put.AddBytes([]byte{'o', 0xff, 'k'})
+
+	var out bytes.Buffer
+	require.NoError(t, ToJSON(put.Pack(), &out))
+	assert.Equal(t, `[1,null,"o�k"]`, out.String())
+
+	put = NewPutAccess()
+	put.AddString("single")
+	out.Reset()
+	require.NoError(t, ToJSON(put.Pack(), &out))
+	assert.Equal(t, `"single"`, out.String())
+}
+
+func TestToJSON_MatchesEncodingJSON(t *testing.T) {
+	items := make([]any, 0, 300)
+	for i := 0; i < 300; i++ {
+		items = append(items, map[string]any{"i": int32(i), "s": strings.Repeat("v", i%4)})
+	}
+	put := NewPutAccess()
+	require.NoError(t, put.AddAnyTuple(items, false))
+	buf := put.Pack()
+
+	var out bytes.Buffer
+	require.NoError(t, ToJSON(buf, &out))
+
+	decoded, err := Decode(buf)
+	require.NoError(t, err)
+	expected, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expected), out.String())
+}
+
+func TestFromJSON_PacksTokens(t *testing.T) {
+	buf, err := FromJSON(strings.NewReader(`{"b": 1, "a": [true, null, 2.5, "x"], "big": 9007199254740993, "empty": {}, "none": []}`))
+	require.NoError(t, err)
+
+	decoded, err := DecodeOrdered(buf)
+	require.NoError(t, err)
+	om := decoded.(*typetags.OrderedMapAny)
+	assert.Equal(t, []string{"b", "a", "big", "empty", "none"}, om.Keys())
+	b, _ := om.Get("b")
+	assert.Equal(t, int8(1), b)
+	a, _ := om.Get("a")
+	assert.Equal(t, []any{true, []any(nil), 2.5, "x"}, a)
+	big, _ := om.Get("big")
+	assert.Equal(t, int64(9007199254740993), big, "integers must not lose precision")
+}
+
+func TestFromJSON_RoundTripWithToJSON(t *testing.T) {
+	src := `{"id":70000,"name":"svc","tags":["a","b"],"inner":{"ok":false,"ratio":0.125},"note":null}`
+	buf, err := FromJSON(strings.NewReader(src))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, ToJSON(buf, &out))
+	assert.Equal(t, src, out.String())
+}
+
+func TestFromJSON_MultipleValuesAndErrors(t *testing.T) {
+	buf, err := FromJSON(strings.NewReader(`1 "two" [3]`))
+	require.NoError(t, err)
+	decoded, err := Decode(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int8(1), "two", []any{int8(3)}}, decoded)
+
+	_, err = FromJSON(strings.NewReader(`{"a": }`))
+	assert.Error(t, err)
+	_, err = FromJSON(strings.NewReader(``))
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+package access
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// DecodeLimits bounds the resources spent reading one buffer, for buffers
+// received from untrusted peers. A zero field means no limit. The limits
+// travel with a SeqGetAccess into the accessors of its nested containers,
+// so Decode, DecodeMapAny and schema decoders all respect them.
+type DecodeLimits struct {
+	// MaxDepth is the deepest container nesting below the root.
+	MaxDepth int
+	// MaxElements bounds the fields of all containers together. A
+	// container is charged each time it is opened.
+	MaxElements int
+	// MaxStringLen is the longest string or byte array, in bytes.
+	MaxStringLen int
+	// MaxTotalBytes bounds the buffer length plus the length of every
+	// decompressed container body.
+	MaxTotalBytes int
+}
+
+// ErrDecodeLimit is wrapped by the errors of reads that exceed a DecodeLimits.
+var ErrDecodeLimit = errors.New("decode limit exceeded")
+
+// decodeBudget is shared by a root accessor and its nested accessors.
+type decodeBudget struct {
+	DecodeLimits
+	elements int
+	bytes    int
+}
+
+// NewSeqGetAccessWithLimits is NewSeqGetAccess for a buffer read within
+// limits.
+func NewSeqGetAccessWithLimits(buf []byte, limits DecodeLimits) (*SeqGetAccess, error) {
+	budget := &decodeBudget{DecodeLimits: limits}
+	if err := budget.chargeBytes(len(buf)); err != nil {
+		return nil, err
+	}
+	s, err := NewSeqGetAccess(buf)
+	if err != nil {
+		return nil, err
+	}
+	if err := budget.chargeElements(s.ArgCount()); err != nil {
+		return nil, err
+	}
+	s.budget = budget
+	return s, nil
+}
+
+// Limits returns the limits s reads within.
+func (s *SeqGetAccess) Limits() DecodeLimits {
+	if s.budget == nil {
+		return DecodeLimits{}
+	}
+	return s.budget.DecodeLimits
+}
+
+func (b *decodeBudget) chargeBytes(n int) error {
+	b.bytes += n
+	if b.MaxTotalBytes > 0 && b.bytes > b.MaxTotalBytes {
+		return fmt.Errorf("%w: more than %d bytes", ErrDecodeLimit, b.MaxTotalBytes)
+	}
+	return nil
+}
+
+func (b *decodeBudget) chargeElements(n int) error {
+	b.elements += n
+	if b.MaxElements > 0 && b.elements > b.MaxElements {
+		return fmt.Errorf("%w: more than %d elements", ErrDecodeLimit, b.MaxElements)
+	}
+	return nil
+}
+
+// remainingBytes is the decompressed length still allowed, or 0 for no
+// limit.
+func (b *decodeBudget) remainingBytes() int {
+	if b == nil || b.MaxTotalBytes <= 0 {
+		return 0
+	}
+	return max(b.MaxTotalBytes-b.bytes, 1)
+}
+
+// checkWidth rejects strings and byte arrays longer than MaxStringLen.
+func (b *decodeBudget) checkWidth(typ typetags.Type, width int) error {
+	if b != nil && b.MaxStringLen > 0 && typ == typetags.TypeString && width > b.MaxStringLen {
+		return fmt.Errorf("%w: %d-byte string, at most %d", ErrDecodeLimit, width, b.MaxStringLen)
+	}
+	return nil
+}
+
+// openNested charges the opening of nested, a container of s.
+func (s *SeqGetAccess) openNested(nested *SeqGetAccess, decompressed int) error {
+	nested.depth = s.depth + 1
+	b := s.budget
+	if b == nil {
+		return nil
+	}
+	nested.budget = b
+	if b.MaxDepth > 0 && nested.depth > b.MaxDepth {
+		return fmt.Errorf("%w: nesting deeper than %d", ErrDecodeLimit, b.MaxDepth)
+	}
+	if err := b.chargeBytes(decompressed); err != nil {
+		return err
+	}
+	return b.chargeElements(nested.ArgCount())
+}
+
+// DecodeWithLimits is Decode within limits.
+func DecodeWithLimits(buf []byte, limits DecodeLimits) (any, error) {
+	seq, err := NewSeqGetAccessWithLimits(buf, limits)
+	if err != nil {
+		return nil, fmt.Errorf("Decode: failed to create sequence: %w", err)
+	}
+	vals, err := DecodeTupleGeneric(seq, true, false)
+	if err != nil {
+		return nil, fmt.Errorf("Decode: tuple decode failed: %w", err)
+	}
+	if len(vals) == 1 {
+		return vals[0], nil
+	}
+	return vals, nil
+}
@@ -0,0 +1,67 @@
+package access
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func nestedTuples(depth int) []byte {
+	var v any = "leaf"
+	for i := 0; i < depth; i++ {
+		v = []any{v}
+	}
+	put := NewPutAccess()
+	if err := put.AddAny(v, false); err != nil {
+		panic(err)
+	}
+	return put.Pack()
+}
+
+func TestDecodeWithLimits(t *testing.T) {
+	deep := nestedTuples(10)
+	_, err := DecodeWithLimits(deep, DecodeLimits{MaxDepth: 10})
+	require.NoError(t, err)
+	_, err = DecodeWithLimits(deep, DecodeLimits{MaxDepth: 9})
+	assert.ErrorIs(t, err, ErrDecodeLimit)
+
+	put := NewPutAccess()
+	require.NoError(t, put.AddMapAny(map[string]any{"a": "x", "b": []any{int8(1), int8(2), int8(3)}}, false))
+	buf := put.Pack()
+	// root 1, map 4, tuple 3
+	_, err = DecodeWithLimits(buf, DecodeLimits{MaxElements: 8})
+	require.NoError(t, err)
+	_, err = DecodeWithLimits(buf, DecodeLimits{MaxElements: 7})
+	assert.ErrorIs(t, err, ErrDecodeLimit)
+
+	seq, err := NewSeqGetAccessWithLimits(buf, DecodeLimits{MaxStringLen: 1})
+	require.NoError(t, err)
+	_, err = DecodeMapAny(seq)
+	require.NoError(t, err)
+
+	put = NewPutAccess()
+	put.AddString(strings.Repeat("s", 100))
+	_, err = DecodeWithLimits(put.Pack(), DecodeLimits{MaxStringLen: 99})
+	assert.ErrorIs(t, err, ErrDecodeLimit)
+	_, err = DecodeWithLimits(put.Pack(), DecodeLimits{MaxTotalBytes: 50})
+	assert.ErrorIs(t, err, ErrDecodeLimit)
+}
+
+func TestDecodeWithLimits_Decompressed(t *testing.T) {
+	put := NewPutAccess()
+	c := put.BeginCompressedTuple(FlateCompressor{})
+	for i := 0; i < 200; i++ {
+		c.AddString("compressible")
+	}
+	put.EndNested(c)
+	buf := put.Pack()
+	require.Less(t, len(buf), 500)
+
+	_, err := DecodeWithLimits(buf, DecodeLimits{MaxTotalBytes: 1000})
+	assert.ErrorIs(t, err, ErrDecodeLimit)
+	v, err := DecodeWithLimits(buf, DecodeLimits{MaxTotalBytes: 1 << 20})
+	require.NoError(t, err)
+	assert.Len(t, v, 200)
+}
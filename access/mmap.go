@@ -0,0 +1,78 @@
+package access
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrMmapClosed = errors.New("mmap: access after Close")
+
+// MmapGetAccess is a GetAccess over a read-only memory-mapped file, so large
+// packed files can be queried without copying them onto the heap.
+//
+// Slices and unsafe strings returned by accessors (GetBytes, GetStringUnsafe,
+// SeqGetAccess.Next and friends) alias the mapping and must not be used after
+// Close. Use GetCopyBytes or GetString for values that outlive it. After Close
+// the embedded GetAccess is empty, so further lookups fail with an error
+// instead of touching unmapped memory. Close is not safe to call concurrently
+// with readers.
+type MmapGetAccess struct {
+	*GetAccess
+	data  []byte
+	unmap func([]byte) error
+}
+
+// NewGetAccessMmap maps the file at path and validates its top-level header.
+func NewGetAccessMmap(path string) (*MmapGetAccess, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size < 4 {
+		return nil, fmt.Errorf("mmap %s: file too small for a packed buffer", path)
+	}
+	if int64(int(size)) != size {
+		return nil, fmt.Errorf("mmap %s: file too large", path)
+	}
+	data, unmap, err := mmapFile(f, int(size))
+	if err != nil {
+		return nil, fmt.Errorf("mmap %s: %w", path, err)
+	}
+	get := NewGetAccess(data)
+	if get == nil {
+		unmap(data)
+		return nil, fmt.Errorf("mmap %s: invalid header", path)
+	}
+	return &MmapGetAccess{GetAccess: get, data: data, unmap: unmap}, nil
+}
+
+// Bytes returns the mapped buffer, or nil after Close.
+func (m *MmapGetAccess) Bytes() []byte {
+	return m.data
+}
+
+// Seq returns a sequential reader over the mapped buffer.
+func (m *MmapGetAccess) Seq() (*SeqGetAccess, error) {
+	if m.data == nil {
+		return nil, ErrMmapClosed
+	}
+	return NewSeqGetAccess(m.data)
+}
+
+// Close unmaps the file. Calling Close more than once is a no-op.
+func (m *MmapGetAccess) Close() error {
+	if m.data == nil {
+		return nil
+	}
+	data := m.data
+	m.data = nil
+	m.GetAccess = &GetAccess{}
+	return m.unmap(data)
+}
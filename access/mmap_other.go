@@ -0,0 +1,17 @@
+//go:build !unix
+
+package access
+
+import (
+	"io"
+	"os"
+)
+
+// mmapFile falls back to reading the file on platforms without syscall.Mmap.
+func mmapFile(f *os.File, size int) ([]byte, func([]byte) error, error) {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(f, data); err != nil {
+		return nil, nil, err
+	}
+	return data, func([]byte) error { return nil }, nil
+}
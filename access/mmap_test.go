@@ -0,0 +1,67 @@
+package access
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGetAccessMmap(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt32(7)
+	put.AddString("mapped")
+	put.AddString("")
+	put.AddMapAny(map[string]any{"k": "v"}, true)
+	path := filepath.Join(t.TempDir(), "data.pack")
+	require.NoError(t, os.WriteFile(path, put.Pack(), 0o644))
+
+	m, err := NewGetAccessMmap(path)
+	require.NoError(t, err)
+
+	v, err := m.GetInt32(0)
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), v)
+	s, err := m.GetString(1)
+	require.NoError(t, err)
+	assert.Equal(t, "mapped", s)
+	empty, err := m.GetStringUnsafe(2)
+	require.NoError(t, err)
+	assert.Equal(t, "", empty)
+	mp, err := m.GetMapAny(3)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"k": "v"}, mp)
+
+	seq, err := m.Seq()
+	require.NoError(t, err)
+	assert.Equal(t, 4, seq.ArgCount())
+
+	require.NoError(t, m.Close())
+	require.NoError(t, m.Close())
+	_, err = m.GetString(1)
+	assert.Error(t, err)
+	_, err = GetAny(m.GetAccess, 0)
+	assert.Error(t, err)
+	_, err = m.Seq()
+	assert.ErrorIs(t, err, ErrMmapClosed)
+	assert.Nil(t, m.Bytes())
+}
+
+func TestNewGetAccessMmap_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewGetAccessMmap(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+
+	small := filepath.Join(dir, "small")
+	require.NoError(t, os.WriteFile(small, []byte{1}, 0o644))
+	_, err = NewGetAccessMmap(small)
+	assert.Error(t, err)
+
+	bad := filepath.Join(dir, "bad")
+	// header claims a 64 byte header block
+	require.NoError(t, os.WriteFile(bad, []byte{0x00, 0x02, 0, 0}, 0o644))
+	_, err = NewGetAccessMmap(bad)
+	assert.Error(t, err)
+}
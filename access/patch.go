@@ -0,0 +1,236 @@
+package access
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// Patcher overwrites fixed-width primitive payloads (ints, floats, bools)
+// inside an already packed buffer without repacking it.
+// Only the payload bytes change; headers and widths stay as they are,
+// so a value can be replaced only by one of the same type and width.
+type Patcher struct {
+	buf []byte
+}
+
+// NewPatcher wraps buf for in-place patching. The buffer is modified directly.
+func NewPatcher(buf []byte) (*Patcher, error) {
+	if NewGetAccess(buf) == nil {
+		return nil, fmt.Errorf("NewPatcher: insufficient header")
+	}
+	return &Patcher{buf: buf}, nil
+}
+
+// Buffer returns the patched buffer.
+func (p *Patcher) Buffer() []byte {
+	return p.buf
+}
+
+// PatchField is a writable view over a single primitive payload.
+type PatchField struct {
+	Tag     typetags.Type
+	payload []byte
+}
+
+// Width returns the payload width of the field in bytes.
+func (f PatchField) Width() int {
+	return len(f.payload)
+}
+
+func fieldAt(g *GetAccess, pos int) (PatchField, error) {
+	if pos < 0 || pos >= g.argCount {
+		return PatchField{}, fmt.Errorf("patch: position %d out of range [0, %d)", pos, g.argCount)
+	}
+	tp, start, end := g.rangeAt(pos)
+	if start < 0 || end < start {
+		return PatchField{}, fmt.Errorf("patch: invalid range %d → %d at pos %d", start, end, pos)
+	}
+	return PatchField{Tag: tp, payload: g.buf[start:end]}, nil
+}
+
+// Field returns the top-level field at header position pos.
+func (p *Patcher) Field(pos int) (PatchField, error) {
+	return fieldAt(NewGetAccess(p.buf), pos)
+}
+
+// FieldPath resolves a dotted path to a field.
+// Segments inside tuples (including the top level) are indexes,
+// segments inside maps are keys: "1.meta.count" → tuple index 1 → key "meta" → key "count".
+func (p *Patcher) FieldPath(path string) (PatchField, error) {
+	g := NewGetAccess(p.buf)
+	tag := typetags.TypeTuple
+	segments := strings.Split(path, ".")
+	for i, seg := range segments {
+		pos, err := segmentPos(g, tag, seg)
+		if err != nil {
+			return PatchField{}, fmt.Errorf("patch: path %q: %w", path, err)
+		}
+		if i == len(segments)-1 {
+			return fieldAt(g, pos)
+		}
+		nested, tp, err := g.GetNestedGetAccess(pos)
+		if err != nil {
+			return PatchField{}, fmt.Errorf("patch: path %q at %q: %w", path, seg, err)
+		}
+		if nested == nil {
+			return PatchField{}, fmt.Errorf("patch: path %q at %q: empty container", path, seg)
+		}
+		g, tag = nested, tp
+	}
+	return PatchField{}, fmt.Errorf("patch: empty path")
+}
+
+// segmentPos maps a path segment to a header position inside g.
+func segmentPos(g *GetAccess, tag typetags.Type, seg string) (int, error) {
+	if tag.IsMap() {
+		for i := 0; i+1 < g.argCount; i += 2 {
+			tp, key := g.GetTypeAndValue(i)
+			if tp != typetags.TypeString {
+				return 0, fmt.Errorf("map key at %d is not a string", i)
+			}
+			if string(key) == seg {
+				return i + 1, nil
+			}
+		}
+		return 0, fmt.Errorf("key %q not found", seg)
+	}
+	idx, err := strconv.Atoi(seg)
+	if err != nil {
+		return 0, fmt.Errorf("tuple index %q: %w", seg, err)
+	}
+	return idx, nil
+}
+
+func (f PatchField) check(tag typetags.Type, width int) error {
+	if f.Tag != tag {
+		return fmt.Errorf("patch: type mismatch — expected %v, got %v", tag, f.Tag)
+	}
+	if len(f.payload) != width {
+		return fmt.Errorf("patch: width mismatch — expected %d, got %d", width, len(f.payload))
+	}
+	return nil
+}
+
+func (f PatchField) SetInt8(v int8) error {
+	if err := f.check(typetags.TypeInteger, 1); err != nil {
+		return err
+	}
+	f.payload[0] = byte(v)
+	return nil
+}
+
+func (f PatchField) SetUint8(v uint8) error {
+	if err := f.check(typetags.TypeInteger, 1); err != nil {
+		return err
+	}
+	f.payload[0] = v
+	return nil
+}
+
+func (f PatchField) SetInt16(v int16) error {
+	return f.SetUint16(uint16(v))
+}
+
+func (f PatchField) SetUint16(v uint16) error {
+	if err := f.check(typetags.TypeInteger, 2); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint16(f.payload, v)
+	return nil
+}
+
+func (f PatchField) SetInt32(v int32) error {
+	return f.SetUint32(uint32(v))
+}
+
+func (f PatchField) SetUint32(v uint32) error {
+	if err := f.check(typetags.TypeInteger, 4); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(f.payload, v)
+	return nil
+}
+
+func (f PatchField) SetInt64(v int64) error {
+	return f.SetUint64(uint64(v))
+}
+
+func (f PatchField) SetUint64(v uint64) error {
+	if err := f.check(typetags.TypeInteger, 8); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint64(f.payload, v)
+	return nil
+}
+
+func (f PatchField) SetFloat16(v float32) error {
+	if err := f.check(typetags.TypeFloating, 2); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint16(f.payload, Float16bits(v))
+	return nil
+}
+
+func (f PatchField) SetBFloat16(v float32) error {
+	if err := f.check(typetags.TypeFloating, 2); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint16(f.payload, BFloat16bits(v))
+	return nil
+}
+
+func (f PatchField) SetFloat32(v float32) error {
+	if err := f.check(typetags.TypeFloating, 4); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(f.payload, math.Float32bits(v))
+	return nil
+}
+
+func (f PatchField) SetFloat64(v float64) error {
+	if err := f.check(typetags.TypeFloating, 8); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint64(f.payload, math.Float64bits(v))
+	return nil
+}
+
+func (f PatchField) SetBool(v bool) error {
+	if err := f.check(typetags.TypeBool, 1); err != nil {
+		return err
+	}
+	f.payload[0] = 0
+	if v {
+		f.payload[0] = 1
+	}
+	return nil
+}
+
+// SetInteger writes v using the field's existing integer width.
+// It fails if v does not fit into that width.
+func (f PatchField) SetInteger(v int64) error {
+	switch len(f.payload) {
+	case 1:
+		if v < math.MinInt8 || v > math.MaxInt8 {
+			return fmt.Errorf("patch: %d overflows int8", v)
+		}
+		return f.SetInt8(int8(v))
+	case 2:
+		if v < math.MinInt16 || v > math.MaxInt16 {
+			return fmt.Errorf("patch: %d overflows int16", v)
+		}
+		return f.SetInt16(int16(v))
+	case 4:
+		if v < math.MinInt32 || v > math.MaxInt32 {
+			return fmt.Errorf("patch: %d overflows int32", v)
+		}
+		return f.SetInt32(int32(v))
+	default:
+		return f.SetInt64(v)
+	}
+}
@@ -0,0 +1,122 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatcher_TopLevelFields(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt32(10)
+	put.AddFloat64(1.5)
+	put.AddBool(false)
+	put.AddString("keep")
+	buf := put.Pack()
+
+	p, err := NewPatcher(buf)
+	require.NoError(t, err)
+
+	f, err := p.Field(0)
+	require.NoError(t, err)
+	require.NoError(t, f.SetInt32(99))
+
+	f, err = p.Field(1)
+	require.NoError(t, err)
+	require.NoError(t, f.SetFloat64(2.25))
+
+	f, err = p.Field(2)
+	require.NoError(t, err)
+	require.NoError(t, f.SetBool(true))
+
+	g := NewGetAccess(p.Buffer())
+	v, err := g.GetInt32(0)
+	require.NoError(t, err)
+	assert.Equal(t, int32(99), v)
+	fv, err := g.GetFloat64(1)
+	require.NoError(t, err)
+	assert.Equal(t, 2.25, fv)
+	bv, err := g.GetBool(2)
+	require.NoError(t, err)
+	assert.True(t, bv)
+	s, err := g.GetString(3)
+	require.NoError(t, err)
+	assert.Equal(t, "keep", s)
+}
+
+func TestPatcher_Mismatch(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt16(1)
+	put.AddString("x")
+	put.AddNullableInt64(nil)
+	p, err := NewPatcher(put.Pack())
+	require.NoError(t, err)
+
+	f, err := p.Field(0)
+	require.NoError(t, err)
+	assert.Error(t, f.SetInt32(1), "width mismatch")
+	assert.Error(t, f.SetFloat32(1), "type mismatch")
+	assert.Error(t, f.SetInteger(70000), "overflow")
+	assert.NoError(t, f.SetInteger(-3))
+
+	f, err = p.Field(1)
+	require.NoError(t, err)
+	assert.Error(t, f.SetBool(true))
+
+	f, err = p.Field(2)
+	require.NoError(t, err)
+	assert.Equal(t, 0, f.Width())
+	assert.Error(t, f.SetInt64(1), "null payload cannot be patched")
+
+	_, err = p.Field(5)
+	assert.Error(t, err)
+}
+
+func TestPatcher_FieldPath(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt8(1)
+	err := put.AddMapAnyOrdered(typetags.NewOrderedMapAny(
+		typetags.OPAny("name", "svc"),
+		typetags.OPAny("stats", typetags.NewOrderedMapAny(
+			typetags.OPAny("hits", int64(5)),
+			typetags.OPAny("ok", true),
+		)),
+		typetags.OPAny("list", []any{int16(1), int16(2)}),
+	), false)
+	require.NoError(t, err)
+	buf := put.Pack()
+
+	p, err := NewPatcher(buf)
+	require.NoError(t, err)
+
+	f, err := p.FieldPath("1.stats.hits")
+	require.NoError(t, err)
+	require.NoError(t, f.SetInt64(6))
+
+	f, err = p.FieldPath("1.stats.ok")
+	require.NoError(t, err)
+	require.NoError(t, f.SetBool(false))
+
+	f, err = p.FieldPath("1.list.1")
+	require.NoError(t, err)
+	require.NoError(t, f.SetInt16(20))
+
+	_, err = p.FieldPath("1.missing")
+	assert.Error(t, err)
+	_, err = p.FieldPath("x")
+	assert.Error(t, err)
+
+	decoded, err := DecodeOrdered(p.Buffer())
+	require.NoError(t, err)
+	arr := decoded.([]any)
+	om := arr[1].(*typetags.OrderedMapAny)
+	stats := typetags.GetAs[*typetags.OrderedMapAny](om, "stats")
+	hits, _ := stats.Get("hits")
+	ok, _ := stats.Get("ok")
+	list, _ := om.Get("list")
+	assert.Equal(t, int64(6), hits)
+	assert.Equal(t, false, ok)
+	assert.Equal(t, []any{int16(1), int16(20)}, list)
+}
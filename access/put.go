@@ -2,11 +2,15 @@ package access
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
 	"math"
 	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 	"unsafe"
 
 	"github.com/quickwritereader/PackOS/typetags"
@@ -22,12 +26,31 @@ var putAccessPool = sync.Pool{
 	},
 }
 
+// GetPutAccess takes an empty PutAccess from the cache set with
+// SetPutAccessCache, or from the shared pool.
 func GetPutAccess() *PutAccess {
+	if c := putCache.Load(); c != nil {
+		return c.Get()
+	}
+	return getPooled()
+}
+
+func getPooled() *PutAccess {
 	p := putAccessPool.Get().(*PutAccess)
+	p.reset()
+	return p
+}
+
+// reset empties p for reuse, keeping its buffers.
+func (p *PutAccess) reset() {
 	p.buf = p.buf[:0]
 	p.offsets = p.offsets[:0]
 	p.position = 0
-	return p
+	p.names = p.names[:0]
+	p.deterministic = defaultDeterministic.Load()
+	p.intern, p.internRoot = nil, false
+	p.compressor = nil
+	p.newHash, p.digest, p.hashed = nil, nil, 0
 }
 
 func GetPutAccessZero() *PutAccess {
@@ -35,29 +58,82 @@ func GetPutAccessZero() *PutAccess {
 	clear(pt.buf)
 	clear(pt.offsets)
 	pt.position = 0
+	pt.names = pt.names[:0]
+	pt.deterministic = defaultDeterministic.Load()
+	pt.intern, pt.internRoot = nil, false
+	pt.compressor = nil
+	pt.newHash, pt.digest, pt.hashed = nil, nil, 0
 	return pt
 }
 
+// ReleasePutAccess returns pa to the cache set with SetPutAccessCache, or
+// to the shared pool.
 func ReleasePutAccess(pa *PutAccess) {
-	// Optionally reset fields before putting back to pool
+	if c := putCache.Load(); c != nil {
+		c.Put(pa)
+		return
+	}
 	putAccessPool.Put(pa)
 }
 
 type PutAccess struct {
-	buf      []byte // payload buffer
-	offsets  []byte // header entries: offset + type tag
-	position int    // current payload write position
+	buf      []byte      // payload buffer
+	offsets  []byte      // header entries: offset + type tag
+	position int         // current payload write position
+	names    []indexName // field names recorded by Named for PackWithIndex
+	// deterministic routes map packing through the sorted-key paths
+	deterministic bool
+	// intern is shared with nested containers; internRoot marks the
+	// PutAccess whose output carries the dictionary
+	intern     *internTable
+	internRoot bool
+	// compressor is set on containers opened with BeginCompressedMap or
+	// BeginCompressedTuple
+	compressor Compressor
+	// digest hashes buf[:hashed] for PackWithDigest; see SetDigest
+	newHash func() hash.Hash
+	digest  hash.Hash
+	hashed  int
 }
 
 // NewPutAccess initializes a new packing buffer
 
 func NewPutAccess() *PutAccess {
 	return &PutAccess{
-		buf:     make([]byte, 0, 256),
-		offsets: make([]byte, 0, 64),
+		buf:           make([]byte, 0, 256),
+		offsets:       make([]byte, 0, 64),
+		deterministic: defaultDeterministic.Load(),
 	}
 }
 
+var defaultDeterministic atomic.Bool
+
+// SetDefaultDeterministic sets whether new PutAccess values start with
+// deterministic map packing. See PutAccess.SetDeterministic.
+func SetDefaultDeterministic(on bool) {
+	defaultDeterministic.Store(on)
+}
+
+// SetDeterministic makes AddMap, AddMapStr, AddMapAny, AddAny and AddAnyTuple
+// write map keys in ascending order at every nesting level, so equal inputs
+// always pack to equal bytes. Ordered maps keep their insertion order.
+func (p *PutAccess) SetDeterministic(on bool) {
+	p.deterministic = on
+}
+
+// Deterministic reports whether p packs maps with sorted keys.
+func (p *PutAccess) Deterministic() bool {
+	return p.deterministic
+}
+
+// newNested takes a pooled PutAccess for a nested container that inherits p's settings.
+func (p *PutAccess) newNested() *PutAccess {
+	nested := NewPutAccessFromPool()
+	nested.deterministic = p.deterministic
+	nested.intern = p.intern
+	return nested
+}
+
 func NewPutAccessFromPool() *PutAccess {
 	return GetPutAccess()
 }
@@ -69,7 +145,7 @@ func NewPutAccessFromPoolZero() *PutAccess {
 func (p *PutAccess) AppendTagAndValue(tag typetags.Type, val []byte) {
 	p.buf = append(p.buf, val...)
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, tag))
-	p.position = len(p.buf)
+	p.advance()
 }
 
 // AddInt16 packs an int16 value
@@ -77,7 +153,7 @@ func (p *PutAccess) AppendTagAndValue(tag typetags.Type, val []byte) {
 func (p *PutAccess) AddInt16(v int16) {
 	p.buf = binary.LittleEndian.AppendUint16(p.buf, uint16(v))
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
-	p.position = len(p.buf)
+	p.advance()
 }
 
 // AddInt32 packs an int32 value
@@ -85,7 +161,7 @@ func (p *PutAccess) AddInt16(v int16) {
 func (p *PutAccess) AddInt32(v int32) {
 	p.buf = binary.LittleEndian.AppendUint32(p.buf, uint32(v))
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
-	p.position = len(p.buf)
+	p.advance()
 }
 
 // AddInt64 packs an int64 value
@@ -93,28 +169,28 @@ func (p *PutAccess) AddInt32(v int32) {
 func (p *PutAccess) AddInt64(v int64) {
 	p.buf = binary.LittleEndian.AppendUint64(p.buf, uint64(v))
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
-	p.position = len(p.buf)
+	p.advance()
 }
 
 // AddUint16 packs a uint16 value.
 func (p *PutAccess) AddUint16(v uint16) {
 	p.buf = binary.LittleEndian.AppendUint16(p.buf, v)
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
-	p.position = len(p.buf)
+	p.advance()
 }
 
 // AddUint32 packs a uint32 value.
 func (p *PutAccess) AddUint32(v uint32) {
 	p.buf = binary.LittleEndian.AppendUint32(p.buf, v)
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
-	p.position = len(p.buf)
+	p.advance()
 }
 
 // AddUint64 packs a uint64 value.
 func (p *PutAccess) AddUint64(v uint64) {
 	p.buf = binary.LittleEndian.AppendUint64(p.buf, v)
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
-	p.position = len(p.buf)
+	p.advance()
 }
 
 // AddFloat32 packs a float32 value
@@ -122,7 +198,7 @@ func (p *PutAccess) AddUint64(v uint64) {
 func (p *PutAccess) AddFloat32(v float32) {
 	p.buf = binary.LittleEndian.AppendUint32(p.buf, math.Float32bits(v))
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeFloating))
-	p.position = len(p.buf)
+	p.advance()
 }
 
 // AddFloat64 packs a float64 value
@@ -130,7 +206,7 @@ func (p *PutAccess) AddFloat32(v float32) {
 func (p *PutAccess) AddFloat64(v float64) {
 	p.buf = binary.LittleEndian.AppendUint64(p.buf, math.Float64bits(v))
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeFloating))
-	p.position = len(p.buf)
+	p.advance()
 }
 
 // AddNumeric chooses the smallest fitting integer type if v is integral,
@@ -164,7 +240,7 @@ func (p *PutAccess) AddUint8(b uint8) {
 
 	p.buf = append(p.buf, byte(b))
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
-	p.position = len(p.buf)
+	p.advance()
 }
 
 // AddInt8 packs a boolean value as a single byte
@@ -172,7 +248,7 @@ func (p *PutAccess) AddInt8(b int8) {
 
 	p.buf = append(p.buf, byte(b))
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
-	p.position = len(p.buf)
+	p.advance()
 }
 
 // AddBool packs a boolean value as a single byte
@@ -185,7 +261,7 @@ func (p *PutAccess) AddBool(b bool) {
 	}
 	p.buf = append(p.buf, bv)
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeBool))
-	p.position = len(p.buf)
+	p.advance()
 }
 
 func (p *PutAccess) AddNullableInt8(v *int8) {
@@ -193,7 +269,7 @@ func (p *PutAccess) AddNullableInt8(v *int8) {
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
 	if v != nil {
 		p.buf = append(p.buf, byte(*v))
-		p.position = len(p.buf)
+		p.advance()
 	}
 }
 
@@ -202,7 +278,7 @@ func (p *PutAccess) AddNullableInt16(v *int16) {
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
 	if v != nil {
 		p.buf = binary.LittleEndian.AppendUint16(p.buf, uint16(*v))
-		p.position = len(p.buf)
+		p.advance()
 	}
 }
 
@@ -211,7 +287,7 @@ func (p *PutAccess) AddNullableInt32(v *int32) {
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
 	if v != nil {
 		p.buf = binary.LittleEndian.AppendUint32(p.buf, uint32(*v))
-		p.position = len(p.buf)
+		p.advance()
 	}
 }
 
@@ -220,7 +296,7 @@ func (p *PutAccess) AddNullableInt64(v *int64) {
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
 	if v != nil {
 		p.buf = binary.LittleEndian.AppendUint64(p.buf, uint64(*v))
-		p.position = len(p.buf)
+		p.advance()
 	}
 }
 
@@ -229,7 +305,7 @@ func (p *PutAccess) AddNullableUint8(v *uint8) {
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
 	if v != nil {
 		p.buf = append(p.buf, byte(*v))
-		p.position = len(p.buf)
+		p.advance()
 	}
 }
 
@@ -238,7 +314,7 @@ func (p *PutAccess) AddNullableUint16(v *uint16) {
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
 	if v != nil {
 		p.buf = binary.LittleEndian.AppendUint16(p.buf, *v)
-		p.position = len(p.buf)
+		p.advance()
 	}
 }
 
@@ -247,7 +323,7 @@ func (p *PutAccess) AddNullableUint32(v *uint32) {
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
 	if v != nil {
 		p.buf = binary.LittleEndian.AppendUint32(p.buf, *v)
-		p.position = len(p.buf)
+		p.advance()
 	}
 }
 
@@ -256,7 +332,7 @@ func (p *PutAccess) AddNullableUint64(v *uint64) {
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
 	if v != nil {
 		p.buf = binary.LittleEndian.AppendUint64(p.buf, *v)
-		p.position = len(p.buf)
+		p.advance()
 	}
 }
 
@@ -265,7 +341,7 @@ func (p *PutAccess) AddNullableFloat32(v *float32) {
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeFloating))
 	if v != nil {
 		p.buf = binary.LittleEndian.AppendUint32(p.buf, math.Float32bits(*v))
-		p.position = len(p.buf)
+		p.advance()
 	}
 }
 
@@ -274,7 +350,7 @@ func (p *PutAccess) AddNullableFloat64(v *float64) {
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeFloating))
 	if v != nil {
 		p.buf = binary.LittleEndian.AppendUint64(p.buf, math.Float64bits(*v))
-		p.position = len(p.buf)
+		p.advance()
 	}
 }
 
@@ -287,7 +363,7 @@ func (p *PutAccess) AddNullableBool(v *bool) {
 			b = 1
 		}
 		p.buf = append(p.buf, b)
-		p.position = len(p.buf)
+		p.advance()
 	}
 }
 
@@ -297,21 +373,49 @@ func (p *PutAccess) AddBytes(b []byte) {
 
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeString))
 	p.buf = append(p.buf, b...)
-	p.position = len(p.buf)
+	p.advance()
+}
+
+// AddNullableBytes packs b, or a null marker if b is nil. Unlike AddBytes,
+// an empty non-nil slice stays distinguishable from nil: the null marker is
+// a zero-width TypeNull field rather than a zero-width byte array.
+func (p *PutAccess) AddNullableBytes(b []byte) {
+	if b == nil {
+		p.AddNull(nil)
+		return
+	}
+	p.AddBytes(b)
+}
+
+// AddNullableString packs *v, or a null marker if v is nil, so that "" and
+// null stay distinguishable. See AddNullableBytes.
+func (p *PutAccess) AddNullableString(v *string) {
+	if v == nil {
+		p.AddNull(nil)
+		return
+	}
+	p.AddString(*v)
 }
 
 // AddString packs a string using unsafe zero-copy conversion
 
 func (p *PutAccess) AddString(s string) {
+	if p.intern != nil && p.addInterned(s) {
+		return
+	}
 	b := unsafe.Slice(unsafe.StringData(s), len(s))
 	p.AddBytes(b)
 }
 
 func (p *PutAccess) AddMap(m map[string][]byte) {
+	if p.deterministic {
+		p.AddMapSortedKey(m)
+		return
+	}
 
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeMap))
 	if len(m) > 0 {
-		nested := NewPutAccessFromPool()
+		nested := p.newNested()
 		for k, v := range m {
 			nested.AddString(k)
 			nested.AddBytes(v)
@@ -333,7 +437,7 @@ func (p *PutAccess) AddStringArray(arr []string) {
 		return
 	}
 
-	nested := NewPutAccessFromPool()
+	nested := p.newNested()
 	for _, s := range arr {
 		nested.AddString(s)
 	}
@@ -341,6 +445,9 @@ func (p *PutAccess) AddStringArray(arr []string) {
 }
 
 func (p *PutAccess) AddAnyTuple(m []interface{}, useNumeric bool) error {
+	if p.deterministic {
+		return p.AddAnyTupleSortedMap(m, useNumeric)
+	}
 	// encode tuple header
 	p.offsets = binary.LittleEndian.AppendUint16(
 		p.offsets,
@@ -351,7 +458,7 @@ func (p *PutAccess) AddAnyTuple(m []interface{}, useNumeric bool) error {
 		return nil
 	}
 
-	nested := NewPutAccessFromPool()
+	nested := p.newNested()
 	for _, elem := range m {
 		if err := packAnyValue(nested, elem, useNumeric); err != nil {
 			return fmt.Errorf("AddAnyTuple: element %T: %w", elem, err)
@@ -372,7 +479,7 @@ func (p *PutAccess) AddAnyTupleSortedMap(m []interface{}, useNumeric bool) error
 		return nil
 	}
 
-	nested := NewPutAccessFromPool()
+	nested := p.newNested()
 	for _, elem := range m {
 		if err := packAnyValueSortedMap(nested, elem, useNumeric); err != nil {
 			return fmt.Errorf("AddAnyTupleSortedMap: element %T: %w", elem, err)
@@ -392,10 +499,14 @@ func (p *PutAccess) AddNull(m []interface{}) {
 }
 
 func (p *PutAccess) AddMapStr(m map[string]string) {
+	if p.deterministic {
+		p.AddMapSortedKeyStr(m)
+		return
+	}
 
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeMap))
 	if len(m) > 0 {
-		nested := NewPutAccessFromPool()
+		nested := p.newNested()
 		for k, v := range m {
 			nested.AddString(k)
 			nested.AddString(v)
@@ -410,7 +521,7 @@ func (p *PutAccess) AddMapSortedKeyStr(m map[string]string) {
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeMap))
 	if len(m) > 0 {
 		keys := utils.SortKeys(m)
-		nested := NewPutAccessFromPool()
+		nested := p.newNested()
 		for _, k := range keys {
 			nested.AddString(k)
 			nested.AddString(m[k])
@@ -425,7 +536,7 @@ func (p *PutAccess) AddMapSortedKey(m map[string][]byte) {
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeMap))
 	if len(m) > 0 {
 		keys := utils.SortKeys(m)
-		nested := NewPutAccessFromPool()
+		nested := p.newNested()
 		for _, k := range keys {
 			nested.AddString(k)
 			nested.AddBytes(m[k])
@@ -474,8 +585,20 @@ func packAnyValue(p *PutAccess, v any, useNumeric bool) error {
 		}
 	case bool:
 		p.AddBool(val)
+	case time.Time:
+		p.AddTime(val)
+	case time.Duration:
+		p.AddDuration(val)
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			p.AddIntegerCompressed(i)
+		} else if f, err := val.Float64(); err == nil {
+			p.AddFloat64(f)
+		} else {
+			return fmt.Errorf("invalid json number %q", val)
+		}
 	case map[string]any:
-		p.AddMapAny(val, useNumeric)
+		err = p.AddMapAny(val, useNumeric)
 	case map[string][]byte:
 		p.AddMap(val)
 	case []string:
@@ -506,6 +629,14 @@ func packAnyValueSortedMap(p *PutAccess, v any, useNumeric bool) error {
 		p.AddBytes(val)
 	case map[string]string:
 		p.AddMapSortedKeyStr(val)
+	case uint8:
+		p.AddUint8(val)
+	case uint16:
+		p.AddUint16(val)
+	case uint32:
+		p.AddUint32(val)
+	case uint64:
+		p.AddUint64(val)
 	case int8:
 		p.AddInt8(val)
 	case int16:
@@ -524,10 +655,24 @@ func packAnyValueSortedMap(p *PutAccess, v any, useNumeric bool) error {
 		}
 	case bool:
 		p.AddBool(val)
+	case time.Time:
+		p.AddTime(val)
+	case time.Duration:
+		p.AddDuration(val)
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			p.AddIntegerCompressed(i)
+		} else if f, err := val.Float64(); err == nil {
+			p.AddFloat64(f)
+		} else {
+			return fmt.Errorf("invalid json number %q", val)
+		}
 	case map[string]any:
-		p.AddMapAny(val, useNumeric)
+		err = p.AddMapAnySortedKey(val, useNumeric)
 	case map[string][]byte:
 		p.AddMapSortedKey(val)
+	case []string:
+		p.AddStringArray(val)
 	case *typetags.OrderedMap[any]:
 		err = p.AddMapAnyOrdered(val, useNumeric)
 	case Packable:
@@ -545,13 +690,16 @@ func (p *PutAccess) AddAny(m any, useNumeric bool) error {
 }
 
 func (p *PutAccess) AddMapAny(m map[string]any, useNumeric bool) error {
+	if p.deterministic {
+		return p.AddMapAnySortedKey(m, useNumeric)
+	}
 	p.offsets = binary.LittleEndian.AppendUint16(
 		p.offsets,
 		typetags.EncodeHeader(p.position, typetags.TypeMap),
 	)
 
 	if len(m) > 0 {
-		nested := NewPutAccessFromPool()
+		nested := p.newNested()
 		for k, v := range m {
 			nested.AddString(k)
 			if err := packAnyValue(nested, v, useNumeric); err != nil {
@@ -564,14 +712,24 @@ func (p *PutAccess) AddMapAny(m map[string]any, useNumeric bool) error {
 }
 
 func (p *PutAccess) AddMapAnySortedKey(m map[string]any, useNumeric bool) error {
+	return p.addMapAnySorted(typetags.TypeMap, m, useNumeric)
+}
+
+// AddSortedMapAny writes m with ascending keys under TypeSortedMap, so readers
+// can binary-search it with GetAccess.LookupKey.
+func (p *PutAccess) AddSortedMapAny(m map[string]any, useNumeric bool) error {
+	return p.addMapAnySorted(typetags.TypeSortedMap, m, useNumeric)
+}
+
+func (p *PutAccess) addMapAnySorted(tag typetags.Type, m map[string]any, useNumeric bool) error {
 	p.offsets = binary.LittleEndian.AppendUint16(
 		p.offsets,
-		typetags.EncodeHeader(p.position, typetags.TypeMap),
+		typetags.EncodeHeader(p.position, tag),
 	)
 
 	if len(m) > 0 {
 		keys := utils.SortKeys(m)
-		nested := NewPutAccessFromPool()
+		nested := p.newNested()
 		for _, k := range keys {
 			nested.AddString(k)
 			if err := packAnyValueSortedMap(nested, m[k], useNumeric); err != nil {
@@ -592,7 +750,7 @@ func (p *PutAccess) AddMapAnyOrdered(om *typetags.OrderedMap[any], useNumeric bo
 	)
 
 	if om != nil && om.Len() > 0 {
-		nested := NewPutAccessFromPool()
+		nested := p.newNested()
 		for k, v := range om.ItemsIter() {
 			// Add key
 			nested.AddString(k)
@@ -608,15 +766,22 @@ func (p *PutAccess) AddMapAnyOrdered(om *typetags.OrderedMap[any], useNumeric bo
 
 func (p *PutAccess) appendAndReleaseNested(nested *PutAccess) {
 
-	p.buf = nested.PackAppend(p.buf)
+	if nested.compressor != nil {
+		p.appendCompressed(nested)
+	} else {
+		p.buf = nested.PackAppend(p.buf)
+	}
 	ReleasePutAccess(nested)
-	p.position = len(p.buf)
+	p.advance()
 
 }
 
 // Pack finalizes the buffer: header + payload + TypeEnd
 
 func (p *PutAccess) Pack() []byte {
+	if p.hasDictionary() {
+		return p.PackAppend(nil)
+	}
 	// Append TypeEnd header for offset-derived slicing
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeEnd(p.position))
 	// Compute final header size after appending TypeEnd
@@ -635,6 +800,9 @@ func (p *PutAccess) Pack() []byte {
 }
 
 func (p *PutAccess) PackAppend(buf []byte) []byte {
+	if p.hasDictionary() {
+		buf = p.intern.appendDictionary(buf)
+	}
 	// Append TypeEnd header for offset-derived slicing
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeEnd(p.position))
 	// Compute final header size after appending TypeEnd
@@ -654,10 +822,20 @@ func (p *PutAccess) PackAppend(buf []byte) []byte {
 // Call it before Pack. Pack adds +2
 func (p *PutAccess) PackSize() int {
 	headerSize := len(p.offsets)
+	if p.hasDictionary() {
+		headerSize += len(p.intern.appendDictionary(nil))
+	}
 	return headerSize + len(p.buf) + 2
 }
 
 func (p *PutAccess) PackBuff(buffer []byte) (int, error) {
+	if p.hasDictionary() {
+		out := p.PackAppend(nil)
+		if len(buffer) < len(out) {
+			return copy(buffer, out), errors.New("insufficient budder")
+		}
+		return copy(buffer, out), nil
+	}
 	// Append TypeEnd header for offset-derived slicing
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeEnd(p.position))
 	// Compute final header size after appending TypeEnd
@@ -686,7 +864,7 @@ func (p *PutAccess) AddPackable(v Packable) {
 
 func (p *PutAccess) BeginMap() *PutAccess {
 	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeMap))
-	return NewPutAccessFromPool()
+	return p.newNested()
 }
 
 func (p *PutAccess) BeginTuple() *PutAccess {
@@ -694,7 +872,7 @@ func (p *PutAccess) BeginTuple() *PutAccess {
 		p.offsets,
 		typetags.EncodeHeader(p.position, typetags.TypeTuple),
 	)
-	return NewPutAccessFromPool()
+	return p.newNested()
 }
 
 func (p *PutAccess) EndNested(nested *PutAccess) {
@@ -244,3 +244,48 @@ func TestPutAccess_IntThenMapOrderedAny(t *testing.T) {
 		assert.Equalf(t, expected[i], actual[i], "Byte %d mismatch: expected %02X, got %02X", i, expected[i], actual[i])
 	}
 }
+
+func TestPutAccess_SetDeterministic(t *testing.T) {
+	value := func() map[string]any {
+		inner := map[string]any{}
+		for i := 0; i < 20; i++ {
+			inner[fmt.Sprintf("n%02d", i)] = int8(i)
+		}
+		return map[string]any{
+			"z":     uint16(1),
+			"inner": inner,
+			"list":  []any{map[string]string{"b": "2", "a": "1"}},
+			"bytes": map[string][]byte{"y": {1}, "x": {2}},
+		}
+	}
+
+	var first []byte
+	for i := 0; i < 10; i++ {
+		put := NewPutAccess()
+		put.SetDeterministic(true)
+		require.True(t, put.Deterministic())
+		require.NoError(t, put.AddAny(value(), false))
+		buf := put.Pack()
+		if first == nil {
+			first = buf
+			continue
+		}
+		assert.Equal(t, first, buf, "run %d", i)
+	}
+
+	sorted := NewPutAccess()
+	require.NoError(t, sorted.AddMapAnySortedKey(value(), false))
+	assert.Equal(t, sorted.Pack(), first)
+
+	decoded, err := DecodeOrdered(first)
+	require.NoError(t, err)
+	om := decoded.(*typetags.OrderedMapAny)
+	assert.Equal(t, []string{"bytes", "inner", "list", "z"}, om.Keys())
+
+	SetDefaultDeterministic(true)
+	defer SetDefaultDeterministic(false)
+	assert.True(t, NewPutAccess().Deterministic())
+	pooled := NewPutAccessFromPool()
+	assert.True(t, pooled.Deterministic())
+	ReleasePutAccess(pooled)
+}
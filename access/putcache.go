@@ -0,0 +1,127 @@
+package access
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// PutAccessCache keeps released PutAccess values in a fixed number of
+// shards, each a short free list behind its own lock. Values in the shared
+// sync.Pool are dropped at every garbage collection, so busy encoders keep
+// allocating fresh 1 KiB buffers that then migrate between CPUs; a cache
+// keeps up to its capacity alive across collections. Each goroutine starts
+// at a shard derived from its own stack, so concurrent callers spread over
+// the shards without touching shared state, and a goroutine keeps coming
+// back to the values it released. When that shard is busy, empty or full,
+// the next ones are tried and then the shared pool, so a cache never
+// blocks and never grows past capacity. Values whose buffers grew past
+// MaxBufCap go to the shared pool, which lets the collector reclaim them.
+//
+// Install a cache with SetPutAccessCache to route GetPutAccess and
+// ReleasePutAccess, and so the schema encoders, through it; or call Get
+// and Put directly.
+type PutAccessCache struct {
+	shards   []putShard
+	perShard int
+	// MaxBufCap is the largest payload buffer capacity the cache keeps.
+	MaxBufCap int
+}
+
+type putShard struct {
+	mu   sync.Mutex
+	free []*PutAccess
+	// pad shards to 64 bytes so they sit on separate cache lines
+	_ [32]byte
+}
+
+// DefaultPutCacheBufCap is the MaxBufCap of caches made by NewPutAccessCache.
+const DefaultPutCacheBufCap = 64 << 10
+
+// NewPutAccessCache returns a cache of shards shards holding at most
+// perShard values each. shards <= 0 uses runtime.GOMAXPROCS(0).
+func NewPutAccessCache(shards, perShard int) *PutAccessCache {
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+	c := &PutAccessCache{shards: make([]putShard, shards), perShard: perShard, MaxBufCap: DefaultPutCacheBufCap}
+	for i := range c.shards {
+		c.shards[i].free = make([]*PutAccess, 0, perShard)
+	}
+	return c
+}
+
+var putCache atomic.Pointer[PutAccessCache]
+
+// SetPutAccessCache makes GetPutAccess and ReleasePutAccess use c, or the
+// shared pool alone when c is nil.
+func SetPutAccessCache(c *PutAccessCache) {
+	putCache.Store(c)
+}
+
+// start returns the first shard for the calling goroutine. Goroutine
+// stacks do not overlap, so the address of a local tells callers apart
+// without a shared counter; a stack that moves when it grows only changes
+// the shard it starts at.
+func (c *PutAccessCache) start() int {
+	var local byte
+	h := uint64(uintptr(unsafe.Pointer(&local))>>10) * 0x9E3779B97F4A7C15
+	return int((h >> 32) % uint64(len(c.shards)))
+}
+
+// Get returns an empty PutAccess, as GetPutAccess does.
+func (c *PutAccessCache) Get() *PutAccess {
+	start := c.start()
+	for i := range c.shards {
+		s := &c.shards[(start+i)%len(c.shards)]
+		if !s.mu.TryLock() {
+			continue
+		}
+		if n := len(s.free); n > 0 {
+			p := s.free[n-1]
+			s.free[n-1] = nil
+			s.free = s.free[:n-1]
+			s.mu.Unlock()
+			p.reset()
+			return p
+		}
+		s.mu.Unlock()
+	}
+	return getPooled()
+}
+
+// Put keeps p for a later Get, or hands it to the shared pool when the
+// cache is full or p's buffer is larger than MaxBufCap.
+func (c *PutAccessCache) Put(p *PutAccess) {
+	if cap(p.buf) > c.MaxBufCap {
+		putAccessPool.Put(p)
+		return
+	}
+	start := c.start()
+	for i := range c.shards {
+		s := &c.shards[(start+i)%len(c.shards)]
+		if !s.mu.TryLock() {
+			continue
+		}
+		if len(s.free) < c.perShard {
+			s.free = append(s.free, p)
+			s.mu.Unlock()
+			return
+		}
+		s.mu.Unlock()
+	}
+	putAccessPool.Put(p)
+}
+
+// Len returns the number of values held by the cache.
+func (c *PutAccessCache) Len() int {
+	n := 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		n += len(s.free)
+		s.mu.Unlock()
+	}
+	return n
+}
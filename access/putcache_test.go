@@ -0,0 +1,89 @@
+package access
+
+import (
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutAccessCache(t *testing.T) {
+	assert.Equal(t, uintptr(64), unsafe.Sizeof(putShard{}))
+
+	c := NewPutAccessCache(2, 1)
+	a := c.Get()
+	a.AddString("secret")
+	a.SetDeterministic(!a.Deterministic())
+	c.Put(a)
+	c.Put(c.Get())
+	c.Put(NewPutAccess())
+	c.Put(NewPutAccess())
+	assert.Equal(t, 2, c.Len())
+
+	// grown buffers are left to the shared pool
+	big := NewPutAccess()
+	big.AddBytes(make([]byte, c.MaxBufCap+1))
+	kept := c.Get()
+	c.Put(big)
+	assert.Equal(t, 1, c.Len())
+	c.Put(kept)
+	assert.Equal(t, 2, c.Len())
+
+	p := c.Get()
+	assert.Equal(t, 0, len(p.buf))
+	assert.Equal(t, 0, len(p.offsets))
+	assert.Equal(t, defaultDeterministic.Load(), p.Deterministic())
+
+	SetPutAccessCache(c)
+	defer SetPutAccessCache(nil)
+	ReleasePutAccess(p)
+	assert.Equal(t, 2, c.Len())
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				put := NewPutAccessFromPool()
+				put.AddInt32(int32(g))
+				nested := put.BeginTuple()
+				nested.AddInt32(int32(i))
+				put.EndNested(nested)
+				buf := put.Pack()
+				ReleasePutAccess(put)
+				v, err := Decode(buf)
+				assert.NoError(t, err)
+				assert.Equal(t, []any{int32(g), []any{int32(i)}}, v)
+			}
+		}(g)
+	}
+	wg.Wait()
+	require.LessOrEqual(t, c.Len(), 2)
+}
+
+func benchmarkPutParallel(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := int32(0); pb.Next(); i++ {
+			put := NewPutAccessFromPool()
+			put.AddInt32(i)
+			put.AddString("gopher")
+			sinkCache = put.Pack()
+			ReleasePutAccess(put)
+		}
+	})
+}
+
+var sinkCache []byte
+
+func BenchmarkPutAccess_Parallel_Pool(b *testing.B) {
+	benchmarkPutParallel(b)
+}
+
+func BenchmarkPutAccess_Parallel_Cache(b *testing.B) {
+	SetPutAccessCache(NewPutAccessCache(0, 64))
+	defer SetPutAccessCache(nil)
+	benchmarkPutParallel(b)
+}
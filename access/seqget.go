@@ -17,9 +17,30 @@ type SeqGetAccess struct {
 	nextType      typetags.Type // decoded type tag of next field
 	currentOffset int           // absolute offset of last field start
 	currentType   typetags.Type // decoded type tag of last field
+	dict          [][]byte      // interned strings, see SetInterning
+	depth         int           // container nesting below the root
+	budget        *decodeBudget // nil when reading without DecodeLimits
 }
 
+// NewSeqGetAccess reads a packed buffer. Buffers packed with interning are
+// recognized and their string references resolved.
 func NewSeqGetAccess(buf []byte) (*SeqGetAccess, error) {
+	if IsInterned(buf) {
+		dict, record, err := splitInterned(buf)
+		if err != nil {
+			return nil, err
+		}
+		s, err := newSeqGetAccess(record)
+		if err != nil {
+			return nil, err
+		}
+		s.dict = dict
+		return s, nil
+	}
+	return newSeqGetAccess(buf)
+}
+
+func newSeqGetAccess(buf []byte) (*SeqGetAccess, error) {
 	if len(buf) < 4 {
 		return nil, errors.New("insufficient header")
 	}
@@ -29,6 +50,9 @@ func NewSeqGetAccess(buf []byte) (*SeqGetAccess, error) {
 	if len(buf) < base {
 		return nil, errors.New("insufficient header")
 	}
+	if err := checkHeaders(buf, base); err != nil {
+		return nil, err
+	}
 
 	h := binary.LittleEndian.Uint16(buf[2:])
 	offset, nt := typetags.DecodeHeader(h)
@@ -46,6 +70,24 @@ func NewSeqGetAccess(buf []byte) (*SeqGetAccess, error) {
 	}, nil
 }
 
+// checkHeaders verifies that the header table of buf describes ranges
+// inside buf that do not run backwards, so that the accessor's offset math
+// cannot go out of range whatever the headers say.
+func checkHeaders(buf []byte, base int) error {
+	if base < 4 || base%2 != 0 {
+		return fmt.Errorf("invalid header base %d", base)
+	}
+	prev := base
+	for i := 1; i < base/2; i++ {
+		off := typetags.DecodeOffset(binary.LittleEndian.Uint16(buf[i*2:])) + base
+		if off < prev || off > len(buf) {
+			return fmt.Errorf("header %d: offset %d outside [%d, %d]", i, off, prev, len(buf))
+		}
+		prev = off
+	}
+	return nil
+}
+
 func (s *SeqGetAccess) ArgCount() int {
 	return s.count - 1 //do not count TypeEnd
 }
@@ -63,6 +105,17 @@ func (s *SeqGetAccess) PeekTypeWidth() (typetags.Type, int, error) {
 		return 0, 0, fmt.Errorf("PeekTypeWidth: out of bounds at pos %d", s.pos)
 	}
 
+	if s.isRef() {
+		entry, err := s.resolveRef()
+		if err != nil {
+			return typetags.TypeString, -1, err
+		}
+		if err := s.budget.checkWidth(typetags.TypeString, len(entry)); err != nil {
+			return typetags.TypeString, -1, err
+		}
+		return typetags.TypeString, len(entry), nil
+	}
+
 	width := s.nextOffset - s.currentOffset
 	if s.nextOffset > len(s.buf) {
 		return s.currentType, -1, fmt.Errorf(
@@ -70,11 +123,24 @@ func (s *SeqGetAccess) PeekTypeWidth() (typetags.Type, int, error) {
 			s.currentOffset, s.nextOffset, len(s.buf),
 		)
 	}
+	if err := s.budget.checkWidth(s.currentType, width); err != nil {
+		return s.currentType, -1, err
+	}
 
 	return s.currentType, width, nil
 }
 
 func (s *SeqGetAccess) GetPayload(width int) ([]byte, error) {
+	if s.isRef() {
+		entry, err := s.resolveRef()
+		if err != nil {
+			return nil, err
+		}
+		if width != len(entry) {
+			return nil, fmt.Errorf("next: interned string has width %d, not %d", len(entry), width)
+		}
+		return entry, nil
+	}
 	if width < 0 || s.currentOffset+width > len(s.buf) {
 		return nil, fmt.Errorf("next: invalid range %d → %d", s.currentOffset, s.currentOffset+width)
 	}
@@ -90,8 +156,9 @@ func (s *SeqGetAccess) Advance() error {
 	s.pos++
 	s.currentOffset = s.nextOffset
 	s.currentType = s.nextType
-	//get next type if is exist
-	if s.currentType != typetags.TypeEnd {
+	//get next type if is exist; TypeEnd before the last header is an
+	//interned string reference
+	if s.pos+1 < s.count {
 		h := binary.LittleEndian.Uint16(s.buf[(s.pos+1)*2:])
 		end, nt := typetags.DecodeHeader(h)
 		end += s.base
@@ -102,8 +169,65 @@ func (s *SeqGetAccess) Advance() error {
 	return nil
 }
 
+// Seek moves to the field at index pos, before or after the current one, so
+// a caller can rewind after a failed attempt to read a field.
+func (s *SeqGetAccess) Seek(pos int) error {
+	if pos < 0 || pos >= s.count {
+		return fmt.Errorf("Seek: position %d out of range [0, %d)", pos, s.count)
+	}
+	header := func(i int) (int, typetags.Type) {
+		off, tp := typetags.DecodeHeader(binary.LittleEndian.Uint16(s.buf[i*2:]))
+		if i == 0 {
+			// the first header holds the absolute payload base
+			return s.base, tp
+		}
+		return off + s.base, tp
+	}
+	s.pos = pos
+	s.currentOffset, s.currentType = header(pos)
+	if pos+1 < s.count {
+		s.nextOffset, s.nextType = header(pos + 1)
+	}
+	return nil
+}
+
+// Checkpoint is a saved cursor position of a SeqGetAccess.
+type Checkpoint struct {
+	buf           []byte
+	pos           int
+	currentOffset int
+	currentType   typetags.Type
+	nextOffset    int
+	nextType      typetags.Type
+}
+
+// Checkpoint saves the cursor, so that a caller trying one reading of the
+// upcoming fields can Restore it and try another when the first fails.
+func (s *SeqGetAccess) Checkpoint() Checkpoint {
+	return Checkpoint{
+		buf:           s.buf,
+		pos:           s.pos,
+		currentOffset: s.currentOffset,
+		currentType:   s.currentType,
+		nextOffset:    s.nextOffset,
+		nextType:      s.nextType,
+	}
+}
+
+// Restore moves the cursor back, or forward, to cp. It fails if cp was not
+// taken on an accessor of the same buffer.
+func (s *SeqGetAccess) Restore(cp Checkpoint) error {
+	if len(cp.buf) != len(s.buf) || len(s.buf) == 0 || &cp.buf[0] != &s.buf[0] {
+		return errors.New("Restore: checkpoint belongs to another buffer")
+	}
+	s.pos = cp.pos
+	s.currentOffset, s.currentType = cp.currentOffset, cp.currentType
+	s.nextOffset, s.nextType = cp.nextOffset, cp.nextType
+	return nil
+}
+
 func (s *SeqGetAccess) PeekNestedSeq() (*SeqGetAccess, error) {
-	if s.currentType != typetags.TypeMap && s.currentType != typetags.TypeTuple {
+	if !s.currentType.IsMap() && s.currentType != typetags.TypeTuple {
 		return nil, fmt.Errorf("peekNestedSeq: current type is not Map or Tuple (got %v)", s.currentType)
 	}
 
@@ -113,10 +237,22 @@ func (s *SeqGetAccess) PeekNestedSeq() (*SeqGetAccess, error) {
 	}
 
 	nestedBuf := s.buf[s.currentOffset:s.nextOffset]
-	nested, err := NewSeqGetAccess(nestedBuf)
+	decompressed := 0
+	if IsCompressed(nestedBuf) {
+		var err error
+		if nestedBuf, err = decompressBody(nestedBuf, s.budget.remainingBytes()); err != nil {
+			return nil, fmt.Errorf("peekNestedSeq: %w", err)
+		}
+		decompressed = len(nestedBuf)
+	}
+	nested, err := newSeqGetAccess(nestedBuf)
 	if err != nil {
 		return nil, fmt.Errorf("peekNestedSeq: failed to initialize nested accessor %w", err)
 	}
+	nested.dict = s.dict
+	if err := s.openNested(nested, decompressed); err != nil {
+		return nil, fmt.Errorf("peekNestedSeq: %w", err)
+	}
 	return nested, nil
 }
 
@@ -125,12 +261,11 @@ func (s *SeqGetAccess) Next() ([]byte, typetags.Type, error) {
 	if err != nil {
 		return nil, 0, fmt.Errorf("next: peek failed at pos %d: %w", s.pos, err)
 	}
-	if width < 0 || s.currentOffset+width > len(s.buf) {
-		return nil, 0, fmt.Errorf("next: invalid range %d → %d", s.currentOffset, s.currentOffset+width)
+	payload, err := s.GetPayload(width)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	payload := s.buf[s.currentOffset : s.currentOffset+width]
-
 	if err := s.Advance(); err != nil {
 		return nil, 0, fmt.Errorf("next: advance failed at pos %d: %w", s.pos, err)
 	}
@@ -139,6 +274,9 @@ func (s *SeqGetAccess) Next() ([]byte, typetags.Type, error) {
 }
 
 func (s *SeqGetAccess) NextOffsetWidth() (int, int, typetags.Type, error) {
+	if s.isRef() {
+		return 0, 0, 0, fmt.Errorf("next: interned string at pos %d has no range in the buffer", s.pos)
+	}
 	typ, width, err := s.PeekTypeWidth()
 	if err != nil {
 		return 0, 0, 0, fmt.Errorf("next: peek failed at pos %d: %w", s.pos, err)
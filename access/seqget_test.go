@@ -1,6 +1,7 @@
 package access
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/quickwritereader/PackOS/typetags"
@@ -149,3 +150,68 @@ func TestSeqGetAccess_ExplicitByteMatch(t *testing.T) {
 	require.Error(t, err)
 
 }
+
+func TestSeqGetAccess_Seek(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt16(7)
+	put.AddString("mid")
+	put.AddInt32(9)
+	seq, err := NewSeqGetAccess(put.Pack())
+	require.NoError(t, err)
+
+	_, _, err = seq.Next()
+	require.NoError(t, err)
+	payload, typ, err := seq.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "mid", string(payload))
+
+	require.NoError(t, seq.Seek(0))
+	assert.Equal(t, 0, seq.CurrentIndex())
+	payload, typ, err = seq.Next()
+	require.NoError(t, err)
+	assert.Equal(t, typetags.TypeInteger, typ)
+	assert.Equal(t, []byte{7, 0}, payload)
+
+	require.NoError(t, seq.Seek(2))
+	payload, _, err = seq.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{9, 0, 0, 0}, payload)
+
+	require.NoError(t, seq.Seek(1))
+	typ, width, err := seq.PeekTypeWidth()
+	require.NoError(t, err)
+	assert.Equal(t, typetags.TypeString, typ)
+	assert.Equal(t, 3, width)
+
+	assert.Error(t, seq.Seek(4))
+	assert.Error(t, seq.Seek(-1))
+}
+
+func TestSeqGetAccess_CheckpointRestore(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt16(7)
+	put.AddString("mid")
+	put.AddInt32(9)
+	buf := put.Pack()
+	seq, err := NewSeqGetAccess(buf)
+	require.NoError(t, err)
+
+	_, _, err = seq.Next()
+	require.NoError(t, err)
+	cp := seq.Checkpoint()
+	_, _, err = seq.Next()
+	require.NoError(t, err)
+	_, _, err = seq.Next()
+	require.NoError(t, err)
+
+	require.NoError(t, seq.Restore(cp))
+	assert.Equal(t, 1, seq.CurrentIndex())
+	payload, typ, err := seq.Next()
+	require.NoError(t, err)
+	assert.Equal(t, typetags.TypeString, typ)
+	assert.Equal(t, "mid", string(payload))
+
+	other, err := NewSeqGetAccess(bytes.Clone(buf))
+	require.NoError(t, err)
+	assert.Error(t, other.Restore(cp))
+}
@@ -0,0 +1,190 @@
+package access
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+type rawField struct {
+	tag   typetags.Type
+	value []byte
+}
+
+// rawFields lists the top-level fields of a packed buffer without decoding them.
+// An empty buffer is treated as a document with no fields.
+func rawFields(buf []byte) ([]rawField, error) {
+	if len(buf) == 0 {
+		return nil, nil
+	}
+	g := NewGetAccess(buf)
+	if g == nil {
+		return nil, fmt.Errorf("insufficient header")
+	}
+	fields := make([]rawField, 0, g.argCount)
+	for i := 0; i < g.argCount; i++ {
+		tp, start, end := g.rangeAt(i)
+		if start < 0 || end < start {
+			return nil, fmt.Errorf("invalid range %d → %d at pos %d", start, end, i)
+		}
+		fields = append(fields, rawField{tag: tp, value: g.buf[start:end]})
+	}
+	return fields, nil
+}
+
+// packRawFields writes fields into a fresh buffer, recomputing every offset header.
+func packRawFields(fields []rawField) []byte {
+	put := NewPutAccessFromPool()
+	defer ReleasePutAccess(put)
+	for _, f := range fields {
+		put.AppendTagAndValue(f.tag, f.value)
+	}
+	return put.Pack()
+}
+
+func packableField(p Packable) rawField {
+	value := make([]byte, p.ValueSize())
+	n := p.Write(value, 0)
+	return rawField{tag: p.HeaderType(), value: value[:n]}
+}
+
+// Splice inserts the top-level fields of the packed buffer insert into dst
+// before position pos, and returns the rebuilt buffer.
+// pos == field count appends. Payloads are copied as-is; only headers are recomputed.
+func Splice(dst []byte, pos int, insert []byte) ([]byte, error) {
+	fields, err := rawFields(dst)
+	if err != nil {
+		return nil, fmt.Errorf("Splice: dst: %w", err)
+	}
+	if pos < 0 || pos > len(fields) {
+		return nil, fmt.Errorf("Splice: position %d out of range [0, %d]", pos, len(fields))
+	}
+	ins, err := rawFields(insert)
+	if err != nil {
+		return nil, fmt.Errorf("Splice: insert: %w", err)
+	}
+	out := make([]rawField, 0, len(fields)+len(ins))
+	out = append(out, fields[:pos]...)
+	out = append(out, ins...)
+	out = append(out, fields[pos:]...)
+	return packRawFields(out), nil
+}
+
+// AppendField appends p as a new last field of buf.
+func AppendField(buf []byte, p Packable) ([]byte, error) {
+	fields, err := rawFields(buf)
+	if err != nil {
+		return nil, fmt.Errorf("AppendField: %w", err)
+	}
+	return packRawFields(append(fields, packableField(p))), nil
+}
+
+// ReplaceField replaces the field at pos with p. Type and width may differ.
+func ReplaceField(buf []byte, pos int, p Packable) ([]byte, error) {
+	fields, err := rawFields(buf)
+	if err != nil {
+		return nil, fmt.Errorf("ReplaceField: %w", err)
+	}
+	if pos < 0 || pos >= len(fields) {
+		return nil, fmt.Errorf("ReplaceField: position %d out of range [0, %d)", pos, len(fields))
+	}
+	fields[pos] = packableField(p)
+	return packRawFields(fields), nil
+}
+
+// RemoveField drops the field at pos.
+func RemoveField(buf []byte, pos int) ([]byte, error) {
+	fields, err := rawFields(buf)
+	if err != nil {
+		return nil, fmt.Errorf("RemoveField: %w", err)
+	}
+	if pos < 0 || pos >= len(fields) {
+		return nil, fmt.Errorf("RemoveField: position %d out of range [0, %d)", pos, len(fields))
+	}
+	fields = append(fields[:pos], fields[pos+1:]...)
+	return packRawFields(fields), nil
+}
+
+// ReplacePath replaces the field at path with the single top-level field of
+// insert. Path segments follow Patcher.FieldPath: indexes inside tuples and
+// keys inside maps. Only the containers along the path are rebuilt; every
+// other payload is copied as-is.
+func ReplacePath(buf []byte, path string, insert []byte) ([]byte, error) {
+	ins, err := rawFields(insert)
+	if err != nil {
+		return nil, fmt.Errorf("ReplacePath: insert: %w", err)
+	}
+	if len(ins) != 1 {
+		return nil, fmt.Errorf("ReplacePath: insert has %d fields, expected 1", len(ins))
+	}
+	out, err := replacePath(buf, typetags.TypeTuple, strings.Split(path, "."), ins[0], 0)
+	if err != nil {
+		return nil, fmt.Errorf("ReplacePath: path %q: %w", path, err)
+	}
+	return out, nil
+}
+
+func replacePath(buf []byte, tag typetags.Type, segments []string, insert rawField, depth int) ([]byte, error) {
+	if depth > maxNestingDepth {
+		return nil, fmt.Errorf("nesting too deep")
+	}
+	fields, err := rawFields(buf)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("segment %q: empty container", segments[0])
+	}
+	pos, err := segmentPos(NewGetAccess(buf), tag, segments[0])
+	if err != nil {
+		return nil, err
+	}
+	if pos < 0 || pos >= len(fields) {
+		return nil, fmt.Errorf("segment %q: position %d out of range [0, %d)", segments[0], pos, len(fields))
+	}
+	if len(segments) == 1 {
+		fields[pos] = insert
+		return packRawFields(fields), nil
+	}
+	f := fields[pos]
+	if !f.tag.IsMap() && f.tag != typetags.TypeTuple {
+		return nil, fmt.Errorf("segment %q: %v is not a container", segments[0], f.tag)
+	}
+	nested, err := replacePath(f.value, f.tag, segments[1:], insert, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	fields[pos].value = nested
+	return packRawFields(fields), nil
+}
+
+// FieldAtPath returns the tag and payload of the field at path, which
+// follows Patcher.FieldPath: indexes inside tuples and keys inside maps.
+// The payload aliases buf.
+func FieldAtPath(buf []byte, path string) (typetags.Type, []byte, error) {
+	tag := typetags.TypeTuple
+	segments := strings.Split(path, ".")
+	for i, seg := range segments {
+		fields, err := rawFields(buf)
+		if err != nil {
+			return 0, nil, fmt.Errorf("FieldAtPath: path %q: %w", path, err)
+		}
+		pos, err := segmentPos(NewGetAccess(buf), tag, seg)
+		if err != nil {
+			return 0, nil, fmt.Errorf("FieldAtPath: path %q: %w", path, err)
+		}
+		if pos < 0 || pos >= len(fields) {
+			return 0, nil, fmt.Errorf("FieldAtPath: path %q: segment %q out of range [0, %d)", path, seg, len(fields))
+		}
+		f := fields[pos]
+		if i == len(segments)-1 {
+			return f.tag, f.value, nil
+		}
+		if !f.tag.IsMap() && f.tag != typetags.TypeTuple {
+			return 0, nil, fmt.Errorf("FieldAtPath: path %q: segment %q: %v is not a container", path, seg, f.tag)
+		}
+		buf, tag = f.value, f.tag
+	}
+	return 0, nil, fmt.Errorf("FieldAtPath: empty path")
+}
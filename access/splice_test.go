@@ -0,0 +1,158 @@
+package access
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func packInts(vals ...int16) []byte {
+	put := NewPutAccess()
+	for _, v := range vals {
+		put.AddInt16(v)
+	}
+	return put.Pack()
+}
+
+func int16Field(v int16) Packable {
+	return &PackableTagValue{head: typetags.TypeInteger, value: binary.LittleEndian.AppendUint16(nil, uint16(v))}
+}
+
+func TestSplice_InsertMiddleAndEnds(t *testing.T) {
+	base := packInts(1, 4)
+
+	put := NewPutAccess()
+	put.AddInt16(2)
+	put.AddString("three")
+	insert := put.Pack()
+
+	out, err := Splice(base, 1, insert)
+	require.NoError(t, err)
+	decoded, err := Decode(out)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int16(1), int16(2), "three", int16(4)}, decoded)
+
+	out, err = Splice(base, 0, packInts(0))
+	require.NoError(t, err)
+	decoded, err = Decode(out)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int16(0), int16(1), int16(4)}, decoded)
+
+	out, err = Splice(base, 2, packInts(5))
+	require.NoError(t, err)
+	assert.Equal(t, packInts(1, 4, 5), out)
+
+	out, err = Splice(nil, 0, base)
+	require.NoError(t, err)
+	assert.Equal(t, base, out)
+
+	_, err = Splice(base, 3, insert)
+	assert.Error(t, err)
+}
+
+func TestAppendReplaceRemoveField(t *testing.T) {
+	buf := packInts(1, 2)
+
+	out, err := AppendField(buf, int16Field(3))
+	require.NoError(t, err)
+	assert.Equal(t, packInts(1, 2, 3), out, "append must match a fresh pack")
+
+	put := NewPutAccess()
+	put.AddInt16(1)
+	put.AddString("two")
+	put.AddInt16(3)
+	expected := put.Pack()
+
+	str := &PackableTagValue{head: typetags.TypeString, value: []byte("two")}
+	out, err = ReplaceField(out, 1, str)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	out, err = RemoveField(out, 1)
+	require.NoError(t, err)
+	assert.Equal(t, packInts(1, 3), out)
+
+	_, err = RemoveField(out, 2)
+	assert.Error(t, err)
+	_, err = ReplaceField(out, -1, str)
+	assert.Error(t, err)
+}
+
+func TestSplice_NestedPayloadPreserved(t *testing.T) {
+	put := NewPutAccess()
+	put.AddMapSortedKeyStr(map[string]string{"a": "x", "b": "y"})
+	withMap := put.Pack()
+
+	out, err := Splice(withMap, 0, packInts(7))
+	require.NoError(t, err)
+
+	decoded, err := Decode(out)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int16(7), map[string]any{"a": "x", "b": "y"}}, decoded)
+}
+
+func TestReplacePath_Nested(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt16(1)
+	require.NoError(t, put.AddMapAnyOrdered(typetags.NewOrderedMapAny(
+		typetags.OPAny("name", "svc"),
+		typetags.OPAny("settings", typetags.NewOrderedMapAny(
+			typetags.OPAny("theme", "dark"),
+			typetags.OPAny("size", int8(3)),
+		)),
+	), false))
+	buf := put.Pack()
+
+	insert := NewPutAccess()
+	insert.AddString("solarized")
+	out, err := ReplacePath(buf, "1.settings.theme", insert.Pack())
+	require.NoError(t, err)
+
+	decoded, err := DecodeOrdered(out)
+	require.NoError(t, err)
+	m := decoded.([]any)[1].(*typetags.OrderedMapAny)
+	settings, _ := m.Get("settings")
+	theme, _ := settings.(*typetags.OrderedMapAny).Get("theme")
+	assert.Equal(t, "solarized", theme)
+	size, _ := settings.(*typetags.OrderedMapAny).Get("size")
+	assert.Equal(t, int8(3), size)
+	assert.Equal(t, []string{"name", "settings"}, m.Keys())
+
+	_, err = ReplacePath(buf, "1.missing", insert.Pack())
+	assert.Error(t, err)
+	_, err = ReplacePath(buf, "0.x", insert.Pack())
+	assert.Error(t, err, "int16 is not a container")
+	_, err = ReplacePath(buf, "5", insert.Pack())
+	assert.Error(t, err)
+	_, err = ReplacePath(buf, "0", packInts(1, 2))
+	assert.Error(t, err, "insert must hold one field")
+}
+
+func TestFieldAtPath(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt16(1)
+	require.NoError(t, put.AddMapAny(map[string]any{"name": "ann", "tags": []any{"a", "b"}}, false))
+	buf := put.Pack()
+
+	tag, val, err := FieldAtPath(buf, "1.name")
+	require.NoError(t, err)
+	assert.Equal(t, typetags.TypeString, tag)
+	assert.Equal(t, "ann", string(val))
+
+	tag, val, err = FieldAtPath(buf, "1.tags")
+	require.NoError(t, err)
+	assert.Equal(t, typetags.TypeTuple, tag)
+	decoded, err := Decode(val)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"a", "b"}, decoded)
+
+	_, _, err = FieldAtPath(buf, "0.x")
+	assert.Error(t, err)
+	_, _, err = FieldAtPath(buf, "1.missing")
+	assert.Error(t, err)
+	_, _, err = FieldAtPath(buf, "5")
+	assert.Error(t, err)
+}
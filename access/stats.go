@@ -0,0 +1,97 @@
+package access
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// statsTopFields is the number of fields kept in BufferStats.LargestFields.
+const statsTopFields = 5
+
+// FieldSize describes one field in BufferStats.LargestFields.
+// Path uses the FieldPath notation: tuple indexes and map keys joined by dots.
+type FieldSize struct {
+	Path string
+	Type typetags.Type
+	Size int
+}
+
+// BufferStats is a size breakdown of a packed buffer.
+// Header and payload bytes are summed over all nesting levels, so
+// HeaderBytes+PayloadBytes equals the buffer length. Map keys count as
+// string fields.
+type BufferStats struct {
+	TotalBytes    int
+	HeaderBytes   int
+	PayloadBytes  int
+	Fields        int
+	TypeCounts    map[typetags.Type]int
+	MaxDepth      int
+	LargestFields []FieldSize // leaf fields, largest first
+}
+
+// Stats walks buf and reports what its bytes are spent on.
+func Stats(buf []byte) (BufferStats, error) {
+	st := BufferStats{TotalBytes: len(buf), TypeCounts: map[typetags.Type]int{}}
+	if err := st.walk(buf, "", false, 0); err != nil {
+		return BufferStats{}, fmt.Errorf("Stats: %w", err)
+	}
+	sort.SliceStable(st.LargestFields, func(i, j int) bool {
+		return st.LargestFields[i].Size > st.LargestFields[j].Size
+	})
+	if len(st.LargestFields) > statsTopFields {
+		st.LargestFields = st.LargestFields[:statsTopFields]
+	}
+	return st, nil
+}
+
+func (st *BufferStats) walk(buf []byte, prefix string, isMap bool, depth int) error {
+	if depth > maxNestingDepth {
+		return fmt.Errorf("nesting too deep")
+	}
+	if depth > st.MaxDepth {
+		st.MaxDepth = depth
+	}
+	g := NewGetAccess(buf)
+	if g == nil {
+		return fmt.Errorf("insufficient header at %q", prefix)
+	}
+	header := g.base
+	st.HeaderBytes += header
+	nestedBytes := 0
+
+	key := ""
+	for i := 0; i < g.argCount; i++ {
+		tp, start, end := g.rangeAt(i)
+		if start < 0 || end < start || end > len(g.buf) {
+			return fmt.Errorf("invalid range %d → %d at %q pos %d", start, end, prefix, i)
+		}
+		st.Fields++
+		st.TypeCounts[tp]++
+		if isMap && i%2 == 0 {
+			key = string(g.buf[start:end])
+			continue
+		}
+		name := strconv.Itoa(i)
+		if isMap {
+			name = key
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		if (tp.IsMap() || tp == typetags.TypeTuple) && end > start {
+			if err := st.walk(g.buf[start:end], path, tp.IsMap(), depth+1); err != nil {
+				return err
+			}
+			nestedBytes += end - start
+			continue
+		}
+		st.LargestFields = append(st.LargestFields, FieldSize{Path: path, Type: tp, Size: end - start})
+	}
+	st.PayloadBytes += len(buf) - header - nestedBytes
+	return nil
+}
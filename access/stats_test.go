@@ -0,0 +1,45 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats_Breakdown(t *testing.T) {
+	put := NewPutAccess()
+	put.AddInt16(1)
+	put.AddString("hello world")
+	err := put.AddMapAnyOrdered(typetags.NewOrderedMapAny(
+		typetags.OPAny("blob", "0123456789abcdefghij"),
+		typetags.OPAny("list", []any{int64(1), int8(2)}),
+	), false)
+	require.NoError(t, err)
+	buf := put.Pack()
+
+	st, err := Stats(buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, len(buf), st.TotalBytes)
+	assert.Equal(t, st.TotalBytes, st.HeaderBytes+st.PayloadBytes)
+	assert.Equal(t, 2, st.MaxDepth)
+	assert.Equal(t, 9, st.Fields)
+	assert.Equal(t, map[typetags.Type]int{
+		typetags.TypeInteger: 3,
+		typetags.TypeString:  4,
+		typetags.TypeMap:     1,
+		typetags.TypeTuple:   1,
+	}, st.TypeCounts)
+
+	require.Len(t, st.LargestFields, 5)
+	assert.Equal(t, FieldSize{Path: "2.blob", Type: typetags.TypeString, Size: 20}, st.LargestFields[0])
+	assert.Equal(t, FieldSize{Path: "1", Type: typetags.TypeString, Size: 11}, st.LargestFields[1])
+	assert.Equal(t, FieldSize{Path: "2.list.0", Type: typetags.TypeInteger, Size: 8}, st.LargestFields[2])
+}
+
+func TestStats_Invalid(t *testing.T) {
+	_, err := Stats([]byte{1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package access
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// Timestamps and durations.
+//
+// A time.Time is packed as an integer field of width 12: Unix seconds as int64
+// followed by nanoseconds as uint32, both little endian. Width 12 is not used
+// by any other integer, so generic readers decode it straight to time.Time.
+// Only the instant survives: the location and any monotonic clock reading are
+// stripped, and decoded values are in UTC.
+//
+// A time.Duration is packed as an int64 count of nanoseconds; it is an
+// ordinary integer on the wire and reads back as a Duration with GetDuration
+// or schema.SDuration.
+
+// TimeWidth is the payload width of a packed time.Time.
+const TimeWidth = 12
+
+var errTimePayload = errors.New("invalid time payload")
+
+// AddTime packs t as Unix seconds and nanoseconds.
+func (p *PutAccess) AddTime(t time.Time) {
+	p.buf = binary.LittleEndian.AppendUint64(p.buf, uint64(t.Unix()))
+	p.buf = binary.LittleEndian.AppendUint32(p.buf, uint32(t.Nanosecond()))
+	p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
+	p.advance()
+}
+
+// AddNullableTime packs t, or a zero-width integer when t is nil.
+func (p *PutAccess) AddNullableTime(t *time.Time) {
+	if t == nil {
+		p.AddNullableInt64(nil)
+		return
+	}
+	p.AddTime(*t)
+}
+
+// AddDuration packs d as int64 nanoseconds.
+func (p *PutAccess) AddDuration(d time.Duration) {
+	p.AddInt64(int64(d))
+}
+
+// DecodeTime decodes a time payload written by AddTime. The result is in UTC.
+func DecodeTime(payload []byte) (time.Time, error) {
+	if len(payload) != TimeWidth {
+		return time.Time{}, errTimePayload
+	}
+	sec := int64(binary.LittleEndian.Uint64(payload))
+	nsec := binary.LittleEndian.Uint32(payload[8:])
+	if nsec >= 1e9 {
+		return time.Time{}, errTimePayload
+	}
+	return time.Unix(sec, int64(nsec)).UTC(), nil
+}
+
+// GetTime decodes a time.Time at position pos
+func (g *GetAccess) GetTime(pos int) (time.Time, error) {
+	tp, start, end := g.rangeAt(pos)
+	if tp != typetags.TypeInteger || end-start != TimeWidth {
+		return time.Time{}, fmt.Errorf("GetTime: not a time at pos %d", pos)
+	}
+	return DecodeTime(g.buf[start:end])
+}
+
+// GetDuration decodes a time.Duration at position pos.
+// Any integer width is accepted.
+func (g *GetAccess) GetDuration(pos int) (time.Duration, error) {
+	v, _, err := g.GetInt(pos)
+	if err != nil {
+		return 0, err
+	}
+	switch n := v.(type) {
+	case int:
+		return 0, nil // null
+	case int8:
+		return time.Duration(n), nil
+	case int16:
+		return time.Duration(n), nil
+	case int32:
+		return time.Duration(n), nil
+	case int64:
+		return time.Duration(n), nil
+	}
+	return 0, fmt.Errorf("GetDuration: unexpected value %T at pos %d", v, pos)
+}
@@ -0,0 +1,69 @@
+package access
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddTime_StripsLocationAndMonotonic(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*3600)
+	ts := time.Date(2024, 5, 17, 12, 30, 45, 123456789, loc)
+	now := time.Now() // carries a monotonic reading
+
+	put := NewPutAccess()
+	put.AddTime(ts)
+	put.AddTime(now)
+	put.AddNullableTime(nil)
+	put.AddDuration(90 * time.Minute)
+	buf := put.Pack()
+
+	get := NewGetAccess(buf)
+	got, err := get.GetTime(0)
+	require.NoError(t, err)
+	assert.True(t, got.Equal(ts))
+	assert.Equal(t, time.UTC, got.Location())
+	assert.Equal(t, 123456789, got.Nanosecond())
+
+	got, err = get.GetTime(1)
+	require.NoError(t, err)
+	assert.Equal(t, now.Round(0).UTC(), got)
+
+	_, err = get.GetTime(2)
+	assert.Error(t, err, "null is not a time")
+	_, err = get.GetTime(3)
+	assert.Error(t, err)
+
+	d, err := get.GetDuration(3)
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, d)
+
+	decoded, err := Decode(buf)
+	require.NoError(t, err)
+	assert.Equal(t, ts.UTC(), decoded.([]any)[0])
+	assert.Equal(t, int64(90*time.Minute), decoded.([]any)[3])
+}
+
+func TestTime_AnyJSONAndCBOR(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	put := NewPutAccess()
+	require.NoError(t, put.AddAny(map[string]any{"at": ts, "ttl": 2 * time.Second}, false))
+	buf := put.Pack()
+
+	var out bytes.Buffer
+	require.NoError(t, ToJSON(buf, &out))
+	assert.JSONEq(t, `{"at":"2024-01-02T03:04:05Z","ttl":2000000000}`, out.String())
+
+	put = NewPutAccess()
+	put.AddTime(ts)
+	cbor, err := ToCBOR(put.Pack())
+	require.NoError(t, err)
+	assert.Equal(t, "c0"+"74"+hex.EncodeToString([]byte("2024-01-02T03:04:05Z")), hex.EncodeToString(cbor))
+
+	_, err = DecodeTime([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff})
+	assert.Error(t, err, "nanoseconds out of range")
+}
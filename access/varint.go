@@ -0,0 +1,60 @@
+package access
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// Compact integers.
+//
+// Fixed-width integers only use payload widths 1, 2, 4 and 8, so the width
+// read from the headers doubles as a subtag: an integer payload of 3, 5, 6 or
+// 7 bytes is a zigzag varint (encoding/binary.AppendVarint). AddIntCompact
+// picks whichever form is shorter, so readers that understand varint widths
+// read both forms and buffers never grow. Values that fit one or two bytes are
+// already as small as a varint; the savings come from values that need three
+// bytes instead of four, or five to seven bytes instead of eight.
+
+// IsVarintWidth reports whether an integer payload of n bytes is varint encoded.
+func IsVarintWidth(n int) bool {
+	return n == 3 || n == 5 || n == 6 || n == 7
+}
+
+var errVarint = errors.New("invalid varint payload")
+
+// DecodeVarint decodes a varint integer payload.
+func DecodeVarint(payload []byte) (int64, error) {
+	v, n := binary.Varint(payload)
+	if n != len(payload) {
+		return 0, errVarint
+	}
+	return v, nil
+}
+
+// AddIntCompact packs v as a fixed-width integer or a zigzag varint,
+// whichever is shorter.
+func (p *PutAccess) AddIntCompact(v int64) {
+	var fixed int
+	switch {
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		fixed = 1
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		fixed = 2
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		fixed = 4
+	default:
+		fixed = 8
+	}
+	var tmp [binary.MaxVarintLen64]byte
+	enc := binary.AppendVarint(tmp[:0], v)
+	if len(enc) < fixed && IsVarintWidth(len(enc)) {
+		p.offsets = binary.LittleEndian.AppendUint16(p.offsets, typetags.EncodeHeader(p.position, typetags.TypeInteger))
+		p.buf = append(p.buf, enc...)
+		p.advance()
+		return
+	}
+	p.AddIntegerCompressed(v)
+}
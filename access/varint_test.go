@@ -0,0 +1,63 @@
+package access
+
+import (
+	"math"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddIntCompact_Widths(t *testing.T) {
+	cases := []struct {
+		v     int64
+		width int
+	}{
+		{0, 1},
+		{-100, 1},
+		{300, 2},
+		{40000, 3},    // varint beats int32
+		{-1 << 20, 3}, // zigzag keeps small negatives short
+		{1 << 30, 4},  // varint needs 5 bytes, fixed int32 wins
+		{1 << 33, 5},  // varint beats int64
+		{1 << 45, 7},
+		{1 << 50, 8}, // varint needs 8 bytes, fixed wins
+		{math.MinInt64, 8},
+	}
+	put := NewPutAccess()
+	for _, c := range cases {
+		put.AddIntCompact(c.v)
+	}
+	buf := put.Pack()
+
+	seq, err := NewSeqGetAccess(buf)
+	require.NoError(t, err)
+	get := NewGetAccess(buf)
+	for i, c := range cases {
+		typ, width, err := seq.PeekTypeWidth()
+		require.NoError(t, err)
+		assert.Equal(t, typetags.TypeInteger, typ)
+		assert.Equal(t, c.width, width, "value %d", c.v)
+		require.NoError(t, seq.Advance())
+
+		v, _, err := get.GetInt(i)
+		require.NoError(t, err)
+		assert.EqualValues(t, c.v, v)
+	}
+
+	decoded, err := Decode(buf)
+	require.NoError(t, err)
+	values := decoded.([]any)
+	assert.Equal(t, int64(40000), values[3])
+	assert.Equal(t, int64(1<<45), values[7])
+}
+
+func TestDecodeVarint_Invalid(t *testing.T) {
+	_, err := DecodeVarint([]byte{0x80, 0x80, 0x80})
+	assert.Error(t, err, "unterminated varint")
+	_, err = DecodeVarint([]byte{0x01, 0x00, 0x00})
+	assert.Error(t, err, "trailing bytes")
+	_, err = DecodePrimitive(typetags.TypeInteger, []byte{0x80, 0x80, 0x80})
+	assert.Error(t, err)
+}
@@ -0,0 +1,291 @@
+// Package analysis provides a go vet style analyzer that checks packos
+// struct tags against registered schemas, so a struct drifting from the
+// SchemaNamedChain it is packed with is caught at build time rather than by
+// an ErrEncode at runtime.
+//
+// Fields name the schema field they carry with a packos tag:
+//
+//	type Order struct {
+//		ID    int32     `packos:"id"`
+//		Code  string    `packos:"code,width=8"`
+//		Notes string    `packos:"-"`
+//		When  time.Time `packos:"created"`
+//	}
+//
+// An empty name stands for the Go field name and "-" skips the field. The
+// width option pins the fixed width of a string or bytes schema. Schemas
+// are registered by the qualified name of the struct type; a vet tool is a
+// main package that registers them and runs the analyzer:
+//
+//	func main() {
+//		singlechecker.Main(analysis.NewAnalyzer(map[string]schema.SchemaNamedChain{
+//			"example.com/shop/orders.Order": orders.Schema,
+//		}))
+//	}
+//
+// Built as ordervet, it runs with go vet -vettool=$(which ordervet) ./...
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/quickwritereader/PackOS/schema"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// TagName is the struct tag key read by the analyzer.
+const TagName = "packos"
+
+// NewAnalyzer returns an analyzer checking the structs named in schemas,
+// keyed by import path and type name as in "example.com/shop/orders.Order".
+// For each such struct it reports:
+//   - tags naming a field the schema does not have, or naming one twice;
+//   - Go types the field's schema does not encode, such as an int64 field
+//     for SInt32 or a string field for SBytes;
+//   - width options that differ from a string or bytes schema's Width;
+//   - non-nullable schema fields that no struct field is tagged with.
+//
+// Schemas whose Go type the analyzer does not know, such as maps or ranged
+// integers built with SchemaGeneric, are matched by name only.
+func NewAnalyzer(schemas map[string]schema.SchemaNamedChain) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name:     "packostag",
+		Doc:      "check packos struct tags against registered schemas",
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+		Run: func(pass *analysis.Pass) (any, error) {
+			run(pass, schemas)
+			return nil, nil
+		},
+	}
+}
+
+func run(pass *analysis.Pass, schemas map[string]schema.SchemaNamedChain) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	insp.Preorder([]ast.Node{(*ast.TypeSpec)(nil)}, func(n ast.Node) {
+		spec := n.(*ast.TypeSpec)
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return
+		}
+		obj := pass.TypesInfo.Defs[spec.Name]
+		if obj == nil {
+			return
+		}
+		chain, ok := schemas[obj.Pkg().Path()+"."+obj.Name()]
+		if !ok {
+			return
+		}
+		checkStruct(pass, spec, st, chain)
+	})
+}
+
+func checkStruct(pass *analysis.Pass, spec *ast.TypeSpec, st *ast.StructType, chain schema.SchemaNamedChain) {
+	tagged := make(map[string]bool, len(chain.FieldNames))
+	for _, field := range st.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		raw, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		value, ok := reflect.StructTag(raw).Lookup(TagName)
+		if !ok || value == "-" {
+			continue
+		}
+		tag, err := parseTag(value)
+		if err != nil {
+			pass.Reportf(field.Tag.Pos(), "%s.%s: %v", spec.Name.Name, fieldName(field), err)
+			continue
+		}
+		if tag.name == "" {
+			tag.name = fieldName(field)
+		}
+		idx := slices.Index(chain.FieldNames, tag.name)
+		if idx < 0 || idx >= len(chain.Schemas) {
+			pass.Reportf(field.Tag.Pos(), "%s.%s: schema has no field %q", spec.Name.Name, fieldName(field), tag.name)
+			continue
+		}
+		if tagged[tag.name] {
+			pass.Reportf(field.Tag.Pos(), "%s.%s: field %q is tagged more than once", spec.Name.Name, fieldName(field), tag.name)
+			continue
+		}
+		tagged[tag.name] = true
+		if err := checkField(pass.TypesInfo.TypeOf(field.Type), tag, chain.Schemas[idx]); err != nil {
+			pass.Reportf(field.Pos(), "%s.%s: field %q: %v", spec.Name.Name, fieldName(field), tag.name, err)
+		}
+	}
+	for i, name := range chain.FieldNames {
+		if i < len(chain.Schemas) && !tagged[name] && !chain.Schemas[i].IsNullable() {
+			pass.Reportf(spec.Name.Pos(), "%s: no field is tagged with required schema field %q", spec.Name.Name, name)
+		}
+	}
+}
+
+// fieldName is the Go name of field, or of its type when embedded.
+func fieldName(field *ast.Field) string {
+	if len(field.Names) > 0 {
+		return field.Names[0].Name
+	}
+	return types.ExprString(field.Type)
+}
+
+type tagOptions struct {
+	name  string
+	width int // 0 when not given
+}
+
+// parseTag parses name[,width=N].
+func parseTag(value string) (tagOptions, error) {
+	name, opts, _ := strings.Cut(value, ",")
+	tag := tagOptions{name: name}
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		key, val, _ := strings.Cut(opt, "=")
+		if key != "width" {
+			return tag, fmt.Errorf("unknown packos tag option %q", opt)
+		}
+		w, err := strconv.Atoi(val)
+		if err != nil || w <= 0 {
+			return tag, fmt.Errorf("invalid packos width %q", val)
+		}
+		tag.width = w
+	}
+	return tag, nil
+}
+
+// checkField reports a mismatch between a field of type t and the schema
+// it is tagged with.
+func checkField(t types.Type, tag tagOptions, s schema.Schema) error {
+	s = unwrap(s)
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		// pointers carry optional values; the schema sees the element
+		t = ptr.Elem()
+	}
+	if accepted := goTypes(s); accepted != nil {
+		if got := typeName(t); !slices.Contains(accepted, got) {
+			return fmt.Errorf("Go type %s does not match %T, which encodes %s",
+				got, s, strings.Join(accepted, " or "))
+		}
+	}
+	if tag.width != 0 {
+		width, ok := fixedWidth(s)
+		if !ok {
+			return fmt.Errorf("width=%d given for %T, which has no width", tag.width, s)
+		}
+		if width <= 0 {
+			return fmt.Errorf("width=%d given for a variable-width %T", tag.width, s)
+		}
+		if width != tag.width {
+			return fmt.Errorf("width=%d does not match %T width %d", tag.width, s, width)
+		}
+	}
+	return nil
+}
+
+// goTypes lists the Go types Encode of s accepts, or nil when the analyzer
+// does not know s.
+func goTypes(s schema.Schema) []string {
+	switch s := s.(type) {
+	case schema.SchemaBool:
+		return []string{"bool"}
+	case schema.SchemaInt8:
+		return []string{"int8"}
+	case schema.SchemaInt16:
+		return []string{"int16"}
+	case schema.SchemaInt32:
+		return []string{"int32"}
+	case schema.SchemaInt64:
+		return []string{"int64"}
+	case schema.SchemaVarint:
+		return []string{"int", "int8", "int16", "int32", "int64"}
+	case schema.SchemaFloat16:
+		return []string{"float32", "float64"}
+	case schema.SchemaFloat32:
+		return []string{"float32"}
+	case schema.SchemaFloat64:
+		return []string{"float64"}
+	case schema.SchemaString:
+		return []string{"string"}
+	case schema.SchemaBytes:
+		if s.AsBase64 {
+			return []string{"[]byte", "string"}
+		}
+		return []string{"[]byte"}
+	case schema.SchemaTime:
+		return []string{"time.Time", "string"}
+	case schema.SchemaDuration:
+		return []string{"time.Duration", "string", "int", "int32", "int64"}
+	case schema.SchemaUUID:
+		return []string{"[16]byte", "[]byte", "string"}
+	}
+	return nil
+}
+
+// typeName names t the way goTypes does: time types by name, others by
+// their underlying type, so a named int32 still matches SInt32.
+func typeName(t types.Type) string {
+	if named, ok := t.(*types.Named); ok {
+		if obj := named.Obj(); obj.Pkg() != nil && obj.Pkg().Path() == "time" {
+			return "time." + obj.Name()
+		}
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		if isByte(u.Elem()) {
+			return "[]byte"
+		}
+	case *types.Array:
+		if isByte(u.Elem()) {
+			return fmt.Sprintf("[%d]byte", u.Len())
+		}
+	}
+	return types.TypeString(t.Underlying(), nil)
+}
+
+func isByte(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Kind() == types.Uint8
+}
+
+func fixedWidth(s schema.Schema) (int, bool) {
+	switch s := s.(type) {
+	case schema.SchemaString:
+		return s.Width, true
+	case schema.SchemaBytes:
+		return s.Width, true
+	}
+	return 0, false
+}
+
+// unwrap returns the schema under wrappers that do not change the encoded
+// Go type.
+func unwrap(s schema.Schema) schema.Schema {
+	for {
+		switch w := s.(type) {
+		case schema.SchemaSanitized:
+			s = w.Schema
+		case schema.SchemaWidened:
+			s = w.Schema
+		case schema.SchemaRequired:
+			s = w.Schema
+		case schema.SchemaRuled:
+			s = w.Schema
+		case schema.SchemaNullable:
+			s = w.Schema
+		case schema.SchemaCached:
+			s = w.Schema
+		default:
+			return s
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/quickwritereader/PackOS/schema"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	schemas := map[string]schema.SchemaNamedChain{
+		"a.User": {
+			SchemaChain: schema.SChain(schema.SInt32, schema.SString, schema.SchemaString{Width: 8},
+				schema.SVariableBytes(), schema.SNullable(schema.STime), schema.SVarint,
+				schema.SInt16.RangeValues(0, 10)),
+			FieldNames: []string{"id", "name", "code", "avatar", "created", "seen", "score"},
+		},
+		"a.Order": {
+			SchemaChain: schema.SChain(schema.SInt32, schema.SBytes(4), schema.SchemaString{Width: 8},
+				schema.SString, schema.SString, schema.SFloat32, schema.SInt64),
+			FieldNames: []string{"id", "ref", "code", "note", "status", "Amount", "total"},
+		},
+	}
+	analysistest.Run(t, analysistest.TestData(), NewAnalyzer(schemas), "a")
+}
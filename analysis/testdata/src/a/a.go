@@ -0,0 +1,35 @@
+package a
+
+import "time"
+
+type UserID int32
+
+// User matches its schema.
+type User struct {
+	ID      UserID     `packos:"id"`
+	Name    string     `packos:"name"`
+	Code    string     `packos:"code,width=8"`
+	Avatar  []byte     `packos:"avatar"`
+	Created *time.Time `packos:"created"`
+	Seen    int64      `packos:"seen"`
+	Score   float64    `packos:"score"`
+	Local   string     `packos:"-"`
+	Other   int
+}
+
+// Order has drifted from its schema.
+type Order struct { // want `Order: no field is tagged with required schema field "total"`
+	ID     int64   `packos:"id"`           // want `Order.ID: field "id": Go type int64 does not match schema.SchemaInt32, which encodes int32`
+	Ref    string  `packos:"ref"`          // want `Order.Ref: field "ref": Go type string does not match schema.SchemaBytes, which encodes \[\]byte`
+	Code   string  `packos:"code,width=6"` // want `Order.Code: field "code": width=6 does not match schema.SchemaString width 8`
+	Note   string  `packos:"note,width=4"` // want `Order.Note: field "note": width=4 given for a variable-width schema.SchemaString`
+	Gone   bool    `packos:"removed"`      // want `Order.Gone: schema has no field "removed"`
+	Again  int64   `packos:"id"`           // want `Order.Again: field "id" is tagged more than once`
+	Status string  `packos:"status,wide"`  // want `Order.Status: unknown packos tag option "wide"`
+	Amount float32 `packos:""`
+}
+
+// Unregistered structs are not checked.
+type Unregistered struct {
+	ID int64 `packos:"id"`
+}
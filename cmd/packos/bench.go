@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/quickwritereader/PackOS/schema"
+)
+
+// benchResult is the outcome of one measured operation.
+type benchResult struct {
+	N           int
+	NsPerOp     float64
+	AllocsPerOp float64
+	BytesPerOp  float64
+}
+
+// bench measures encoding, validation and decoding of the sample at
+// samplePath with the schema at schemaPath.
+func bench(schemaPath, samplePath string, d time.Duration, w io.Writer) error {
+	if schemaPath == "" {
+		return errUsage
+	}
+	if samplePath == "" {
+		return errNoSample
+	}
+	chain, err := loadSchema(schemaPath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(samplePath)
+	if err != nil {
+		return err
+	}
+	val, err := parseJSON(data)
+	if err != nil {
+		return fmt.Errorf("sample %s: %w", samplePath, err)
+	}
+	buf, err := schema.EncodeValue(val, *chain)
+	if err != nil {
+		return fmt.Errorf("sample %s: %w", samplePath, err)
+	}
+	if err := schema.ValidateBuffer(buf, *chain); err != nil {
+		return fmt.Errorf("sample %s: encoded buffer does not validate: %w", samplePath, err)
+	}
+
+	ops := []struct {
+		name string
+		fn   func() error
+	}{
+		{"encode", func() error { _, err := schema.EncodeValue(val, *chain); return err }},
+		{"validate", func() error { return schema.ValidateBuffer(buf, *chain) }},
+		{"decode", func() error { _, err := schema.DecodeBuffer(buf, *chain); return err }},
+	}
+	fmt.Fprintf(w, "sample: %d bytes JSON, %d bytes packed\n", len(data), len(buf))
+	for _, op := range ops {
+		r, err := measure(d, op.fn)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op.name, err)
+		}
+		mbps := float64(len(buf)) / r.NsPerOp * 1e9 / 1e6
+		fmt.Fprintf(w, "%-9s %10d ops %12.1f ns/op %10.2f MB/s %8.1f allocs/op %10.1f B/op\n",
+			op.name, r.N, r.NsPerOp, mbps, r.AllocsPerOp, r.BytesPerOp)
+	}
+	return nil
+}
+
+// measure runs fn in growing batches, like testing.Benchmark, until one
+// batch takes at least d, and reports that batch.
+func measure(d time.Duration, fn func() error) (benchResult, error) {
+	n := 1
+	for {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			if err := fn(); err != nil {
+				return benchResult{}, err
+			}
+		}
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+		if elapsed >= d || n >= 1e9 {
+			return benchResult{
+				N:           n,
+				NsPerOp:     float64(elapsed.Nanoseconds()) / float64(n),
+				AllocsPerOp: float64(after.Mallocs-before.Mallocs) / float64(n),
+				BytesPerOp:  float64(after.TotalAlloc-before.TotalAlloc) / float64(n),
+			}, nil
+		}
+		// aim 20% past d, growing at most 100x per batch
+		next := n * 100
+		if perOp := elapsed.Nanoseconds() / int64(n); perOp > 0 {
+			next = min(next, int(d.Nanoseconds()*6/5/perOp))
+		}
+		n = max(next, n+1)
+	}
+}
@@ -0,0 +1,365 @@
+// Command packos inspects, validates and converts packed buffers.
+//
+//	packos dump [file]
+//	packos validate --schema schema.json [file]
+//	packos to-json [--schema schema.json] [file]
+//	packos from-json [--schema schema.json] [-o out.bin] [file]
+//	packos diff [--schema schema.json] old new
+//	packos bench --schema schema.json --sample data.json [--time 1s]
+//
+// Input is read from file, or from standard input when file is omitted or
+// "-". diff prints the changes that turn old into new, one per line, as
+// found by access.Diff; with a schema, both buffers are validated first and
+// tuple indexes in the paths are replaced by field names. bench encodes
+// the JSON sample with the schema, then measures encoding, validation and
+// decoding of it. Schemas are SchemaJSON documents as accepted by schema.BuildSchema
+// and describe the whole buffer; imports are read with schema.SchemaModules
+// relative to the schema file. Without a schema, to-json and from-json use
+// the schemaless access.ToJSON and access.FromJSON.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/debug"
+	"github.com/quickwritereader/PackOS/schema"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const usage = `usage:
+  packos dump [file]
+  packos validate --schema schema.json [file]
+  packos to-json [--schema schema.json] [file]
+  packos from-json [--schema schema.json] [-o out.bin] [file]
+  packos diff [--schema schema.json] old new
+  packos bench --schema schema.json --sample data.json [--time 1s]
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes one command and returns the process exit code: 0 on
+// success, 1 when the command fails and 2 on bad usage.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	cmd, args := args[0], args[1:]
+	fs := flag.NewFlagSet("packos "+cmd, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schemaPath := fs.String("schema", "", "SchemaJSON `file` describing the buffer")
+	outPath := fs.String("o", "", "write the result to `file` instead of standard output")
+	samplePath := fs.String("sample", "", "JSON `file` with the value to benchmark")
+	benchTime := fs.Duration("time", time.Second, "run each benchmark for about `duration`")
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	wantFiles := map[string]int{"diff": 2, "bench": 0}
+	if n, ok := wantFiles[cmd]; ok && len(files) != n || !ok && len(files) > 1 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	switch cmd {
+	case "dump", "validate", "to-json", "from-json":
+		err = execute(cmd, first(files), *schemaPath, *outPath, stdin, stdout)
+	case "diff":
+		err = diff(files[0], files[1], *schemaPath, stdout)
+	case "bench":
+		err = bench(*schemaPath, *samplePath, *benchTime, stdout)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "packos: unknown command %q\n%s", cmd, usage)
+		return 2
+	}
+	if errors.Is(err, errUsage) || errors.Is(err, errNoSample) {
+		fmt.Fprintf(stderr, "packos %s: %v\n%s", cmd, err, usage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "packos %s: %v\n", cmd, err)
+		return 1
+	}
+	return 0
+}
+
+var (
+	errUsage    = errors.New("missing --schema")
+	errNoSample = errors.New("missing --sample")
+)
+
+// parseArgs parses flags placed before, between or after the file
+// arguments, which it returns.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var files []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return files, nil
+		}
+		files = append(files, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func first(files []string) string {
+	if len(files) == 0 {
+		return ""
+	}
+	return files[0]
+}
+
+func execute(cmd, inPath, schemaPath, outPath string, stdin io.Reader, stdout io.Writer) error {
+	if cmd == "validate" && schemaPath == "" {
+		return errUsage
+	}
+	in, err := readInput(inPath, stdin)
+	if err != nil {
+		return err
+	}
+	var chain *schema.SchemaChain
+	if schemaPath != "" {
+		if chain, err = loadSchema(schemaPath); err != nil {
+			return err
+		}
+	}
+	var out bytes.Buffer
+	switch cmd {
+	case "dump":
+		err = debug.Dump(in, &out)
+	case "validate":
+		if err = schema.ValidateBuffer(in, *chain); err == nil {
+			out.WriteString("ok\n")
+		}
+	case "to-json":
+		err = toJSON(in, chain, &out)
+	case "from-json":
+		var buf []byte
+		if buf, err = fromJSON(in, chain); err == nil {
+			out.Write(buf)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if outPath != "" {
+		return os.WriteFile(outPath, out.Bytes(), 0o644)
+	}
+	_, err = stdout.Write(out.Bytes())
+	return err
+}
+
+func readInput(path string, stdin io.Reader) ([]byte, error) {
+	if path == "" || path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// loadSchema builds the chain for the SchemaJSON document at path, with
+// the modules it imports from its directory. Builder panics on invalid
+// documents are returned as errors.
+func loadSchema(path string) (chain *schema.SchemaChain, err error) {
+	js, err := schema.NewSchemaModules(os.DirFS(filepath.Dir(path))).Load(filepath.Base(path))
+	if err != nil {
+		return nil, fmt.Errorf("schema %s: %w", path, err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			chain, err = nil, fmt.Errorf("schema %s: %v", path, r)
+		}
+	}()
+	c := schema.SChain(schema.BuildSchema(js))
+	return &c, nil
+}
+
+func toJSON(buf []byte, chain *schema.SchemaChain, w io.Writer) error {
+	if chain == nil {
+		if err := access.ToJSON(buf, w); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	}
+	val, err := schema.DecodeBuffer(buf, *chain)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(val)
+}
+
+func fromJSON(data []byte, chain *schema.SchemaChain) ([]byte, error) {
+	if chain == nil {
+		return access.FromJSON(bytes.NewReader(data))
+	}
+	val, err := parseJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return schema.EncodeValue(val, *chain)
+}
+
+// parseJSON reads one JSON value. Numbers stay json.Number until a schema
+// coerces them, as in schema.EncodeJSON.
+func parseJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var val any
+	if err := dec.Decode(&val); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("trailing data after JSON value")
+	}
+	return val, nil
+}
+
+func diff(oldPath, newPath, schemaPath string, w io.Writer) error {
+	old, err := os.ReadFile(oldPath)
+	if err != nil {
+		return err
+	}
+	cur, err := os.ReadFile(newPath)
+	if err != nil {
+		return err
+	}
+	var chain *schema.SchemaChain
+	if schemaPath != "" {
+		if chain, err = loadSchema(schemaPath); err != nil {
+			return err
+		}
+		if err := schema.ValidateBuffer(old, *chain); err != nil {
+			return fmt.Errorf("%s: %w", oldPath, err)
+		}
+		if err := schema.ValidateBuffer(cur, *chain); err != nil {
+			return fmt.Errorf("%s: %w", newPath, err)
+		}
+	}
+	patch, err := access.Diff(old, cur)
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	for _, op := range patch {
+		if op.Path == "" {
+			fmt.Fprintf(&out, "~ (root): %s -> %s\n", jsonBuffer(old), jsonBuffer(op.Value))
+			continue
+		}
+		path := op.Path
+		if chain != nil {
+			path = namedPath(*chain, path)
+		}
+		tag, was, err := access.PathValue(old, op.Path)
+		switch {
+		case op.Kind == access.PatchDelete:
+			fmt.Fprintf(&out, "- %s: %s\n", path, jsonValue(tag, was))
+		case err != nil:
+			// a map key that new adds
+			fmt.Fprintf(&out, "+ %s: %s\n", path, jsonValue(op.Tag, op.Value))
+		default:
+			fmt.Fprintf(&out, "~ %s: %s -> %s\n", path, jsonValue(tag, was), jsonValue(op.Tag, op.Value))
+		}
+	}
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+// jsonValue renders one field as JSON.
+func jsonValue(tag typetags.Type, value []byte) string {
+	put := access.NewPutAccess()
+	put.AppendTagAndValue(tag, value)
+	return jsonBuffer(put.Pack())
+}
+
+// jsonBuffer renders a packed buffer as JSON, or as a note when it cannot.
+func jsonBuffer(buf []byte) string {
+	var sb strings.Builder
+	if err := access.ToJSON(buf, &sb); err != nil {
+		return fmt.Sprintf("<%d bytes: %v>", len(buf), err)
+	}
+	return sb.String()
+}
+
+// namedPath replaces the tuple indexes of a Diff path by the field names
+// the schema gives them, as far as the schema describes the path.
+func namedPath(chain schema.SchemaChain, path string) string {
+	segments := strings.Split(path, ".")
+	schemas := chain.Schemas
+	var names []string
+	var fields map[string]schema.Schema
+	for i, seg := range segments {
+		var next schema.Schema
+		if fields != nil {
+			next = fields[seg]
+		} else if idx, err := strconv.Atoi(seg); err == nil && idx >= 0 && idx < len(schemas) {
+			next = schemas[idx]
+			if idx < len(names) {
+				segments[i] = names[idx]
+			}
+		}
+		schemas, names, fields = nil, nil, nil
+		switch s := unwrap(next).(type) {
+		case schema.TupleSchema:
+			schemas = s.Schemas
+		case schema.TupleSchemaNamed:
+			schemas, names = s.Schemas, s.FieldNames
+		case schema.SchemaMapUnordered:
+			fields = s.Fields
+		default:
+			return strings.Join(segments, ".")
+		}
+		if slices.ContainsFunc(schemas, isRepeat) {
+			// indexes no longer line up with schemas
+			return strings.Join(segments, ".")
+		}
+	}
+	return strings.Join(segments, ".")
+}
+
+// unwrap returns the schema under wrappers that do not change the layout.
+func unwrap(s schema.Schema) schema.Schema {
+	for {
+		switch w := s.(type) {
+		case schema.SchemaSanitized:
+			s = w.Schema
+		case schema.SchemaWidened:
+			s = w.Schema
+		case schema.SchemaRequired:
+			s = w.Schema
+		case schema.SchemaRuled:
+			s = w.Schema
+		case schema.SchemaNullable:
+			s = w.Schema
+		case schema.SchemaCached:
+			s = w.Schema
+		default:
+			return s
+		}
+	}
+}
+
+func isRepeat(s schema.Schema) bool {
+	_, ok := s.(schema.SRepeatSchema)
+	return ok
+}
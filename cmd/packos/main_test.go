@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const personSchema = `{"type": "mapUnordered", "fieldNames": ["name", "age"],
+	"schema": [{"type": "string"}, {"type": "int16", "min": 0}]}`
+
+func runCmd(t *testing.T, stdin []byte, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	code = run(args, bytes.NewReader(stdin), &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestRun_FromJSONValidateToJSON(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema.json")
+	require.NoError(t, os.WriteFile(schemaPath, []byte(personSchema), 0o644))
+	binPath := filepath.Join(dir, "person.bin")
+
+	code, _, stderr := runCmd(t, []byte(`{"name": "ann", "age": 31}`), "from-json", "--schema", schemaPath, "-o", binPath)
+	require.Equal(t, 0, code, stderr)
+
+	code, stdout, _ := runCmd(t, nil, "validate", "--schema", schemaPath, binPath)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "ok\n", stdout)
+
+	code, stdout, _ = runCmd(t, nil, "to-json", "--schema", schemaPath, binPath)
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, `{"name": "ann", "age": 31}`, stdout)
+
+	code, stdout, _ = runCmd(t, nil, "dump", binPath)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, `"ann"`)
+
+	code, _, stderr = runCmd(t, []byte(`{"name": "ann", "age": -1}`), "from-json", "--schema", schemaPath)
+	assert.Equal(t, 1, code)
+	assert.True(t, strings.HasPrefix(stderr, "packos from-json: "))
+}
+
+func TestRun_Schemaless(t *testing.T) {
+	code, bin, _ := runCmd(t, []byte(`{"a": [1, "x"]}`), "from-json")
+	require.Equal(t, 0, code)
+	code, stdout, _ := runCmd(t, []byte(bin), "to-json", "-")
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, `{"a": [1, "x"]}`, stdout)
+}
+
+func TestRun_Usage(t *testing.T) {
+	code, _, _ := runCmd(t, nil)
+	assert.Equal(t, 2, code)
+	code, _, stderr := runCmd(t, nil, "validate")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "missing --schema")
+	code, _, _ = runCmd(t, nil, "frobnicate")
+	assert.Equal(t, 2, code)
+	code, _, _ = runCmd(t, nil, "dump", "a", "b")
+	assert.Equal(t, 2, code)
+	code, _, stderr = runCmd(t, nil, "dump", filepath.Join(t.TempDir(), "missing.bin"))
+	assert.Equal(t, 1, code)
+	assert.NotEmpty(t, stderr)
+}
+
+func TestRun_Diff(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema.json")
+	require.NoError(t, os.WriteFile(schemaPath, []byte(`{"type": "tuple", "fieldNames": ["id", "profile"],
+		"schema": [{"type": "int32"}, {"type": "mapUnordered", "fieldNames": ["name", "city"],
+			"schema": [{"type": "string"}, {"type": "string"}]}]}`), 0o644))
+	oldPath := filepath.Join(dir, "old.bin")
+	newPath := filepath.Join(dir, "new.bin")
+	code, _, stderr := runCmd(t, []byte(`{"id": 7, "profile": {"name": "ann", "city": "oslo"}}`), "from-json", "--schema", schemaPath, "-o", oldPath)
+	require.Equal(t, 0, code, stderr)
+	code, _, stderr = runCmd(t, []byte(`{"id": 8, "profile": {"name": "ann", "city": "bergen"}}`), "from-json", "--schema", schemaPath, "-o", newPath)
+	require.Equal(t, 0, code, stderr)
+
+	code, stdout, stderr := runCmd(t, nil, "diff", oldPath, newPath, "--schema", schemaPath)
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, "~ 0.id: 7 -> 8\n~ 0.profile.city: \"oslo\" -> \"bergen\"\n", stdout)
+
+	code, stdout, _ = runCmd(t, nil, "diff", oldPath, newPath)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "~ 0.0: 7 -> 8\n~ 0.1.city: \"oslo\" -> \"bergen\"\n", stdout)
+
+	code, stdout, _ = runCmd(t, nil, "diff", oldPath, oldPath)
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout)
+
+	code, _, _ = runCmd(t, nil, "diff", oldPath)
+	assert.Equal(t, 2, code)
+}
+
+func TestRun_Bench(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema.json")
+	require.NoError(t, os.WriteFile(schemaPath, []byte(personSchema), 0o644))
+	samplePath := filepath.Join(dir, "ann.json")
+	require.NoError(t, os.WriteFile(samplePath, []byte(`{"name": "ann", "age": 31}`), 0o644))
+
+	code, stdout, stderr := runCmd(t, nil, "bench", "--schema", schemaPath, "--sample", samplePath, "--time", "5ms")
+	require.Equal(t, 0, code, stderr)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "sample: 26 bytes JSON"))
+	for i, name := range []string{"encode", "validate", "decode"} {
+		assert.True(t, strings.HasPrefix(lines[i+1], name), lines[i+1])
+		assert.Contains(t, lines[i+1], "allocs/op")
+	}
+
+	code, _, stderr = runCmd(t, nil, "bench", "--schema", schemaPath)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "missing --sample")
+
+	require.NoError(t, os.WriteFile(samplePath, []byte(`{"name": "ann", "age": -1}`), 0o644))
+	code, _, _ = runCmd(t, nil, "bench", "--schema", schemaPath, "--sample", samplePath)
+	assert.Equal(t, 1, code)
+}
+
+func TestRun_SchemaImports(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "common"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common", "person.json"),
+		[]byte(`{"defs": {"Person": `+personSchema+`}}`), 0o644))
+	schemaPath := filepath.Join(dir, "schema.json")
+	require.NoError(t, os.WriteFile(schemaPath, []byte(`{"imports": ["common/person.json"], "type": "ref", "ref": "Person"}`), 0o644))
+
+	code, bin, stderr := runCmd(t, []byte(`{"name": "ann", "age": 31}`), "from-json", "--schema", schemaPath)
+	require.Equal(t, 0, code, stderr)
+	code, stdout, _ := runCmd(t, []byte(bin), "validate", "--schema", schemaPath)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "ok\n", stdout)
+
+	require.NoError(t, os.WriteFile(schemaPath, []byte(`{"type": "ref", "ref": "Person"}`), 0o644))
+	code, _, stderr = runCmd(t, []byte(bin), "validate", "--schema", schemaPath)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, `unknown ref "Person"`)
+}
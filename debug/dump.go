@@ -0,0 +1,198 @@
+// Package debug prints packed buffers for humans.
+//
+// Dump writes the header table of a buffer followed by its fields, each
+// with its type, width, raw bytes and decoded value, descending into maps
+// and tuples:
+//
+//	headers (base 10, 4 fields)
+//	  51 00  [0] offset 10 Integer
+//	  15 00  [1] delta 2 bool
+//	  1E 00  [2] delta 3 string
+//	  2E 00  [3] delta 5 string
+//	  38 00  [4] delta 7 end
+//	fields
+//	  [0] Integer w=2  2A 00  int16(42)
+//	  [1] bool w=1  01  true
+//	  [2] string w=2  67 6F  "go"
+//	  [3] string w=2  AA BB  "\xaa\xbb"
+//
+// Malformed headers are reported in place and end the dump of their
+// container, so the output shows how far a broken buffer is readable.
+package debug
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// maxHexBytes bounds the raw bytes printed for one payload.
+const maxHexBytes = 16
+
+// Dump writes an annotated view of buf to w. It returns the first write
+// error; problems in buf itself are part of the output.
+func Dump(buf []byte, w io.Writer) error {
+	d := dumper{w: w}
+	if access.IsInterned(buf) {
+		d.interned(buf)
+	} else {
+		d.list(buf, "", false)
+	}
+	return d.err
+}
+
+// Sprint returns the output of Dump as a string.
+func Sprint(buf []byte) string {
+	var sb strings.Builder
+	_ = Dump(buf, &sb)
+	return sb.String()
+}
+
+type dumper struct {
+	w   io.Writer
+	err error
+}
+
+func (d *dumper) printf(format string, args ...any) {
+	if d.err == nil {
+		_, d.err = fmt.Fprintf(d.w, format, args...)
+	}
+}
+
+// interned dumps the string dictionary and the record that refers to it.
+func (d *dumper) interned(buf []byte) {
+	n, k := binary.Uvarint(buf[2:])
+	if k <= 0 || n > uint64(len(buf)-2-k) {
+		d.printf("interned: invalid dictionary length\n")
+		return
+	}
+	dict := buf[2+k : 2+k+int(n)]
+	d.printf("interned dictionary (%d bytes)\n", len(dict))
+	d.list(dict, "  ", false)
+	d.printf("record\n")
+	d.list(buf[2+k+int(n):], "  ", true)
+}
+
+// list dumps one packed list: a header table and its payloads. refs is set
+// inside interned records, where TypeEnd before the last header marks a
+// dictionary reference.
+func (d *dumper) list(buf []byte, indent string, refs bool) {
+	if len(buf) < 4 {
+		d.printf("%smalformed: %d bytes, need at least 4\n", indent, len(buf))
+		return
+	}
+	base, _ := typetags.DecodeHeader(binary.LittleEndian.Uint16(buf))
+	if base < 4 || base%2 != 0 || base > len(buf) {
+		d.printf("%smalformed: base %d for %d bytes\n", indent, base, len(buf))
+		return
+	}
+	count := base / 2
+	d.printf("%sheaders (base %d, %d fields)\n", indent, base, count-1)
+
+	offsets := make([]int, count)
+	types := make([]typetags.Type, count)
+	for i := 0; i < count; i++ {
+		h := binary.LittleEndian.Uint16(buf[i*2:])
+		off, tp := typetags.DecodeHeader(h)
+		label := "delta"
+		if i == 0 {
+			label, offsets[i] = "offset", off
+		} else {
+			offsets[i] = off + base
+		}
+		types[i] = tp
+		name := tp.String()
+		switch {
+		case i == count-1:
+			name = "end"
+		case tp == typetags.TypeEnd && refs:
+			name = "ref"
+		}
+		d.printf("%s  %02X %02X  [%d] %s %d %s\n", indent, buf[i*2], buf[i*2+1], i, label, off, name)
+	}
+
+	d.printf("%sfields\n", indent)
+	for i := 0; i < count-1; i++ {
+		start, end := offsets[i], offsets[i+1]
+		if start > end || end > len(buf) {
+			d.printf("%s  [%d] malformed: range %d → %d of %d bytes\n", indent, i, start, end, len(buf))
+			return
+		}
+		d.field(i, types[i], buf[start:end], indent+"  ", refs)
+	}
+}
+
+func (d *dumper) field(i int, tp typetags.Type, payload []byte, indent string, refs bool) {
+	if tp == typetags.TypeEnd && refs {
+		idx, k := binary.Uvarint(payload)
+		if k <= 0 {
+			d.printf("%s[%d] ref w=%d  %s  malformed\n", indent, i, len(payload), hex(payload))
+			return
+		}
+		d.printf("%s[%d] ref w=%d  %s  #%d\n", indent, i, len(payload), hex(payload), idx)
+		return
+	}
+	if (tp.IsMap() || tp == typetags.TypeTuple) && len(payload) > 0 {
+		body := payload
+		if access.IsCompressed(payload) {
+			d.printf("%s[%d] %v w=%d  compressed with id %d\n", indent, i, tp, len(payload), payload[2])
+			var err error
+			if body, err = decompress(tp, payload); err != nil {
+				d.printf("%s  %v\n", indent, err)
+				return
+			}
+		} else {
+			d.printf("%s[%d] %v w=%d\n", indent, i, tp, len(payload))
+		}
+		d.list(body, indent+"  ", false)
+		return
+	}
+	if len(payload) == 0 {
+		d.printf("%s[%d] %v w=0  null\n", indent, i, tp)
+		return
+	}
+	d.printf("%s[%d] %v w=%d  %s  %s\n", indent, i, tp, len(payload), hex(payload), value(tp, payload))
+}
+
+// decompress returns the packed list of a compressed container body.
+func decompress(tp typetags.Type, body []byte) ([]byte, error) {
+	put := access.NewPutAccessFromPool()
+	defer access.ReleasePutAccess(put)
+	put.AppendTagAndValue(tp, body)
+	seq, err := access.NewSeqGetAccess(put.Pack())
+	if err != nil {
+		return nil, err
+	}
+	nested, err := seq.PeekNestedSeq()
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Clone(nested.UnderlineBuffer()), nil
+}
+
+func value(tp typetags.Type, payload []byte) string {
+	v, err := access.DecodePrimitive(tp, payload)
+	if err != nil {
+		return "?"
+	}
+	switch v := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case bool:
+		return fmt.Sprint(v)
+	default:
+		return fmt.Sprintf("%T(%v)", v, v)
+	}
+}
+
+func hex(b []byte) string {
+	if len(b) > maxHexBytes {
+		return fmt.Sprintf("% X …", b[:maxHexBytes])
+	}
+	return fmt.Sprintf("% X", b)
+}
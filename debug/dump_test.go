@@ -0,0 +1,85 @@
+package debug
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDump_Flat(t *testing.T) {
+	put := access.NewPutAccess()
+	put.AddInt16(42)
+	put.AddBool(true)
+	put.AddString("go")
+	put.AddNullableInt32(nil)
+
+	want := `headers (base 10, 4 fields)
+  51 00  [0] offset 10 Integer
+  15 00  [1] delta 2 bool
+  1E 00  [2] delta 3 string
+  29 00  [3] delta 5 Integer
+  28 00  [4] delta 5 end
+fields
+  [0] Integer w=2  2A 00  int16(42)
+  [1] bool w=1  01  true
+  [2] string w=2  67 6F  "go"
+  [3] Integer w=0  null
+`
+	assert.Equal(t, want, Sprint(put.Pack()))
+}
+
+func TestDump_NestedAndCompressed(t *testing.T) {
+	put := access.NewPutAccess()
+	m := put.BeginMap()
+	m.AddString("k")
+	m.AddFloat64(1.5)
+	put.EndNested(m)
+	c := put.BeginCompressedTuple(access.FlateCompressor{})
+	for i := 0; i < 40; i++ {
+		c.AddString("repeated payload")
+	}
+	put.EndNested(c)
+
+	out := Sprint(put.Pack())
+	assert.Contains(t, out, "  [0] map w=")
+	assert.Contains(t, out, "      [1] Float w=8  00 00 00 00 00 00 F8 3F  float64(1.5)\n")
+	assert.Contains(t, out, "  [1] tuple w=")
+	assert.Contains(t, out, "compressed with id 1\n")
+	assert.Contains(t, out, "      [39] string w=16  72 65 70 65 61 74 65 64 20 70 61 79 6C 6F 61 64  \"repeated payload\"\n")
+}
+
+func TestDump_Interned(t *testing.T) {
+	put := access.NewPutAccess()
+	put.SetInterning(true)
+	put.AddString("alpha")
+	put.AddString("alpha")
+	out := Sprint(put.Pack())
+
+	assert.Contains(t, out, "interned dictionary")
+	assert.Contains(t, out, "record\n")
+	assert.Contains(t, out, "] ref w=1  00  #0\n")
+}
+
+func TestDump_Malformed(t *testing.T) {
+	assert.Equal(t, "malformed: 3 bytes, need at least 4\n", Sprint([]byte{0x51, 0, 1}))
+
+	buf := access.NewPutAccess()
+	buf.AddString("abc")
+	broken := buf.Pack()
+	broken = broken[:len(broken)-1]
+	assert.Contains(t, Sprint(broken), "[0] malformed: range 4 → 7 of 6 bytes\n")
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("closed") }
+
+func TestDump_WriteError(t *testing.T) {
+	assert.Error(t, Dump([]byte{0x51, 0, 1}, failingWriter{}))
+	var b bytes.Buffer
+	require.NoError(t, Dump([]byte{0x51, 0, 1}, &b))
+}
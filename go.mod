@@ -10,6 +10,9 @@ require (
 	github.com/mus-format/mus-go v0.7.0
 	github.com/stretchr/testify v1.11.1
 	github.com/vmihailenco/msgpack/v5 v5.4.1
+	golang.org/x/exp v0.0.0-20230321023759-10a507213a29
+	golang.org/x/text v0.33.0
+	golang.org/x/tools v0.40.0
 )
 
 require (
@@ -19,7 +22,7 @@ require (
 	github.com/mus-format/common-go v0.0.0-20250307125743-867bbd6eb59c // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
-	golang.org/x/exp v0.0.0-20230321023759-10a507213a29 // indirect
-	golang.org/x/text v0.33.0 // indirect
+	golang.org/x/mod v0.31.0 // indirect
+	golang.org/x/sync v0.19.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
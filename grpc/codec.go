@@ -0,0 +1,85 @@
+// Package grpc provides a gRPC codec that carries PackOS payloads.
+//
+// Codec satisfies google.golang.org/grpc/encoding.Codec structurally, so this
+// module does not depend on gRPC. Register it from the service binary:
+//
+//	import (
+//		packosgrpc "github.com/quickwritereader/PackOS/grpc"
+//		"google.golang.org/grpc/encoding"
+//	)
+//
+//	encoding.RegisterCodec(packosgrpc.Codec{})
+//
+// Clients then select it per call with grpc.CallContentSubtype(packosgrpc.Name).
+package grpc
+
+import (
+	"fmt"
+
+	"github.com/quickwritereader/PackOS/access"
+)
+
+// Name is the content-subtype negotiated on the wire ("application/grpc+packos").
+const Name = "packos"
+
+// Marshaler is implemented by messages that pack themselves.
+type Marshaler interface {
+	MarshalPackOS() ([]byte, error)
+}
+
+// Unmarshaler is implemented by messages that unpack themselves.
+type Unmarshaler interface {
+	UnmarshalPackOS(buf []byte) error
+}
+
+// Codec marshals messages to PackOS buffers.
+//
+// Marshal accepts, in order of preference: a Marshaler, an access.Packable,
+// an already packed []byte, or any value accepted by PutAccess.AddAny.
+// Unmarshal fills an Unmarshaler, a *[]byte (raw buffer copy) or a *any
+// (generic decode as produced by access.Decode).
+type Codec struct{}
+
+func (Codec) Name() string {
+	return Name
+}
+
+func (Codec) Marshal(v any) ([]byte, error) {
+	switch m := v.(type) {
+	case Marshaler:
+		return m.MarshalPackOS()
+	case access.Packable:
+		put := access.NewPutAccessFromPool()
+		defer access.ReleasePutAccess(put)
+		put.AddPackable(m)
+		return put.Pack(), nil
+	case []byte:
+		return m, nil
+	default:
+		put := access.NewPutAccessFromPool()
+		defer access.ReleasePutAccess(put)
+		if err := put.AddAny(v, false); err != nil {
+			return nil, fmt.Errorf("packos codec: marshal %T: %w", v, err)
+		}
+		return put.Pack(), nil
+	}
+}
+
+func (Codec) Unmarshal(data []byte, v any) error {
+	switch m := v.(type) {
+	case Unmarshaler:
+		return m.UnmarshalPackOS(data)
+	case *[]byte:
+		*m = append((*m)[:0], data...)
+		return nil
+	case *any:
+		decoded, err := access.Decode(data)
+		if err != nil {
+			return fmt.Errorf("packos codec: %w", err)
+		}
+		*m = decoded
+		return nil
+	default:
+		return fmt.Errorf("packos codec: cannot unmarshal into %T", v)
+	}
+}
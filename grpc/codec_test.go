@@ -0,0 +1,74 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// codec mirrors google.golang.org/grpc/encoding.Codec.
+type codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	Name() string
+}
+
+var _ codec = Codec{}
+
+type ping struct {
+	ID   int32
+	Note string
+}
+
+func (p *ping) MarshalPackOS() ([]byte, error) {
+	put := access.NewPutAccess()
+	put.AddInt32(p.ID)
+	put.AddString(p.Note)
+	return put.Pack(), nil
+}
+
+func (p *ping) UnmarshalPackOS(buf []byte) error {
+	g := access.NewGetAccess(buf)
+	id, err := g.GetInt32(0)
+	if err != nil {
+		return err
+	}
+	note, err := g.GetString(1)
+	if err != nil {
+		return err
+	}
+	p.ID, p.Note = id, note
+	return nil
+}
+
+func TestCodec_MarshalerRoundTrip(t *testing.T) {
+	c := Codec{}
+	assert.Equal(t, "packos", c.Name())
+
+	data, err := c.Marshal(&ping{ID: 7, Note: "hi"})
+	require.NoError(t, err)
+
+	var out ping
+	require.NoError(t, c.Unmarshal(data, &out))
+	assert.Equal(t, ping{ID: 7, Note: "hi"}, out)
+}
+
+func TestCodec_GenericValues(t *testing.T) {
+	c := Codec{}
+	data, err := c.Marshal(map[string]any{"k": "v"})
+	require.NoError(t, err)
+
+	var decoded any
+	require.NoError(t, c.Unmarshal(data, &decoded))
+	assert.Equal(t, map[string]any{"k": "v"}, decoded)
+
+	var raw []byte
+	require.NoError(t, c.Unmarshal(data, &raw))
+	assert.Equal(t, data, raw)
+
+	_, err = c.Marshal(struct{}{})
+	assert.Error(t, err)
+	assert.Error(t, c.Unmarshal(data, &struct{}{}))
+}
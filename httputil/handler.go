@@ -0,0 +1,198 @@
+// Package httputil adapts PackOS schemas to net/http.
+package httputil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/schema"
+)
+
+const (
+	ContentTypePackOS = "application/x-packos"
+	ContentTypeJSON   = "application/json"
+)
+
+// MaxBodySize bounds request bodies read by Handler.
+var MaxBodySize int64 = 16 << 20
+
+type exchangeKey struct{}
+
+// exchange carries the decoded request and the pending response through the context.
+type exchange struct {
+	body      map[string]any
+	bodyType  string
+	status    int
+	response  any
+	encode    func(val any) ([]byte, error)
+	responded bool
+}
+
+// Handler decodes request bodies against chain before calling next.
+//
+// application/x-packos bodies are decoded with DecodeBufferNamed; application/json
+// bodies are encoded with schema.EncodeJSON first so they pass the same
+// validation and numeric coercion. Invalid bodies get 400, other content types 415.
+// Requests without a body reach next with a nil Body.
+//
+// Values passed to Respond or RespondSchema are written after next returns,
+// as PackOS or JSON depending on the Accept header.
+func Handler(chain schema.SchemaNamedChain, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ex := &exchange{}
+		if r.Body != nil && r.ContentLength != 0 {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			if len(data) > 0 {
+				ex.bodyType = mediaType
+				if ex.body, err = decodeBody(mediaType, data, chain); err != nil {
+					status := http.StatusBadRequest
+					if errors.Is(err, errUnsupportedMedia) {
+						status = http.StatusUnsupportedMediaType
+					}
+					http.Error(w, err.Error(), status)
+					return
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), exchangeKey{}, ex)))
+
+		if ex.responded {
+			writeResponse(w, r, ex)
+		}
+	})
+}
+
+var errUnsupportedMedia = errors.New("unsupported content type")
+
+func decodeBody(mediaType string, data []byte, chain schema.SchemaNamedChain) (map[string]any, error) {
+	switch mediaType {
+	case ContentTypePackOS:
+	case ContentTypeJSON:
+		packed, err := schema.EncodeJSON(data, chain)
+		if err != nil {
+			return nil, err
+		}
+		data = packed
+	default:
+		return nil, errUnsupportedMedia
+	}
+	decoded, err := schema.DecodeBufferNamed(data, chain)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := decoded.(map[string]any)
+	if !ok {
+		return nil, schema.ErrTypeMisMatch
+	}
+	return m, nil
+}
+
+func fromContext(r *http.Request) *exchange {
+	ex, _ := r.Context().Value(exchangeKey{}).(*exchange)
+	return ex
+}
+
+// Body returns the request body decoded by Handler, or nil when there was none.
+func Body(r *http.Request) map[string]any {
+	if ex := fromContext(r); ex != nil {
+		return ex.body
+	}
+	return nil
+}
+
+// Respond schedules val to be written with status once the handler returns.
+// PackOS responses are packed generically with sorted map keys.
+func Respond(r *http.Request, status int, val any) {
+	setResponse(r, status, val, func(val any) ([]byte, error) {
+		put := access.NewPutAccessFromPool()
+		defer access.ReleasePutAccess(put)
+		var err error
+		if m, ok := val.(map[string]any); ok {
+			err = put.AddMapAnySortedKey(m, false)
+		} else {
+			err = put.AddAny(val, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return put.Pack(), nil
+	})
+}
+
+// RespondSchema is Respond with PackOS responses encoded by chain.
+func RespondSchema(r *http.Request, status int, chain schema.SchemaNamedChain, val map[string]any) {
+	setResponse(r, status, val, func(val any) ([]byte, error) {
+		return schema.EncodeValueNamed(val, chain)
+	})
+}
+
+func setResponse(r *http.Request, status int, val any, encode func(any) ([]byte, error)) {
+	ex := fromContext(r)
+	if ex == nil {
+		panic("httputil: Respond called outside Handler")
+	}
+	ex.status, ex.response, ex.encode, ex.responded = status, val, encode, true
+}
+
+func writeResponse(w http.ResponseWriter, r *http.Request, ex *exchange) {
+	var data []byte
+	var err error
+	contentType := negotiate(r.Header.Get("Accept"), ex.bodyType)
+	if contentType == ContentTypePackOS {
+		data, err = ex.encode(ex.response)
+	} else {
+		data, err = json.Marshal(ex.response)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(ex.status)
+	w.Write(data)
+}
+
+// negotiate picks PackOS or JSON from an Accept header by q-value.
+// Without a usable preference the request body type wins, then JSON.
+func negotiate(accept, bodyType string) string {
+	bestType, bestQ := "", 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if mediaType != ContentTypePackOS && mediaType != ContentTypeJSON {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > bestQ {
+			bestType, bestQ = mediaType, q
+		}
+	}
+	if bestType != "" {
+		return bestType
+	}
+	if bodyType == ContentTypePackOS {
+		return ContentTypePackOS
+	}
+	return ContentTypeJSON
+}
@@ -0,0 +1,81 @@
+package httputil
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var userChain = schema.SchemaNamedChain{
+	SchemaChain: schema.SChain(schema.SInt32, schema.SString),
+	FieldNames:  []string{"id", "name"},
+}
+
+func echoHandler() http.Handler {
+	return Handler(userChain, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		RespondSchema(r, http.StatusOK, userChain, Body(r))
+	}))
+}
+
+func TestHandler_PackOSInJSONOut(t *testing.T) {
+	body, err := schema.EncodeValueNamed(map[string]any{"id": int32(5), "name": "ann"}, userChain)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", ContentTypePackOS)
+	req.Header.Set("Accept", "application/x-packos;q=0.5, application/json")
+	rec := httptest.NewRecorder()
+	echoHandler().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ContentTypeJSON, rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"id":5,"name":"ann"}`, rec.Body.String())
+}
+
+func TestHandler_JSONInPackOSOut(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id": 7, "name": "bo"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Accept", ContentTypePackOS)
+	rec := httptest.NewRecorder()
+	echoHandler().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ContentTypePackOS, rec.Header().Get("Content-Type"))
+	g := access.NewGetAccess(rec.Body.Bytes())
+	id, err := g.GetInt32(0)
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), id)
+}
+
+func TestHandler_Rejects(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id": "x", "name": "bo"}`))
+	req.Header.Set("Content-Type", ContentTypeJSON)
+	rec := httptest.NewRecorder()
+	echoHandler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`id=1`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	echoHandler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+}
+
+func TestHandler_GenericRespond(t *testing.T) {
+	h := Handler(userChain, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, Body(r))
+		Respond(r, http.StatusCreated, map[string]any{"ok": true})
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t, `{"ok":true}`, rec.Body.String())
+}
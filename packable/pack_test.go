@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/packostest"
+	"github.com/quickwritereader/PackOS/typetags"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -254,3 +256,87 @@ func TestPackable_TestPutAccessWithPackOrdered(t *testing.T) {
 		assert.Equalf(t, expected[i], actual[i], "Byte %d mismatch: expected %02X, got %02X", i, expected[i], actual[i])
 	}
 }
+
+func TestPackable_PackSortedMapLookupKey(t *testing.T) {
+	m := PackSortedMap{
+		"zeta":  PackInt16(26),
+		"alpha": PackString("a"),
+		"mid":   PackSortedMap{"inner": PackBool(true)},
+	}
+	buf := Pack(m)
+
+	// same payload as PackMapSorted, only the tag differs
+	plain := Pack(PackMapSorted(m))
+	assert.Equal(t, plain[4:], buf[4:])
+	assert.NotEqual(t, plain[:2], buf[:2])
+
+	p := access.NewPutAccess()
+	p.AddPackable(m)
+	assert.Equal(t, buf, p.Pack())
+
+	g := access.NewGetAccess(buf)
+	tp, val, err := g.LookupKey(0, "zeta")
+	require.NoError(t, err)
+	assert.Equal(t, typetags.TypeInteger, tp)
+	assert.Equal(t, []byte{26, 0}, val)
+
+	tp, val, err = g.LookupKey(0, "mid")
+	require.NoError(t, err)
+	assert.Equal(t, typetags.TypeSortedMap, tp)
+	inner := access.NewGetAccess(val)
+	flag, err := inner.GetBool(1)
+	require.NoError(t, err)
+	assert.True(t, flag)
+
+	decoded, err := access.Decode(buf)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"zeta": int16(26), "alpha": "a", "mid": map[string]any{"inner": true}}, decoded)
+}
+
+func TestPackable_PackMapNatural(t *testing.T) {
+	m := PackMapNatural{
+		"item10": PackInt16(10),
+		"item2":  PackInt16(2),
+		"item1":  PackInt16(1),
+	}
+	buf := Pack(m)
+	decoded, err := access.DecodeOrdered(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"item1", "item2", "item10"}, decoded.(*typetags.OrderedMapAny).Keys())
+
+	p := access.NewPutAccess()
+	p.AddPackable(m)
+	assert.Equal(t, buf, p.Pack())
+}
+
+func TestPackable_Arrays(t *testing.T) {
+	items := []access.Packable{
+		PackInt16(3),
+		PackInt32Array([]int32{1, -1, 1 << 20}),
+		PackFloat64Array([]float64{0.25, -8}),
+		PackInt64Array(nil),
+		PackFloat32Array([]float32{}),
+		PackInt16Array([]int16{7}),
+	}
+	actual := Pack(items...)
+
+	put := access.NewPutAccess()
+	for _, item := range items {
+		item.PackInto(put)
+	}
+	packostest.AssertBuffer(t, put.Pack(), actual)
+
+	get := access.NewGetAccess(actual)
+	i32, err := get.GetInt32Slice(1)
+	require.NoError(t, err)
+	assert.Equal(t, []int32{1, -1, 1 << 20}, i32)
+	f64, err := get.GetFloat64Slice(2)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.25, -8}, f64)
+	i64, err := get.GetInt64Slice(3)
+	require.NoError(t, err)
+	assert.Nil(t, i64)
+	i16, err := get.GetInt16Slice(5)
+	require.NoError(t, err)
+	assert.Equal(t, []int16{7}, i16)
+}
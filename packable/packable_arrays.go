@@ -0,0 +1,50 @@
+package packable
+
+import (
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// PackArrayRef implements the Packable interface for a typed numeric array.
+// Like PackByteArrayRef it holds the slice by reference to avoid boxing.
+// See access.AddArray for the layout.
+type PackArrayRef[T access.ArrayElem] struct {
+	ref *[]T
+}
+
+func (p PackArrayRef[T]) HeaderType() typetags.Type { return typetags.TypeByteArray }
+func (p PackArrayRef[T]) ValueSize() int {
+	if *p.ref == nil {
+		return 0
+	}
+	return access.ArrayValueSize[T](len(*p.ref))
+}
+func (p PackArrayRef[T]) Write(buf []byte, pos int) int {
+	if *p.ref == nil {
+		return pos
+	}
+	return access.WriteArray(buf, pos, *p.ref)
+}
+func (v PackArrayRef[T]) PackInto(p *access.PutAccess) {
+	access.AddArray(p, *v.ref)
+}
+
+func PackInt16Array(v []int16) PackArrayRef[int16] {
+	return PackArrayRef[int16]{ref: &v}
+}
+
+func PackInt32Array(v []int32) PackArrayRef[int32] {
+	return PackArrayRef[int32]{ref: &v}
+}
+
+func PackInt64Array(v []int64) PackArrayRef[int64] {
+	return PackArrayRef[int64]{ref: &v}
+}
+
+func PackFloat32Array(v []float32) PackArrayRef[float32] {
+	return PackArrayRef[float32]{ref: &v}
+}
+
+func PackFloat64Array(v []float64) PackArrayRef[float64] {
+	return PackArrayRef[float64]{ref: &v}
+}
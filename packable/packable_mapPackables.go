@@ -32,7 +32,11 @@ func (p PackMapSorted) Write(buf []byte, pos int) int {
 	if len(p) == 0 {
 		return pos
 	}
-	keys := utils.SortKeys(p)
+	return writeMapKeys(buf, pos, p, utils.SortKeys(p))
+}
+
+// writeMapKeys packs the entries of p in the order of keys.
+func writeMapKeys(buf []byte, pos int, p map[string]access.Packable, keys []string) int {
 	headerSize := len(p)*2*access.HeaderTagSize + access.HeaderTagSize
 	first := pos
 	posH := pos
@@ -52,6 +56,49 @@ func (p PackMapSorted) Write(buf []byte, pos int) int {
 	return pos
 }
 
+// PackSortedMap packs like PackMapSorted but tags the container as
+// TypeSortedMap, so readers may binary-search its keys.
+type PackSortedMap map[string]access.Packable
+
+// ValueSize returns the size of the packed map's content.
+func (p PackSortedMap) ValueSize() int {
+	return PackMapSorted(p).ValueSize()
+}
+
+// HeaderType returns the type of the header for a sorted map.
+func (p PackSortedMap) HeaderType() typetags.Type {
+	return typetags.TypeSortedMap
+}
+
+// Write packs the map with ascending keys.
+func (p PackSortedMap) Write(buf []byte, pos int) int {
+	return PackMapSorted(p).Write(buf, pos)
+}
+
+// PackMapNatural packs like PackMapSorted with keys in natural order, as
+// utils.NaturalLess defines it, so "item2" comes before "item10" for
+// readers that show keys as stored. It is tagged TypeMap: readers that
+// binary-search TypeSortedMap containers rely on byte order.
+type PackMapNatural map[string]access.Packable
+
+// ValueSize returns the size of the packed map's content.
+func (p PackMapNatural) ValueSize() int {
+	return PackMapSorted(p).ValueSize()
+}
+
+// HeaderType returns the type of the header for a map.
+func (p PackMapNatural) HeaderType() typetags.Type {
+	return typetags.TypeMap
+}
+
+// Write packs the map with keys in natural order.
+func (p PackMapNatural) Write(buf []byte, pos int) int {
+	if len(p) == 0 {
+		return pos
+	}
+	return writeMapKeys(buf, pos, p, utils.SortKeysFunc(p, utils.NaturalLess))
+}
+
 // PackMap packs a map of Packable values. This is the unsorted version.
 type PackMap map[string]access.Packable
 
@@ -319,6 +366,24 @@ func (pack PackMapSorted) PackInto(p *access.PutAccess) {
 	BufferPoolInst.Release(buffer)
 }
 
+func (pack PackSortedMap) PackInto(p *access.PutAccess) {
+	size := pack.ValueSize()
+	buffer := BufferPoolInst.Acquire(size)
+	pos := 0
+	pos = pack.Write(buffer, pos)
+	p.AppendTagAndValue(typetags.TypeSortedMap, buffer[:pos])
+	BufferPoolInst.Release(buffer)
+}
+
+func (pack PackMapNatural) PackInto(p *access.PutAccess) {
+	size := pack.ValueSize()
+	buffer := BufferPoolInst.Acquire(size)
+	pos := 0
+	pos = pack.Write(buffer, pos)
+	p.AppendTagAndValue(typetags.TypeMap, buffer[:pos])
+	BufferPoolInst.Release(buffer)
+}
+
 func (pack PackMapStr) PackInto(p *access.PutAccess) {
 	size := pack.ValueSize()
 	buffer := BufferPoolInst.Acquire(size)
@@ -184,3 +184,12 @@ func PackFlags(flags ...bool) PackByteArrayRef {
 
 	return PackByteArrayRef{ref: &buf}
 }
+
+// PackBitset packs b in the compressed layout of access.EncodeBitset.
+// A nil b is packed as null.
+func PackBitset(b *typetags.Bitset) PackByteArrayRef {
+	if b == nil {
+		return PackByteArray(nil)
+	}
+	return PackByteArray(access.EncodeBitset(b))
+}
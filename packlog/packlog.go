@@ -0,0 +1,223 @@
+// Package packlog is an append-only log of PackOS buffers.
+//
+// Record layout, all integers little endian:
+//
+//	uint32  payload length
+//	uint64  sequence number, strictly increasing by one
+//	uint32  CRC-32C of the sequence number and payload
+//	...     payload
+//
+// A crash can leave a partially written record at the end of the file.
+// Open detects it and truncates the file back to the last complete record.
+// A bad record with more data after it is not a torn tail, and Open
+// returns ErrCorrupt rather than drop the records that follow.
+package packlog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+const headerSize = 4 + 8 + 4
+
+// MaxRecordSize bounds payloads accepted by Append and Reader.
+const MaxRecordSize = 64 << 20
+
+var (
+	ErrCorrupt        = errors.New("packlog: corrupt record")
+	ErrRecordTooLarge = errors.New("packlog: record too large")
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Record is one log entry.
+type Record struct {
+	Seq     uint64
+	Payload []byte
+}
+
+func checksum(seq uint64, payload []byte) uint32 {
+	var seqBuf [8]byte
+	binary.LittleEndian.PutUint64(seqBuf[:], seq)
+	crc := crc32.Update(0, castagnoli, seqBuf[:])
+	return crc32.Update(crc, castagnoli, payload)
+}
+
+// Reader reads records sequentially.
+type Reader struct {
+	r       *bufio.Reader
+	hdr     [headerSize]byte
+	lastSeq uint64
+	started bool
+	offset  int64
+}
+
+// NewReader reads records from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Offset is the byte offset just past the last record returned by Next.
+func (lr *Reader) Offset() int64 {
+	return lr.offset
+}
+
+// Next returns the next record. It returns io.EOF at a clean end of log,
+// io.ErrUnexpectedEOF for a torn tail and ErrCorrupt for checksum or
+// sequence mismatches.
+func (lr *Reader) Next() (Record, error) {
+	if _, err := io.ReadFull(lr.r, lr.hdr[:]); err != nil {
+		return Record{}, err
+	}
+	size := binary.LittleEndian.Uint32(lr.hdr[0:])
+	seq := binary.LittleEndian.Uint64(lr.hdr[4:])
+	crc := binary.LittleEndian.Uint32(lr.hdr[12:])
+	if size > MaxRecordSize {
+		return Record{}, ErrRecordTooLarge
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(lr.r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return Record{}, err
+	}
+	if checksum(seq, payload) != crc {
+		return Record{}, fmt.Errorf("%w: checksum mismatch at seq %d", ErrCorrupt, seq)
+	}
+	if lr.started && seq != lr.lastSeq+1 {
+		return Record{}, fmt.Errorf("%w: seq %d follows %d", ErrCorrupt, seq, lr.lastSeq)
+	}
+	lr.started, lr.lastSeq = true, seq
+	lr.offset += int64(headerSize) + int64(size)
+	return Record{Seq: seq, Payload: payload}, nil
+}
+
+// Writer appends records to a log file.
+type Writer struct {
+	f       *os.File
+	w       *bufio.Writer
+	nextSeq uint64
+	hdr     [headerSize]byte
+}
+
+// Open opens or creates the log at path for appending. An incomplete or
+// corrupt last record is truncated away; the returned count reports how
+// many bytes were dropped. A corrupt record followed by more data fails
+// with ErrCorrupt and leaves the file untouched.
+func Open(path string) (*Writer, int64, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, 0, err
+	}
+	next, valid, err := scan(f)
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	dropped := info.Size() - valid
+	if dropped > 0 {
+		if err := f.Truncate(valid); err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+	}
+	if _, err := f.Seek(valid, io.SeekStart); err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return &Writer{f: f, w: bufio.NewWriter(f), nextSeq: next}, dropped, nil
+}
+
+// scan returns the next sequence number and the length of the valid prefix.
+func scan(f *os.File) (next uint64, valid int64, err error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+	lr := NewReader(f)
+	next = 1
+	for {
+		rec, err := lr.Next()
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return next, lr.Offset(), nil
+		}
+		if errors.Is(err, ErrCorrupt) || errors.Is(err, ErrRecordTooLarge) {
+			// only the last record can be torn by a crash
+			valid = lr.Offset()
+			var size [4]byte
+			if _, err := f.ReadAt(size[:], valid); err != nil {
+				return 0, 0, err
+			}
+			end := valid + headerSize + int64(binary.LittleEndian.Uint32(size[:]))
+			if end < info.Size() {
+				return 0, 0, fmt.Errorf("%w: bad record at offset %d is followed by %d bytes", ErrCorrupt, valid, info.Size()-end)
+			}
+			return next, valid, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		next = rec.Seq + 1
+	}
+}
+
+// NextSeq is the sequence number the next Append will use.
+func (lw *Writer) NextSeq() uint64 {
+	return lw.nextSeq
+}
+
+// Append buffers payload as a new record and returns its sequence number.
+// Call Flush or Sync to make it durable.
+func (lw *Writer) Append(payload []byte) (uint64, error) {
+	if len(payload) > MaxRecordSize {
+		return 0, ErrRecordTooLarge
+	}
+	seq := lw.nextSeq
+	binary.LittleEndian.PutUint32(lw.hdr[0:], uint32(len(payload)))
+	binary.LittleEndian.PutUint64(lw.hdr[4:], seq)
+	binary.LittleEndian.PutUint32(lw.hdr[12:], checksum(seq, payload))
+	if _, err := lw.w.Write(lw.hdr[:]); err != nil {
+		return 0, err
+	}
+	if _, err := lw.w.Write(payload); err != nil {
+		return 0, err
+	}
+	lw.nextSeq++
+	return seq, nil
+}
+
+// Flush writes buffered records to the file.
+func (lw *Writer) Flush() error {
+	return lw.w.Flush()
+}
+
+// Sync flushes and fsyncs the file.
+func (lw *Writer) Sync() error {
+	if err := lw.w.Flush(); err != nil {
+		return err
+	}
+	return lw.f.Sync()
+}
+
+// Close syncs and closes the file.
+func (lw *Writer) Close() error {
+	if err := lw.Sync(); err != nil {
+		lw.f.Close()
+		return err
+	}
+	return lw.f.Close()
+}
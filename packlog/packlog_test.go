@@ -0,0 +1,123 @@
+package packlog
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pack "github.com/quickwritereader/PackOS/packable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, path string) []Record {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var out []Record
+	lr := NewReader(f)
+	for {
+		rec, err := lr.Next()
+		if err == io.EOF {
+			return out
+		}
+		require.NoError(t, err)
+		out = append(out, rec)
+	}
+}
+
+func TestPacklog_AppendReopenContinue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.plog")
+
+	w, dropped, err := Open(path)
+	require.NoError(t, err)
+	assert.Zero(t, dropped)
+	for i := int32(0); i < 3; i++ {
+		seq, err := w.Append(pack.Pack(pack.PackInt32(i)))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(i+1), seq)
+	}
+	require.NoError(t, w.Close())
+
+	w, _, err = Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), w.NextSeq())
+	_, err = w.Append(pack.Pack(pack.PackString("four")))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	recs := readAll(t, path)
+	require.Len(t, recs, 4)
+	assert.Equal(t, uint64(4), recs[3].Seq)
+	assert.Equal(t, pack.Pack(pack.PackInt32(1)), recs[1].Payload)
+}
+
+func TestPacklog_RecoveryTruncatesTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wal.plog")
+	w, _, err := Open(path)
+	require.NoError(t, err)
+	_, err = w.Append(pack.Pack(pack.PackInt32(1)))
+	require.NoError(t, err)
+	_, err = w.Append(pack.Pack(pack.PackInt32(2)))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	full := info.Size()
+	// tear the last record
+	require.NoError(t, os.Truncate(path, full-3))
+
+	w, dropped, err := Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, full/2-3, dropped)
+	assert.Equal(t, uint64(2), w.NextSeq())
+	require.NoError(t, w.Close())
+	assert.Len(t, readAll(t, path), 1)
+
+	// corrupt the payload of the only record
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[len(data)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	_, err = NewReader(openFile(t, path)).Next()
+	assert.ErrorIs(t, err, ErrCorrupt)
+
+	w, dropped, err = Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), dropped)
+	assert.Equal(t, uint64(1), w.NextSeq())
+	require.NoError(t, w.Close())
+}
+
+func openFile(t *testing.T, path string) *os.File {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestPacklog_CorruptMiddleRecordFailsOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wal.plog")
+	w, _, err := Open(path)
+	require.NoError(t, err)
+	for i := int32(1); i <= 3; i++ {
+		_, err = w.Append(pack.Pack(pack.PackInt32(i)))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	// flip a payload bit of the second record
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[len(data)/3*2-1] ^= 0x01
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	_, _, err = Open(path)
+	assert.ErrorIs(t, err, ErrCorrupt)
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, data, after)
+}
@@ -0,0 +1,100 @@
+// Package packostest provides helpers for tests that build and compare PackOS
+// buffers: a fluent Builder, golden-buffer comparison with annotated diffs, and
+// schema assertions.
+package packostest
+
+import (
+	"github.com/quickwritereader/PackOS/access"
+)
+
+// Builder packs fields fluently:
+//
+//	buf := packostest.New().
+//		Int16(42).
+//		String("go").
+//		Map(func(m *packostest.Builder) { m.String("k").Bool(true) }).
+//		Pack()
+type Builder struct {
+	put    *access.PutAccess
+	fields int
+	err    error
+}
+
+// New returns an empty Builder.
+func New() *Builder {
+	return &Builder{put: access.NewPutAccess()}
+}
+
+func (b *Builder) add(fn func(p *access.PutAccess)) *Builder {
+	fn(b.put)
+	b.fields++
+	return b
+}
+
+func (b *Builder) Int8(v int8) *Builder   { return b.add(func(p *access.PutAccess) { p.AddInt8(v) }) }
+func (b *Builder) Int16(v int16) *Builder { return b.add(func(p *access.PutAccess) { p.AddInt16(v) }) }
+func (b *Builder) Int32(v int32) *Builder { return b.add(func(p *access.PutAccess) { p.AddInt32(v) }) }
+func (b *Builder) Int64(v int64) *Builder { return b.add(func(p *access.PutAccess) { p.AddInt64(v) }) }
+func (b *Builder) Float32(v float32) *Builder {
+	return b.add(func(p *access.PutAccess) { p.AddFloat32(v) })
+}
+func (b *Builder) Float64(v float64) *Builder {
+	return b.add(func(p *access.PutAccess) { p.AddFloat64(v) })
+}
+func (b *Builder) Bool(v bool) *Builder { return b.add(func(p *access.PutAccess) { p.AddBool(v) }) }
+func (b *Builder) String(v string) *Builder {
+	return b.add(func(p *access.PutAccess) { p.AddString(v) })
+}
+func (b *Builder) Bytes(v []byte) *Builder { return b.add(func(p *access.PutAccess) { p.AddBytes(v) }) }
+
+// Null adds a null tuple.
+func (b *Builder) Null() *Builder { return b.add(func(p *access.PutAccess) { p.AddNull(nil) }) }
+
+// Int adds an integer in the smallest width that holds it.
+func (b *Builder) Int(v int64) *Builder {
+	return b.add(func(p *access.PutAccess) { p.AddIntegerCompressed(v) })
+}
+
+// Any adds v the way PutAccess.AddAny does. Errors surface from Pack.
+func (b *Builder) Any(v any) *Builder {
+	return b.add(func(p *access.PutAccess) {
+		if err := p.AddAny(v, false); err != nil && b.err == nil {
+			b.err = err
+		}
+	})
+}
+
+// Map adds a map whose keys and values are written by fn in order.
+// A map with no entries is packed zero-width.
+func (b *Builder) Map(fn func(m *Builder)) *Builder {
+	return b.nested(b.put.BeginMap(), fn)
+}
+
+// Tuple adds a tuple whose fields are written by fn. An empty tuple is packed as null.
+func (b *Builder) Tuple(fn func(t *Builder)) *Builder {
+	return b.nested(b.put.BeginTuple(), fn)
+}
+
+func (b *Builder) nested(put *access.PutAccess, fn func(*Builder)) *Builder {
+	child := &Builder{put: put}
+	fn(child)
+	if child.err != nil && b.err == nil {
+		b.err = child.err
+	}
+	if child.fields == 0 {
+		access.ReleasePutAccess(put)
+	} else {
+		b.put.EndNested(put)
+	}
+	b.fields++
+	return b
+}
+
+// Pack finalizes the buffer. It panics if an Any value could not be packed,
+// which keeps call sites in tests to a single expression.
+func (b *Builder) Pack() []byte {
+	if b.err != nil {
+		panic("packostest: " + b.err.Error())
+	}
+	return b.put.Pack()
+}
@@ -0,0 +1,108 @@
+package packostest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// maxReportedDiffs bounds the annotated lines in a failure message.
+const maxReportedDiffs = 10
+
+// Diff compares two buffers byte by byte and describes each difference using
+// the layout of expected, for example
+//
+//	byte 17 differs: header 2 delta 5→6 (0x2E→0x36)
+//	byte 40 differs: field 1 > field 3 payload +2 (string) 0x61→0x62
+//
+// It returns nil when the buffers are equal.
+func Diff(expected, actual []byte) []string {
+	var out []string
+	if len(expected) != len(actual) {
+		out = append(out, fmt.Sprintf("length differs: %d→%d", len(expected), len(actual)))
+	}
+	for i := 0; i < min(len(expected), len(actual)); i++ {
+		if expected[i] == actual[i] {
+			continue
+		}
+		if len(out) >= maxReportedDiffs {
+			out = append(out, "...")
+			break
+		}
+		out = append(out, fmt.Sprintf("byte %d differs: %s", i, describe(expected, actual, i, "")))
+	}
+	return out
+}
+
+// AssertBuffer fails t with an annotated diff when actual differs from expected.
+func AssertBuffer(t testing.TB, expected, actual []byte) bool {
+	t.Helper()
+	diffs := Diff(expected, actual)
+	if diffs == nil {
+		return true
+	}
+	t.Errorf("buffers differ:\n  %s\nexpected: % X\nactual:   % X",
+		strings.Join(diffs, "\n  "), expected, actual)
+	return false
+}
+
+// describe locates byte i inside the container exp, descending into nested containers.
+func describe(exp, act []byte, i int, path string) string {
+	raw := fmt.Sprintf("0x%02X→0x%02X", exp[i], act[i])
+	if len(exp) < 4 {
+		return path + raw
+	}
+	base, _ := typetags.DecodeHeader(binary.LittleEndian.Uint16(exp))
+	if base < 4 || base > len(exp) {
+		return path + raw
+	}
+	count := base / 2
+
+	if i < base {
+		entry := i / 2
+		he := binary.LittleEndian.Uint16(exp[entry*2:])
+		ha := he
+		if entry*2+2 <= len(act) {
+			ha = binary.LittleEndian.Uint16(act[entry*2:])
+		}
+		oe, te := typetags.DecodeHeader(he)
+		oa, ta := typetags.DecodeHeader(ha)
+		var parts []string
+		label := "delta"
+		switch entry {
+		case 0:
+			label = "base"
+		case count - 1:
+			label = "end delta"
+		}
+		if oe != oa {
+			parts = append(parts, fmt.Sprintf("%s %d→%d", label, oe, oa))
+		}
+		if te != ta && entry != count-1 {
+			parts = append(parts, fmt.Sprintf("type %v→%v", te, ta))
+		}
+		if len(parts) == 0 {
+			parts = append(parts, "unchanged fields")
+		}
+		return fmt.Sprintf("%sheader %d %s (%s)", path, entry, strings.Join(parts, ", "), raw)
+	}
+
+	for k := 0; k < count-1; k++ {
+		start, tp := typetags.DecodeHeader(binary.LittleEndian.Uint16(exp[k*2:]))
+		if k > 0 {
+			start += base
+		}
+		end := typetags.DecodeOffset(binary.LittleEndian.Uint16(exp[(k+1)*2:])) + base
+		if i < start || i >= end || end > len(exp) {
+			continue
+		}
+		if (tp.IsMap() || tp == typetags.TypeTuple) && end <= len(act) {
+			return describe(exp[start:end], act[start:end], i-start, fmt.Sprintf("%sfield %d > ", path, k))
+		}
+		return fmt.Sprintf("%sfield %d payload +%d (%v) %s", path, k, i-start, tp, raw)
+	}
+	return path + raw
+}
@@ -0,0 +1,116 @@
+package packostest
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder captures failures so assertion helpers can be tested for failing too.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestBuilder_MatchesPutAccess(t *testing.T) {
+	put := access.NewPutAccess()
+	put.AddInt16(42)
+	put.AddString("go")
+	m := put.BeginMap()
+	m.AddString("k")
+	m.AddBool(true)
+	put.EndNested(m)
+	put.AddNull(nil)
+	put.AddIntegerCompressed(300)
+	require.NoError(t, put.AddAny([]any{int8(1)}, false))
+	put.AddNull(nil)
+
+	actual := New().
+		Int16(42).
+		String("go").
+		Map(func(m *Builder) { m.String("k").Bool(true) }).
+		Null().
+		Int(300).
+		Any([]any{int8(1)}).
+		Tuple(func(*Builder) {}).
+		Pack()
+	AssertBuffer(t, put.Pack(), actual)
+
+	assert.Panics(t, func() { New().Any(struct{}{}).Pack() })
+}
+
+func TestDiff_Annotations(t *testing.T) {
+	expected := New().Int16(42).String("go").Tuple(func(tb *Builder) { tb.Int8(1).String("ab") }).Pack()
+	assert.Nil(t, Diff(expected, expected))
+
+	// same layout, different payload byte in the nested string
+	actual := New().Int16(42).String("go").Tuple(func(tb *Builder) { tb.Int8(1).String("ac") }).Pack()
+	diffs := Diff(expected, actual)
+	require.Len(t, diffs, 1)
+	assert.Contains(t, diffs[0], "field 2 > field 1 payload +1 (string) 0x62→0x63")
+
+	// longer string shifts the end header and the nested container
+	actual = New().Int16(42).String("gox").Tuple(func(tb *Builder) { tb.Int8(1).String("ab") }).Pack()
+	diffs = Diff(expected, actual)
+	assert.Equal(t, fmt.Sprintf("length differs: %d→%d", len(expected), len(expected)+1), diffs[0])
+	assert.Contains(t, diffs[1], "header 2 delta 4→5")
+
+	// a type change in the first header
+	actual = New().Int16(42).Bool(true).Bool(false).Pack()
+	other := New().Int16(42).Int8(1).Int8(0).Pack()
+	diffs = Diff(other, actual)
+	assert.Contains(t, strings.Join(diffs, "\n"), "type Integer→bool")
+
+	r := &recorder{TB: t}
+	assert.False(t, AssertBuffer(r, expected, actual))
+	require.Len(t, r.errs, 1)
+	assert.Contains(t, r.errs[0], "buffers differ")
+}
+
+func TestAssertBuffer(t *testing.T) {
+	expected := New().Int16(42).String("go").Pack()
+	r := &recorder{TB: t}
+	assert.True(t, AssertBuffer(r, expected, New().Int16(42).String("go").Pack()))
+	assert.Empty(t, r.errs)
+
+	actual := New().Int16(42).String("gop").Pack()
+	assert.False(t, AssertBuffer(r, expected, actual))
+	require.Len(t, r.errs, 1)
+	for _, line := range Diff(expected, actual) {
+		assert.Contains(t, r.errs[0], line)
+	}
+	assert.Contains(t, r.errs[0], fmt.Sprintf("expected: % X", expected))
+	assert.Contains(t, r.errs[0], fmt.Sprintf("actual:   % X", actual))
+
+	// long runs of differences are cut short
+	r.errs = nil
+	assert.False(t, AssertBuffer(r, make([]byte, 32), bytes.Repeat([]byte{1}, 32)))
+	require.Len(t, r.errs, 1)
+	assert.Equal(t, maxReportedDiffs, strings.Count(r.errs[0], "differs:"))
+	assert.Contains(t, r.errs[0], "...")
+}
+
+func TestSchemaAssertions(t *testing.T) {
+	chain := schema.SChain(schema.SInt16.RangeValues(0, 10), schema.SString)
+	AssertValid(t, chain, New().Int16(5).String("x").Pack())
+	AssertInvalid(t, chain, New().Int16(50).String("x").Pack(), schema.ErrOutOfRange)
+	buf := AssertRoundTrip(t, chain, []any{int16(3), "y"})
+	assert.NotEmpty(t, buf)
+
+	r := &recorder{TB: t}
+	assert.False(t, AssertValid(r, chain, New().Int16(50).String("x").Pack()))
+	assert.False(t, AssertInvalid(r, chain, New().Int16(5).String("x").Pack(), schema.ErrOutOfRange))
+	assert.False(t, AssertInvalid(r, chain, New().Int16(50).String("x").Pack(), schema.ErrStringPrefix))
+	assert.Len(t, r.errs, 3)
+}
@@ -0,0 +1,62 @@
+package packostest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+// AssertValid fails t unless buf validates and decodes against chain.
+func AssertValid(t testing.TB, chain schema.SchemaChain, buf []byte) bool {
+	t.Helper()
+	if err := schema.ValidateBuffer(buf, chain); err != nil {
+		t.Errorf("validate: %v", err)
+		return false
+	}
+	if _, err := schema.DecodeBuffer(buf, chain); err != nil {
+		t.Errorf("decode: %v", err)
+		return false
+	}
+	return true
+}
+
+// AssertInvalid fails t unless validating buf against chain fails with a
+// SchemaError carrying code somewhere in its chain.
+func AssertInvalid(t testing.TB, chain schema.SchemaChain, buf []byte, code schema.ErrorCode) bool {
+	t.Helper()
+	err := schema.ValidateBuffer(buf, chain)
+	if err == nil {
+		t.Errorf("validate: expected %v, got no error", code)
+		return false
+	}
+	for e := err; e != nil; {
+		var se *schema.SchemaError
+		if !errors.As(e, &se) {
+			break
+		}
+		if se.Code == code {
+			return true
+		}
+		e = se.InnerErr
+	}
+	t.Errorf("validate: expected %v, got %v", code, err)
+	return false
+}
+
+// AssertRoundTrip encodes val with chain, decodes the result and compares it with val.
+// It returns the encoded buffer for further checks.
+func AssertRoundTrip(t testing.TB, chain schema.SchemaChain, val any) []byte {
+	t.Helper()
+	buf, err := schema.EncodeValue(val, chain)
+	if !assert.NoError(t, err, "encode") {
+		return nil
+	}
+	decoded, err := schema.DecodeBuffer(buf, chain)
+	if !assert.NoError(t, err, "decode") {
+		return buf
+	}
+	assert.Equal(t, val, decoded, "round trip")
+	return buf
+}
@@ -1,21 +1,28 @@
 package schema
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"math"
 	"net/mail"
 	"net/url"
-	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/quickwritereader/PackOS/access"
 	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/quickwritereader/PackOS/utils"
 	"golang.org/x/exp/constraints"
 	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
 )
 
 type ErrorCode int
@@ -39,6 +46,22 @@ const (
 	// Numeric validation codes
 	ErrOutOfRange     // integer value out of allowed range
 	ErrDateOutOfRange // timestamp/date value out of allowed range
+	ErrNotMultipleOf  // numeric value is not a multiple of the configured factor
+	// Map validation codes
+	ErrKeyOrder // map keys are not in strictly ascending order
+	// Registered check codes
+	ErrStringCheck // a check registered with RegisterStringCheck rejected the value
+	// Rule codes
+	ErrRuleViolated // a rule from RegisterRule or SchemaNamedChain.WithRule rejected the value
+	// Network address codes
+	ErrStringNetAddr // IP, CIDR or MAC address format validation failed
+	// Phone number codes
+	ErrStringPhone // phone number is not in E.164 form
+	// Version and identifier codes
+	ErrStringVersion // semantic version format or range check failed
+	ErrStringID      // ULID or KSUID format validation failed
+	// Enum codes
+	ErrEnumDeprecated // a deprecated enum value was encoded
 )
 
 // String implements fmt.Stringer
@@ -74,6 +97,24 @@ func (e ErrorCode) String() string {
 		return "ErrOutOfRange"
 	case ErrDateOutOfRange:
 		return "ErrDateOutOfRange"
+	case ErrNotMultipleOf:
+		return "ErrNotMultipleOf"
+	case ErrKeyOrder:
+		return "ErrKeyOrder"
+	case ErrStringCheck:
+		return "ErrStringCheck"
+	case ErrRuleViolated:
+		return "ErrRuleViolated"
+	case ErrStringNetAddr:
+		return "ErrStringNetAddr"
+	case ErrStringPhone:
+		return "ErrStringPhone"
+	case ErrStringVersion:
+		return "ErrStringVersion"
+	case ErrStringID:
+		return "ErrStringID"
+	case ErrEnumDeprecated:
+		return "ErrEnumDeprecated"
 	default:
 		return fmt.Sprintf("ErrorCode(%d)", int(e))
 	}
@@ -88,6 +129,12 @@ type SchemaError struct {
 	Field    string
 	Position int
 	InnerErr error
+	// Path locates the failing field below the schema that returned the
+	// error: chain and tuple indexes or field names and map keys, e.g.
+	// ["users", "2", "settings", "theme"]. Elements of repeats are named by
+	// their index in the enclosing tuple. It is empty when the error is
+	// about the field the schema itself reads.
+	Path []string
 }
 
 type SizeExact struct {
@@ -101,18 +148,33 @@ func (r SizeExact) Error() string {
 
 // RangeErrorDetails represents a structured range violation for any ordered type.
 type RangeErrorDetails[T constraints.Ordered] struct {
-	Min    *T
-	Max    *T
-	Actual T
+	Min          *T
+	Max          *T
+	Actual       T
+	ExclusiveMin bool
+	ExclusiveMax bool
 }
 
 func (r RangeErrorDetails[T]) Error() string {
 	switch {
 	case r.Min != nil && r.Max != nil:
-		return fmt.Sprintf("%v not in [%v , %v]", r.Actual, *r.Min, *r.Max)
+		open, close := "[", "]"
+		if r.ExclusiveMin {
+			open = "("
+		}
+		if r.ExclusiveMax {
+			close = ")"
+		}
+		return fmt.Sprintf("%v not in %s%v , %v%s", r.Actual, open, *r.Min, *r.Max, close)
 	case r.Min != nil:
+		if r.ExclusiveMin {
+			return fmt.Sprintf("%v <= %v", r.Actual, *r.Min)
+		}
 		return fmt.Sprintf("%v < %v", r.Actual, *r.Min)
 	case r.Max != nil:
+		if r.ExclusiveMax {
+			return fmt.Sprintf("%v >= %v", r.Actual, *r.Max)
+		}
 		return fmt.Sprintf("%v > %v", r.Actual, *r.Max)
 	default:
 		return fmt.Sprintf("%v", r.Actual)
@@ -128,6 +190,28 @@ func CheckRange[T constraints.Ordered](val T, min *T, max *T) error {
 	return nil
 }
 
+// CheckRangeBounds is CheckRange with optionally exclusive bounds.
+func CheckRangeBounds[T constraints.Ordered](val T, min *T, max *T, exclusiveMin, exclusiveMax bool) error {
+	belowMin := min != nil && (val < *min || exclusiveMin && val == *min)
+	aboveMax := max != nil && (val > *max || exclusiveMax && val == *max)
+	if belowMin || aboveMax {
+		return RangeErrorDetails[T]{Min: min, Max: max, Actual: val, ExclusiveMin: exclusiveMin, ExclusiveMax: exclusiveMax}
+	}
+	return nil
+}
+
+// CheckFloatBounds is CheckRangeBounds with a tolerance: a value within
+// epsilon of a bound counts as equal to it, so an inclusive bound accepts
+// it and an exclusive one rejects it.
+func CheckFloatBounds(val float64, min, max *float64, exclusiveMin, exclusiveMax bool, epsilon float64) error {
+	belowMin := min != nil && (val < *min-epsilon || exclusiveMin && val <= *min+epsilon)
+	aboveMax := max != nil && (val > *max+epsilon || exclusiveMax && val >= *max-epsilon)
+	if belowMin || aboveMax {
+		return RangeErrorDetails[float64]{Min: min, Max: max, Actual: val, ExclusiveMin: exclusiveMin, ExclusiveMax: exclusiveMax}
+	}
+	return nil
+}
+
 // For int64
 func CheckIntRange(val int64, min *int64, max *int64) error {
 	return CheckRange(val, min, max)
@@ -138,6 +222,67 @@ func CheckFloatRange(val float64, min *float64, max *float64) error {
 	return CheckRange(val, min, max)
 }
 
+// MultipleOfErrorDetails reports a value that is not a multiple of the required factor.
+type MultipleOfErrorDetails[T int64 | float64] struct {
+	MultipleOf T
+	Actual     T
+}
+
+func (e MultipleOfErrorDetails[T]) Error() string {
+	return fmt.Sprintf("%v is not a multiple of %v", e.Actual, e.MultipleOf)
+}
+
+func (e MultipleOfErrorDetails[T]) notMultipleOf() {}
+
+// CheckIntMultipleOf validates that val is a multiple of *m. A nil or zero m disables the check.
+func CheckIntMultipleOf(val int64, m *int64) error {
+	if m != nil && *m != 0 && val%*m != 0 {
+		return MultipleOfErrorDetails[int64]{MultipleOf: *m, Actual: val}
+	}
+	return nil
+}
+
+// multipleOfEpsilon absorbs binary rounding, e.g. 0.3 / 0.1 = 2.9999999999999996.
+// It is relative to the quotient, since the rounding error grows with it.
+const multipleOfEpsilon = 1e-9
+
+// CheckFloatMultipleOf validates that val is a multiple of *m within a small tolerance.
+func CheckFloatMultipleOf(val float64, m *float64) error {
+	if m == nil || *m == 0 {
+		return nil
+	}
+	q := val / *m
+	if math.Abs(q-math.Round(q)) > multipleOfEpsilon*math.Max(1, math.Abs(q)) {
+		return MultipleOfErrorDetails[float64]{MultipleOf: *m, Actual: val}
+	}
+	return nil
+}
+
+// numericErrorCode picks the ErrorCode for an error returned by numeric checks.
+func numericErrorCode(err error) ErrorCode {
+	if _, ok := err.(interface{ notMultipleOf() }); ok {
+		return ErrNotMultipleOf
+	}
+	return ErrOutOfRange
+}
+
+// IntConstraints bundles the numeric vocabulary for integer schemas.
+type IntConstraints struct {
+	Min          *int64
+	Max          *int64
+	ExclusiveMin bool
+	ExclusiveMax bool
+	MultipleOf   *int64
+}
+
+// Check validates val against every configured constraint.
+func (c IntConstraints) Check(val int64) error {
+	if err := CheckRangeBounds(val, c.Min, c.Max, c.ExclusiveMin, c.ExclusiveMax); err != nil {
+		return err
+	}
+	return CheckIntMultipleOf(val, c.MultipleOf)
+}
+
 type StringErrorDetails struct {
 	Expected string
 	Actual   string
@@ -155,6 +300,16 @@ func (e MissingKeyErrorDetails) Error() string {
 	return fmt.Sprintf("Missing key '%s'", e.Key)
 }
 
+// KeyOrderErrorDetails reports a map key that does not sort after its predecessor.
+type KeyOrderErrorDetails struct {
+	Previous string
+	Key      string
+}
+
+func (e KeyOrderErrorDetails) Error() string {
+	return fmt.Sprintf("key '%s' does not sort after '%s'", e.Key, e.Previous)
+}
+
 func formatError(code ErrorCode, name string, field string, pos int, inner error) string {
 	if inner != nil {
 		return fmt.Sprintf("%s %s:%s#%d { %s }", name, code, field, pos, inner)
@@ -170,8 +325,85 @@ func (v *SchemaError) Unwrap() error {
 	return v.InnerErr
 }
 
+// NewSchemaError returns a SchemaError. When inner is a SchemaError, its
+// Path is carried over.
 func NewSchemaError(code ErrorCode, name, field string, pos int, inner error) *SchemaError {
-	return &SchemaError{Code: code, Name: name, Field: field, Position: pos, InnerErr: inner}
+	e := &SchemaError{Code: code, Name: name, Field: field, Position: pos, InnerErr: inner}
+	var se *SchemaError
+	if inner != nil && errors.As(inner, &se) {
+		e.Path = slices.Clone(se.Path)
+	}
+	return e
+}
+
+// nestedError is NewSchemaError for the failure of a field inside a
+// container, which seg names within it.
+func nestedError(code ErrorCode, name, field string, pos int, seg string, inner error) *SchemaError {
+	e := NewSchemaError(code, name, field, pos, inner)
+	e.Path = append([]string{seg}, e.Path...)
+	return e
+}
+
+// childError is nestedError for the failure of sch, a child schema of a
+// tuple. Repeats name their elements by field index themselves, so their
+// failures get no segment for the repeat.
+func childError(code ErrorCode, name, field string, pos int, sch Schema, seg string, inner error) *SchemaError {
+	if _, ok := sch.(SRepeatSchema); ok {
+		return NewSchemaError(code, name, field, pos, inner)
+	}
+	return nestedError(code, name, field, pos, seg, inner)
+}
+
+// prefixPath adds seg in front of the Path of err, the failure of sch, the
+// top-level field seg of a chain.
+func prefixPath(err error, sch Schema, seg string) error {
+	var se *SchemaError
+	if _, ok := sch.(SRepeatSchema); !ok && errors.As(err, &se) {
+		se.Path = append([]string{seg}, se.Path...)
+	}
+	return err
+}
+
+// ErrorPath returns the Path of the outermost SchemaError in err's chain,
+// or nil.
+func ErrorPath(err error) []string {
+	var se *SchemaError
+	if errors.As(err, &se) {
+		return se.Path
+	}
+	return nil
+}
+
+// MarshalJSON writes the error as an object with its code name, schema
+// name, field, position, path and message, and the inner error as a
+// nested object when it is a SchemaError or as its message otherwise.
+func (v *SchemaError) MarshalJSON() ([]byte, error) {
+	type jsonError struct {
+		Code     string   `json:"code"`
+		Name     string   `json:"name"`
+		Field    string   `json:"field,omitempty"`
+		Position int      `json:"position"`
+		Path     []string `json:"path,omitempty"`
+		Message  string   `json:"message"`
+		Inner    any      `json:"inner,omitempty"`
+	}
+	out := jsonError{
+		Code:     v.Code.String(),
+		Name:     v.Name,
+		Field:    v.Field,
+		Position: v.Position,
+		Path:     v.Path,
+		Message:  v.Error(),
+	}
+	var se *SchemaError
+	switch {
+	case v.InnerErr == nil:
+	case errors.As(v.InnerErr, &se):
+		out.Inner = se
+	default:
+		out.Inner = v.InnerErr.Error()
+	}
+	return json.Marshal(out)
 }
 
 type Schema interface {
@@ -201,11 +433,14 @@ const (
 	SchemaEnumNamedListName          = "SchemaEnumNamedList"
 	SchemaNumberName                 = "SchemaNumber"
 	ChainName                        = "SchemaChain"
-
-	TupleSchemaName      = "TupleSchema"
-	TupleSchemaNamedName = "TupleSchemaNamed"
-	SRepeatSchemaName    = "SRepeatSchema"
-	SchemaMapRepeatName  = "SchemaMapRepeat"
+	SchemaDispatcherName             = "SchemaDispatcher"
+
+	TupleSchemaName         = "TupleSchema"
+	TupleSchemaNamedName    = "TupleSchemaNamed"
+	SRepeatSchemaName       = "SRepeatSchema"
+	SchemaMapRepeatName     = "SchemaMapRepeat"
+	SchemaMapSortedKeysName = "SchemaMapSortedKeys"
+	SchemaMapByKeyName      = "SchemaMapByKey"
 )
 
 type SchemaGeneric struct {
@@ -245,13 +480,29 @@ func (s SchemaAny) Validate(seq *access.SeqGetAccess) error {
 	return nil
 }
 
+// Decode decodes the current field, whatever its type, and moves past it.
 func (s SchemaAny) Decode(seq *access.SeqGetAccess) (any, error) {
-	v, err := access.DecodeTupleGeneric(seq, true, s.DecodeAsOrderedMap)
+	pos := seq.CurrentIndex()
+	typ, _, err := seq.PeekTypeWidth()
 	if err != nil {
-		return nil, NewSchemaError(ErrInvalidFormat, SchemaAnyName, "", seq.CurrentIndex(), err)
+		return nil, NewSchemaError(ErrUnexpectedEOF, SchemaAnyName, "", pos, err)
 	}
-	if err := seq.Advance(); err != nil {
-		return nil, NewSchemaError(ErrUnexpectedEOF, SchemaAnyName, "", seq.CurrentIndex(), err)
+	var v any
+	switch {
+	case typ.IsMap() && s.DecodeAsOrderedMap:
+		v, err = access.DecodeOrderedMapAny(seq)
+	case typ.IsMap():
+		v, err = access.DecodeMapAny(seq)
+	case typ == typetags.TypeTuple:
+		v, err = access.DecodeTupleGeneric(seq, false, s.DecodeAsOrderedMap)
+	default:
+		var payload []byte
+		if payload, typ, err = seq.Next(); err == nil {
+			v, err = access.DecodePrimitive(typ, payload)
+		}
+	}
+	if err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, SchemaAnyName, "", pos, err)
 	}
 	return v, nil
 }
@@ -267,31 +518,111 @@ func (s SchemaAny) IsNullable() bool {
 	return true
 }
 
+// SchemaString holds a string. Width > 0 requires that many bytes; Width
+// <= 0 allows any length and reads a zero-width field as null or "". With
+// NullTag, null is written as the TypeNull marker of
+// PutAccess.AddNullableString instead and decodes to nil, while a
+// zero-width string decodes to "", so the two stay distinct at any Width;
+// DefaultDecodeVal then no longer replaces "". MinLen and MaxLen, when
+// > 0, bound the length of the stored string, in bytes or, with
+// CountRunes, in UTF-8 characters; MinLen > 0 rejects empty strings.
+// ValidUTF8 rejects malformed UTF-8, and NFC further rejects text not in
+// Unicode Normalization Form C, which Encode produces before checking.
+// FoldCase and Trim make Match, Prefix and Suffix ignore case and
+// surrounding whitespace; the string is stored as given.
 type SchemaString struct {
 	Width            int
 	DefaultDecodeVal string
+	NullTag          bool
+	MinLen, MaxLen   int
+	CountRunes       bool
+	ValidUTF8        bool
+	NFC              bool
+	FoldCase         bool
+	Trim             bool
 }
 
+var (
+	errInvalidUTF8 = errors.New("invalid UTF-8")
+	errNotNFC      = errors.New("not in Unicode normalization form NFC")
+	errSameNFC     = errors.New("another key has the same NFC form")
+)
+
 func (s SchemaString) Validate(seq *access.SeqGetAccess) error {
-	return validatePrimitive(SchemaStringName, seq, typetags.TypeString, s.Width, s.IsNullable())
+	if null, err := skipNullTag(SchemaStringName, s.NullTag, seq); null || err != nil {
+		return err
+	}
+	if s.MinLen <= 0 && s.MaxLen <= 0 && !s.ValidUTF8 && !s.NFC {
+		return validatePrimitive(SchemaStringName, seq, typetags.TypeString, s.Width, s.Width <= 0)
+	}
+	pos := seq.CurrentIndex()
+	payload, err := validatePrimitiveAndGetPayload(SchemaStringName, seq, typetags.TypeString, s.Width, s.Width <= 0)
+	if err != nil {
+		return err
+	}
+	return s.checkText(string(payload), pos)
+}
+
+// checkText applies the encoding and length options to str, read at pos.
+func (s SchemaString) checkText(str string, pos int) error {
+	if (s.ValidUTF8 || s.NFC) && !utf8.ValidString(str) {
+		return NewSchemaError(ErrInvalidFormat, SchemaStringName, "", pos, errInvalidUTF8)
+	}
+	if s.NFC && !norm.NFC.IsNormalString(str) {
+		return NewSchemaError(ErrInvalidFormat, SchemaStringName, "", pos, errNotNFC)
+	}
+	if s.MinLen <= 0 && s.MaxLen <= 0 {
+		return nil
+	}
+	n := len(str)
+	if s.CountRunes {
+		n = utf8.RuneCountInString(str)
+	}
+	var min, max *int
+	if s.MinLen > 0 {
+		min = &s.MinLen
+	}
+	if s.MaxLen > 0 {
+		max = &s.MaxLen
+	}
+	if err := CheckRange(n, min, max); err != nil {
+		return NewSchemaError(ErrOutOfRange, SchemaStringName, "", pos, err)
+	}
+	return nil
 }
 
 func (s SchemaString) Decode(seq *access.SeqGetAccess) (any, error) {
-	payload, err := validatePrimitiveAndGetPayload(SchemaStringName, seq, typetags.TypeString, s.Width, s.IsNullable())
+	if null, err := skipNullTag(SchemaStringName, s.NullTag, seq); null || err != nil {
+		return nil, err
+	}
+	pos := seq.CurrentIndex()
+	payload, err := validatePrimitiveAndGetPayload(SchemaStringName, seq, typetags.TypeString, s.Width, s.Width <= 0)
 	if err != nil {
 		return nil, err
 	}
-	if len(payload) == 0 && len(s.DefaultDecodeVal) > 0 {
+	if err := s.checkText(string(payload), pos); err != nil {
+		return nil, err
+	}
+	if len(payload) == 0 && len(s.DefaultDecodeVal) > 0 && !s.NullTag {
 		return s.DefaultDecodeVal, nil
 	}
 	return string(payload), nil
 }
 
 func (s SchemaString) Encode(put *access.PutAccess, val any) error {
+	if s.NullTag && val == nil {
+		put.AddNull(nil)
+		return nil
+	}
 	if s.IsNullable() && val == nil {
 		put.AddString("")
+		return nil
 	}
 	if value, ok := val.(string); ok {
+		value = s.normalize(value)
+		if err := s.checkText(value, -1); err != nil {
+			return NewSchemaError(ErrEncode, SchemaStringName, "", -1, err)
+		}
 		put.AddString(value)
 	} else {
 		return NewSchemaError(ErrEncode, SchemaStringName, "", -1, ErrTypeMisMatch)
@@ -299,29 +630,117 @@ func (s SchemaString) Encode(put *access.PutAccess, val any) error {
 	return nil
 }
 
-type SchemaBytes struct{ Width int }
+// SchemaBytes holds a byte array; Width and NullTag work as in
+// SchemaString. With NullTag an empty array decodes to an empty non-nil
+// slice and the null marker to nil. Magic, when set, must start every
+// non-empty payload, as the signature of a file format does. AsBase64
+// carries the bytes as standard base64 strings on the Go side, as in JSON:
+// Encode also accepts such a string and Decode returns one.
+type SchemaBytes struct {
+	Width    int
+	NullTag  bool
+	Magic    []byte
+	AsBase64 bool
+}
 
 func (s SchemaBytes) Validate(seq *access.SeqGetAccess) error {
-	return validatePrimitive(SchemaBytesName, seq, typetags.TypeString, s.Width, s.IsNullable())
+	if null, err := skipNullTag(SchemaBytesName, s.NullTag, seq); null || err != nil {
+		return err
+	}
+	if len(s.Magic) == 0 {
+		return validatePrimitive(SchemaBytesName, seq, typetags.TypeString, s.Width, s.Width <= 0)
+	}
+	pos := seq.CurrentIndex()
+	payload, err := validatePrimitiveAndGetPayload(SchemaBytesName, seq, typetags.TypeByteArray, s.Width, s.Width <= 0)
+	if err != nil {
+		return err
+	}
+	return s.checkMagic(payload, pos)
+}
+
+// checkMagic checks that payload, read at pos, starts with Magic.
+func (s SchemaBytes) checkMagic(payload []byte, pos int) error {
+	if len(payload) == 0 || bytes.HasPrefix(payload, s.Magic) {
+		return nil
+	}
+	return NewSchemaError(ErrConstraintViolated, SchemaBytesName, "", pos,
+		fmt.Errorf("payload does not start with %x", s.Magic))
 }
 
 func (s SchemaBytes) Decode(seq *access.SeqGetAccess) (any, error) {
-	payload, err := validatePrimitiveAndGetPayload(SchemaBytesName, seq, typetags.TypeByteArray, s.Width, s.IsNullable())
+	if null, err := skipNullTag(SchemaBytesName, s.NullTag, seq); null || err != nil {
+		return nil, err
+	}
+	pos := seq.CurrentIndex()
+	payload, err := validatePrimitiveAndGetPayload(SchemaBytesName, seq, typetags.TypeByteArray, s.Width, s.Width <= 0)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.checkMagic(payload, pos); err != nil {
+		return nil, err
+	}
+	if s.AsBase64 {
+		return base64.StdEncoding.EncodeToString(payload), nil
+	}
+	if payload == nil && s.NullTag {
+		payload = []byte{}
+	}
 	return payload, nil
 }
 
+// Prefix returns s requiring payloads to start with magic, such as
+// "\x89PNG\r\n\x1a\n" for PNG images.
+func (s SchemaBytes) Prefix(magic []byte) SchemaBytes {
+	s.Magic = magic
+	return s
+}
+
+// Base64 returns s carrying its bytes as base64 strings; see AsBase64.
+func (s SchemaBytes) Base64() SchemaBytes {
+	s.AsBase64 = true
+	return s
+}
+
+// ExactLen returns s accepting payloads of exactly n bytes, such as a
+// 32-byte SHA-256 hash.
+func (s SchemaBytes) ExactLen(n int) SchemaBytes {
+	s.Width = n
+	return s
+}
+
+// WithNullTag returns s with NullTag set.
+func (s SchemaBytes) WithNullTag() SchemaBytes {
+	s.NullTag = true
+	return s
+}
+
 func (s SchemaBytes) Encode(put *access.PutAccess, val any) error {
+	if s.NullTag && val == nil {
+		put.AddNull(nil)
+		return nil
+	}
 	if s.IsNullable() && val == nil {
 		put.AddBytes(nil)
+		return nil
 	}
-	if value, ok := val.([]byte); ok {
-		put.AddBytes(value)
-	} else {
+	value, ok := val.([]byte)
+	if str, isStr := val.(string); isStr && s.AsBase64 {
+		var err error
+		if value, err = base64.StdEncoding.DecodeString(str); err != nil {
+			return NewSchemaError(ErrEncode, SchemaBytesName, "", -1, err)
+		}
+		ok = true
+	}
+	if !ok {
 		return NewSchemaError(ErrEncode, SchemaBytesName, "", -1, ErrTypeMisMatch)
 	}
+	if s.Width > 0 && len(value) != s.Width {
+		return NewSchemaError(ErrEncode, SchemaBytesName, "", -1, SizeExact{Actual: len(value), Exact: s.Width})
+	}
+	if err := s.checkMagic(value, -1); err != nil {
+		return NewSchemaError(ErrEncode, SchemaBytesName, "", -1, err)
+	}
+	put.AddBytes(value)
 	return nil
 }
 
@@ -345,9 +764,9 @@ func (s SchemaMap) Validate(seq *access.SeqGetAccess) error {
 		if err != nil {
 			return NewSchemaError(ErrInvalidFormat, SchemaMapName, "", pos, err)
 		}
-		for _, sch := range s.Schemas {
+		for i, sch := range s.Schemas {
 			if err := sch.Validate(sub); err != nil {
-				return NewSchemaError(ErrInvalidFormat, SchemaMapName, "", pos, err)
+				return nestedError(ErrInvalidFormat, SchemaMapName, "", pos, strconv.Itoa(i), err)
 			}
 		}
 	}
@@ -387,12 +806,12 @@ func (s SchemaMap) Decode(seq *access.SeqGetAccess) (any, error) {
 		for i := 0; i < len(s.Schemas); i += 2 {
 			key, err := s.Schemas[i].Decode(sub)
 			if err != nil {
-				return nil, NewSchemaError(ErrInvalidFormat, SchemaMapName, "", pos, err)
+				return nil, nestedError(ErrInvalidFormat, SchemaMapName, "", pos, strconv.Itoa(i), err)
 			}
 			value, err := s.Schemas[i+1].Decode(sub)
 			if err != nil {
-				keyStr := key.(string)
-				return nil, NewSchemaError(ErrInvalidFormat, SchemaMapName, keyStr, pos, err)
+				keyStr, _ := key.(string)
+				return nil, nestedError(ErrInvalidFormat, SchemaMapName, keyStr, pos, keyStr, err)
 			}
 			if keyStr, ok := key.(string); ok {
 				out.Set(keyStr, value)
@@ -586,8 +1005,8 @@ type Nullable interface {
 	IsNullable() bool
 }
 
-func (s SchemaString) IsNullable() bool { return s.Width <= 0 }
-func (s SchemaBytes) IsNullable() bool  { return s.Width <= 0 }
+func (s SchemaString) IsNullable() bool { return s.Width <= 0 && s.MinLen <= 0 || s.NullTag }
+func (s SchemaBytes) IsNullable() bool  { return s.Width <= 0 || s.NullTag }
 func (s SchemaMap) IsNullable() bool    { return s.Width <= 0 }
 
 // Primitives
@@ -751,6 +1170,12 @@ func convertToNumber[T constraints.Integer | constraints.Float](val any) (T, boo
 			return zero, false
 		}
 		return T(parsed), true
+	case json.Number:
+		parsed, err := v.Float64()
+		if err != nil {
+			return zero, false
+		}
+		return T(parsed), true
 	default:
 		return zero, false
 	}
@@ -761,6 +1186,10 @@ func (s SchemaInt8) Encode(put *access.PutAccess, val any) error {
 		put.AddNullableInt8(nil)
 		return nil
 	}
+	val, err := coerceJSONNumber[int8](val)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaInt8Name, "", -1, err)
+	}
 	if v, ok := val.(int8); ok {
 		put.AddInt8(v)
 		return nil
@@ -773,6 +1202,10 @@ func (s SchemaInt16) Encode(put *access.PutAccess, val any) error {
 		put.AddNullableInt16(nil)
 		return nil
 	}
+	val, err := coerceJSONNumber[int16](val)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaInt16Name, "", -1, err)
+	}
 	if v, ok := val.(int16); ok {
 		put.AddInt16(v)
 		return nil
@@ -785,6 +1218,10 @@ func (s SchemaInt32) Encode(put *access.PutAccess, val any) error {
 		put.AddNullableInt32(nil)
 		return nil
 	}
+	val, err := coerceJSONNumber[int32](val)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaInt32Name, "", -1, err)
+	}
 	if v, ok := val.(int32); ok {
 		put.AddInt32(v)
 		return nil
@@ -797,6 +1234,10 @@ func (s SchemaInt64) Encode(put *access.PutAccess, val any) error {
 		put.AddNullableInt64(nil)
 		return nil
 	}
+	val, err := coerceJSONNumber[int64](val)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaInt64Name, "", -1, err)
+	}
 	if v, ok := val.(int64); ok {
 		put.AddInt64(v)
 		return nil
@@ -809,6 +1250,10 @@ func (s SchemaFloat32) Encode(put *access.PutAccess, val any) error {
 		put.AddNullableFloat32(nil)
 		return nil
 	}
+	val, err := coerceJSONNumber[float32](val)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaFloat32Name, "", -1, err)
+	}
 	if v, ok := val.(float32); ok {
 		put.AddFloat32(v)
 		return nil
@@ -821,6 +1266,10 @@ func (s SchemaFloat64) Encode(put *access.PutAccess, val any) error {
 		put.AddNullableFloat64(nil)
 		return nil
 	}
+	val, err := coerceJSONNumber[float64](val)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaFloat64Name, "", -1, err)
+	}
 	if v, ok := val.(float64); ok {
 		put.AddFloat64(v)
 		return nil
@@ -882,12 +1331,7 @@ func ValidateBuffer(buf []byte, chain SchemaChain) error {
 	if err != nil {
 		return NewSchemaError(ErrInvalidFormat, ChainName, "", -1, err)
 	}
-	for _, schema := range chain.Schemas {
-		if err := schema.Validate(seq); err != nil {
-			return err
-		}
-	}
-	return nil
+	return validateSeq(seq, chain)
 }
 
 func DecodeBuffer(buf []byte, chain SchemaChain) (any, error) {
@@ -895,11 +1339,25 @@ func DecodeBuffer(buf []byte, chain SchemaChain) (any, error) {
 	if err != nil {
 		return nil, NewSchemaError(ErrInvalidFormat, ChainName, "", -1, err)
 	}
+	return decodeSeq(seq, chain)
+}
+
+// DecodeBufferWithLimits is DecodeBuffer for untrusted buffers: the chain
+// reads buf, and the containers nested in it, within limits.
+func DecodeBufferWithLimits(buf []byte, chain SchemaChain, limits access.DecodeLimits) (any, error) {
+	seq, err := access.NewSeqGetAccessWithLimits(buf, limits)
+	if err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, ChainName, "", -1, err)
+	}
+	return decodeSeq(seq, chain)
+}
+
+func decodeSeq(seq *access.SeqGetAccess, chain SchemaChain) (any, error) {
 	out := make([]any, 0, len(chain.Schemas))
-	for _, schema := range chain.Schemas {
+	for i, schema := range chain.Schemas {
 		val, err := schema.Decode(seq)
 		if err != nil {
-			return nil, err
+			return nil, prefixPath(err, schema, strconv.Itoa(i))
 		}
 		out = append(out, val)
 	}
@@ -910,39 +1368,43 @@ func DecodeBuffer(buf []byte, chain SchemaChain) (any, error) {
 }
 
 func EncodeValue(val any, chain SchemaChain) ([]byte, error) {
+	if len(chain.Schemas) == 0 {
+		return nil, nil
+	}
+	put := access.NewPutAccessFromPool()
+	defer access.ReleasePutAccess(put)
+	if err := encodeChain(put, val, chain); err != nil {
+		return nil, err
+	}
+	return put.Pack(), nil
+}
 
-	c := len(chain.Schemas)
-	if c > 1 {
-		put := access.NewPutAccessFromPool()
-		defer access.ReleasePutAccess(put)
-		valArr, ok := val.([]any)
-		if !ok {
-			return nil, NewSchemaError(ErrEncode, ChainName, "", -1, ErrTypeMisMatch)
-		}
-		i := 0
-		for _, schema := range chain.Schemas {
-			err := schema.Encode(put, valArr[i])
-			if err != nil {
-				return nil, NewSchemaError(ErrEncode, ChainName, "", -1, err)
-			}
-			i++
+// encodeChain writes val with chain into put: the value itself for a chain
+// of one schema, or a []any with one value per schema.
+func encodeChain(put *access.PutAccess, val any, chain SchemaChain) error {
+	if len(chain.Schemas) == 1 {
+		if err := chain.Schemas[0].Encode(put, val); err != nil {
+			return NewSchemaError(ErrEncode, ChainName, "", -1, err)
 		}
-		return put.Pack(), nil
-	} else if c == 1 {
-		put := access.NewPutAccessFromPool()
-		defer access.ReleasePutAccess(put)
-		err := chain.Schemas[0].Encode(put, val)
-		if err != nil {
-			return nil, NewSchemaError(ErrEncode, ChainName, "", -1, err)
+		return nil
+	}
+	valArr, ok := val.([]any)
+	if !ok {
+		return NewSchemaError(ErrEncode, ChainName, "", -1, ErrTypeMisMatch)
+	}
+	for i, schema := range chain.Schemas {
+		if err := schema.Encode(put, valArr[i]); err != nil {
+			return NewSchemaError(ErrEncode, ChainName, "", -1, err)
 		}
-		return put.Pack(), nil
 	}
-	return nil, nil
+	return nil
 }
 
 type SchemaNamedChain struct {
 	SchemaChain
 	FieldNames []string
+	// Rules check invariants across fields; see WithRule.
+	Rules []Rule
 }
 
 func DecodeBufferNamed(buf []byte, chain SchemaNamedChain) (any, error) {
@@ -958,10 +1420,13 @@ func DecodeBufferNamed(buf []byte, chain SchemaNamedChain) (any, error) {
 	for i, schema := range chain.Schemas {
 		val, err := schema.Decode(seq)
 		if err != nil {
-			return nil, err
+			return nil, prefixPath(err, schema, chain.FieldNames[i])
 		}
 		out[chain.FieldNames[i]] = val
 	}
+	if err := chain.checkRules(out); err != nil {
+		return nil, err
+	}
 	return out, nil
 }
 
@@ -991,7 +1456,14 @@ func EncodeValueNamed(val any, chain SchemaNamedChain) ([]byte, error) {
 		}
 
 	}
-	return put.Pack(), nil
+	buf := put.Pack()
+	if len(chain.Rules) > 0 {
+		// rules see the fields as decoding returns them
+		if _, err := DecodeBufferNamed(buf, chain); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
 }
 
 func precheck(errorName string, pos int, seq *access.SeqGetAccess, tag typetags.Type, hint int, nullable bool) (int, error) {
@@ -1000,8 +1472,8 @@ func precheck(errorName string, pos int, seq *access.SeqGetAccess, tag typetags.
 		return 0, NewSchemaError(ErrConstraintViolated, errorName, "", pos, err)
 	}
 
-	if typ != tag {
-		// Type mismatch
+	if typ != tag && !(tag == typetags.TypeMap && typ == typetags.TypeSortedMap) {
+		// Type mismatch; sorted maps satisfy map schemas
 		return 0, NewSchemaError(ErrConstraintViolated, errorName, "", pos, ErrTypeMisMatch)
 	}
 
@@ -1012,6 +1484,26 @@ func precheck(errorName string, pos int, seq *access.SeqGetAccess, tag typetags.
 	return width, nil
 }
 
+// skipNullTag advances past the current field and reports true if enabled
+// and the field is the TypeNull marker written for null strings and bytes.
+func skipNullTag(errorName string, enabled bool, seq *access.SeqGetAccess) (bool, error) {
+	if !enabled {
+		return false, nil
+	}
+	pos := seq.CurrentIndex()
+	typ, width, err := seq.PeekTypeWidth()
+	if err != nil {
+		return false, NewSchemaError(ErrUnexpectedEOF, errorName, "", pos, err)
+	}
+	if typ != typetags.TypeNull || width != 0 {
+		return false, nil
+	}
+	if err := seq.Advance(); err != nil {
+		return true, NewSchemaError(ErrUnexpectedEOF, errorName, "", pos, err)
+	}
+	return true, nil
+}
+
 // Helper for primitive validation
 func validatePrimitive(errorName string, seq *access.SeqGetAccess, tag typetags.Type, hint int, nullable bool) error {
 	pos := seq.CurrentIndex()
@@ -1071,18 +1563,24 @@ func (s SchemaString) CheckFunc(code ErrorCode, expected string, test func(paylo
 	return SchemaGeneric{
 		ValidateFunc: func(seq *access.SeqGetAccess) error {
 			pos := seq.CurrentIndex()
-			payload, err := validatePrimitiveAndGetPayload(SchemaStringName, seq, typetags.TypeString, s.Width, s.IsNullable())
+			if null, err := skipNullTag(SchemaStringName, s.NullTag, seq); null || err != nil {
+				return err
+			}
+			payload, err := validatePrimitiveAndGetPayload(SchemaStringName, seq, typetags.TypeString, s.Width, s.Width <= 0)
 			if err != nil {
 				return err
 			}
+			if err := s.checkText(string(payload), pos); err != nil {
+				return err
+			}
 			var str string
-			if len(payload) == 0 && len(s.DefaultDecodeVal) > 0 {
+			if len(payload) == 0 && len(s.DefaultDecodeVal) > 0 && !s.NullTag {
 
 				str = s.DefaultDecodeVal
 			} else {
 				str = string(payload)
 			}
-			if s.IsNullable() && str == "" {
+			if s.Width <= 0 && str == "" {
 				return nil
 			}
 			if !test(str) {
@@ -1092,12 +1590,18 @@ func (s SchemaString) CheckFunc(code ErrorCode, expected string, test func(paylo
 		},
 		DecodeFunc: func(seq *access.SeqGetAccess) (any, error) {
 			pos := seq.CurrentIndex()
-			payload, err := validatePrimitiveAndGetPayload(SchemaStringName, seq, typetags.TypeString, s.Width, s.IsNullable())
+			if null, err := skipNullTag(SchemaStringName, s.NullTag, seq); null || err != nil {
+				return nil, err
+			}
+			payload, err := validatePrimitiveAndGetPayload(SchemaStringName, seq, typetags.TypeString, s.Width, s.Width <= 0)
 			if err != nil {
 				return nil, err
 			}
+			if err := s.checkText(string(payload), pos); err != nil {
+				return nil, err
+			}
 			var str string
-			if len(payload) == 0 && len(s.DefaultDecodeVal) > 0 {
+			if len(payload) == 0 && len(s.DefaultDecodeVal) > 0 && !s.NullTag {
 
 				str = s.DefaultDecodeVal
 			} else {
@@ -1109,8 +1613,15 @@ func (s SchemaString) CheckFunc(code ErrorCode, expected string, test func(paylo
 			return str, nil
 		},
 		EncodeFunc: func(put *access.PutAccess, val any) error {
-
+			if s.NullTag && val == nil {
+				put.AddNull(nil)
+				return nil
+			}
 			if value, ok := val.(string); ok {
+				value = s.normalize(value)
+				if err := s.checkText(value, -1); err != nil {
+					return NewSchemaError(ErrEncode, SchemaStringName, "", -1, err)
+				}
 				if test(value) {
 					put.AddString(value)
 				} else {
@@ -1137,15 +1648,32 @@ func (s SchemaString) Match(expected string) Schema {
 	return s.CheckFunc(
 		ErrStringMatch,
 		expected,
-		func(payloadStr string) bool { return payloadStr == expected },
+		func(payloadStr string) bool {
+			payloadStr = s.matchForm(payloadStr)
+			if s.FoldCase {
+				return strings.EqualFold(payloadStr, expected)
+			}
+			return payloadStr == expected
+		},
 	)
 }
 
+// MatchFold is Match ignoring case, as s.IgnoreCase().Match(expected).
+func (s SchemaString) MatchFold(expected string) Schema {
+	return s.IgnoreCase().Match(expected)
+}
+
 func (s SchemaString) Prefix(prefix string) Schema {
 	return s.CheckFunc(
 		ErrStringPrefix,
 		prefix+"*",
-		func(payloadStr string) bool { return strings.HasPrefix(payloadStr, prefix) },
+		func(payloadStr string) bool {
+			payloadStr = s.matchForm(payloadStr)
+			if s.FoldCase {
+				return hasPrefixFold(payloadStr, prefix)
+			}
+			return strings.HasPrefix(payloadStr, prefix)
+		},
 	)
 }
 
@@ -1153,26 +1681,156 @@ func (s SchemaString) Suffix(suffix string) Schema {
 	return s.CheckFunc(
 		ErrStringSuffix,
 		"*"+suffix,
-		func(payloadStr string) bool { return strings.HasSuffix(payloadStr, suffix) },
+		func(payloadStr string) bool {
+			payloadStr = s.matchForm(payloadStr)
+			if s.FoldCase {
+				return hasSuffixFold(payloadStr, suffix)
+			}
+			return strings.HasSuffix(payloadStr, suffix)
+		},
 	)
 }
 
+// IgnoreCase returns s whose Match, Prefix and Suffix compare under Unicode
+// case folding.
+func (s SchemaString) IgnoreCase() SchemaString {
+	s.FoldCase = true
+	return s
+}
+
+// Trimmed returns s whose Match, Prefix and Suffix ignore leading and
+// trailing white space. Use the "trim" sanitizer to also store it trimmed.
+func (s SchemaString) Trimmed() SchemaString {
+	s.Trim = true
+	return s
+}
+
+// matchForm returns str as Match, Prefix and Suffix compare it.
+func (s SchemaString) matchForm(str string) string {
+	if s.Trim {
+		return strings.TrimSpace(str)
+	}
+	return str
+}
+
+// hasPrefixFold is strings.HasPrefix under Unicode case folding; folded
+// runes may differ in encoded length, so it compares rune by rune.
+func hasPrefixFold(str, prefix string) bool {
+	for _, p := range prefix {
+		r, n := utf8.DecodeRuneInString(str)
+		if n == 0 || !strings.EqualFold(string(r), string(p)) {
+			return false
+		}
+		str = str[n:]
+	}
+	return true
+}
+
+// hasSuffixFold is strings.HasSuffix under Unicode case folding.
+func hasSuffixFold(str, suffix string) bool {
+	for len(suffix) > 0 {
+		p, m := utf8.DecodeLastRuneInString(suffix)
+		r, n := utf8.DecodeLastRuneInString(str)
+		if n == 0 || !strings.EqualFold(string(r), string(p)) {
+			return false
+		}
+		str, suffix = str[:len(str)-n], suffix[:len(suffix)-m]
+	}
+	return true
+}
+
+// Pattern requires the string to match the regular expression expr, within
+// DefaultPatternLimits. It panics if expr does not compile.
 func (s SchemaString) Pattern(expr string) Schema {
-	re := regexp.MustCompile(expr)
+	return s.pattern(expr, nil)
+}
+
+// PatternWithLimits is Pattern with its own limits.
+func (s SchemaString) PatternWithLimits(expr string, limits PatternLimits) Schema {
+	return s.pattern(expr, &limits)
+}
+
+func (s SchemaString) pattern(expr string, limits *PatternLimits) Schema {
+	re := mustCompileBounded(expr)
 	return s.CheckFunc(
 		ErrStringPattern,
 		expr,
-		func(payloadStr string) bool { return re.MatchString(payloadStr) },
+		func(payloadStr string) bool { return re.match(payloadStr, limits) },
 	)
 }
 
+// Check validates the string with the function registered under name.
+// It panics if no such check is registered.
+func (s SchemaString) Check(name string) Schema {
+	test, ok := stringChecks[name]
+	if !ok {
+		panic("unknown string check: " + name)
+	}
+	return s.CheckFunc(ErrStringCheck, name, test)
+}
+
 func (s SchemaString) WithWidth(n int) SchemaString {
-	return SchemaString{Width: n}
+	s.Width, s.DefaultDecodeVal = n, ""
+	return s
+}
+
+// LenRange returns s accepting strings of min to max bytes, or characters
+// with WithRuneCount, at any width. A bound <= 0 is not checked.
+func (s SchemaString) LenRange(min, max int) SchemaString {
+	s.Width, s.MinLen, s.MaxLen = -1, min, max
+	return s
+}
+
+// UTF8 returns s rejecting strings that are not well-formed UTF-8.
+func (s SchemaString) UTF8() SchemaString {
+	s.ValidUTF8 = true
+	return s
+}
+
+// NormalizeNFC returns s encoding strings in Unicode Normalization Form C
+// and rejecting payloads that are not, so equal text always packs to equal
+// bytes, as map keys that must sort deterministically need.
+func (s SchemaString) NormalizeNFC() SchemaString {
+	s.ValidUTF8, s.NFC = true, true
+	return s
+}
+
+// normalize returns str in the form s encodes. Malformed UTF-8 is left for
+// checkText to reject.
+func (s SchemaString) normalize(str string) string {
+	if s.NFC && utf8.ValidString(str) {
+		return norm.NFC.String(str)
+	}
+	return str
+}
+
+// WithRuneCount returns s measuring MinLen and MaxLen in UTF-8 characters
+// rather than bytes, as users count them in form fields.
+func (s SchemaString) WithRuneCount() SchemaString {
+	s.CountRunes = true
+	return s
+}
+
+// WithNullTag returns s with NullTag set.
+func (s SchemaString) WithNullTag() SchemaString {
+	s.NullTag = true
+	return s
 }
 func (s SchemaInt16) RangeValues(min, max int64) Schema {
 	return s.Range(&min, &max)
 }
 func (s SchemaInt16) Range(min, max *int64) Schema {
+	return s.Constrain(IntConstraints{Min: min, Max: max})
+}
+
+// MultipleOf requires values to be multiples of n, failing with
+// ErrNotMultipleOf. Use Constrain to combine it with bounds.
+func (s SchemaInt16) MultipleOf(n int64) Schema {
+	return s.Constrain(IntConstraints{MultipleOf: &n})
+}
+
+// Constrain applies bounds, exclusivity and multiple-of checks to SchemaInt16 values.
+func (s SchemaInt16) Constrain(c IntConstraints) Schema {
 	return SchemaGeneric{
 		ValidateFunc: func(seq *access.SeqGetAccess) error {
 			pos := seq.CurrentIndex()
@@ -1181,9 +1839,9 @@ func (s SchemaInt16) Range(min, max *int64) Schema {
 				return err
 			}
 			val := int16(binary.LittleEndian.Uint16(payload))
-			err = CheckIntRange(int64(val), min, max)
+			err = c.Check(int64(val))
 			if err != nil {
-				return NewSchemaError(ErrOutOfRange, SchemaInt16Name, "", pos, err)
+				return NewSchemaError(numericErrorCode(err), SchemaInt16Name, "", pos, err)
 			}
 			return nil
 		},
@@ -1194,17 +1852,21 @@ func (s SchemaInt16) Range(min, max *int64) Schema {
 				return nil, err
 			}
 			val := int16(binary.LittleEndian.Uint16(payload))
-			err = CheckIntRange(int64(val), min, max)
+			err = c.Check(int64(val))
 			if err != nil {
-				return nil, NewSchemaError(ErrOutOfRange, SchemaInt16Name, "", pos, err)
+				return nil, NewSchemaError(numericErrorCode(err), SchemaInt16Name, "", pos, err)
 			}
 			return val, nil
 		},
 		EncodeFunc: func(put *access.PutAccess, val any) error {
+			val, err := coerceJSONNumber[int16](val)
+			if err != nil {
+				return NewSchemaError(ErrEncode, SchemaInt16Name, "", -1, err)
+			}
 			if value, ok := val.(int16); ok {
-				err := CheckIntRange(int64(value), min, max)
+				err := c.Check(int64(value))
 				if err != nil {
-					return NewSchemaError(ErrOutOfRange, SchemaInt16Name, "", -1, err)
+					return NewSchemaError(numericErrorCode(err), SchemaInt16Name, "", -1, err)
 				}
 				put.AddInt16(value)
 
@@ -1213,12 +1875,24 @@ func (s SchemaInt16) Range(min, max *int64) Schema {
 			}
 			return nil
 		},
+		NullableCheck: func() bool { return false },
 	}
 }
 func (s SchemaInt32) RangeValues(min, max int64) Schema {
 	return s.Range(&min, &max)
 }
 func (s SchemaInt32) Range(min, max *int64) Schema {
+	return s.Constrain(IntConstraints{Min: min, Max: max})
+}
+
+// MultipleOf requires values to be multiples of n, failing with
+// ErrNotMultipleOf. Use Constrain to combine it with bounds.
+func (s SchemaInt32) MultipleOf(n int64) Schema {
+	return s.Constrain(IntConstraints{MultipleOf: &n})
+}
+
+// Constrain applies bounds, exclusivity and multiple-of checks to SchemaInt32 values.
+func (s SchemaInt32) Constrain(c IntConstraints) Schema {
 	return SchemaGeneric{
 		ValidateFunc: func(seq *access.SeqGetAccess) error {
 			pos := seq.CurrentIndex()
@@ -1227,9 +1901,9 @@ func (s SchemaInt32) Range(min, max *int64) Schema {
 				return err
 			}
 			val := int32(binary.LittleEndian.Uint32(payload))
-			err = CheckIntRange(int64(val), min, max)
+			err = c.Check(int64(val))
 			if err != nil {
-				return NewSchemaError(ErrOutOfRange, SchemaInt32Name, "", pos, err)
+				return NewSchemaError(numericErrorCode(err), SchemaInt32Name, "", pos, err)
 			}
 			return nil
 		},
@@ -1240,17 +1914,21 @@ func (s SchemaInt32) Range(min, max *int64) Schema {
 				return nil, err
 			}
 			val := int32(binary.LittleEndian.Uint32(payload))
-			err = CheckIntRange(int64(val), min, max)
+			err = c.Check(int64(val))
 			if err != nil {
-				return nil, NewSchemaError(ErrOutOfRange, SchemaInt32Name, "", pos, err)
+				return nil, NewSchemaError(numericErrorCode(err), SchemaInt32Name, "", pos, err)
 			}
 			return val, nil
 		},
 		EncodeFunc: func(put *access.PutAccess, val any) error {
+			val, err := coerceJSONNumber[int32](val)
+			if err != nil {
+				return NewSchemaError(ErrEncode, SchemaInt32Name, "", -1, err)
+			}
 			if value, ok := val.(int32); ok {
-				err := CheckIntRange(int64(value), min, max)
+				err := c.Check(int64(value))
 				if err != nil {
-					return NewSchemaError(ErrOutOfRange, SchemaInt32Name, "", -1, err)
+					return NewSchemaError(numericErrorCode(err), SchemaInt32Name, "", -1, err)
 				}
 				put.AddInt32(value)
 
@@ -1259,12 +1937,24 @@ func (s SchemaInt32) Range(min, max *int64) Schema {
 			}
 			return nil
 		},
+		NullableCheck: func() bool { return false },
 	}
 }
 func (s SchemaInt64) RangeValues(min, max int64) Schema {
 	return s.Range(&min, &max)
 }
 func (s SchemaInt64) Range(min, max *int64) Schema {
+	return s.Constrain(IntConstraints{Min: min, Max: max})
+}
+
+// MultipleOf requires values to be multiples of n, failing with
+// ErrNotMultipleOf. Use Constrain to combine it with bounds.
+func (s SchemaInt64) MultipleOf(n int64) Schema {
+	return s.Constrain(IntConstraints{MultipleOf: &n})
+}
+
+// Constrain applies bounds, exclusivity and multiple-of checks to SchemaInt64 values.
+func (s SchemaInt64) Constrain(c IntConstraints) Schema {
 	return SchemaGeneric{
 		ValidateFunc: func(seq *access.SeqGetAccess) error {
 			pos := seq.CurrentIndex()
@@ -1273,9 +1963,9 @@ func (s SchemaInt64) Range(min, max *int64) Schema {
 				return err
 			}
 			val := int64(binary.LittleEndian.Uint64(payload))
-			err = CheckIntRange(val, min, max)
+			err = c.Check(val)
 			if err != nil {
-				return NewSchemaError(ErrOutOfRange, SchemaInt64Name, "", pos, err)
+				return NewSchemaError(numericErrorCode(err), SchemaInt64Name, "", pos, err)
 			}
 			return nil
 		},
@@ -1286,17 +1976,21 @@ func (s SchemaInt64) Range(min, max *int64) Schema {
 				return nil, err
 			}
 			val := int64(binary.LittleEndian.Uint64(payload))
-			err = CheckIntRange(val, min, max)
+			err = c.Check(val)
 			if err != nil {
-				return nil, NewSchemaError(ErrOutOfRange, SchemaInt64Name, "", pos, err)
+				return nil, NewSchemaError(numericErrorCode(err), SchemaInt64Name, "", pos, err)
 			}
 			return val, nil
 		},
 		EncodeFunc: func(put *access.PutAccess, val any) error {
+			val, err := coerceJSONNumber[int64](val)
+			if err != nil {
+				return NewSchemaError(ErrEncode, SchemaInt64Name, "", -1, err)
+			}
 			if value, ok := val.(int64); ok {
-				err := CheckIntRange(value, min, max)
+				err := c.Check(value)
 				if err != nil {
-					return NewSchemaError(ErrOutOfRange, SchemaInt64Name, "", -1, err)
+					return NewSchemaError(numericErrorCode(err), SchemaInt64Name, "", -1, err)
 				}
 				put.AddInt64(value)
 
@@ -1305,6 +1999,7 @@ func (s SchemaInt64) Range(min, max *int64) Schema {
 			}
 			return nil
 		},
+		NullableCheck: func() bool { return false },
 	}
 }
 
@@ -1312,6 +2007,10 @@ func PtrToInt64[T constraints.Integer](val T) *int64 {
 	var v int64 = int64(val)
 	return &v
 }
+func PtrToFloat64[T constraints.Integer | constraints.Float](val T) *float64 {
+	var v float64 = float64(val)
+	return &v
+}
 func (s SchemaInt64) DateRangeValues(from, to time.Time) Schema {
 	return s.DateRange(&from, &to)
 }
@@ -1358,6 +2057,10 @@ func (s SchemaInt64) DateRange(from, to *time.Time) Schema {
 			return val, nil
 		},
 		EncodeFunc: func(put *access.PutAccess, val any) error {
+			val, err := coerceJSONNumber[int64](val)
+			if err != nil {
+				return NewSchemaError(ErrEncode, SchemaInt64Name, "", -1, err)
+			}
 			if value, ok := val.(int64); ok {
 				err := CheckIntRange(value, min, max)
 				if err != nil {
@@ -1372,8 +2075,14 @@ func (s SchemaInt64) DateRange(from, to *time.Time) Schema {
 	}
 }
 
+// SchemaMapUnordered accepts map keys in any order when validating and decoding.
+// Encode writes keys in Order, followed by any remaining Fields keys sorted,
+// so the same value always packs to the same bytes. Keys missing from the
+// value are left out when their schema is nullable, as Validate accepts
+// them, so partial values such as patches encode with the full schema.
 type SchemaMapUnordered struct {
 	Fields   map[string]Schema
+	Order    []string
 	Nullable bool
 }
 
@@ -1381,8 +2090,38 @@ func SMapUnordered(mappedSchemas map[string]Schema) Schema {
 	return SchemaMapUnordered{Fields: mappedSchemas, Nullable: false}
 }
 
-func SMapUnorderedOptional(mappedSchemas map[string]Schema) Schema {
-	return SchemaMapUnordered{Fields: mappedSchemas, Nullable: true}
+func SMapUnorderedOptional(mappedSchemas map[string]Schema) Schema {
+	return SchemaMapUnordered{Fields: mappedSchemas, Nullable: true}
+}
+
+// SMapUnorderedNamed builds an unordered map schema whose Encode emits keys in
+// the order of fieldNames.
+func SMapUnorderedNamed(fieldNames []string, schemas ...Schema) SchemaMapUnordered {
+	fields := make(map[string]Schema, len(fieldNames))
+	for i, name := range fieldNames {
+		fields[name] = schemas[i]
+	}
+	return SchemaMapUnordered{Fields: fields, Order: fieldNames}
+}
+
+// encodeOrder returns the keys of s.Fields in encode order.
+func (s SchemaMapUnordered) encodeOrder() []string {
+	keys := make([]string, 0, len(s.Fields))
+	seen := make(map[string]bool, len(s.Order))
+	for _, k := range s.Order {
+		if _, ok := s.Fields[k]; ok && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	rest := len(keys)
+	for k := range s.Fields {
+		if !seen[k] {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys[rest:])
+	return keys
 }
 
 func (s SchemaMapUnordered) IsNullable() bool {
@@ -1397,7 +2136,7 @@ func (s SchemaMapUnordered) Validate(seq *access.SeqGetAccess) error {
 	if err != nil {
 		return NewSchemaError(ErrInvalidFormat, SchemaMapUnorderedName, "", pos, err)
 	}
-	if typ != typetags.TypeMap {
+	if !typ.IsMap() {
 		return NewSchemaError(ErrConstraintViolated, SchemaMapUnorderedName, "", pos, ErrUnsupportedType)
 	}
 
@@ -1407,6 +2146,12 @@ func (s SchemaMapUnordered) Validate(seq *access.SeqGetAccess) error {
 			return NewSchemaError(ErrInvalidFormat, SchemaMapUnorderedName, "", pos, err)
 		}
 		seen := make(map[string]bool)
+		// conditional fields are validated last, once all siblings are known
+		var fields map[string]fieldAt
+		var pending []string
+		if hasConditionalField(s.Fields) {
+			fields = make(map[string]fieldAt, len(s.Fields))
+		}
 
 		for {
 			keyPayload, keyType, err := subseq.Next()
@@ -1423,8 +2168,18 @@ func (s SchemaMapUnordered) Validate(seq *access.SeqGetAccess) error {
 			seen[key] = true
 
 			if validator, ok := s.Fields[key]; ok {
+				if fields != nil {
+					fields[key] = fieldAt{pos: subseq.CurrentIndex(), schema: validator}
+					if _, ok := validator.(SchemaConditional); ok {
+						pending = append(pending, key)
+						if err := subseq.Advance(); err != nil {
+							return NewSchemaError(ErrUnexpectedEOF, SchemaMapUnorderedName, "", pos, err)
+						}
+						continue
+					}
+				}
 				if err := validator.Validate(subseq); err != nil {
-					return NewSchemaError(ErrInvalidFormat, SchemaMapUnorderedName, key, pos, err)
+					return nestedError(ErrInvalidFormat, SchemaMapUnorderedName, key, pos, key, err)
 				}
 			} else {
 				if err := subseq.Advance(); err != nil {
@@ -1433,9 +2188,23 @@ func (s SchemaMapUnordered) Validate(seq *access.SeqGetAccess) error {
 			}
 		}
 
+		var siblings siblingLookup
+		if fields != nil {
+			siblings = seqSiblings(subseq, conditionalSiblings(fields))
+		}
+		for _, key := range pending {
+			validator := resolveField(s.Fields[key], siblings)
+			if err := subseq.Seek(fields[key].pos); err != nil {
+				return NewSchemaError(ErrInvalidFormat, SchemaMapUnorderedName, "", pos, err)
+			}
+			if err := validator.Validate(subseq); err != nil {
+				return nestedError(ErrInvalidFormat, SchemaMapUnorderedName, key, pos, key, err)
+			}
+		}
+
 		for key, schema := range s.Fields {
 			if !seen[key] {
-				if !schema.IsNullable() {
+				if !resolveField(schema, siblings).IsNullable() {
 					return NewSchemaError(ErrConstraintViolated, SchemaMapUnorderedName, "", pos, MissingKeyErrorDetails{Key: key})
 				}
 
@@ -1456,7 +2225,7 @@ func (s SchemaMapUnordered) Decode(seq *access.SeqGetAccess) (any, error) {
 	if err != nil {
 		return nil, NewSchemaError(ErrInvalidFormat, SchemaMapUnorderedName, "", pos, err)
 	}
-	if typ != typetags.TypeMap {
+	if !typ.IsMap() {
 		return nil, NewSchemaError(ErrConstraintViolated, SchemaMapUnorderedName, "", pos, ErrUnsupportedType)
 	}
 
@@ -1467,6 +2236,11 @@ func (s SchemaMapUnordered) Decode(seq *access.SeqGetAccess) (any, error) {
 			return nil, NewSchemaError(ErrInvalidFormat, SchemaMapUnorderedName, "", pos, err)
 		}
 		out = make(map[string]any, subseq.ArgCount()/2)
+		// conditional fields are decoded last, once all siblings are known
+		var pending map[string]int
+		if hasConditionalField(s.Fields) {
+			pending = map[string]int{}
+		}
 
 		for {
 			keyPayload, keyType, err := subseq.Next()
@@ -1482,9 +2256,16 @@ func (s SchemaMapUnordered) Decode(seq *access.SeqGetAccess) (any, error) {
 
 			key := string(keyPayload)
 			if validator, ok := s.Fields[key]; ok {
+				if _, ok := validator.(SchemaConditional); ok && pending != nil {
+					pending[key] = subseq.CurrentIndex()
+					if err := subseq.Advance(); err != nil {
+						return nil, NewSchemaError(ErrUnexpectedEOF, SchemaMapUnorderedName, "", pos, err)
+					}
+					continue
+				}
 				val, err := validator.Decode(subseq)
 				if err != nil {
-					return nil, NewSchemaError(ErrInvalidFormat, SchemaMapUnorderedName, key, pos, err)
+					return nil, nestedError(ErrInvalidFormat, SchemaMapUnorderedName, key, pos, key, err)
 				}
 				out[key] = val
 			} else {
@@ -1494,9 +2275,25 @@ func (s SchemaMapUnordered) Decode(seq *access.SeqGetAccess) (any, error) {
 			}
 		}
 
+		var siblings siblingLookup
+		if pending != nil {
+			siblings = mapSiblings(out, s.Fields)
+		}
+		for _, key := range slices.Sorted(maps.Keys(pending)) {
+			validator := resolveField(s.Fields[key], siblings)
+			if err := subseq.Seek(pending[key]); err != nil {
+				return nil, NewSchemaError(ErrInvalidFormat, SchemaMapUnorderedName, "", pos, err)
+			}
+			val, err := validator.Decode(subseq)
+			if err != nil {
+				return nil, nestedError(ErrInvalidFormat, SchemaMapUnorderedName, key, pos, key, err)
+			}
+			out[key] = val
+		}
+
 		for key, schema := range s.Fields {
 			if _, ok := out[key]; !ok {
-				if !schema.IsNullable() {
+				if !resolveField(schema, siblings).IsNullable() {
 					return nil, NewSchemaError(ErrConstraintViolated, SchemaMapUnorderedName, "", pos, MissingKeyErrorDetails{Key: key})
 				}
 			}
@@ -1522,17 +2319,21 @@ func (s SchemaMapUnordered) Encode(put *access.PutAccess, val any) error {
 		nested := put.BeginMap()
 		defer put.EndNested(nested)
 		ss := SString
-		for key, sch := range s.Fields {
+		var siblings siblingLookup
+		if hasConditionalField(s.Fields) {
+			siblings = mapSiblings(mapKV, s.Fields)
+		}
+		for _, key := range s.encodeOrder() {
+			sch := resolveField(s.Fields[key], siblings)
 			if val, exist := mapKV[key]; exist {
 				ss.Encode(nested, key)
 				err := sch.Encode(nested, val)
 				if err != nil {
 					return NewSchemaError(ErrInvalidFormat, SchemaMapUnorderedName, key, -1, err)
 				}
-			} else {
+			} else if !sch.IsNullable() {
 				return NewSchemaError(ErrInvalidFormat, SchemaMapUnorderedName, "", -1, MissingKeyErrorDetails{Key: key})
 			}
-
 		}
 
 	} else {
@@ -1579,9 +2380,9 @@ func (s TupleSchema) Validate(seq *access.SeqGetAccess) error {
 		if argCount > 0 && sub.ArgCount() != argCount && !s.VariableLength {
 			return NewSchemaError(ErrConstraintViolated, TupleSchemaName, "", pos, SizeExact{Actual: argCount, Exact: sub.ArgCount()})
 		}
-		for _, sch := range s.Schemas {
+		for i, sch := range s.Schemas {
 			if err := sch.Validate(sub); err != nil {
-				return NewSchemaError(ErrInvalidFormat, TupleSchemaName, "", pos, err)
+				return childError(ErrInvalidFormat, TupleSchemaName, "", pos, sch, strconv.Itoa(i), err)
 			}
 		}
 	}
@@ -1608,10 +2409,10 @@ func (s TupleSchema) Decode(seq *access.SeqGetAccess) (any, error) {
 			return nil, NewSchemaError(ErrConstraintViolated, TupleSchemaName, "", pos, SizeExact{Actual: argCount, Exact: sub.ArgCount()})
 		}
 		out = make([]any, 0, sub.ArgCount())
-		for _, sch := range s.Schemas {
+		for i, sch := range s.Schemas {
 			v, err := sch.Decode(sub)
 			if err != nil {
-				return nil, NewSchemaError(ErrInvalidFormat, TupleSchemaName, "", pos, err)
+				return nil, childError(ErrInvalidFormat, TupleSchemaName, "", pos, sch, strconv.Itoa(i), err)
 			}
 			if s.Flatten {
 				if _, ok := sch.(SRepeatSchema); ok {
@@ -1648,8 +2449,14 @@ func (s TupleSchema) Encode(put *access.PutAccess, val any) error {
 
 			if schRet, ok := sch.(SRepeatSchema); ok {
 				var err error
-				if s.Flatten {
+				if fr, ok := elementAt(valArr, j).(FlattenedRepeat); ok && s.Flatten {
+					err = schRet.Encode(nested, fr)
+					j++
+				} else if s.Flatten {
 					if lastI != k {
+						if j+schRet.max > len(valArr) {
+							return NewSchemaError(ErrInvalidFormat, TupleSchemaName, "", -1, SizeExact{Actual: len(valArr) - j, Exact: schRet.max})
+						}
 						if schRet.max < 1 {
 							return NewSchemaError(ErrInvalidFormat, TupleSchemaName, "", -1, fmt.Errorf("max should be provided if repeat is not in the end. max: %d", schRet.max))
 						}
@@ -1740,9 +2547,20 @@ func (s TupleSchemaNamed) Validate(seq *access.SeqGetAccess) error {
 		if !s.VariableLength && sub.ArgCount() != argCount {
 			return NewSchemaError(ErrConstraintViolated, TupleSchemaNamedName, "", pos, SizeExact{Actual: argCount, Exact: sub.ArgCount()})
 		}
-		for _, sch := range s.Schemas {
+		var fields map[string]fieldAt
+		if hasConditional(s.Schemas) {
+			fields = make(map[string]fieldAt, argCount)
+		}
+		for i, sch := range s.Schemas {
+			at := sub.CurrentIndex()
+			if fields != nil {
+				sch = resolveField(sch, seqSiblings(sub, fields))
+			}
 			if err := sch.Validate(sub); err != nil {
-				return NewSchemaError(ErrInvalidFormat, TupleSchemaNamedName, "", pos, err)
+				return childError(ErrInvalidFormat, TupleSchemaNamedName, s.FieldNames[i], pos, sch, s.FieldNames[i], err)
+			}
+			if fields != nil {
+				fields[s.FieldNames[i]] = fieldAt{pos: at, schema: s.Schemas[i]}
 			}
 		}
 	}
@@ -1773,10 +2591,14 @@ func (s TupleSchemaNamed) Decode(seq *access.SeqGetAccess) (any, error) {
 		if !s.VariableLength && sub.ArgCount() != argCount {
 			return nil, NewSchemaError(ErrConstraintViolated, TupleSchemaNamedName, "", pos, SizeExact{Actual: argCount, Exact: sub.ArgCount()})
 		}
+		conditional := hasConditional(s.Schemas)
 		for i, sch := range s.Schemas {
+			if conditional {
+				sch = resolveField(sch, tupleSiblings(out, s.FieldNames[:i]))
+			}
 			v, err := sch.Decode(sub)
 			if err != nil {
-				return nil, NewSchemaError(ErrInvalidFormat, TupleSchemaNamedName, s.FieldNames[i], pos, err)
+				return nil, childError(ErrInvalidFormat, TupleSchemaNamedName, s.FieldNames[i], pos, sch, s.FieldNames[i], err)
 			}
 			if s.Flatten {
 				if _, ok := sch.(SRepeatSchema); ok {
@@ -1812,8 +2634,15 @@ func (s TupleSchemaNamed) Encode(put *access.PutAccess, val any) error {
 
 		nested := put.BeginTuple()
 		defer put.EndNested(nested)
+		conditional := hasConditional(s.Schemas)
 		for i, key := range s.FieldNames {
 			if sch, ok := s.Schemas[i].(SRepeatSchema); ok && s.Flatten {
+				if fr, ok := mapKV[key].(FlattenedRepeat); ok {
+					if err := sch.Encode(nested, fr); err != nil {
+						return NewSchemaError(ErrInvalidFormat, TupleSchemaNamedName, key, -1, err)
+					}
+					continue
+				}
 
 				minx := sch.min
 				max := sch.max
@@ -1836,7 +2665,7 @@ func (s TupleSchemaNamed) Encode(put *access.PutAccess, val any) error {
 				}
 				if max == -1 || max > minx {
 
-					for {
+					for ; max == -1 || j < max; j++ {
 						keyx := fmt.Sprintf("%s_%d", key, j)
 						if val, exist := mapKV[keyx]; exist {
 							err := sch.Schemas[schi].Encode(nested, val)
@@ -1850,20 +2679,22 @@ func (s TupleSchemaNamed) Encode(put *access.PutAccess, val any) error {
 						} else {
 							break
 						}
-
-						j++
 					}
 
 				}
 			} else {
+				sch := s.Schemas[i]
+				if conditional {
+					sch = resolveField(sch, tupleSiblings(mapKV, s.FieldNames[:i]))
+				}
 				if val, exist := mapKV[key]; exist {
-					err := s.Schemas[i].Encode(nested, val)
+					err := sch.Encode(nested, val)
 					if err != nil {
 						return NewSchemaError(ErrInvalidFormat, TupleSchemaNamedName, key, -1, err)
 					}
-				} else if s.Schemas[i].IsNullable() {
+				} else if sch.IsNullable() {
 					//just add null tag and skip
-					err := s.Schemas[i].Encode(nested, nil)
+					err := sch.Encode(nested, nil)
 					if err != nil {
 						return NewSchemaError(ErrInvalidFormat, TupleSchemaNamedName, key, -1, err)
 					}
@@ -1880,6 +2711,22 @@ func (s TupleSchemaNamed) Encode(put *access.PutAccess, val any) error {
 	return nil
 }
 
+// FlattenedRepeat holds the elements of a repeat in a flattened tuple as
+// one value, which encodes the same whether the tuple is named or not: in
+// the slot of the repeat for STupleValFlatten, or under the field name of
+// the repeat for STupleNamedValFlattened. Decoding still splices the
+// elements into the tuple, or into "name_0", "name_1", ... keys, and those
+// forms encode back to the same bytes.
+type FlattenedRepeat []any
+
+// elementAt returns arr[i], or nil when i is out of range.
+func elementAt(arr []any, i int) any {
+	if i < len(arr) {
+		return arr[i]
+	}
+	return nil
+}
+
 type SRepeatSchema struct {
 	Schemas []Schema
 	max     int
@@ -1928,12 +2775,12 @@ func (s SRepeatSchema) Validate(seq *access.SeqGetAccess) error {
 outer:
 	for {
 		for _, schema := range s.Schemas {
-			if err := schema.Validate(seq); err != nil {
-				return NewSchemaError(ErrInvalidFormat, SRepeatSchemaName, "", pos, err)
-			}
 			if i >= maxIter {
 				break outer
 			}
+			if err := schema.Validate(seq); err != nil {
+				return nestedError(ErrInvalidFormat, SRepeatSchemaName, "", pos, strconv.Itoa(pos+i), err)
+			}
 			i++
 		}
 	}
@@ -1968,7 +2815,7 @@ outer:
 			}
 			val, err := schema.Decode(seq)
 			if err != nil {
-				return nil, NewSchemaError(ErrInvalidFormat, SRepeatSchemaName, "", pos, err)
+				return nil, nestedError(ErrInvalidFormat, SRepeatSchemaName, "", pos, strconv.Itoa(pos+i), err)
 			}
 			out = append(out, val)
 			i++
@@ -1978,7 +2825,9 @@ outer:
 }
 
 func (s SRepeatSchema) Encode(put *access.PutAccess, val any) error {
-
+	if fr, ok := val.(FlattenedRepeat); ok {
+		val = []any(fr)
+	}
 	valArr, ok := val.([]any)
 	if !ok {
 		return NewSchemaError(ErrEncode, SRepeatSchemaName, "", -1, ErrTypeMisMatch)
@@ -2186,12 +3035,154 @@ func SDate(nullable bool, from, to time.Time) Schema {
 	return SDateRange(nullable, &from, &to)
 }
 func SDateRange(nullable bool, from, to *time.Time) Schema {
-	var min, max *int64 = nil, nil
+	var opts DateRangeOptions
 	if from != nil {
-		min = PtrToInt64(from.Unix())
+		b := AbsoluteDateBound(*from)
+		opts.From = &b
 	}
 	if to != nil {
-		max = PtrToInt64(to.Unix())
+		b := AbsoluteDateBound(*to)
+		opts.To = &b
+	}
+	return SDateRangeWith(nullable, opts)
+}
+
+// DateBound is one end of a date range: either a fixed instant or an offset
+// from the time of validation ("now-30d", "now+1h").
+type DateBound struct {
+	At       time.Time
+	Relative bool
+	Days     int           // calendar days, applied with AddDate in the range location
+	Offset   time.Duration // applied after Days
+}
+
+// AbsoluteDateBound returns a bound fixed at t.
+func AbsoluteDateBound(t time.Time) DateBound {
+	return DateBound{At: t}
+}
+
+// RelativeDateBound returns a bound at now + days + offset, evaluated at validation time.
+func RelativeDateBound(days int, offset time.Duration) DateBound {
+	return DateBound{Relative: true, Days: days, Offset: offset}
+}
+
+// ParseDateBound parses "now", "now-30d", "now+1h30m", "now-2w", an RFC3339
+// timestamp, or a zone-less "2006-01-02T15:04:05" / "2006-01-02" value, which
+// is interpreted in loc (UTC when loc is nil).
+func ParseDateBound(s string, loc *time.Location) (DateBound, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	s = strings.TrimSpace(s)
+	if rest, ok := strings.CutPrefix(s, "now"); ok {
+		return parseRelativeDateBound(rest)
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return AbsoluteDateBound(t), nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return AbsoluteDateBound(t), nil
+		}
+	}
+	return DateBound{}, fmt.Errorf("invalid date bound %q", s)
+}
+
+func parseRelativeDateBound(rest string) (DateBound, error) {
+	if rest == "" {
+		return RelativeDateBound(0, 0), nil
+	}
+	sign := 1
+	switch rest[0] {
+	case '+':
+	case '-':
+		sign = -1
+	default:
+		return DateBound{}, fmt.Errorf("invalid relative date bound %q", "now"+rest)
+	}
+	if len(rest) == 1 {
+		return DateBound{}, fmt.Errorf("invalid relative date bound %q", "now"+rest)
+	}
+	rest = rest[1:]
+	days := 0
+	var clock strings.Builder
+	for rest != "" {
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		j := i
+		for j < len(rest) && (rest[j] < '0' || rest[j] > '9') {
+			j++
+		}
+		if i == 0 || j == i {
+			return DateBound{}, fmt.Errorf("invalid relative offset %q", rest)
+		}
+		n, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return DateBound{}, fmt.Errorf("invalid relative offset %q: %w", rest, err)
+		}
+		switch rest[i:j] {
+		case "d":
+			days += n
+		case "w":
+			days += 7 * n
+		default:
+			clock.WriteString(rest[:j])
+		}
+		rest = rest[j:]
+	}
+	var offset time.Duration
+	if clock.Len() > 0 {
+		d, err := time.ParseDuration(clock.String())
+		if err != nil {
+			return DateBound{}, fmt.Errorf("invalid relative offset: %w", err)
+		}
+		offset = d
+	}
+	return RelativeDateBound(sign*days, time.Duration(sign)*offset), nil
+}
+
+// resolve returns the bound in Unix seconds for the given evaluation time.
+func (b DateBound) resolve(now time.Time, loc *time.Location) int64 {
+	if !b.Relative {
+		return b.At.Unix()
+	}
+	return now.In(loc).AddDate(0, 0, b.Days).Add(b.Offset).Unix()
+}
+
+// DateRangeOptions configures SDateRangeWith.
+// Location is used for calendar arithmetic of relative bounds and for decoded
+// values; it defaults to UTC. Now defaults to time.Now.
+type DateRangeOptions struct {
+	From     *DateBound
+	To       *DateBound
+	Location *time.Location
+	Now      func() time.Time
+}
+
+// bounds resolves the configured bounds; relative ones are evaluated on every call.
+func (o DateRangeOptions) bounds() (min, max *int64) {
+	now := time.Time{}
+	if (o.From != nil && o.From.Relative) || (o.To != nil && o.To.Relative) {
+		now = o.Now()
+	}
+	if o.From != nil {
+		min = PtrToInt64(o.From.resolve(now, o.Location))
+	}
+	if o.To != nil {
+		max = PtrToInt64(o.To.resolve(now, o.Location))
+	}
+	return min, max
+}
+
+// SDateRangeWith is SDateRange with location-aware and relative bounds.
+func SDateRangeWith(nullable bool, opts DateRangeOptions) Schema {
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
 	}
 
 	return SchemaGeneric{
@@ -2205,6 +3196,7 @@ func SDateRange(nullable bool, from, to *time.Time) Schema {
 				return nil // allow nullable
 			}
 			val := int64(binary.LittleEndian.Uint64(payload))
+			min, max := opts.bounds()
 			err = CheckIntRange(val, min, max)
 			if err != nil {
 				return NewSchemaError(ErrDateOutOfRange, SchemaDateName, "", pos, err)
@@ -2221,18 +3213,23 @@ func SDateRange(nullable bool, from, to *time.Time) Schema {
 				return nil, nil // allow nullable
 			}
 			val := int64(binary.LittleEndian.Uint64(payload))
+			min, max := opts.bounds()
 			err = CheckIntRange(val, min, max)
 			if err != nil {
 				return nil, NewSchemaError(ErrDateOutOfRange, SchemaDateName, "", pos, err)
 			}
 			// decode as time.Time
-			return time.Unix(val, 0).UTC(), nil
+			return time.Unix(val, 0).In(opts.Location), nil
 		},
 		EncodeFunc: func(put *access.PutAccess, val any) error {
 			if nullable && val == nil {
 				put.AddNullableInt64(nil)
 				return nil
 			}
+			val, err := coerceJSONNumber[int64](val)
+			if err != nil {
+				return NewSchemaError(ErrEncode, SchemaDateName, "", -1, err)
+			}
 			var ret int64
 			switch v := val.(type) {
 			case int64:
@@ -2243,7 +3240,8 @@ func SDateRange(nullable bool, from, to *time.Time) Schema {
 			default:
 				return NewSchemaError(ErrEncode, SchemaDateName, "", -1, ErrTypeMisMatch)
 			}
-			err := CheckIntRange(ret, min, max)
+			min, max := opts.bounds()
+			err = CheckIntRange(ret, min, max)
 			if err != nil {
 				return NewSchemaError(ErrDateOutOfRange, SchemaDateName, "", -1, err)
 			}
@@ -2255,13 +3253,57 @@ func SDateRange(nullable bool, from, to *time.Time) Schema {
 
 // SchemaEnumNamedList constrains an index to a list of names, encoded in 2 bytes.
 // Perfect for radio groups or select dropdowns.
+//
+// Encode accepts a plain int as the index itself, one of Values, or a name.
+// An int is never looked up in Values, even when they are ints.
 type SchemaEnumNamedList struct {
 	FieldNames []string
 	Nullable   bool
+	// Values, when set, holds one value per name. Decode returns these
+	// shared values instead of boxing a name on every call, and Encode
+	// accepts them in place of names.
+	Values []any
 }
 
+// SEnum decodes to the names themselves, boxed once here so that decoding
+// does not allocate.
 func SEnum(fieldNames []string, nullable bool) Schema {
-	return SchemaEnumNamedList{FieldNames: fieldNames, Nullable: nullable}
+	values := make([]any, len(fieldNames))
+	for i, name := range fieldNames {
+		values[i] = name
+	}
+	return SchemaEnumNamedList{FieldNames: fieldNames, Nullable: nullable, Values: values}
+}
+
+// SEnumOf decodes each name to the constant at the same index of values,
+// such as a typed string or integer constant of the caller's enum type. It
+// panics if the lengths differ.
+func SEnumOf[T comparable](fieldNames []string, values []T, nullable bool) Schema {
+	if len(values) != len(fieldNames) {
+		panic(fmt.Sprintf("SEnumOf: %d values for %d names", len(values), len(fieldNames)))
+	}
+	boxed := make([]any, len(values))
+	for i, v := range values {
+		boxed[i] = v
+	}
+	return SchemaEnumNamedList{FieldNames: fieldNames, Nullable: nullable, Values: boxed}
+}
+
+// SEnumInt decodes each name to its index converted to T, for enums
+// declared as integer constants in the order of the names:
+//
+//	type Role int8
+//	const (
+//	    Admin Role = iota
+//	    User
+//	)
+//	SEnumInt[Role]([]string{"admin", "user"}, false)
+func SEnumInt[T constraints.Integer](fieldNames []string, nullable bool) Schema {
+	values := make([]T, len(fieldNames))
+	for i := range values {
+		values[i] = T(i)
+	}
+	return SEnumOf(fieldNames, values, nullable)
 }
 
 func (s SchemaEnumNamedList) IsNullable() bool { return s.Nullable }
@@ -2296,6 +3338,9 @@ func (s SchemaEnumNamedList) Decode(seq *access.SeqGetAccess) (any, error) {
 		return nil, NewSchemaError(ErrConstraintViolated, SchemaEnumNamedListName, "", -1,
 			SizeExact{Actual: idx, Exact: len(s.FieldNames)})
 	}
+	if s.Values != nil {
+		return s.Values[idx], nil
+	}
 	return s.FieldNames[idx], nil // return the name string
 }
 
@@ -2305,23 +3350,26 @@ func (s SchemaEnumNamedList) Encode(put *access.PutAccess, val any) error {
 		return nil
 	}
 
-	var idx int
+	// a plain int is always an index, so SEnumOf with int values stays
+	// unambiguous; pass such values by name
+	idx := -1
+	if _, isInt := val.(int); !isInt {
+		idx = slices.Index(s.Values, val)
+	}
 	switch v := val.(type) {
 	case int:
 		idx = v
 	case string:
-		idx = -1
-		for i, name := range s.FieldNames {
-			if name == v {
-				idx = i
-				break
-			}
+		if idx == -1 {
+			idx = slices.Index(s.FieldNames, v)
 		}
 		if idx == -1 {
 			return NewSchemaError(ErrEncode, SchemaEnumNamedListName, "", -1, ErrTypeMisMatch)
 		}
 	default:
-		return NewSchemaError(ErrEncode, SchemaEnumNamedListName, "", -1, ErrTypeMisMatch)
+		if idx == -1 {
+			return NewSchemaError(ErrEncode, SchemaEnumNamedListName, "", -1, ErrTypeMisMatch)
+		}
 	}
 
 	if idx < 0 || idx >= len(s.FieldNames) {
@@ -2342,10 +3390,12 @@ func SColor(nullable bool) Schema {
 }
 
 type SchemaMapRepeat struct {
-	Key   Schema
-	Value Schema
-	min   int
-	max   int
+	Key        Schema
+	Value      Schema
+	min        int
+	max        int
+	keyPattern *boundedRegexp
+	keySet     []string
 }
 
 func SMapRepeat(key Schema, value Schema) SchemaMapRepeat {
@@ -2368,6 +3418,66 @@ func (s SchemaMapRepeat) IsNullable() bool {
 	return s.min <= 0
 }
 
+// KeysMatch restricts keys to those matching the regular expression pattern,
+// within DefaultPatternLimits. It panics if pattern does not compile.
+func (s SchemaMapRepeat) KeysMatch(pattern string) SchemaMapRepeat {
+	re := mustCompileBounded(pattern)
+	s.keyPattern = &re
+	return s
+}
+
+// KeysOneOf restricts keys to the given set.
+func (s SchemaMapRepeat) KeysOneOf(set ...string) SchemaMapRepeat {
+	s.keySet = append([]string(nil), set...)
+	return s
+}
+
+// checkKey applies the KeysMatch and KeysOneOf restrictions.
+func (s SchemaMapRepeat) checkKey(key string, pos int) error {
+	if s.keyPattern != nil && !s.keyPattern.match(key, nil) {
+		return NewSchemaError(ErrStringPattern, SchemaMapRepeatName, key, pos,
+			StringErrorDetails{Expected: s.keyPattern.re.String(), Actual: key})
+	}
+	if s.keySet != nil && !slices.Contains(s.keySet, key) {
+		return NewSchemaError(ErrStringMatch, SchemaMapRepeatName, key, pos,
+			StringErrorDetails{Expected: strings.Join(s.keySet, "|"), Actual: key})
+	}
+	return nil
+}
+
+// peekKey checks the key at the current position without consuming it.
+func (s SchemaMapRepeat) peekKey(subseq *access.SeqGetAccess, pos int) error {
+	if s.keyPattern == nil && s.keySet == nil {
+		return nil
+	}
+	_, width, err := subseq.PeekTypeWidth()
+	if err != nil {
+		return NewSchemaError(ErrInvalidFormat, SchemaMapRepeatName, "", pos, err)
+	}
+	payload, err := subseq.GetPayload(width)
+	if err != nil {
+		return NewSchemaError(ErrInvalidFormat, SchemaMapRepeatName, "", pos, err)
+	}
+	return s.checkKey(string(payload), pos)
+}
+
+// keyAt returns the map key at index at for error paths, or "?" when it is
+// not a readable string. It moves subseq, so it is only called on failure.
+func keyAt(subseq *access.SeqGetAccess, at int) string {
+	if subseq.Seek(at) != nil {
+		return "?"
+	}
+	typ, width, err := subseq.PeekTypeWidth()
+	if err != nil || typ != typetags.TypeString {
+		return "?"
+	}
+	payload, err := subseq.GetPayload(width)
+	if err != nil {
+		return "?"
+	}
+	return string(payload)
+}
+
 func (s SchemaMapRepeat) Validate(seq *access.SeqGetAccess) error {
 	pos := seq.CurrentIndex()
 	w, err := precheck(SchemaMapRepeatName, pos, seq, typetags.TypeMap, -1, s.IsNullable())
@@ -2395,12 +3505,16 @@ func (s SchemaMapRepeat) Validate(seq *access.SeqGetAccess) error {
 		}
 
 		for i := 0; i < maxIter; i++ {
-
+			if err := s.peekKey(subseq, pos); err != nil {
+				return err
+			}
+			at := subseq.CurrentIndex()
 			if err := s.Key.Validate(subseq); err != nil {
-				return NewSchemaError(ErrInvalidFormat, SchemaMapRepeatName, "", pos, err)
+				return nestedError(ErrInvalidFormat, SchemaMapRepeatName, "", pos, keyAt(subseq, at), err)
 			}
 			if err := s.Value.Validate(subseq); err != nil {
-				return NewSchemaError(ErrInvalidFormat, SchemaMapRepeatName, "", pos, err)
+				key := keyAt(subseq, at)
+				return nestedError(ErrInvalidFormat, SchemaMapRepeatName, key, pos, key, err)
 			}
 		}
 	}
@@ -2438,13 +3552,18 @@ func (s SchemaMapRepeat) Decode(seq *access.SeqGetAccess) (any, error) {
 		}
 		out = make(map[string]any, pairCount)
 		for i := 0; i < maxIter; i++ {
+			if err := s.peekKey(subseq, pos); err != nil {
+				return nil, err
+			}
+			at := subseq.CurrentIndex()
 			k, err := s.Key.Decode(subseq)
 			if err != nil {
-				return nil, NewSchemaError(ErrInvalidFormat, SchemaMapRepeatName, "", pos, err)
+				return nil, nestedError(ErrInvalidFormat, SchemaMapRepeatName, "", pos, keyAt(subseq, at), err)
 			}
 			v, err := s.Value.Decode(subseq)
 			if err != nil {
-				return nil, NewSchemaError(ErrInvalidFormat, SchemaMapRepeatName, "", pos, err)
+				key := keyAt(subseq, at)
+				return nil, nestedError(ErrInvalidFormat, SchemaMapRepeatName, key, pos, key, err)
 			}
 			if keyStr, ok := k.(string); ok {
 				out[keyStr] = v
@@ -2472,11 +3591,35 @@ func (s SchemaMapRepeat) Encode(put *access.PutAccess, val any) error {
 		return NewSchemaError(ErrEncode, SchemaMapRepeatName, "", -1, ErrTypeMisMatch)
 	}
 
+	// keys are sorted as they are written
+	if key, ok := s.Key.(SchemaString); ok && key.NFC {
+		normalized := make(map[string]any, len(mapKV))
+		for k, v := range mapKV {
+			nk := key.normalize(k)
+			if _, dup := normalized[nk]; dup {
+				return NewSchemaError(ErrEncode, SchemaMapRepeatName, k, -1, errSameNFC)
+			}
+			normalized[nk] = v
+		}
+		mapKV = normalized
+	}
+
 	nested := put.BeginMap()
 	defer put.EndNested(nested)
 
+	// no declared order here; sorted keys keep the output reproducible
+	keys := make([]string, 0, len(mapKV))
+	for key := range mapKV {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
 	count := 0
-	for key, v := range mapKV {
+	for _, key := range keys {
+		v := mapKV[key]
+		if err := s.checkKey(key, -1); err != nil {
+			return err
+		}
 		// Encode key
 		if err := s.Key.Encode(nested, key); err != nil {
 			return NewSchemaError(ErrEncode, SchemaMapRepeatName, key, -1, err)
@@ -2508,10 +3651,223 @@ func (s SchemaMapRepeat) Encode(put *access.PutAccess, val any) error {
 	return nil
 }
 
+// SchemaMapSortedKeys is a map whose keys share KeyPrefix and are strictly
+// ascending on the wire, which binary-search readers rely on.
+// Value validates every value; nil accepts any value.
+type SchemaMapSortedKeys struct {
+	KeyPrefix string
+	Value     Schema
+	min       int
+	max       int
+}
+
+// SMapSortedKeys builds a sorted-key map schema holding min..max entries.
+// A negative min or max leaves that end unbounded.
+func SMapSortedKeys(keyPrefix string, min, max int) SchemaMapSortedKeys {
+	if min < 0 {
+		min = -1
+	}
+	if max < 0 {
+		max = -1
+	}
+	return SchemaMapSortedKeys{KeyPrefix: keyPrefix, min: min, max: max}
+}
+
+// WithValue returns a copy that validates values with v.
+func (s SchemaMapSortedKeys) WithValue(v Schema) SchemaMapSortedKeys {
+	s.Value = v
+	return s
+}
+
+func (s SchemaMapSortedKeys) IsNullable() bool {
+	return s.min <= 0
+}
+
+func (s SchemaMapSortedKeys) checkCount(count int, pos int) error {
+	if (s.min != -1 && count < s.min) || (s.max != -1 && count > s.max) {
+		var min, max *int64
+		if s.min != -1 {
+			min = PtrToInt64(s.min)
+		}
+		if s.max != -1 {
+			max = PtrToInt64(s.max)
+		}
+		return NewSchemaError(ErrConstraintViolated, SchemaMapSortedKeysName, "", pos,
+			RangeErrorDetails[int64]{Min: min, Max: max, Actual: int64(count)})
+	}
+	return nil
+}
+
+func (s SchemaMapSortedKeys) checkKey(prev, key string, first bool, pos int) error {
+	if !strings.HasPrefix(key, s.KeyPrefix) {
+		return NewSchemaError(ErrStringPrefix, SchemaMapSortedKeysName, key, pos,
+			StringErrorDetails{Expected: s.KeyPrefix, Actual: key})
+	}
+	if !first && key <= prev {
+		return NewSchemaError(ErrKeyOrder, SchemaMapSortedKeysName, key, pos,
+			KeyOrderErrorDetails{Previous: prev, Key: key})
+	}
+	return nil
+}
+
+// walk checks count, prefix and order, and hands each value to fn.
+func (s SchemaMapSortedKeys) walk(seq *access.SeqGetAccess, fn func(key string, subseq *access.SeqGetAccess) error) error {
+	pos := seq.CurrentIndex()
+	w, err := precheck(SchemaMapSortedKeysName, pos, seq, typetags.TypeMap, 0, s.IsNullable())
+	if err != nil {
+		return err
+	}
+	count := 0
+	if w != 0 {
+		subseq, err := seq.PeekNestedSeq()
+		if err != nil {
+			return NewSchemaError(ErrInvalidFormat, SchemaMapSortedKeysName, "", pos, err)
+		}
+		if subseq.ArgCount()%2 != 0 {
+			return NewSchemaError(ErrInvalidFormat, SchemaMapSortedKeysName, "", pos,
+				fmt.Errorf("map has odd field count %d", subseq.ArgCount()))
+		}
+		count = subseq.ArgCount() / 2
+		if err := s.checkCount(count, pos); err != nil {
+			return err
+		}
+		prev := ""
+		for i := 0; i < count; i++ {
+			payload, typ, err := subseq.Next()
+			if err != nil {
+				return NewSchemaError(ErrInvalidFormat, SchemaMapSortedKeysName, "", pos, err)
+			}
+			if typ != typetags.TypeString {
+				return NewSchemaError(ErrConstraintViolated, SchemaMapSortedKeysName, "", pos, ErrTypeMisMatch)
+			}
+			key := string(payload)
+			if err := s.checkKey(prev, key, i == 0, pos); err != nil {
+				return err
+			}
+			if err := fn(key, subseq); err != nil {
+				return nestedError(ErrInvalidFormat, SchemaMapSortedKeysName, key, pos, key, err)
+			}
+			prev = key
+		}
+	} else if err := s.checkCount(0, pos); err != nil {
+		return err
+	}
+	if err := seq.Advance(); err != nil {
+		return NewSchemaError(ErrUnexpectedEOF, SchemaMapSortedKeysName, "", pos, err)
+	}
+	return nil
+}
+
+func (s SchemaMapSortedKeys) Validate(seq *access.SeqGetAccess) error {
+	return s.walk(seq, func(_ string, subseq *access.SeqGetAccess) error {
+		if s.Value == nil {
+			return subseq.Advance()
+		}
+		return s.Value.Validate(subseq)
+	})
+}
+
+func (s SchemaMapSortedKeys) Decode(seq *access.SeqGetAccess) (any, error) {
+	var out map[string]any
+	err := s.walk(seq, func(key string, subseq *access.SeqGetAccess) error {
+		var v any
+		var err error
+		if s.Value == nil {
+			v, err = SType(mustPeekType(subseq)).Decode(subseq)
+		} else {
+			v, err = s.Value.Decode(subseq)
+		}
+		if err != nil {
+			return err
+		}
+		if out == nil {
+			out = make(map[string]any)
+		}
+		out[key] = v
+		return nil
+	})
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Encode writes a map[string]any in sorted key order. An ordered map is written
+// in its own order and fails when that order is not strictly ascending.
+func (s SchemaMapSortedKeys) Encode(put *access.PutAccess, val any) error {
+	if s.IsNullable() && val == nil {
+		put.AddMap(nil)
+		return nil
+	}
+	var keys []string
+	var get func(string) any
+	switch m := val.(type) {
+	case map[string]any:
+		keys = utils.SortKeys(m)
+		get = func(k string) any { return m[k] }
+	case *typetags.OrderedMapAny:
+		keys = m.Keys()
+		get = func(k string) any { v, _ := m.Get(k); return v }
+	default:
+		return NewSchemaError(ErrEncode, SchemaMapSortedKeysName, "", -1, ErrTypeMisMatch)
+	}
+	if err := s.checkCount(len(keys), -1); err != nil {
+		return err
+	}
+	for i, k := range keys {
+		prev := ""
+		if i > 0 {
+			prev = keys[i-1]
+		}
+		if err := s.checkKey(prev, k, i == 0, -1); err != nil {
+			return err
+		}
+	}
+
+	nested := put.BeginMap()
+	defer put.EndNested(nested)
+	for _, k := range keys {
+		nested.AddString(k)
+		v := get(k)
+		var err error
+		if s.Value == nil {
+			err = nested.AddAny(v, false)
+		} else {
+			err = s.Value.Encode(nested, v)
+		}
+		if err != nil {
+			return NewSchemaError(ErrEncode, SchemaMapSortedKeysName, k, -1, err)
+		}
+	}
+	return nil
+}
+
+// mustPeekType returns the type tag of the next field, or TypeInvalid when there is none.
+func mustPeekType(seq *access.SeqGetAccess) typetags.Type {
+	typ, _, err := seq.PeekTypeWidth()
+	if err != nil {
+		return typetags.TypeInvalid
+	}
+	return typ
+}
+
 type SchemaNumber struct {
 	DecodeAsString bool
 	Min            *float64
 	Max            *float64
+	ExclusiveMin   bool
+	ExclusiveMax   bool
+	MultipleOf     *float64
+	// Epsilon is the tolerance of the Min and Max comparisons; see
+	// CheckFloatBounds.
+	Epsilon float64
+}
+
+// WithMultipleOf returns s requiring values to be multiples of n, such as
+// 0.05 for prices, within a small tolerance for binary rounding.
+func (s SchemaNumber) WithMultipleOf(n float64) SchemaNumber {
+	s.MultipleOf = &n
+	return s
 }
 
 func (s SchemaNumber) IsNullable() bool {
@@ -2529,7 +3885,7 @@ func (s SchemaNumber) DecodeValidate(seq *access.SeqGetAccess, decodeAlways bool
 	}
 
 	// If no range constraints and not decoding, skip decodePrimitive entirely
-	if s.Min == nil && s.Max == nil && !decodeAlways {
+	if s.Min == nil && s.Max == nil && s.MultipleOf == nil && !decodeAlways {
 		return nil, nil
 	}
 
@@ -2554,10 +3910,13 @@ func (s SchemaNumber) DecodeValidate(seq *access.SeqGetAccess, decodeAlways bool
 
 	// Range check if constraints exist
 	if s.Min != nil || s.Max != nil {
-		if err := CheckFloatRange(f, s.Min, s.Max); err != nil {
+		if err := CheckFloatBounds(f, s.Min, s.Max, s.ExclusiveMin, s.ExclusiveMax, s.Epsilon); err != nil {
 			return nil, NewSchemaError(ErrOutOfRange, SchemaNumberName, "", pos, err)
 		}
 	}
+	if err := CheckFloatMultipleOf(f, s.MultipleOf); err != nil {
+		return nil, NewSchemaError(ErrNotMultipleOf, SchemaNumberName, "", pos, err)
+	}
 
 	if s.DecodeAsString {
 		return fmt.Sprintf("%v", f), nil
@@ -2591,9 +3950,12 @@ func (s SchemaNumber) Encode(put *access.PutAccess, val any) error {
 		return NewSchemaError(ErrEncode, SchemaNumberName, "", -1, ErrUnsupportedType)
 	}
 
-	if err := CheckFloatRange(f, s.Min, s.Max); err != nil {
+	if err := CheckFloatBounds(f, s.Min, s.Max, s.ExclusiveMin, s.ExclusiveMax, s.Epsilon); err != nil {
 		return NewSchemaError(ErrOutOfRange, SchemaNumberName, "", -1, err)
 	}
+	if err := CheckFloatMultipleOf(f, s.MultipleOf); err != nil {
+		return NewSchemaError(ErrNotMultipleOf, SchemaNumberName, "", -1, err)
+	}
 
 	put.AddNumeric(f)
 	return nil
@@ -0,0 +1,194 @@
+package schema
+
+import (
+	"encoding/binary"
+	"reflect"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const SchemaArrayName = "SchemaArray"
+
+// SchemaArray is a typed numeric array packed with access.AddArray, such as
+// the fields written by PackInt32Array or PutAccess.AddFloat64Array. Every
+// element is checked against Elem, so ranges and other numeric constraints
+// apply per element. Decode returns []int16, []int32, []int64, []float32 or
+// []float64 according to the element type on the wire. Encode accepts any Go
+// slice or array whose elements Elem can encode to a fixed-width number;
+// all elements must encode to the same type. Use SRepeat for arrays of
+// strings or containers.
+type SchemaArray struct {
+	Elem     Schema
+	Min, Max int // element count bounds; negative means unbounded
+	Nullable bool
+}
+
+// SArray builds a SchemaArray of elem with min to max elements. Pass -1 for
+// an unbounded side.
+func SArray(elem Schema, min, max int) SchemaArray {
+	return SchemaArray{Elem: elem, Min: min, Max: max}
+}
+
+func (s SchemaArray) IsNullable() bool { return s.Nullable }
+
+func (s SchemaArray) Validate(seq *access.SeqGetAccess) error {
+	_, err := s.decode(seq, false)
+	return err
+}
+
+func (s SchemaArray) Decode(seq *access.SeqGetAccess) (any, error) {
+	return s.decode(seq, true)
+}
+
+func (s SchemaArray) decode(seq *access.SeqGetAccess, wantValue bool) (any, error) {
+	pos := seq.CurrentIndex()
+	payload, err := validatePrimitiveAndGetPayload(SchemaArrayName, seq, typetags.TypeByteArray, 0, s.Nullable)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) == 0 {
+		if s.Nullable {
+			return nil, nil
+		}
+		return nil, NewSchemaError(ErrConstraintViolated, SchemaArrayName, "", pos, ErrTypeMisMatch)
+	}
+	tag, width, count, err := access.ArrayLayout(payload)
+	if err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, SchemaArrayName, "", pos, err)
+	}
+	if err := s.checkCount(pos, count); err != nil {
+		return nil, err
+	}
+
+	// Run Elem over each element through a one-field buffer so that the
+	// element schemas see an ordinary field.
+	elemBuf := make([]byte, 4+width)
+	binary.LittleEndian.PutUint16(elemBuf, typetags.EncodeHeader(4, tag))
+	binary.LittleEndian.PutUint16(elemBuf[2:], typetags.EncodeHeader(width, typetags.TypeEnd))
+	head, err := access.NewSeqGetAccess(elemBuf)
+	if err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, SchemaArrayName, "", pos, err)
+	}
+	for i := 0; i < count; i++ {
+		copy(elemBuf[4:], payload[i*width:])
+		elem := *head
+		if err := s.Elem.Validate(&elem); err != nil {
+			return nil, NewSchemaError(ErrInvalidFormat, SchemaArrayName, "", pos, err)
+		}
+	}
+	if !wantValue {
+		return nil, nil
+	}
+
+	switch {
+	case tag == typetags.TypeInteger && width == 2:
+		return access.DecodeArray[int16](payload)
+	case tag == typetags.TypeInteger && width == 4:
+		return access.DecodeArray[int32](payload)
+	case tag == typetags.TypeInteger:
+		return access.DecodeArray[int64](payload)
+	case width == 4:
+		return access.DecodeArray[float32](payload)
+	default:
+		return access.DecodeArray[float64](payload)
+	}
+}
+
+func (s SchemaArray) checkCount(pos, count int) error {
+	if (s.Min >= 0 && count < s.Min) || (s.Max >= 0 && count > s.Max) {
+		return NewSchemaError(ErrConstraintViolated, SchemaArrayName, "", pos, RangeErrorDetails[int64]{
+			Min:    PtrToInt64(s.Min),
+			Max:    PtrToInt64(s.Max),
+			Actual: int64(count),
+		})
+	}
+	return nil
+}
+
+func (s SchemaArray) Encode(put *access.PutAccess, val any) error {
+	if val == nil {
+		if !s.Nullable {
+			return NewSchemaError(ErrEncode, SchemaArrayName, "", -1, ErrTypeMisMatch)
+		}
+		put.AddBytes(nil)
+		return nil
+	}
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return NewSchemaError(ErrEncode, SchemaArrayName, "", -1, ErrTypeMisMatch)
+	}
+	if rv.Kind() == reflect.Slice && rv.IsNil() && s.Nullable {
+		put.AddBytes(nil)
+		return nil
+	}
+	n := rv.Len()
+	if err := s.checkCount(-1, n); err != nil {
+		return err
+	}
+
+	scratch := access.NewPutAccessFromPool()
+	defer access.ReleasePutAccess(scratch)
+	for i := 0; i < n; i++ {
+		if err := s.Elem.Encode(scratch, rv.Index(i).Interface()); err != nil {
+			return NewSchemaError(ErrEncode, SchemaArrayName, "", i, err)
+		}
+	}
+	if n == 0 {
+		// the descriptor still needs an element type
+		if err := s.encodeZero(scratch, rv.Type().Elem()); err != nil {
+			return NewSchemaError(ErrEncode, SchemaArrayName, "", -1, err)
+		}
+	}
+	elems, err := access.NewSeqGetAccess(scratch.Pack())
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaArrayName, "", -1, err)
+	}
+
+	var payload []byte
+	var tag typetags.Type
+	var width int
+	for i := 0; i < elems.ArgCount(); i++ {
+		typ, w, err := elems.PeekTypeWidth()
+		if err != nil {
+			return NewSchemaError(ErrEncode, SchemaArrayName, "", i, err)
+		}
+		if i == 0 {
+			tag, width = typ, w
+		} else if typ != tag || w != width {
+			return NewSchemaError(ErrEncode, SchemaArrayName, "", i, SizeExact{width, w})
+		}
+		b, err := elems.GetPayload(w)
+		if err != nil {
+			return NewSchemaError(ErrEncode, SchemaArrayName, "", i, err)
+		}
+		if n > 0 {
+			payload = append(payload, b...)
+		}
+		if err := elems.Advance(); err != nil {
+			return NewSchemaError(ErrEncode, SchemaArrayName, "", i, err)
+		}
+	}
+	d, err := access.ArrayDescriptor(tag, width)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaArrayName, "", -1, err)
+	}
+	put.AppendTagAndValue(typetags.TypeByteArray, append(payload, d))
+	return nil
+}
+
+// encodeZero encodes one zero element so an empty array can be given the
+// element type Elem produces. For interface element types each numeric zero
+// is tried in turn.
+func (s SchemaArray) encodeZero(put *access.PutAccess, t reflect.Type) error {
+	if t.Kind() != reflect.Interface {
+		return s.Elem.Encode(put, reflect.Zero(t).Interface())
+	}
+	var err error
+	for _, zero := range []any{int64(0), int32(0), int16(0), float64(0), float32(0)} {
+		if err = s.Elem.Encode(put, zero); err == nil {
+			return nil
+		}
+	}
+	return err
+}
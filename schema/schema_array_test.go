@@ -0,0 +1,101 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/access"
+	pack "github.com/quickwritereader/PackOS/packable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSArray_DecodesTypedSlices(t *testing.T) {
+	chain := SChain(SArray(SInt32, 1, 4), SArray(SFloat64, -1, -1))
+	buf := pack.Pack(pack.PackInt32Array([]int32{3, -1, 7}), pack.PackFloat64Array([]float64{}))
+
+	require.NoError(t, ValidateBuffer(buf, chain))
+	v, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, []any{[]int32{3, -1, 7}, []float64{}}, v)
+}
+
+func TestSArray_ElementConstraints(t *testing.T) {
+	chain := SChain(SArray(SInt16.RangeValues(0, 10), -1, -1))
+	require.NoError(t, ValidateBuffer(pack.Pack(pack.PackInt16Array([]int16{0, 10})), chain))
+
+	err := ValidateBuffer(pack.Pack(pack.PackInt16Array([]int16{0, 11})), chain)
+	assertSchemaError(t, err, ErrInvalidFormat)
+	var se *SchemaError
+	require.True(t, errors.As(err, &se))
+	assertSchemaError(t, se.InnerErr, ErrOutOfRange)
+
+	_, err = EncodeValue([]int16{-1}, chain)
+	require.Error(t, err)
+}
+
+func TestSArray_CountAndTypeChecks(t *testing.T) {
+	chain := SChain(SArray(SInt32, 1, 2))
+	err := ValidateBuffer(pack.Pack(pack.PackInt32Array([]int32{1, 2, 3})), chain)
+	assertSchemaError(t, err, ErrConstraintViolated)
+	err = ValidateBuffer(pack.Pack(pack.PackInt64Array([]int64{1})), chain)
+	assertSchemaError(t, err, ErrInvalidFormat)
+	err = ValidateBuffer(pack.Pack(pack.PackByteArray([]byte{1, 2, 3})), chain)
+	assertSchemaError(t, err, ErrInvalidFormat)
+	err = ValidateBuffer(pack.Pack(pack.PackInt32Array(nil)), chain)
+	assertSchemaError(t, err, ErrConstraintViolated)
+	_, err = EncodeValue([]int32{}, chain)
+	require.Error(t, err)
+}
+
+func TestSArray_EncodeFromGoSlices(t *testing.T) {
+	chain := SChain(SArray(SInt64, -1, -1))
+	for _, in := range []any{[]int64{1, 2}, []any{int64(1), int64(2)}, [2]int64{1, 2}} {
+		buf, err := EncodeValue(in, chain)
+		require.NoError(t, err, in)
+		got, err := access.NewGetAccess(buf).GetInt64Slice(0)
+		require.NoError(t, err, in)
+		assert.Equal(t, []int64{1, 2}, got, in)
+	}
+
+	buf, err := EncodeValue([]float32{}, SChain(SArray(SFloat32, -1, -1)))
+	require.NoError(t, err)
+	f32, err := access.NewGetAccess(buf).GetFloat32Slice(0)
+	require.NoError(t, err)
+	assert.Equal(t, []float32{}, f32)
+
+	buf, err = EncodeValue([]any{}, SChain(SArray(SInt16, -1, -1)))
+	require.NoError(t, err)
+	i16, err := access.NewGetAccess(buf).GetInt16Slice(0)
+	require.NoError(t, err)
+	assert.Equal(t, []int16{}, i16)
+
+	_, err = EncodeValue("nope", chain)
+	require.Error(t, err)
+	_, err = EncodeValue([]string{"a"}, SChain(SArray(SString, -1, -1)))
+	require.Error(t, err, "strings are not fixed-width numbers")
+
+	nullable := SChain(SchemaArray{Elem: SInt32, Min: -1, Max: -1, Nullable: true})
+	buf, err = EncodeValue(nil, nullable)
+	require.NoError(t, err)
+	v, err := DecodeBuffer(buf, nullable)
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestBuildSchema_Array(t *testing.T) {
+	js := SchemaJSON{Type: "array", Max: PtrToInt64(3), Schema: []SchemaJSON{{Type: "float32"}}}
+	chain := SChain(BuildSchema(&js))
+	buf, err := EncodeValue([]float32{0.5, 2}, chain)
+	require.NoError(t, err)
+	v, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, []float32{0.5, 2}, v)
+	_, err = EncodeValue([]float32{1, 2, 3, 4}, chain)
+	require.Error(t, err)
+
+	fcs := ExtractConstraints(&SchemaJSON{Type: "tuple", FieldNames: []string{"xs"}, Schema: []SchemaJSON{js}})
+	require.Len(t, fcs, 2)
+	assert.Equal(t, 3, *fcs[0].MaxItems)
+	assert.Equal(t, "xs[]", fcs[1].Path)
+}
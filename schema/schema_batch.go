@@ -0,0 +1,101 @@
+package schema
+
+import (
+	"encoding/binary"
+	"strconv"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const BatchName = "Batch"
+
+// EncodeBatch packs records that share chain into one buffer, a tuple of
+// records each written as a nested tuple, as EncodeValue would write it.
+// The batch interns its strings (see access.PutAccess.SetInterning), so map
+// keys and repeated values such as string enums are stored once in a
+// dictionary shared by all records and referenced from each of them, which
+// shrinks a batch well below the records packed one by one.
+func EncodeBatch(records []any, chain SchemaChain) ([]byte, error) {
+	put := access.NewPutAccessFromPool()
+	defer access.ReleasePutAccess(put)
+	put.SetInterning(true)
+	for i, rec := range records {
+		nested := put.BeginTuple()
+		err := encodeChain(nested, rec, chain)
+		put.EndNested(nested)
+		if err != nil {
+			return nil, nestedError(ErrEncode, BatchName, "", i, strconv.Itoa(i), err)
+		}
+	}
+	return put.Pack(), nil
+}
+
+// DecodeBatch decodes the records of a buffer written by EncodeBatch, each
+// as DecodeBuffer would.
+func DecodeBatch(buf []byte, chain SchemaChain) ([]any, error) {
+	if emptyBatch(buf) {
+		return []any{}, nil
+	}
+	seq, err := access.NewSeqGetAccess(buf)
+	if err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, BatchName, "", -1, err)
+	}
+	out := make([]any, 0, seq.ArgCount())
+	err = eachRecord(seq, func(rec *access.SeqGetAccess) error {
+		v, err := decodeSeq(rec, chain)
+		out = append(out, v)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ValidateBatch validates every record of a buffer written by EncodeBatch.
+func ValidateBatch(buf []byte, chain SchemaChain) error {
+	if emptyBatch(buf) {
+		return nil
+	}
+	seq, err := access.NewSeqGetAccess(buf)
+	if err != nil {
+		return NewSchemaError(ErrInvalidFormat, BatchName, "", -1, err)
+	}
+	return eachRecord(seq, func(rec *access.SeqGetAccess) error {
+		return validateSeq(rec, chain)
+	})
+}
+
+// emptyBatch reports whether buf is the batch of no records, a lone end
+// header, which SeqGetAccess does not accept.
+func emptyBatch(buf []byte) bool {
+	if len(buf) != 2 {
+		return false
+	}
+	base, _ := typetags.DecodeHeader(binary.LittleEndian.Uint16(buf))
+	return base == 2
+}
+
+func eachRecord(seq *access.SeqGetAccess, fn func(*access.SeqGetAccess) error) error {
+	for i := 0; i < seq.ArgCount(); i++ {
+		typ, _, err := seq.PeekTypeWidth()
+		if err != nil {
+			return NewSchemaError(ErrUnexpectedEOF, BatchName, "", i, err)
+		}
+		if typ != typetags.TypeTuple {
+			return NewSchemaError(ErrInvalidFormat, BatchName, "", i, ErrTypeMisMatch)
+		}
+		rec, err := seq.PeekNestedSeq()
+		if err != nil {
+			return NewSchemaError(ErrInvalidFormat, BatchName, "", i, err)
+		}
+		if err := fn(rec); err != nil {
+			return nestedError(ErrInvalidFormat, BatchName, "", i, strconv.Itoa(i), err)
+		}
+		if err := seq.Advance(); err != nil {
+			return NewSchemaError(ErrUnexpectedEOF, BatchName, "", i, err)
+		}
+	}
+	return nil
+}
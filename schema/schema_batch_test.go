@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch_RoundTripAndSize(t *testing.T) {
+	chain := SChain(SMapUnorderedNamed([]string{"level", "service", "msg"},
+		SString, SString, SString))
+	var records []any
+	single := 0
+	for i := 0; i < 50; i++ {
+		rec := map[string]any{"level": "warning", "service": "payments-api", "msg": fmt.Sprintf("retry %d", i)}
+		records = append(records, rec)
+		buf, err := EncodeValue(rec, chain)
+		require.NoError(t, err)
+		single += len(buf)
+	}
+
+	batch, err := EncodeBatch(records, chain)
+	require.NoError(t, err)
+	assert.Less(t, len(batch), single*2/3)
+	require.NoError(t, ValidateBatch(batch, chain))
+	got, err := DecodeBatch(batch, chain)
+	require.NoError(t, err)
+	assert.Equal(t, records, got)
+
+	empty, err := EncodeBatch(nil, chain)
+	require.NoError(t, err)
+	got, err = DecodeBatch(empty, chain)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestBatch_Errors(t *testing.T) {
+	chain := SChain(SInt16, SString)
+	_, err := EncodeBatch([]any{[]any{int16(1), "a"}, []any{"x", "b"}}, chain)
+	require.Error(t, err)
+	assert.Equal(t, "1", ErrorPath(err)[0])
+
+	batch, err := EncodeBatch([]any{[]any{int16(1), "a"}, []any{int16(2), "b"}}, chain)
+	require.NoError(t, err)
+	got, err := DecodeBatch(batch, chain)
+	require.NoError(t, err)
+	assert.Equal(t, []any{[]any{int16(1), "a"}, []any{int16(2), "b"}}, got)
+
+	err = ValidateBatch(batch, SChain(SString, SString))
+	require.Error(t, err)
+	assert.Equal(t, []string{"0", "0"}, ErrorPath(err))
+
+	notBatch, err := EncodeValue(int16(1), SChain(SInt16))
+	require.NoError(t, err)
+	assert.Error(t, ValidateBatch(notBatch, chain))
+}
@@ -0,0 +1,102 @@
+package schema
+
+import (
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const SchemaBitsetName = "SchemaBitset"
+
+// SchemaBitset is a bitset of any length packed with access.AddBitset.
+// Unlike SchemaMultiCheckNames the bits are unnamed and sparse sets are
+// compressed. Decode returns *typetags.Bitset. Encode accepts
+// *typetags.Bitset, typetags.Bitset, []bool, and a list of set positions as
+// []int or []any. When Len is positive the bitset must have exactly Len bits;
+// position lists are then given that length.
+type SchemaBitset struct {
+	Nullable bool
+	Len      int
+}
+
+var (
+	SBitset     = SchemaBitset{}
+	SNullBitset = SchemaBitset{Nullable: true}
+)
+
+// SBitsetLen is a bitset of exactly n bits.
+func SBitsetLen(n int) SchemaBitset { return SchemaBitset{Len: n} }
+
+func (s SchemaBitset) IsNullable() bool { return s.Nullable }
+
+func (s SchemaBitset) Validate(seq *access.SeqGetAccess) error {
+	_, err := s.Decode(seq)
+	return err
+}
+
+func (s SchemaBitset) Decode(seq *access.SeqGetAccess) (any, error) {
+	pos := seq.CurrentIndex()
+	payload, err := validatePrimitiveAndGetPayload(SchemaBitsetName, seq, typetags.TypeByteArray, 0, s.Nullable)
+	if err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		if s.Nullable {
+			return nil, nil
+		}
+		return nil, NewSchemaError(ErrConstraintViolated, SchemaBitsetName, "", pos, ErrTypeMisMatch)
+	}
+	b, err := access.DecodeBitset(payload)
+	if err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, SchemaBitsetName, "", pos, err)
+	}
+	if s.Len > 0 && b.Len() != s.Len {
+		return nil, NewSchemaError(ErrConstraintViolated, SchemaBitsetName, "", pos, SizeExact{s.Len, b.Len()})
+	}
+	return b, nil
+}
+
+func (s SchemaBitset) Encode(put *access.PutAccess, val any) error {
+	if s.Nullable && val == nil {
+		put.AddBitset(nil)
+		return nil
+	}
+	var b *typetags.Bitset
+	switch v := val.(type) {
+	case *typetags.Bitset:
+		b = v
+	case typetags.Bitset:
+		b = &v
+	case []bool:
+		b = typetags.NewBitset(len(v))
+		for i, set := range v {
+			if set {
+				b.Set(i)
+			}
+		}
+	case []int:
+		b = typetags.NewBitset(s.Len)
+		for _, i := range v {
+			if i < 0 || i >= access.MaxBitsetBits {
+				return NewSchemaError(ErrEncode, SchemaBitsetName, "", -1, ErrTypeMisMatch)
+			}
+			b.Set(i)
+		}
+	case []any:
+		b = typetags.NewBitset(s.Len)
+		for _, item := range v {
+			i, ok := convertToNumber[int](item)
+			if !ok || i < 0 || i >= access.MaxBitsetBits {
+				return NewSchemaError(ErrEncode, SchemaBitsetName, "", -1, ErrTypeMisMatch)
+			}
+			b.Set(i)
+		}
+	}
+	if b == nil {
+		return NewSchemaError(ErrEncode, SchemaBitsetName, "", -1, ErrTypeMisMatch)
+	}
+	if s.Len > 0 && b.Len() != s.Len {
+		return NewSchemaError(ErrEncode, SchemaBitsetName, "", -1, SizeExact{s.Len, b.Len()})
+	}
+	put.AddBitset(b)
+	return nil
+}
@@ -0,0 +1,63 @@
+package schema
+
+import (
+	"testing"
+
+	pack "github.com/quickwritereader/PackOS/packable"
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaBitset_RoundTrip(t *testing.T) {
+	chain := SChain(SBitset)
+	for _, in := range []any{
+		typetags.BitsetOf(3000, 7, 2999),
+		*typetags.BitsetOf(3000, 7, 2999),
+		[]int{7, 2999},
+		[]any{7, 2999.0},
+	} {
+		buf, err := EncodeValue(in, chain)
+		require.NoError(t, err, in)
+		v, err := DecodeBuffer(buf, chain)
+		require.NoError(t, err, in)
+		require.IsType(t, &typetags.Bitset{}, v)
+		b := v.(*typetags.Bitset)
+		assert.Equal(t, []int{7, 2999}, b.Ones(), in)
+		assert.True(t, b.Test(2999))
+	}
+
+	buf, err := EncodeValue([]bool{true, false, true}, chain)
+	require.NoError(t, err)
+	v, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.True(t, typetags.BitsetOf(3, 0, 2).Equal(v.(*typetags.Bitset)))
+
+	_, err = EncodeValue("x", chain)
+	require.Error(t, err)
+	_, err = EncodeValue([]int{-1}, chain)
+	require.Error(t, err)
+}
+
+func TestSchemaBitset_Len(t *testing.T) {
+	chain := SChain(BuildSchema(&SchemaJSON{Type: "bitset", Width: 4096}))
+	buf, err := EncodeValue([]int{1, 4000}, chain)
+	require.NoError(t, err)
+	v, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, 4096, v.(*typetags.Bitset).Len())
+
+	_, err = EncodeValue([]int{4096}, chain)
+	require.Error(t, err)
+	err = ValidateBuffer(pack.Pack(pack.PackBitset(typetags.NewBitset(10))), chain)
+	assertSchemaError(t, err, ErrConstraintViolated)
+	err = ValidateBuffer(pack.Pack(pack.PackBitset(nil)), chain)
+	assertSchemaError(t, err, ErrConstraintViolated)
+
+	nullable := SChain(SNullBitset)
+	buf, err = EncodeValue(nil, nullable)
+	require.NoError(t, err)
+	v, err = DecodeBuffer(buf, nullable)
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
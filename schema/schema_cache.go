@@ -0,0 +1,139 @@
+package schema
+
+import (
+	"hash/maphash"
+	"sync"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const SchemaCachedName = "SchemaCached"
+
+// ValidationCache remembers fields that passed validation, keyed by a hash
+// of their type and payload, so that SchemaCached can skip identical
+// containers repeated across a batch. Only successes are remembered. Keys
+// are 64-bit hashes, so two different payloads of the same length share a
+// key with a chance of about 2^-64 per pair. It is safe for concurrent use.
+type ValidationCache struct {
+	mu         sync.Mutex
+	seed       maphash.Seed
+	maxEntries int
+	entries    map[cacheKey]struct{}
+	hits       int
+	misses     int
+}
+
+type cacheKey struct {
+	hash uint64
+	size int
+	tag  typetags.Type
+}
+
+// NewValidationCache returns a cache holding up to maxEntries fields. When
+// it is full it is cleared and starts over; maxEntries <= 0 means no limit.
+func NewValidationCache(maxEntries int) *ValidationCache {
+	return &ValidationCache{
+		seed:       maphash.MakeSeed(),
+		maxEntries: maxEntries,
+		entries:    map[cacheKey]struct{}{},
+	}
+}
+
+// Stats returns the number of lookups that were answered by the cache and
+// the number that were not.
+func (c *ValidationCache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// Reset empties the cache and its counters.
+func (c *ValidationCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[cacheKey]struct{}{}
+	c.hits, c.misses = 0, 0
+}
+
+func (c *ValidationCache) key(tag typetags.Type, payload []byte) cacheKey {
+	return cacheKey{hash: maphash.Bytes(c.seed, payload), size: len(payload), tag: tag}
+}
+
+func (c *ValidationCache) lookup(k cacheKey) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.entries[k]
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	return ok
+}
+
+func (c *ValidationCache) add(k cacheKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.entries = map[cacheKey]struct{}{}
+	}
+	c.entries[k] = struct{}{}
+}
+
+// SchemaCached validates with Schema unless Cache holds an identical field
+// that Schema accepted before, in which case the field is skipped. Use one
+// cache per wrapped schema: a field valid for one schema says nothing about
+// another. Wrap the schema of a whole container, not an SRepeat, which
+// tuples need to see unwrapped. Fields of interned buffers are always
+// validated, since their bytes can refer to the buffer's own dictionary.
+// Decode and Encode are those of Schema.
+type SchemaCached struct {
+	Schema Schema
+	Cache  *ValidationCache
+}
+
+// SCached wraps s so that validation results are remembered in cache.
+func SCached(s Schema, cache *ValidationCache) SchemaCached {
+	return SchemaCached{Schema: s, Cache: cache}
+}
+
+func (s SchemaCached) IsNullable() bool { return s.Schema.IsNullable() }
+
+func (s SchemaCached) Validate(seq *access.SeqGetAccess) error {
+	if seq.Interned() {
+		return s.Schema.Validate(seq)
+	}
+	pos := seq.CurrentIndex()
+	typ, width, err := seq.PeekTypeWidth()
+	if err != nil {
+		return s.Schema.Validate(seq)
+	}
+	payload, err := seq.GetPayload(width)
+	if err != nil {
+		return s.Schema.Validate(seq)
+	}
+	k := s.Cache.key(typ, payload)
+	if s.Cache.lookup(k) {
+		if err := seq.Advance(); err != nil {
+			return NewSchemaError(ErrUnexpectedEOF, SchemaCachedName, "", pos, err)
+		}
+		return nil
+	}
+	if err := s.Schema.Validate(seq); err != nil {
+		return err
+	}
+	// schemas like repeats may consume more than one field
+	if seq.CurrentIndex() == pos+1 {
+		s.Cache.add(k)
+	}
+	return nil
+}
+
+func (s SchemaCached) Decode(seq *access.SeqGetAccess) (any, error) {
+	return s.Schema.Decode(seq)
+}
+
+func (s SchemaCached) Encode(put *access.PutAccess, val any) error {
+	return s.Schema.Encode(put, val)
+}
@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingSchema counts the Validate calls that reach s.
+func countingSchema(s Schema, calls *int) Schema {
+	return SchemaGeneric{
+		ValidateFunc: func(seq *access.SeqGetAccess) error {
+			*calls++
+			return s.Validate(seq)
+		},
+		DecodeFunc:    s.Decode,
+		EncodeFunc:    s.Encode,
+		NullableCheck: s.IsNullable,
+	}
+}
+
+func TestSCached_SkipsRepeatedContainers(t *testing.T) {
+	meta := SMapUnordered(map[string]Schema{"region": SString, "tier": SInt16})
+	calls := 0
+	cache := NewValidationCache(0)
+	chain := SChain(SRepeat(0, -1, STuple(SInt64, SCached(countingSchema(meta, &calls), cache))))
+
+	var rows []any
+	for i := 0; i < 50; i++ {
+		rows = append(rows, []any{int64(i), map[string]any{"region": "eu-west", "tier": int16(i % 2)}})
+	}
+	buf, err := EncodeValue(rows, chain)
+	require.NoError(t, err)
+
+	require.NoError(t, ValidateBuffer(buf, chain))
+	assert.Equal(t, 2, calls, "one validation per distinct block")
+	hits, misses := cache.Stats()
+	assert.Equal(t, 48, hits)
+	assert.Equal(t, 2, misses)
+
+	got, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	want, err := DecodeBuffer(buf, SChain(SRepeat(0, -1, STuple(SInt64, meta))))
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	cache.Reset()
+	hits, misses = cache.Stats()
+	assert.Zero(t, hits+misses)
+}
+
+func TestSCached_FailuresAreNotCached(t *testing.T) {
+	cache := NewValidationCache(1)
+	chain := SChain(SCached(STuple(SInt16), cache), SCached(STuple(SInt16), cache))
+
+	put := access.NewPutAccess()
+	require.NoError(t, put.AddAnyTuple([]any{"x"}, false))
+	require.NoError(t, put.AddAnyTuple([]any{"x"}, false))
+	bad := put.Pack()
+	assert.Error(t, ValidateBuffer(bad, chain))
+	assert.Error(t, ValidateBuffer(bad, chain))
+	hits, _ := cache.Stats()
+	assert.Zero(t, hits)
+
+	good, err := EncodeValue([]any{[]any{int16(1)}, []any{int16(2)}}, chain)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(good, chain))
+	require.NoError(t, ValidateBuffer(good, chain))
+	hits, _ = cache.Stats()
+	// a one-entry cache is cleared before each new entry
+	assert.Equal(t, 0, hits)
+}
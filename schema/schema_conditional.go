@@ -0,0 +1,157 @@
+package schema
+
+import (
+	"reflect"
+
+	"github.com/quickwritereader/PackOS/access"
+)
+
+const SchemaConditionalName = "SchemaConditional"
+
+// SchemaConditional reads a field of a named tuple or unordered map with
+// Then when the sibling Field satisfies When, and with Else otherwise, as in
+// "expiry is required when payment_type is card". When sees the decoded
+// sibling, or nil when the sibling is missing, null or invalid. In a named
+// tuple only fields before the conditional one are visible; in a map the
+// sibling may appear anywhere, but conditional fields do not see each other.
+// Encode looks the sibling up in the value being encoded. Used anywhere
+// else, or under a wrapper such as SRequired, a conditional has no siblings
+// and When always sees nil.
+type SchemaConditional struct {
+	Field      string
+	When       func(any) bool
+	Then, Else Schema
+}
+
+// SIf builds a SchemaConditional choosing then or els by the value of the
+// sibling field.
+func SIf(field string, when func(any) bool, then, els Schema) SchemaConditional {
+	return SchemaConditional{Field: field, When: when, Then: then, Else: els}
+}
+
+// Equals returns a predicate for SIf matching values deeply equal to want.
+// Compare with the decoded type, such as int16 for SInt16 or string for
+// SEnum.
+func Equals(want any) func(any) bool {
+	return func(v any) bool { return reflect.DeepEqual(v, want) }
+}
+
+// siblingLookup returns the decoded value of the named sibling, or nil.
+type siblingLookup func(name string) any
+
+func (s SchemaConditional) resolve(sibling siblingLookup) Schema {
+	var v any
+	if sibling != nil {
+		v = sibling(s.Field)
+	}
+	if s.When(v) {
+		return s.Then
+	}
+	return s.Else
+}
+
+func (s SchemaConditional) IsNullable() bool {
+	return s.Then.IsNullable() || s.Else.IsNullable()
+}
+
+func (s SchemaConditional) Validate(seq *access.SeqGetAccess) error {
+	return s.resolve(nil).Validate(seq)
+}
+
+func (s SchemaConditional) Decode(seq *access.SeqGetAccess) (any, error) {
+	return s.resolve(nil).Decode(seq)
+}
+
+func (s SchemaConditional) Encode(put *access.PutAccess, val any) error {
+	return s.resolve(nil).Encode(put, val)
+}
+
+// resolveField returns sch, or the branch sch selects when it is a
+// conditional.
+func resolveField(sch Schema, sibling siblingLookup) Schema {
+	if c, ok := sch.(SchemaConditional); ok {
+		return c.resolve(sibling)
+	}
+	return sch
+}
+
+func hasConditional(schemas []Schema) bool {
+	for _, sch := range schemas {
+		if _, ok := sch.(SchemaConditional); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func hasConditionalField(fields map[string]Schema) bool {
+	for _, sch := range fields {
+		if _, ok := sch.(SchemaConditional); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionalSiblings drops the conditional fields from fields, since they
+// do not see each other.
+func conditionalSiblings(fields map[string]fieldAt) map[string]fieldAt {
+	out := make(map[string]fieldAt, len(fields))
+	for k, f := range fields {
+		if _, ok := f.schema.(SchemaConditional); !ok {
+			out[k] = f
+		}
+	}
+	return out
+}
+
+// fieldAt is where a field starts in a sequence and the schema that reads it.
+type fieldAt struct {
+	pos    int
+	schema Schema
+}
+
+// seqSiblings looks siblings up by decoding them again from seq, which is
+// left where it was.
+func seqSiblings(seq *access.SeqGetAccess, fields map[string]fieldAt) siblingLookup {
+	return func(name string) any {
+		f, ok := fields[name]
+		if !ok {
+			return nil
+		}
+		cp := seq.Checkpoint()
+		defer func() { _ = seq.Restore(cp) }()
+		if seq.Seek(f.pos) != nil {
+			return nil
+		}
+		v, err := f.schema.Decode(seq)
+		if err != nil {
+			return nil
+		}
+		return v
+	}
+}
+
+// mapSiblings looks siblings up in a map being encoded or decoded, hiding
+// the fields that are conditional themselves.
+func mapSiblings(m map[string]any, fields map[string]Schema) siblingLookup {
+	return func(name string) any {
+		if _, ok := fields[name].(SchemaConditional); ok {
+			return nil
+		}
+		return m[name]
+	}
+}
+
+// tupleSiblings looks siblings up in a map being encoded or decoded with a
+// named tuple, showing only the fields before the current one.
+func tupleSiblings(m map[string]any, before []string) siblingLookup {
+	return func(name string) any {
+		for _, n := range before {
+			if n == name {
+				return m[name]
+			}
+		}
+		return nil
+	}
+}
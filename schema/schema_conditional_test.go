@@ -0,0 +1,81 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditional_NamedTuple(t *testing.T) {
+	payment := SChain(STupleNamed([]string{"payment_type", "expiry"},
+		SEnum([]string{"cash", "card"}, false),
+		SIf("payment_type", Equals("card"), SString.WithWidth(5), SString.Optional()),
+	))
+
+	card, err := EncodeValue(map[string]any{"payment_type": "card", "expiry": "12/29"}, payment)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(card, payment))
+	got, err := DecodeBuffer(card, payment)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"payment_type": "card", "expiry": "12/29"}, got)
+
+	cash, err := EncodeValue(map[string]any{"payment_type": "cash"}, payment)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(cash, payment))
+
+	_, err = EncodeValue(map[string]any{"payment_type": "card"}, payment)
+	assert.Error(t, err, "expiry is required for cards")
+
+	// the same bytes with the type switched to card
+	loose := SChain(STupleNamed([]string{"payment_type", "expiry"}, SInt16, SString.Optional()))
+	bad, err := EncodeValue(map[string]any{"payment_type": int16(1)}, loose)
+	require.NoError(t, err)
+	err = ValidateBuffer(bad, payment)
+	require.Error(t, err)
+	assert.Equal(t, []string{"0", "expiry"}, ErrorPath(err))
+	_, err = DecodeBuffer(bad, payment)
+	assert.Error(t, err)
+
+	errs := ValidateAll(bad, payment)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "0.expiry", errs[0].Field)
+}
+
+func TestConditional_MapSiblingAfterField(t *testing.T) {
+	shipping := SChain(SMapUnorderedNamed([]string{"zip", "country"},
+		SIf("country", Equals("US"), SString.Pattern(`^[0-9]{5}$`), SString),
+		SString,
+	))
+
+	us, err := EncodeValue(map[string]any{"zip": "12345", "country": "US"}, shipping)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(us, shipping))
+	got, err := DecodeBuffer(us, shipping)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"zip": "12345", "country": "US"}, got)
+
+	uk, err := EncodeValue(map[string]any{"zip": "SW1A 1AA", "country": "UK"}, shipping)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(uk, shipping))
+
+	_, err = EncodeValue(map[string]any{"zip": "SW1A 1AA", "country": "US"}, shipping)
+	assert.Error(t, err)
+
+	// zip precedes country in the buffer
+	bad, err := EncodeValue(map[string]any{"zip": "SW1A 1AA", "country": "US"},
+		SChain(SMapUnorderedNamed([]string{"zip", "country"}, SString, SString)))
+	require.NoError(t, err)
+	err = ValidateBuffer(bad, shipping)
+	require.Error(t, err)
+	assert.Equal(t, []string{"0", "zip"}, ErrorPath(err))
+	_, err = DecodeBuffer(bad, shipping)
+	assert.Error(t, err)
+}
+
+func TestConditional_NoSiblings(t *testing.T) {
+	s := SIf("missing", func(v any) bool { return v == nil }, SInt16, SString)
+	buf, err := EncodeValue(int16(3), SChain(s))
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, SChain(s)))
+}
@@ -0,0 +1,171 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// FieldConstraint is the flat, client-facing view of the rules on one field.
+// Path joins field names with '.', uses indexes for positional tuple fields,
+// "[]" for repeated items and "*" for the values of key-repeating maps.
+// Only rules that BuildSchema enforces for the field's type are exported.
+type FieldConstraint struct {
+	Path         string   `json:"path"`
+	Type         string   `json:"type"`
+	Nullable     bool     `json:"nullable,omitempty"`
+	Min          *float64 `json:"min,omitempty"`
+	Max          *float64 `json:"max,omitempty"`
+	ExclusiveMin bool     `json:"exclusiveMin,omitempty"`
+	ExclusiveMax bool     `json:"exclusiveMax,omitempty"`
+	MultipleOf   *float64 `json:"multipleOf,omitempty"`
+	Epsilon      float64  `json:"epsilon,omitempty"`
+	MinLen       *int     `json:"minLen,omitempty"`
+	MaxLen       *int     `json:"maxLen,omitempty"`
+	CountRunes   bool     `json:"countRunes,omitempty"`
+	Exact        string   `json:"exact,omitempty"`
+	Prefix       string   `json:"prefix,omitempty"`
+	Suffix       string   `json:"suffix,omitempty"`
+	IgnoreCase   bool     `json:"ignoreCase,omitempty"`
+	Trimmed      bool     `json:"trimmed,omitempty"`
+	Pattern      string   `json:"pattern,omitempty"`
+	Check        string   `json:"check,omitempty"`
+	Enum         []string `json:"enum,omitempty"`
+	DateFrom     string   `json:"dateFrom,omitempty"`
+	DateTo       string   `json:"dateTo,omitempty"`
+	MinItems     *int     `json:"minItems,omitempty"`
+	MaxItems     *int     `json:"maxItems,omitempty"`
+	KeyPattern   string   `json:"keyPattern,omitempty"`
+	KeyPrefix    string   `json:"keyPrefix,omitempty"`
+	KeyEnum      []string `json:"keyEnum,omitempty"`
+	Sanitize     []string `json:"sanitize,omitempty"`
+	Rules        []string `json:"rules,omitempty"`
+}
+
+// ExtractConstraints flattens the rules declared in js into one entry per
+// field, parents before children, so web and mobile clients can pre-validate
+// input with the same definition the server builds its schema from.
+func ExtractConstraints(js *SchemaJSON) []FieldConstraint {
+	var out []FieldConstraint
+	extractConstraints(js, "", &out)
+	return out
+}
+
+func extractConstraints(js *SchemaJSON, path string, out *[]FieldConstraint) {
+	fc := FieldConstraint{Path: path, Type: js.Type, Nullable: js.Nullable, Sanitize: js.Sanitize, Rules: js.Rules}
+	child := func(seg string) string {
+		if path == "" {
+			return seg
+		}
+		return path + "." + seg
+	}
+
+	switch js.Type {
+	case "int16", "int32", "int64", "varint", "number", "numberString":
+		fc.Min, fc.Max = floatBounds(js)
+		fc.ExclusiveMin, fc.ExclusiveMax = js.ExclusiveMin, js.ExclusiveMax
+		fc.MultipleOf = js.MultipleOf
+		if js.Type == "number" || js.Type == "numberString" {
+			fc.Epsilon = js.Epsilon
+		}
+	case "string":
+		if js.Width > 0 && !js.Nullable {
+			fc.MinLen, fc.MaxLen = &js.Width, &js.Width
+		}
+		if min, max := itemBounds(js); min != nil || max != nil {
+			fc.MinLen, fc.MaxLen, fc.CountRunes = min, max, js.CountRunes
+		}
+		// BuildSchema applies the first of these that is set
+		switch {
+		case js.Exact != "":
+			fc.Exact, fc.IgnoreCase, fc.Trimmed = js.Exact, js.IgnoreCase, js.Trimmed
+		case js.Prefix != "":
+			fc.Prefix, fc.IgnoreCase, fc.Trimmed = js.Prefix, js.IgnoreCase, js.Trimmed
+		case js.Suffix != "":
+			fc.Suffix, fc.IgnoreCase, fc.Trimmed = js.Suffix, js.IgnoreCase, js.Trimmed
+		case js.Pattern != "":
+			fc.Pattern = js.Pattern
+		case js.Check != "":
+			fc.Check = js.Check
+		}
+		fc.Enum = extraStrings(js.Extra["enum"])
+	case "bytes", "bitset":
+		if js.Width > 0 {
+			fc.MinLen, fc.MaxLen = &js.Width, &js.Width
+		}
+	case "date":
+		fc.DateFrom, fc.DateTo = js.DateFrom, js.DateTo
+	case "enum", "multicheck":
+		fc.Enum = js.FieldNames
+		if et, ok := enumTypes[js.EnumType]; ok && len(fc.Enum) == 0 {
+			fc.Enum = et.names
+		}
+	case "repeat", "array":
+		fc.MinItems, fc.MaxItems = itemBounds(js)
+	case "mapRepeat":
+		fc.MinItems, fc.MaxItems = itemBounds(js)
+		fc.KeyPattern = js.Pattern
+		fc.KeyEnum = js.FieldNames
+	case "mapSortedKeys":
+		fc.MinItems, fc.MaxItems = itemBounds(js)
+		fc.KeyPrefix = js.Prefix
+	}
+	if path != "" || len(js.Schema) == 0 {
+		*out = append(*out, fc)
+	}
+
+	switch js.Type {
+	case "tuple", "mapUnordered":
+		for i := range js.Schema {
+			seg := strconv.Itoa(i)
+			if i < len(js.FieldNames) {
+				seg = js.FieldNames[i]
+			}
+			extractConstraints(&js.Schema[i], child(seg), out)
+		}
+	case "repeat", "array":
+		for i := range js.Schema {
+			seg := "[]"
+			if len(js.Schema) > 1 {
+				seg = fmt.Sprintf("[%d]", i)
+			}
+			extractConstraints(&js.Schema[i], path+seg, out)
+		}
+	case "mapRepeat":
+		if len(js.Schema) == 2 {
+			extractConstraints(&js.Schema[1], child("*"), out)
+		}
+	case "mapSortedKeys":
+		if len(js.Schema) > 0 {
+			extractConstraints(&js.Schema[0], child("*"), out)
+		}
+	}
+}
+
+// itemBounds converts Min/Max entry counts; negative values mean unbounded.
+func itemBounds(js *SchemaJSON) (min, max *int) {
+	if js.Min != nil && *js.Min >= 0 {
+		v := int(*js.Min)
+		min = &v
+	}
+	if js.Max != nil && *js.Max >= 0 {
+		v := int(*js.Max)
+		max = &v
+	}
+	return min, max
+}
+
+func extraStrings(v any) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []any:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractConstraints(t *testing.T) {
+	var js SchemaJSON
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "mapUnordered",
+		"fieldNames": ["age", "code", "role", "tags", "labels", "born"],
+		"schema": [
+			{"type": "int16", "min": 0, "max": 150, "exclusiveMax": true},
+			{"type": "string", "width": 6, "pattern": "^[A-Z0-9]+$"},
+			{"type": "enum", "fieldNames": ["admin", "user"], "nullable": true},
+			{"type": "repeat", "min": 1, "max": 5, "schema": [{"type": "string", "prefix": "#"}]},
+			{"type": "mapRepeat", "max": 10, "pattern": "^[a-z]+$", "schema": [{"type": "string"}, {"type": "number", "minFloat": 0.5}]},
+			{"type": "date", "dateFrom": "2000-01-01"}
+		]
+	}`), &js))
+
+	got := ExtractConstraints(&js)
+	paths := make([]string, len(got))
+	byPath := map[string]FieldConstraint{}
+	for i, fc := range got {
+		paths[i] = fc.Path
+		byPath[fc.Path] = fc
+	}
+	assert.Equal(t, []string{"age", "code", "role", "tags", "tags[]", "labels", "labels.*", "born"}, paths)
+
+	age := byPath["age"]
+	assert.Equal(t, 0.0, *age.Min)
+	assert.Equal(t, 150.0, *age.Max)
+	assert.True(t, age.ExclusiveMax)
+
+	code := byPath["code"]
+	assert.Equal(t, 6, *code.MinLen)
+	assert.Equal(t, 6, *code.MaxLen)
+	assert.Equal(t, "^[A-Z0-9]+$", code.Pattern)
+
+	assert.Equal(t, []string{"admin", "user"}, byPath["role"].Enum)
+	assert.True(t, byPath["role"].Nullable)
+	assert.Equal(t, 1, *byPath["tags"].MinItems)
+	assert.Equal(t, 5, *byPath["tags"].MaxItems)
+	assert.Equal(t, "#", byPath["tags[]"].Prefix)
+	assert.Nil(t, byPath["labels"].MinItems)
+	assert.Equal(t, "^[a-z]+$", byPath["labels"].KeyPattern)
+	assert.Equal(t, 0.5, *byPath["labels.*"].Min)
+	assert.Equal(t, "2000-01-01", byPath["born"].DateFrom)
+
+	out, err := json.Marshal(byPath["role"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"path":"role","type":"enum","nullable":true,"enum":["admin","user"]}`, string(out))
+
+	// a single leaf reports itself with an empty path
+	leaf := ExtractConstraints(&SchemaJSON{Type: "int8", Min: PtrToInt64(1)})
+	require.Len(t, leaf, 1)
+	assert.Nil(t, leaf[0].Min, "int8 ranges are not enforced by BuildSchema")
+}
@@ -0,0 +1,180 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/quickwritereader/PackOS/access"
+)
+
+// FieldCoverage aggregates what a corpus looked like for one top-level field.
+type FieldCoverage struct {
+	Name     string
+	Nullable bool
+	// Present counts decoded non-null values, Null counts null or absent values.
+	Present int
+	Null    int
+	// Failures counts rejected values by the most specific error code.
+	Failures map[ErrorCode]int
+
+	// Observed numeric range, set when HasRange is true.
+	HasRange bool
+	Min, Max float64
+	// Observed length range of strings, bytes, tuples and maps, set when HasLen is true.
+	HasLen         bool
+	MinLen, MaxLen int
+}
+
+// CoverageReport is the result of running a schema over a corpus of buffers.
+type CoverageReport struct {
+	Buffers int
+	Valid   int
+	Fields  []FieldCoverage
+}
+
+// Coverage decodes every buffer with chain and reports per-field statistics.
+// Fields are listed in chain order. Decoding of a buffer stops at its first
+// failing field, whose error is attributed to that field.
+func Coverage(chain SchemaNamedChain, bufs [][]byte) (CoverageReport, error) {
+	if len(chain.FieldNames) != len(chain.Schemas) {
+		return CoverageReport{}, NewSchemaError(ErrConstraintViolated, SchemaNamedChainName, "", -1,
+			SizeExact{Actual: len(chain.FieldNames), Exact: len(chain.Schemas)})
+	}
+	report := CoverageReport{Fields: make([]FieldCoverage, len(chain.Schemas))}
+	for i, s := range chain.Schemas {
+		report.Fields[i] = FieldCoverage{
+			Name:     chain.FieldNames[i],
+			Nullable: s.IsNullable(),
+			Failures: map[ErrorCode]int{},
+		}
+	}
+	for _, buf := range bufs {
+		report.Buffers++
+		seq, err := access.NewSeqGetAccess(buf)
+		if err != nil {
+			if len(report.Fields) > 0 {
+				report.Fields[0].Failures[ErrInvalidFormat]++
+			}
+			continue
+		}
+		ok := true
+		for i, s := range chain.Schemas {
+			fc := &report.Fields[i]
+			val, err := s.Decode(seq)
+			if err != nil {
+				fc.Failures[specificCode(err)]++
+				ok = false
+				break
+			}
+			fc.observe(val)
+		}
+		if ok {
+			report.Valid++
+		}
+	}
+	return report, nil
+}
+
+// specificCode returns the code of the innermost SchemaError in err's chain.
+func specificCode(err error) ErrorCode {
+	if se := innermostSchemaError(err); se != nil {
+		return se.Code
+	}
+	return ErrUnknown
+}
+
+func (fc *FieldCoverage) observe(val any) {
+	switch v := val.(type) {
+	case nil:
+		fc.Null++
+		return
+	case []any:
+		if v == nil {
+			fc.Null++
+			return
+		}
+		fc.observeLen(len(v))
+	case map[string]any:
+		fc.observeLen(len(v))
+	case string:
+		fc.observeLen(len(v))
+	case []byte:
+		fc.observeLen(len(v))
+	case time.Time:
+		fc.observeNumber(float64(v.Unix()))
+	default:
+		if f, ok := coverageNumber(val); ok {
+			fc.observeNumber(f)
+		}
+	}
+	fc.Present++
+}
+
+func (fc *FieldCoverage) observeNumber(f float64) {
+	if !fc.HasRange {
+		fc.HasRange, fc.Min, fc.Max = true, f, f
+		return
+	}
+	fc.Min = min(fc.Min, f)
+	fc.Max = max(fc.Max, f)
+}
+
+func (fc *FieldCoverage) observeLen(n int) {
+	if !fc.HasLen {
+		fc.HasLen, fc.MinLen, fc.MaxLen = true, n, n
+		return
+	}
+	fc.MinLen = min(fc.MinLen, n)
+	fc.MaxLen = max(fc.MaxLen, n)
+}
+
+func coverageNumber(val any) (float64, bool) {
+	switch v := val.(type) {
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// NeverTriggered lists fields whose constraints rejected no value in the corpus.
+// Their bounds are candidates for tightening towards the observed ranges.
+func (r CoverageReport) NeverTriggered() []string {
+	var out []string
+	for _, fc := range r.Fields {
+		if len(fc.Failures) == 0 {
+			out = append(out, fc.Name)
+		}
+	}
+	return out
+}
+
+// AlwaysAbsent lists nullable fields that were null or absent in every decoded buffer.
+func (r CoverageReport) AlwaysAbsent() []string {
+	var out []string
+	for _, fc := range r.Fields {
+		if fc.Nullable && fc.Present == 0 && fc.Null > 0 {
+			out = append(out, fc.Name)
+		}
+	}
+	return out
+}
+
+// NeverNull lists nullable fields that always carried a value and could be made required.
+func (r CoverageReport) NeverNull() []string {
+	var out []string
+	for _, fc := range r.Fields {
+		if fc.Nullable && fc.Present > 0 && fc.Null == 0 {
+			out = append(out, fc.Name)
+		}
+	}
+	return out
+}
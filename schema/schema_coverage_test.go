@@ -0,0 +1,43 @@
+package schema
+
+import (
+	"testing"
+
+	pack "github.com/quickwritereader/PackOS/packable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverage_RangesAbsenceAndFailures(t *testing.T) {
+	chain := SchemaNamedChain{
+		SchemaChain: SChain(SInt16.RangeValues(0, 1000), SString, SNullInt32, SNullInt64),
+		FieldNames:  []string{"qty", "name", "note", "ts"},
+	}
+	bufs := [][]byte{
+		pack.Pack(pack.PackInt16(3), pack.PackString("ab"), pack.PackNullableInt32(nil), pack.PackInt64(10)),
+		pack.Pack(pack.PackInt16(40), pack.PackString("abcd"), pack.PackNullableInt32(nil), pack.PackInt64(20)),
+		pack.Pack(pack.PackInt16(2000), pack.PackString("x"), pack.PackNullableInt32(nil), pack.PackInt64(30)),
+		{0x01},
+	}
+
+	report, err := Coverage(chain, bufs)
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Buffers)
+	assert.Equal(t, 2, report.Valid)
+
+	qty := report.Fields[0]
+	assert.Equal(t, map[ErrorCode]int{ErrOutOfRange: 1, ErrInvalidFormat: 1}, qty.Failures)
+	assert.True(t, qty.HasRange)
+	assert.Equal(t, 3.0, qty.Min)
+	assert.Equal(t, 40.0, qty.Max)
+
+	name := report.Fields[1]
+	assert.True(t, name.HasLen)
+	assert.Equal(t, 2, name.MinLen)
+	assert.Equal(t, 4, name.MaxLen)
+
+	assert.Equal(t, []string{"name", "note", "ts"}, report.NeverTriggered())
+	assert.Equal(t, []string{"note"}, report.AlwaysAbsent())
+	// variable-width strings are nullable, so name shows up as never null too
+	assert.Equal(t, []string{"name", "ts"}, report.NeverNull())
+}
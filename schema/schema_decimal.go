@@ -0,0 +1,126 @@
+package schema
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const SchemaDecimalName = "SchemaDecimal"
+
+// SchemaDecimal is an arbitrary-precision decimal packed with
+// access.PutAccess.AddDecimal. Decode returns a typetags.Decimal, or its plain
+// string form when AsString is set.
+//
+// Encode accepts typetags.Decimal, decimal strings, json.Number, *big.Int,
+// *big.Rat with a finite decimal expansion, Go integers and floats. Floats use
+// their shortest exact representation, so 0.1 packs as 1e-1.
+type SchemaDecimal struct {
+	Nullable bool
+	AsString bool
+}
+
+var (
+	SDecimal       = SchemaDecimal{}
+	SDecimalString = SchemaDecimal{AsString: true}
+)
+
+func (s SchemaDecimal) IsNullable() bool { return s.Nullable }
+
+func (s SchemaDecimal) decodeValidate(seq *access.SeqGetAccess, decode bool) (any, error) {
+	pos := seq.CurrentIndex()
+	width, err := precheck(SchemaDecimalName, pos, seq, typetags.TypeTuple, 0, s.Nullable)
+	if err != nil {
+		return nil, err
+	}
+	if width == 0 {
+		if !s.Nullable {
+			return nil, NewSchemaError(ErrConstraintViolated, SchemaDecimalName, "", pos, ErrTypeMisMatch)
+		}
+		if err := seq.Advance(); err != nil {
+			return nil, NewSchemaError(ErrUnexpectedEOF, SchemaDecimalName, "", pos, err)
+		}
+		return nil, nil
+	}
+	payload, err := seq.GetPayload(width)
+	if err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, SchemaDecimalName, "", pos, err)
+	}
+	d, err := access.DecodeDecimal(payload)
+	if err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, SchemaDecimalName, "", pos, err)
+	}
+	if err := seq.Advance(); err != nil {
+		return nil, NewSchemaError(ErrUnexpectedEOF, SchemaDecimalName, "", pos, err)
+	}
+	if !decode {
+		return nil, nil
+	}
+	if s.AsString {
+		return d.String(), nil
+	}
+	return d, nil
+}
+
+func (s SchemaDecimal) Validate(seq *access.SeqGetAccess) error {
+	_, err := s.decodeValidate(seq, false)
+	return err
+}
+
+func (s SchemaDecimal) Decode(seq *access.SeqGetAccess) (any, error) {
+	return s.decodeValidate(seq, true)
+}
+
+func (s SchemaDecimal) Encode(put *access.PutAccess, val any) error {
+	if s.Nullable && val == nil {
+		put.AddAnyTuple(nil, false)
+		return nil
+	}
+	d, err := toDecimal(val)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaDecimalName, "", -1, err)
+	}
+	put.AddDecimal(d)
+	return nil
+}
+
+func toDecimal(val any) (typetags.Decimal, error) {
+	switch v := val.(type) {
+	case typetags.Decimal:
+		return v, nil
+	case *typetags.Decimal:
+		if v != nil {
+			return *v, nil
+		}
+	case string:
+		return typetags.ParseDecimal(v)
+	case json.Number:
+		return typetags.ParseDecimal(v.String())
+	case *big.Int:
+		if v != nil {
+			return typetags.NewDecimal(new(big.Int).Set(v), 0), nil
+		}
+	case *big.Rat:
+		if v != nil {
+			return typetags.DecimalFromRat(v)
+		}
+	case int:
+		return typetags.NewDecimalInt64(int64(v), 0), nil
+	case int8:
+		return typetags.NewDecimalInt64(int64(v), 0), nil
+	case int16:
+		return typetags.NewDecimalInt64(int64(v), 0), nil
+	case int32:
+		return typetags.NewDecimalInt64(int64(v), 0), nil
+	case int64:
+		return typetags.NewDecimalInt64(v, 0), nil
+	case float32:
+		return typetags.ParseDecimal(strconv.FormatFloat(float64(v), 'e', -1, 32))
+	case float64:
+		return typetags.ParseDecimal(strconv.FormatFloat(v, 'e', -1, 64))
+	}
+	return typetags.Decimal{}, ErrTypeMisMatch
+}
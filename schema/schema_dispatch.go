@@ -0,0 +1,175 @@
+package schema
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// UnknownSchemaErrorDetails reports a discriminator value with no registered schema.
+type UnknownSchemaErrorDetails struct {
+	ID string
+}
+
+func (e UnknownSchemaErrorDetails) Error() string {
+	return fmt.Sprintf("Unknown schema '%s'", e.ID)
+}
+
+// SchemaDispatcher resolves the SchemaNamedChain for an envelope by reading
+// a discriminator field, so one intake point can handle many message types.
+//
+// Envelope layout: the discriminator is packed as the first top-level string,
+// followed by the body fields of the selected SchemaNamedChain.
+//
+//	[ "order.created" ][ body field 0 ][ body field 1 ] ...
+//
+// Decode returns the body as map[string]any with the discriminator stored
+// under Field. Encode expects the same shape.
+type SchemaDispatcher struct {
+	Field  string
+	mu     sync.RWMutex
+	chains map[string]SchemaNamedChain
+}
+
+// NewSchemaDispatcher creates a dispatcher keyed by the given discriminator field name.
+func NewSchemaDispatcher(field string) *SchemaDispatcher {
+	return &SchemaDispatcher{
+		Field:  field,
+		chains: make(map[string]SchemaNamedChain),
+	}
+}
+
+// Register binds a discriminator value to a SchemaNamedChain.
+// Registering the same id again replaces the previous chain.
+func (d *SchemaDispatcher) Register(id string, chain SchemaNamedChain) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.chains[id] = chain
+}
+
+// Unregister removes the chain bound to id. Unknown ids are ignored.
+func (d *SchemaDispatcher) Unregister(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.chains, id)
+}
+
+// Lookup returns the chain registered for id.
+func (d *SchemaDispatcher) Lookup(id string) (SchemaNamedChain, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	chain, ok := d.chains[id]
+	return chain, ok
+}
+
+// resolve reads the discriminator and leaves seq positioned on the first body field.
+func (d *SchemaDispatcher) resolve(buf []byte) (*access.SeqGetAccess, string, SchemaNamedChain, error) {
+	seq, err := access.NewSeqGetAccess(buf)
+	if err != nil {
+		return nil, "", SchemaNamedChain{}, NewSchemaError(ErrInvalidFormat, SchemaDispatcherName, d.Field, -1, err)
+	}
+	payload, typ, err := seq.Next()
+	if err != nil {
+		return nil, "", SchemaNamedChain{}, NewSchemaError(ErrUnexpectedEOF, SchemaDispatcherName, d.Field, 0, err)
+	}
+	if typ != typetags.TypeString {
+		return nil, "", SchemaNamedChain{}, NewSchemaError(ErrConstraintViolated, SchemaDispatcherName, d.Field, 0, ErrTypeMisMatch)
+	}
+	id := string(payload)
+	chain, ok := d.Lookup(id)
+	if !ok {
+		return nil, id, SchemaNamedChain{}, NewSchemaError(ErrConstraintViolated, SchemaDispatcherName, d.Field, 0, UnknownSchemaErrorDetails{ID: id})
+	}
+	if len(chain.FieldNames) != len(chain.Schemas) {
+		return nil, id, SchemaNamedChain{}, NewSchemaError(ErrConstraintViolated, SchemaDispatcherName, id, -1,
+			SizeExact{Actual: len(chain.FieldNames), Exact: len(chain.Schemas)})
+	}
+	return seq, id, chain, nil
+}
+
+// Resolve returns the discriminator value and the chain registered for it.
+func (d *SchemaDispatcher) Resolve(buf []byte) (string, SchemaNamedChain, error) {
+	_, id, chain, err := d.resolve(buf)
+	return id, chain, err
+}
+
+// Validate validates the envelope body against the chain selected by the discriminator.
+// When the chain has rules, the body is decoded as well to check them.
+func (d *SchemaDispatcher) Validate(buf []byte) error {
+	seq, _, chain, err := d.resolve(buf)
+	if err != nil {
+		return err
+	}
+	for _, schema := range chain.Schemas {
+		if err := schema.Validate(seq); err != nil {
+			return err
+		}
+	}
+	if len(chain.Rules) == 0 {
+		return nil
+	}
+	_, err = d.Decode(buf)
+	return err
+}
+
+// Decode decodes the envelope body into map[string]any using the selected chain
+// and checks the chain's rules on it. The discriminator value is included
+// under d.Field, but rules do not see it.
+func (d *SchemaDispatcher) Decode(buf []byte) (map[string]any, error) {
+	seq, id, chain, err := d.resolve(buf)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]any, len(chain.Schemas)+1)
+	for i, schema := range chain.Schemas {
+		val, err := schema.Decode(seq)
+		if err != nil {
+			return nil, err
+		}
+		out[chain.FieldNames[i]] = val
+	}
+	if err := chain.checkRules(out); err != nil {
+		return nil, err
+	}
+	out[d.Field] = id
+	return out, nil
+}
+
+// Encode packs val as an envelope. The discriminator is taken from val[d.Field].
+func (d *SchemaDispatcher) Encode(val map[string]any) ([]byte, error) {
+	id, ok := val[d.Field].(string)
+	if !ok {
+		return nil, NewSchemaError(ErrEncode, SchemaDispatcherName, d.Field, -1, MissingKeyErrorDetails{Key: d.Field})
+	}
+	chain, ok := d.Lookup(id)
+	if !ok {
+		return nil, NewSchemaError(ErrEncode, SchemaDispatcherName, d.Field, -1, UnknownSchemaErrorDetails{ID: id})
+	}
+	if len(chain.FieldNames) != len(chain.Schemas) {
+		return nil, NewSchemaError(ErrEncode, SchemaDispatcherName, id, -1,
+			SizeExact{Actual: len(chain.FieldNames), Exact: len(chain.Schemas)})
+	}
+
+	put := access.NewPutAccessFromPool()
+	defer access.ReleasePutAccess(put)
+	put.AddString(id)
+	for i, fn := range chain.FieldNames {
+		v, ok := val[fn]
+		if !ok && !chain.Schemas[i].IsNullable() {
+			return nil, NewSchemaError(ErrEncode, SchemaDispatcherName, fn, -1, MissingKeyErrorDetails{Key: fn})
+		}
+		if err := chain.Schemas[i].Encode(put, v); err != nil {
+			return nil, NewSchemaError(ErrEncode, SchemaDispatcherName, fn, -1, err)
+		}
+	}
+	buf := put.Pack()
+	if len(chain.Rules) > 0 {
+		// rules see the fields as decoding returns them
+		if _, err := d.Decode(buf); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
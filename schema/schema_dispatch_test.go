@@ -0,0 +1,147 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	pack "github.com/quickwritereader/PackOS/packable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDispatcher() *SchemaDispatcher {
+	d := NewSchemaDispatcher("type")
+	d.Register("user", SchemaNamedChain{
+		SchemaChain: SChain(SInt32, SString),
+		FieldNames:  []string{"id", "name"},
+	})
+	d.Register("ping", SchemaNamedChain{
+		SchemaChain: SChain(SInt64),
+		FieldNames:  []string{"ts"},
+	})
+	return d
+}
+
+func TestSchemaDispatcher_ValidateDecode(t *testing.T) {
+	d := newTestDispatcher()
+
+	buf := pack.Pack(
+		pack.PackString("user"),
+		pack.PackInt32(7),
+		pack.PackString("alice"),
+	)
+
+	require.NoError(t, d.Validate(buf))
+
+	id, chain, err := d.Resolve(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "user", id)
+	assert.Equal(t, []string{"id", "name"}, chain.FieldNames)
+
+	decoded, err := d.Decode(buf)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"type": "user",
+		"id":   int32(7),
+		"name": "alice",
+	}, decoded)
+
+	// Same intake, different message type
+	ping := pack.Pack(pack.PackString("ping"), pack.PackInt64(1234))
+	decoded, err = d.Decode(ping)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"type": "ping", "ts": int64(1234)}, decoded)
+
+	// Body does not match the selected chain
+	bad := pack.Pack(pack.PackString("ping"), pack.PackInt32(1))
+	require.Error(t, d.Validate(bad))
+}
+
+func TestSchemaDispatcher_UnknownType(t *testing.T) {
+	d := newTestDispatcher()
+
+	buf := pack.Pack(pack.PackString("order"), pack.PackInt32(1))
+	err := d.Validate(buf)
+	require.Error(t, err)
+
+	var details UnknownSchemaErrorDetails
+	require.True(t, errors.As(err, &details))
+	assert.Equal(t, "order", details.ID)
+
+	d.Unregister("ping")
+	_, err = d.Decode(pack.Pack(pack.PackString("ping"), pack.PackInt64(1)))
+	require.Error(t, err)
+}
+
+func TestSchemaDispatcher_EncodeRoundTrip(t *testing.T) {
+	d := newTestDispatcher()
+
+	val := map[string]any{
+		"type": "user",
+		"id":   int32(42),
+		"name": "gopher",
+	}
+	encoded, err := d.Encode(val)
+	require.NoError(t, err)
+
+	expected := pack.Pack(
+		pack.PackString("user"),
+		pack.PackInt32(42),
+		pack.PackString("gopher"),
+	)
+	assert.Equal(t, expected, encoded)
+
+	decoded, err := d.Decode(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, val, decoded)
+
+	_, err = d.Encode(map[string]any{"id": int32(1)})
+	require.Error(t, err, "missing discriminator should fail")
+}
+
+func TestSchemaDispatcher_MismatchedChain(t *testing.T) {
+	d := NewSchemaDispatcher("type")
+	d.Register("bad", SchemaNamedChain{
+		SchemaChain: SChain(SInt32),
+		FieldNames:  []string{"id", "name"},
+	})
+
+	_, err := d.Encode(map[string]any{"type": "bad", "id": int32(1), "name": "x"})
+	var size SizeExact
+	require.ErrorAs(t, err, &size)
+	assert.Equal(t, SizeExact{Actual: 2, Exact: 1}, size)
+
+	_, err = d.Decode(pack.Pack(pack.PackString("bad"), pack.PackInt32(1)))
+	assert.ErrorAs(t, err, &size)
+}
+
+func TestSchemaDispatcher_Rules(t *testing.T) {
+	d := NewSchemaDispatcher("type")
+	d.Register("range", SchemaNamedChain{
+		SchemaChain: SChain(SInt32, SInt32),
+		FieldNames:  []string{"lo", "hi"},
+	}.WithRule(func(f map[string]any) error {
+		if _, ok := f["type"]; ok {
+			return errors.New("rules see only the body")
+		}
+		if f["lo"].(int32) > f["hi"].(int32) {
+			return errors.New("lo is above hi")
+		}
+		return nil
+	}))
+
+	good := pack.Pack(pack.PackString("range"), pack.PackInt32(1), pack.PackInt32(2))
+	require.NoError(t, d.Validate(good))
+	v, err := d.Decode(good)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"type": "range", "lo": int32(1), "hi": int32(2)}, v)
+
+	bad := pack.Pack(pack.PackString("range"), pack.PackInt32(3), pack.PackInt32(2))
+	err = d.Validate(bad)
+	assertSchemaError(t, err, ErrRuleViolated)
+	assert.ErrorContains(t, err, "lo is above hi")
+	_, err = d.Decode(bad)
+	assertSchemaError(t, err, ErrRuleViolated)
+	_, err = d.Encode(map[string]any{"type": "range", "lo": int32(3), "hi": int32(2)})
+	assertSchemaError(t, err, ErrRuleViolated)
+}
@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"golang.org/x/exp/constraints"
+)
+
+// EncodeJSON encodes a JSON object against chain in one pass.
+// Numbers are kept as json.Number until the schema that consumes them
+// coerces them to its declared width, so loosely typed input such as 3.0
+// for an int16 field still produces a tight buffer. Fractions and values that
+// do not fit the declared width fail with ErrEncode.
+func EncodeJSON(jsonBytes []byte, chain SchemaNamedChain) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(jsonBytes))
+	dec.UseNumber()
+	var val any
+	if err := dec.Decode(&val); err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, SchemaNamedChainName, "", -1, err)
+	}
+	if dec.More() {
+		return nil, NewSchemaError(ErrInvalidFormat, SchemaNamedChainName, "", -1,
+			fmt.Errorf("trailing data after JSON value"))
+	}
+	return EncodeValueNamed(val, chain)
+}
+
+// coerceJSONNumber converts a json.Number into T and returns any other value unchanged.
+// Integer targets reject fractions and out-of-range values; float32 rejects overflow.
+func coerceJSONNumber[T constraints.Integer | constraints.Float](val any) (any, error) {
+	n, ok := val.(json.Number)
+	if !ok {
+		return val, nil
+	}
+	var zero T
+	switch any(zero).(type) {
+	case float32:
+		f, err := strconv.ParseFloat(n.String(), 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s does not fit float32: %w", n, err)
+		}
+		return T(f), nil
+	case float64:
+		f, err := n.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return T(f), nil
+	}
+
+	i, err := n.Int64()
+	if err != nil {
+		// accept integral floats such as 3.0 or 1e3
+		f, ferr := n.Float64()
+		if ferr != nil || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return nil, fmt.Errorf("%s is not an integer: %w", n, ErrTypeMisMatch)
+		}
+		i = int64(f)
+	}
+	if int64(T(i)) != i {
+		return nil, RangeErrorDetails[int64]{
+			Min:    PtrToInt64(minOf[T]()),
+			Max:    PtrToInt64(maxOf[T]()),
+			Actual: i,
+		}
+	}
+	return T(i), nil
+}
+
+func minOf[T constraints.Integer | constraints.Float]() int64 {
+	var zero T
+	switch any(zero).(type) {
+	case int8:
+		return math.MinInt8
+	case int16:
+		return math.MinInt16
+	case int32:
+		return math.MinInt32
+	case int64:
+		return math.MinInt64
+	}
+	return 0
+}
+
+func maxOf[T constraints.Integer | constraints.Float]() int64 {
+	var zero T
+	switch any(zero).(type) {
+	case int8:
+		return math.MaxInt8
+	case int16:
+		return math.MaxInt16
+	case int32:
+		return math.MaxInt32
+	case uint8:
+		return math.MaxUint8
+	case uint16:
+		return math.MaxUint16
+	case uint32:
+		return math.MaxUint32
+	}
+	return math.MaxInt64
+}
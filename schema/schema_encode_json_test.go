@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"testing"
+
+	pack "github.com/quickwritereader/PackOS/packable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeJSON_CoercesToDeclaredWidths(t *testing.T) {
+	chain := SchemaNamedChain{
+		SchemaChain: SChain(SInt8, SInt16.RangeValues(0, 100), SInt32, SFloat32, SNumber, SString),
+		FieldNames:  []string{"flag", "pct", "count", "ratio", "num", "name"},
+	}
+
+	buf, err := EncodeJSON([]byte(`{"flag": 1, "pct": 42.0, "count": 1e3, "ratio": 0.5, "num": 2.25, "name": "x"}`), chain)
+	require.NoError(t, err)
+
+	expected := pack.Pack(
+		pack.PackInt8(1),
+		pack.PackInt16(42),
+		pack.PackInt32(1000),
+		pack.PackFloat32(0.5),
+		pack.PackFloat64(2.25),
+		pack.PackString("x"),
+	)
+	assert.Equal(t, expected, buf)
+}
+
+func TestEncodeJSON_Rejects(t *testing.T) {
+	chain := SchemaNamedChain{
+		SchemaChain: SChain(SInt8, SInt16.RangeValues(0, 100)),
+		FieldNames:  []string{"a", "b"},
+	}
+
+	_, err := EncodeJSON([]byte(`{"a": 1.5, "b": 1}`), chain)
+	assert.Error(t, err, "fraction for an integer field")
+	_, err = EncodeJSON([]byte(`{"a": 300, "b": 1}`), chain)
+	assert.Error(t, err, "overflow of int8")
+	_, err = EncodeJSON([]byte(`{"a": 1, "b": 101}`), chain)
+	assert.Error(t, err, "constraint violated")
+	_, err = EncodeJSON([]byte(`{"a": 1, "b": 1} {}`), chain)
+	assert.Error(t, err, "trailing data")
+	_, err = EncodeJSON([]byte(`[1, 2]`), chain)
+	assert.Error(t, err, "not an object")
+}
+
+func TestEncodeJSON_NestedAndAny(t *testing.T) {
+	chain := SchemaNamedChain{
+		SchemaChain: SChain(SInt64, SAny),
+		FieldNames:  []string{"id", "extra"},
+	}
+	buf, err := EncodeJSON([]byte(`{"id": 9007199254740993, "extra": 7}`), chain)
+	require.NoError(t, err)
+	assert.Equal(t, pack.Pack(pack.PackInt64(9007199254740993), pack.PackInt8(7)), buf)
+}
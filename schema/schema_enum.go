@@ -0,0 +1,181 @@
+package schema
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const SchemaEnumValuesName = "SchemaEnumValues"
+
+// SchemaEnumValues is an enum whose names map to explicit int64 codes,
+// which may be sparse, negative or beyond the 65535 names of
+// SchemaEnumNamedList. Codes are written with
+// access.PutAccess.AddIntCompact, so small codes stay small on the wire.
+//
+// Encode accepts a name, an alias or a code. Decode returns the name, or
+// the int64 code when DecodeCode is set. Deprecated names still validate
+// and decode, so stored data keeps reading, but Encode rejects them with
+// ErrEnumDeprecated.
+type SchemaEnumValues struct {
+	Codes      map[string]int64
+	Aliases    map[string]string // alias → name in Codes
+	Deprecated map[string]bool
+	DecodeCode bool
+	Nullable   bool
+	names      map[int64]string
+}
+
+// SEnumValues builds an enum over codes. It panics if two names share a
+// code, as decoding could not tell them apart.
+func SEnumValues(codes map[string]int64) SchemaEnumValues {
+	names := make(map[int64]string, len(codes))
+	for name, code := range codes {
+		if other, ok := names[code]; ok {
+			if other > name {
+				other, name = name, other
+			}
+			panic(fmt.Sprintf("SEnumValues: %q and %q share code %d", other, name, code))
+		}
+		names[code] = name
+	}
+	return SchemaEnumValues{Codes: codes, names: names}
+}
+
+// Alias returns a copy of s that also encodes alias as name. It panics if
+// name is not in s.Codes or alias is.
+func (s SchemaEnumValues) Alias(alias, name string) SchemaEnumValues {
+	if _, ok := s.Codes[name]; !ok {
+		panic(fmt.Sprintf("SEnumValues: alias %q of unknown name %q", alias, name))
+	}
+	if _, ok := s.Codes[alias]; ok {
+		panic(fmt.Sprintf("SEnumValues: alias %q is already a name", alias))
+	}
+	s.Aliases = maps.Clone(s.Aliases)
+	if s.Aliases == nil {
+		s.Aliases = map[string]string{}
+	}
+	s.Aliases[alias] = name
+	return s
+}
+
+// Deprecate returns a copy of s that rejects names on encode. It panics if
+// a name is not in s.Codes.
+func (s SchemaEnumValues) Deprecate(names ...string) SchemaEnumValues {
+	s.Deprecated = maps.Clone(s.Deprecated)
+	if s.Deprecated == nil {
+		s.Deprecated = map[string]bool{}
+	}
+	for _, name := range names {
+		if _, ok := s.Codes[name]; !ok {
+			panic(fmt.Sprintf("SEnumValues: cannot deprecate unknown name %q", name))
+		}
+		s.Deprecated[name] = true
+	}
+	return s
+}
+
+// ToCode returns a copy of s that decodes to the int64 code.
+func (s SchemaEnumValues) ToCode() SchemaEnumValues {
+	s.DecodeCode = true
+	return s
+}
+
+// Optional returns a copy of s that accepts nulls.
+func (s SchemaEnumValues) Optional() SchemaEnumValues {
+	s.Nullable = true
+	return s
+}
+
+func (s SchemaEnumValues) IsNullable() bool { return s.Nullable }
+
+// nameOf returns the name of code, scanning Codes when s was not made by
+// SEnumValues.
+func (s SchemaEnumValues) nameOf(code int64) (string, bool) {
+	if s.names != nil {
+		name, ok := s.names[code]
+		return name, ok
+	}
+	for name, c := range s.Codes {
+		if c == code {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (s SchemaEnumValues) decodeValidate(seq *access.SeqGetAccess) (any, error) {
+	pos := seq.CurrentIndex()
+	payload, err := validatePrimitiveAndGetPayload(SchemaEnumValuesName, seq, typetags.TypeInteger, 0, s.Nullable)
+	if err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		if !s.Nullable {
+			return nil, NewSchemaError(ErrConstraintViolated, SchemaEnumValuesName, "", pos, ErrTypeMisMatch)
+		}
+		return nil, nil
+	}
+	v, err := access.DecodePrimitive(typetags.TypeInteger, payload)
+	if err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, SchemaEnumValuesName, "", pos, err)
+	}
+	code, _ := convertToNumber[int64](v)
+	name, ok := s.nameOf(code)
+	if !ok {
+		return nil, NewSchemaError(ErrConstraintViolated, SchemaEnumValuesName, "", pos,
+			fmt.Errorf("unknown enum code %d", code))
+	}
+	if s.DecodeCode {
+		return code, nil
+	}
+	return name, nil
+}
+
+func (s SchemaEnumValues) Validate(seq *access.SeqGetAccess) error {
+	_, err := s.decodeValidate(seq)
+	return err
+}
+
+func (s SchemaEnumValues) Decode(seq *access.SeqGetAccess) (any, error) {
+	return s.decodeValidate(seq)
+}
+
+func (s SchemaEnumValues) Encode(put *access.PutAccess, val any) error {
+	if val == nil && s.Nullable {
+		put.AddNullableInt64(nil)
+		return nil
+	}
+	val, err := coerceJSONNumber[int64](val)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaEnumValuesName, "", -1, err)
+	}
+	var name string
+	var code int64
+	var ok bool
+	switch v := val.(type) {
+	case string:
+		name = v
+		if alias, isAlias := s.Aliases[v]; isAlias {
+			name = alias
+		}
+		code, ok = s.Codes[name]
+	case int, int8, int16, int32, int64:
+		code, _ = convertToNumber[int64](v)
+		name, ok = s.nameOf(code)
+	default:
+		return NewSchemaError(ErrEncode, SchemaEnumValuesName, "", -1, ErrTypeMisMatch)
+	}
+	if !ok {
+		return NewSchemaError(ErrEncode, SchemaEnumValuesName, "", -1,
+			fmt.Errorf("unknown enum value %v", val))
+	}
+	if s.Deprecated[name] {
+		return NewSchemaError(ErrEnumDeprecated, SchemaEnumValuesName, "", -1,
+			fmt.Errorf("enum value %q is deprecated", name))
+	}
+	put.AddIntCompact(code)
+	return nil
+}
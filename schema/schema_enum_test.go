@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSEnumValues(t *testing.T) {
+	status := SEnumValues(map[string]int64{
+		"active":   1,
+		"paused":   70000,
+		"archived": -2,
+		"legacy":   5_000_000_000,
+	}).Alias("enabled", "active").Deprecate("legacy")
+	chain := SChain(status, SString)
+
+	buf, err := EncodeValue([]any{"paused", "x"}, chain)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, chain))
+	v, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"paused", "x"}, v)
+	assert.LessOrEqual(t, len(buf), chain.EstimateSize([]any{"paused", "x"}))
+
+	for in, want := range map[any]any{"enabled": "active", int64(-2): "archived", json.Number("1"): "active"} {
+		buf, err := EncodeValue([]any{in, "x"}, chain)
+		require.NoError(t, err, "%v", in)
+		v, err := DecodeBuffer(buf, chain)
+		require.NoError(t, err)
+		assert.Equal(t, []any{want, "x"}, v, "%v", in)
+	}
+
+	for _, bad := range []any{"missing", 2, 1.5, nil} {
+		_, err := EncodeValue([]any{bad, "x"}, chain)
+		assert.Error(t, err, "%v", bad)
+	}
+
+	// deprecated values are refused on encode but still read
+	var se *SchemaError
+	require.ErrorAs(t, status.Encode(access.NewPutAccess(), "legacy"), &se)
+	assert.Equal(t, ErrEnumDeprecated, se.Code)
+	stored, err := EncodeValue([]any{"legacy", "x"}, SChain(SEnumValues(status.Codes), SString))
+	require.NoError(t, err)
+	v, err = DecodeBuffer(stored, chain)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"legacy", "x"}, v)
+
+	v, err = DecodeBuffer(stored, SChain(status.ToCode(), SString))
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(5_000_000_000), "x"}, v)
+
+	unknown, err := EncodeValue([]any{int64(3), "x"}, SChain(SVarint, SString))
+	require.NoError(t, err)
+	require.ErrorAs(t, ValidateBuffer(unknown, chain), &se)
+	assert.Equal(t, ErrConstraintViolated, se.Code)
+
+	assert.Panics(t, func() { SEnumValues(map[string]int64{"a": 1, "b": 1}) })
+	assert.Panics(t, func() { status.Alias("x", "missing") })
+	assert.Panics(t, func() { status.Deprecate("missing") })
+}
+
+func TestBuildSchema_EnumValues(t *testing.T) {
+	var js SchemaJSON
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "enumValues", "codes": {"low": 10, "high": 100000},
+		"aliases": {"hi": "high"}, "deprecated": ["low"], "decodeCode": true, "nullable": true}`), &js))
+	chain := SChain(BuildSchema(&js))
+
+	buf, err := EncodeValue("hi", chain)
+	require.NoError(t, err)
+	v, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100000), v)
+
+	_, err = EncodeValue("low", chain)
+	assert.Error(t, err)
+	buf, err = EncodeValue(nil, chain)
+	require.NoError(t, err)
+	v, err = DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
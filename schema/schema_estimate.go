@@ -0,0 +1,272 @@
+package schema
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// headerSize is the size of one field header.
+const headerSize = 2
+
+// SizeEstimator can be implemented by custom schemas to take part in
+// SchemaChain.EstimateSize. EstimateSize returns an upper bound of the bytes
+// Encode(put, val) adds to put, headers included.
+type SizeEstimator interface {
+	EstimateSize(val any) int
+}
+
+// EstimateSize returns an upper bound of len(EncodeValue(val, c)) without
+// packing anything, so callers can size destination buffers or reject
+// oversize input early. Fixed-width schemas use their width and strings and
+// collections their length. Schemas the estimator does not know, such as
+// constrained primitives built on SchemaGeneric, are bounded from the value
+// alone; custom schemas can implement SizeEstimator instead. The result says
+// nothing about whether val is valid.
+func (c SchemaChain) EstimateSize(val any) int {
+	size := headerSize // end header
+	switch len(c.Schemas) {
+	case 0:
+		return 0
+	case 1:
+		return size + fieldSize(c.Schemas[0], val)
+	}
+	arr, _ := val.([]any)
+	for i, s := range c.Schemas {
+		var v any
+		if i < len(arr) {
+			v = arr[i]
+		}
+		size += fieldSize(s, v)
+	}
+	return size
+}
+
+// EstimateSize is SchemaChain.EstimateSize for EncodeValueNamed.
+func (c SchemaNamedChain) EstimateSize(val any) int {
+	m, _ := val.(map[string]any)
+	size := headerSize
+	for i, s := range c.Schemas {
+		var v any
+		if i < len(c.FieldNames) {
+			v = m[c.FieldNames[i]]
+		}
+		size += fieldSize(s, v)
+	}
+	return size
+}
+
+// fieldSize bounds the bytes s writes for val at the current level.
+func fieldSize(s Schema, val any) int {
+	switch s := s.(type) {
+	case SizeEstimator:
+		return s.EstimateSize(val)
+	case SchemaSanitized:
+		for _, fn := range s.Sanitizers {
+			val = fn(val)
+		}
+		return fieldSize(s.Schema, val)
+	case SchemaWidened:
+		return fieldSize(s.Schema, val)
+	case SchemaFallback:
+		return max(fieldSize(s.Primary, val), fieldSize(s.Secondary, val))
+	case SchemaConditional:
+		return max(fieldSize(s.Then, val), fieldSize(s.Else, val))
+	case SchemaNullable:
+		if val == nil {
+			return headerSize
+		}
+		return fieldSize(s.Schema, val)
+	case SchemaRequired:
+		return fieldSize(s.Schema, val)
+	case SchemaRuled:
+		return fieldSize(s.Schema, val)
+	case SchemaCached:
+		return fieldSize(s.Schema, val)
+	case SchemaBool, SchemaInt8:
+		return headerSize + 1
+	case SchemaInt16, SchemaFloat16:
+		return headerSize + 2
+	case SchemaInt32, SchemaFloat32:
+		return headerSize + 4
+	case SchemaInt64, SchemaFloat64, SchemaVarint, SchemaDuration, SchemaEnumValues:
+		return headerSize + 8
+	case SchemaTime:
+		return headerSize + 12
+	case SchemaUUID:
+		return headerSize + 16
+	case SchemaIP:
+		return headerSize + s.width()
+	case SchemaString:
+		return headerSize + max(s.Width, valueSize(val))
+	case SchemaBytes:
+		return headerSize + max(s.Width, valueSize(val))
+	case SchemaDecimal:
+		// exponent and int64 coefficient in a tuple, or the coefficient bytes
+		return headerSize + 3*headerSize + 4 + max(8, valueSize(val))
+	case SchemaMoney:
+		// int64 amount and currency code in a tuple
+		return headerSize + 3*headerSize + 8 + 3
+	case SchemaGeoPoint:
+		return headerSize + 3*headerSize + 2*s.width()
+	case SchemaArray:
+		return headerSize + 1 + 8*collectionLen(val)
+	case SchemaBitset:
+		n := s.Len
+		if b, ok := val.(*typetags.Bitset); ok && b != nil {
+			n = b.Len()
+		} else if l := collectionLen(val); l > n {
+			n = l
+		}
+		if ints, ok := val.([]int); ok {
+			for _, i := range ints {
+				n = max(n, i+1)
+			}
+		}
+		blocks := n/4096 + 1
+		return headerSize + 4 + 5*blocks + (n+7)/8
+	case TupleSchema:
+		arr, ok := val.([]any)
+		if !ok || s.Flatten {
+			break
+		}
+		size := 2 * headerSize
+		for i, v := range arr {
+			if i < len(s.Schemas) {
+				size += fieldSize(s.Schemas[i], v)
+			} else {
+				size += anySize(v)
+			}
+		}
+		return size
+	case TupleSchemaNamed:
+		m, ok := val.(map[string]any)
+		if !ok || s.Flatten {
+			break
+		}
+		size := 2 * headerSize
+		for i, name := range s.FieldNames {
+			if i < len(s.Schemas) {
+				size += fieldSize(s.Schemas[i], m[name])
+			}
+		}
+		return size
+	case SRepeatSchema:
+		arr, ok := val.([]any)
+		if !ok || len(s.Schemas) == 0 {
+			break
+		}
+		size := 0
+		for i, v := range arr {
+			size += fieldSize(s.Schemas[i%len(s.Schemas)], v)
+		}
+		return size
+	case SchemaMapUnordered:
+		m, ok := val.(map[string]any)
+		if !ok {
+			break
+		}
+		size := 2 * headerSize
+		for key, sch := range s.Fields {
+			size += headerSize + len(key) + fieldSize(sch, m[key])
+		}
+		return size
+	case SchemaMapRepeat:
+		m, ok := val.(map[string]any)
+		if !ok {
+			break
+		}
+		size := 2 * headerSize
+		for key, v := range m {
+			size += fieldSize(s.Key, key) + fieldSize(s.Value, v)
+		}
+		return size
+	}
+	return anySize(val)
+}
+
+// anySize bounds the bytes of val packed as one field by any schema that
+// writes numbers in at most 8 bytes and strings no longer than they are.
+func anySize(val any) int {
+	switch v := val.(type) {
+	case nil:
+		return headerSize
+	case bool:
+		return headerSize + 1
+	case time.Time:
+		return headerSize + 12
+	case string, []byte, json.Number:
+		return headerSize + max(8, valueSize(v))
+	case *big.Int, *big.Rat, typetags.Decimal:
+		return fieldSize(SchemaDecimal{}, val)
+	case []any:
+		size := 2 * headerSize
+		for _, e := range v {
+			size += anySize(e)
+		}
+		return size
+	case map[string]any:
+		size := 2 * headerSize
+		for k, e := range v {
+			size += headerSize + len(k) + anySize(e)
+		}
+		return size
+	}
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		size := 2 * headerSize
+		for i := 0; i < rv.Len(); i++ {
+			size += anySize(rv.Index(i).Interface())
+		}
+		return size
+	case reflect.Map:
+		size := 2 * headerSize
+		iter := rv.MapRange()
+		for iter.Next() {
+			size += anySize(iter.Key().Interface()) + anySize(iter.Value().Interface())
+		}
+		return size
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return headerSize
+		}
+		return anySize(rv.Elem().Interface())
+	}
+	return headerSize + 8
+}
+
+// valueSize is the byte length of string-like values and of the decimal
+// text of big numbers.
+func valueSize(val any) int {
+	switch v := val.(type) {
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
+	case json.Number:
+		return len(v)
+	case *big.Int:
+		return len(v.Bytes()) + 1
+	case typetags.Decimal:
+		if v.Coef != nil {
+			return len(v.Coef.Bytes()) + 1
+		}
+	case *big.Rat:
+		// a denominator of 2^k multiplies the coefficient by 5^k
+		return len(v.Num().Bytes()) + 3*len(v.Denom().Bytes()) + 1
+	}
+	return 0
+}
+
+func collectionLen(val any) int {
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len()
+	}
+	return 0
+}
@@ -0,0 +1,72 @@
+package schema
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateSize_UpperBound(t *testing.T) {
+	cases := []struct {
+		name  string
+		chain SchemaChain
+		val   any
+	}{
+		{"int16", SChain(SInt16), int16(5)},
+		{"string", SChain(SString), "hello world"},
+		{"mixed", SChain(SInt32, SString, SFloat64, SBool), []any{int32(1), "abc", 2.5, true}},
+		{"constrained", SChain(SInt64.RangeValues(0, 10)), int64(3)},
+		{"date", SChain(SDateRange(true, nil, nil)), time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"tuple", SChain(STuple(SInt8, SString, STuple(SInt64))), []any{int8(1), "x", []any{int64(9)}}},
+		{"named", SChain(STupleNamed([]string{"a", "b"}, SString, SNullInt32)), map[string]any{"a": "zz"}},
+		{"repeat", SChain(SRepeat(0, -1, SString)), []any{"a", "bb", "ccc"}},
+		{"mapUnordered", SChain(SMapUnordered(map[string]Schema{"k": SInt16, "name": SString})), map[string]any{"k": int16(1), "name": "n"}},
+		{"mapRepeat", SChain(SMapRepeat(SString, SAny)), map[string]any{"a": int64(1), "b": []any{"x", 2.5}}},
+		{"decimal", SChain(SDecimal), "123456789012345678901234567890.5"},
+		{"decimalRat", SChain(SDecimal), big.NewRat(1, 1024)},
+		{"time", SChain(STime, SDuration), []any{time.Now(), "90m"}},
+		{"uuid", SChain(SUUID), "123e4567-e89b-12d3-a456-426614174000"},
+		{"array", SChain(SArray(SInt32, -1, -1)), []int32{1, 2, 3}},
+		{"bitset", SChain(SBitset), typetags.BitsetOf(10000, 1, 5000, 9999)},
+		{"bitsetDense", SChain(SBitset), []bool{true, true, false, true}},
+		{"number", SChain(SchemaNumber{}), json.Number("12.5")},
+		{"any", SChain(SAny), map[string]any{"nested": map[string]any{"list": []any{int32(1), "two", 3.0, nil}}}},
+		{"sanitized", SChain(SSanitized(SString, SanitizeTrim)), "   padded   "},
+	}
+	for _, c := range cases {
+		buf, err := EncodeValue(c.val, c.chain)
+		require.NoError(t, err, c.name)
+		est := c.chain.EstimateSize(c.val)
+		assert.GreaterOrEqual(t, est, len(buf), c.name)
+	}
+}
+
+func TestEstimateSize_ExactForFixedWidths(t *testing.T) {
+	chain := SChain(SInt32, SString, SFloat64)
+	val := []any{int32(7), "abcd", 1.5}
+	buf, err := EncodeValue(val, chain)
+	require.NoError(t, err)
+	assert.Equal(t, len(buf), chain.EstimateSize(val))
+
+	named := SchemaNamedChain{
+		SchemaChain: SChain(SInt64, SString),
+		FieldNames:  []string{"id", "tag"},
+	}
+	rec := map[string]any{"id": int64(1), "tag": "t"}
+	buf, err = EncodeValueNamed(rec, named)
+	require.NoError(t, err)
+	assert.Equal(t, len(buf), named.EstimateSize(rec))
+}
+
+type fixedEstimate struct{ SchemaAny }
+
+func (fixedEstimate) EstimateSize(any) int { return 1000 }
+
+func TestEstimateSize_CustomEstimator(t *testing.T) {
+	assert.Equal(t, 2+1000, SChain(fixedEstimate{}).EstimateSize("x"))
+}
@@ -0,0 +1,144 @@
+package schema
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/quickwritereader/PackOS/access"
+)
+
+// FailureObserver is called once for every Validate or Decode of a chain
+// that fails. name and code are those of the innermost SchemaError, which
+// names the schema whose constraint rejected the input rather than the
+// containers around it.
+type FailureObserver interface {
+	ObserveFailure(name string, code ErrorCode)
+}
+
+// WithFailureObserver returns a copy of c that reports every failed
+// Validate and Decode to obs. Encode is not observed.
+func (c SchemaChain) WithFailureObserver(obs FailureObserver) SchemaChain {
+	out := SchemaChain{Schemas: make([]Schema, len(c.Schemas))}
+	for i, s := range c.Schemas {
+		out.Schemas[i] = failureObserved{s, obs}
+	}
+	return out
+}
+
+// WithFailureObserver is SchemaChain.WithFailureObserver for named chains.
+func (c SchemaNamedChain) WithFailureObserver(obs FailureObserver) SchemaNamedChain {
+	c.SchemaChain = c.SchemaChain.WithFailureObserver(obs)
+	return c
+}
+
+// failureObserved reports the errors of Schema.
+type failureObserved struct {
+	Schema
+	obs FailureObserver
+}
+
+func (s failureObserved) Validate(seq *access.SeqGetAccess) error {
+	err := s.Schema.Validate(seq)
+	s.report(err)
+	return err
+}
+
+func (s failureObserved) Decode(seq *access.SeqGetAccess) (any, error) {
+	val, err := s.Schema.Decode(seq)
+	s.report(err)
+	return val, err
+}
+
+func (s failureObserved) report(err error) {
+	if err == nil {
+		return
+	}
+	if se := innermostSchemaError(err); se != nil {
+		s.obs.ObserveFailure(se.Name, se.Code)
+	} else {
+		s.obs.ObserveFailure("", ErrUnknown)
+	}
+}
+
+// innermostSchemaError returns the last SchemaError in err's chain, or nil.
+func innermostSchemaError(err error) *SchemaError {
+	var last *SchemaError
+	for err != nil {
+		var se *SchemaError
+		if !errors.As(err, &se) {
+			break
+		}
+		last = se
+		err = se.InnerErr
+	}
+	return last
+}
+
+// FailureCount is the number of failures attributed to one schema name and
+// error code.
+type FailureCount struct {
+	Name  string
+	Code  ErrorCode
+	Count int
+}
+
+// FailureStats is a FailureObserver that counts failures per schema name
+// and error code, to show which constraints reject real traffic most
+// often. It is safe for concurrent use.
+type FailureStats struct {
+	mu     sync.Mutex
+	counts map[failureKey]int
+}
+
+type failureKey struct {
+	name string
+	code ErrorCode
+}
+
+// NewFailureStats returns an empty FailureStats.
+func NewFailureStats() *FailureStats {
+	return &FailureStats{counts: map[failureKey]int{}}
+}
+
+func (f *FailureStats) ObserveFailure(name string, code ErrorCode) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.counts[failureKey{name, code}]++
+}
+
+// Count returns the failures recorded for name and code.
+func (f *FailureStats) Count(name string, code ErrorCode) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.counts[failureKey{name, code}]
+}
+
+// Counts returns the counters, most frequent first and then by name and
+// code.
+func (f *FailureStats) Counts() []FailureCount {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]FailureCount, 0, len(f.counts))
+	for k, n := range f.counts {
+		out = append(out, FailureCount{Name: k.name, Code: k.code, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Code < b.Code
+	})
+	return out
+}
+
+// Reset discards all counters.
+func (f *FailureStats) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.counts = map[failureKey]int{}
+}
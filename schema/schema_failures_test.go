@@ -0,0 +1,54 @@
+package schema
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailureStats_CountsInnermostFailures(t *testing.T) {
+	loose := SChain(STupleNamed([]string{"id", "age"}, SString, SInt16))
+	strict := SChain(STupleNamed([]string{"id", "age"}, SString.Prefix("u-"), SInt16.RangeValues(0, 150)))
+
+	records := []map[string]any{
+		{"id": "u-1", "age": int16(30)},
+		{"id": "x-2", "age": int16(30)},
+		{"id": "u-3", "age": int16(200)},
+		{"id": "u-4", "age": int16(-1)},
+	}
+	var bufs [][]byte
+	for _, rec := range records {
+		buf, err := EncodeValue(rec, loose)
+		require.NoError(t, err)
+		bufs = append(bufs, buf)
+	}
+
+	stats := NewFailureStats()
+	observed := strict.WithFailureObserver(stats)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, buf := range bufs {
+				_ = ValidateBuffer(buf, observed)
+				_, _ = DecodeBuffer(buf, observed)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 4 goroutines, each validating and decoding every buffer
+	assert.Equal(t, []FailureCount{
+		{Name: SchemaInt16Name, Code: ErrOutOfRange, Count: 16},
+		{Name: SchemaStringName, Code: ErrStringPrefix, Count: 8},
+	}, stats.Counts())
+	assert.Equal(t, 8, stats.Count(SchemaStringName, ErrStringPrefix))
+
+	stats.Reset()
+	assert.Empty(t, stats.Counts())
+	require.NoError(t, ValidateBuffer(bufs[0], observed))
+	assert.Empty(t, stats.Counts())
+}
@@ -0,0 +1,105 @@
+package schema
+
+import (
+	"errors"
+
+	"github.com/quickwritereader/PackOS/access"
+)
+
+const SchemaFallbackName = "SchemaFallback"
+
+// SchemaFallback reads a field with Primary and, when the field does not
+// have the type or width Primary expects, rewinds and reads it with
+// Secondary. It suits fields whose representation changed between producer
+// versions, such as an int32 id that became a string. Other failures, such
+// as a value out of range, are reported without trying Secondary. Encode
+// likewise tries Primary first and falls back when it rejects the value's
+// type.
+type SchemaFallback struct {
+	Primary, Secondary Schema
+}
+
+// SFallback builds a SchemaFallback trying primary, then secondary.
+func SFallback(primary, secondary Schema) SchemaFallback {
+	return SchemaFallback{Primary: primary, Secondary: secondary}
+}
+
+func (s SchemaFallback) IsNullable() bool {
+	return s.Primary.IsNullable() || s.Secondary.IsNullable()
+}
+
+func (s SchemaFallback) Validate(seq *access.SeqGetAccess) error {
+	_, err := s.decode(seq, func(sch Schema) (any, error) { return nil, sch.Validate(seq) })
+	return err
+}
+
+func (s SchemaFallback) Decode(seq *access.SeqGetAccess) (any, error) {
+	return s.decode(seq, func(sch Schema) (any, error) { return sch.Decode(seq) })
+}
+
+func (s SchemaFallback) decode(seq *access.SeqGetAccess, read func(Schema) (any, error)) (any, error) {
+	pos := seq.CurrentIndex()
+	cp := seq.Checkpoint()
+	v, err := read(s.Primary)
+	if err == nil || !isShapeMismatch(err) {
+		return v, err
+	}
+	if serr := seq.Restore(cp); serr != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, SchemaFallbackName, "", pos, serr)
+	}
+	v, err2 := read(s.Secondary)
+	if err2 != nil {
+		return nil, NewSchemaError(ErrConstraintViolated, SchemaFallbackName, "", pos, errors.Join(err, err2))
+	}
+	return v, nil
+}
+
+func (s SchemaFallback) Encode(put *access.PutAccess, val any) error {
+	// Encode into a scratch buffer first, so that a failed attempt leaves
+	// nothing behind in put.
+	scratch := access.NewPutAccessFromPool()
+	defer access.ReleasePutAccess(scratch)
+	err := s.Primary.Encode(scratch, val)
+	if err == nil {
+		return appendPackedFields(put, scratch.Pack())
+	}
+	if !isShapeMismatch(err) {
+		return err
+	}
+	if err2 := s.Secondary.Encode(put, val); err2 != nil {
+		return NewSchemaError(ErrEncode, SchemaFallbackName, "", -1, errors.Join(err, err2))
+	}
+	return nil
+}
+
+// isShapeMismatch reports whether err, or a SchemaError it wraps, says a
+// field or value had the wrong type or width.
+func isShapeMismatch(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if se, ok := err.(*SchemaError); ok {
+			if se.InnerErr == ErrTypeMisMatch {
+				return true
+			}
+			if _, ok := se.InnerErr.(SizeExact); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// appendPackedFields copies the top-level fields of a packed buffer to put.
+func appendPackedFields(put *access.PutAccess, buf []byte) error {
+	seq, err := access.NewSeqGetAccess(buf)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaFallbackName, "", -1, err)
+	}
+	for i := 0; i < seq.ArgCount(); i++ {
+		payload, typ, err := seq.Next()
+		if err != nil {
+			return NewSchemaError(ErrEncode, SchemaFallbackName, "", i, err)
+		}
+		put.AppendTagAndValue(typ, payload)
+	}
+	return nil
+}
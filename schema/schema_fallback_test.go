@@ -0,0 +1,72 @@
+package schema
+
+import (
+	"testing"
+
+	pack "github.com/quickwritereader/PackOS/packable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSFallback_DecodeOldAndNew(t *testing.T) {
+	// v1 producers wrote the id as int32, v2 producers as a string
+	chain := SChain(SFallback(SInt32, SString), SBool)
+	v1 := pack.Pack(pack.PackInt32(17), pack.PackBool(true))
+	v2 := pack.Pack(pack.PackString("A-17"), pack.PackBool(false))
+
+	require.NoError(t, ValidateBuffer(v1, chain))
+	require.NoError(t, ValidateBuffer(v2, chain))
+	got, err := DecodeBuffer(v1, chain)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int32(17), true}, got)
+	got, err = DecodeBuffer(v2, chain)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"A-17", false}, got)
+
+	// width mismatch also falls back
+	wide := SChain(SFallback(SInt32, SInt64), SBool)
+	got, err = DecodeBuffer(pack.Pack(pack.PackInt64(1<<40), pack.PackBool(true)), wide)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(1 << 40), true}, got)
+
+	_, err = DecodeBuffer(pack.Pack(pack.PackFloat64(1), pack.PackBool(true)), chain)
+	assertSchemaError(t, err, ErrConstraintViolated)
+}
+
+func TestSFallback_ValueErrorsDoNotFallBack(t *testing.T) {
+	chain := SChain(SFallback(SInt32.RangeValues(0, 10), SInt32))
+	buf := pack.Pack(pack.PackInt32(50))
+	_, err := DecodeBuffer(buf, chain)
+	assertSchemaError(t, err, ErrOutOfRange)
+}
+
+func TestSFallback_NestedMismatch(t *testing.T) {
+	chain := SChain(SFallback(STuple(SInt16, SString), STuple(SString, SString)))
+	buf, err := EncodeValue([]any{"x", "y"}, SChain(STuple(SString, SString)))
+	require.NoError(t, err)
+	got, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"x", "y"}, got)
+}
+
+func TestSFallback_Encode(t *testing.T) {
+	chain := SChain(SFallback(SInt32, SString), SBool)
+	buf, err := EncodeValue([]any{int32(3), true}, chain)
+	require.NoError(t, err)
+	assert.Equal(t, pack.Pack(pack.PackInt32(3), pack.PackBool(true)), buf)
+
+	buf, err = EncodeValue([]any{"B-3", true}, chain)
+	require.NoError(t, err)
+	assert.Equal(t, pack.Pack(pack.PackString("B-3"), pack.PackBool(true)), buf)
+
+	_, err = EncodeValue([]any{2.5, true}, chain)
+	assert.Error(t, err)
+}
+
+func TestBuildSchema_Fallback(t *testing.T) {
+	s := BuildSchema(&SchemaJSON{Type: "fallback", Schema: []SchemaJSON{{Type: "int32"}, {Type: "string"}}})
+	got, err := DecodeBuffer(pack.Pack(pack.PackString("z")), SChain(s))
+	require.NoError(t, err)
+	assert.Equal(t, "z", got)
+	assert.Panics(t, func() { BuildSchema(&SchemaJSON{Type: "fallback"}) })
+}
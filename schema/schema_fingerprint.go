@@ -0,0 +1,33 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+)
+
+// fingerprintVersion starts the hashed form, so that a change to it cannot
+// collide with fingerprints computed before.
+const fingerprintVersion = "packos-schema-fingerprint-v1\n"
+
+// Fingerprint returns the SHA-256 hash of js in a canonical form: documents
+// that differ only in key order, white space or fields left at their zero
+// value share a fingerprint. It suits registry ids, schema references in
+// envelopes and compatibility caches. The order of lists such as
+// fieldNames and schema is significant, since it sets field positions and
+// encode order. Refs are hashed as written; fingerprint the document
+// SchemaModules.Load returns to cover the definitions they name. Fingerprint
+// panics if Extra holds values encoding/json cannot marshal.
+func Fingerprint(js *SchemaJSON) [32]byte {
+	// encoding/json writes struct fields in declaration order, map keys
+	// sorted and no insignificant white space
+	data, err := json.Marshal(js)
+	if err != nil {
+		panic("Fingerprint: " + err.Error())
+	}
+	h := sha256.New()
+	h.Write([]byte(fingerprintVersion))
+	h.Write(data)
+	var out [32]byte
+	h.Sum(out[:0])
+	return out
+}
@@ -0,0 +1,31 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	parse := func(doc string) *SchemaJSON {
+		var js SchemaJSON
+		require.NoError(t, json.Unmarshal([]byte(doc), &js))
+		return &js
+	}
+	a := parse(`{"type": "tuple", "fieldNames": ["id", "name"],
+		"schema": [{"type": "int32", "min": 1}, {"type": "string", "extra": {"label": "Name", "hint": "full"}}]}`)
+	b := parse(`{"schema":[{"min":1,"type":"int32","nullable":false},{"extra":{"hint":"full","label":"Name"},"type":"string"}],
+		"fieldNames":["id","name"],"type":"tuple","defs":{}}`)
+	assert.Equal(t, Fingerprint(a), Fingerprint(b))
+
+	c := parse(`{"type": "tuple", "fieldNames": ["id", "name"],
+		"schema": [{"type": "int32", "min": 2}, {"type": "string", "extra": {"label": "Name", "hint": "full"}}]}`)
+	assert.NotEqual(t, Fingerprint(a), Fingerprint(c))
+	d := parse(`{"type": "tuple", "fieldNames": ["name", "id"],
+		"schema": [{"type": "string", "extra": {"label": "Name", "hint": "full"}}, {"type": "int32", "min": 1}]}`)
+	assert.NotEqual(t, Fingerprint(a), Fingerprint(d), "field order sets positions")
+
+	assert.Panics(t, func() { Fingerprint(&SchemaJSON{Type: "int8", Extra: map[string]any{"f": func() {}}}) })
+}
@@ -0,0 +1,71 @@
+package schema
+
+import (
+	"encoding/binary"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const SchemaFloat16Name = "SchemaFloat16"
+
+// SchemaFloat16 is a two-byte float field that decodes to float32. BFloat
+// selects bfloat16 instead of IEEE half precision; the wire cannot tell them
+// apart, so writer and reader must agree on it.
+type SchemaFloat16 struct {
+	Nullable bool
+	BFloat   bool
+}
+
+var (
+	SFloat16      = SchemaFloat16{}
+	SBFloat16     = SchemaFloat16{BFloat: true}
+	SNullFloat16  = SchemaFloat16{Nullable: true}
+	SNullBFloat16 = SchemaFloat16{Nullable: true, BFloat: true}
+)
+
+func (s SchemaFloat16) IsNullable() bool { return s.Nullable }
+
+func (s SchemaFloat16) Validate(seq *access.SeqGetAccess) error {
+	return validatePrimitive(SchemaFloat16Name, seq, typetags.TypeFloating, 2, s.Nullable)
+}
+
+func (s SchemaFloat16) Decode(seq *access.SeqGetAccess) (any, error) {
+	payload, err := validatePrimitiveAndGetPayload(SchemaFloat16Name, seq, typetags.TypeFloating, 2, s.Nullable)
+	if err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		return nil, nil
+	}
+	if s.BFloat {
+		return access.BFloat16frombits(binary.LittleEndian.Uint16(payload)), nil
+	}
+	return access.Float16frombits(binary.LittleEndian.Uint16(payload)), nil
+}
+
+func (s SchemaFloat16) Encode(put *access.PutAccess, val any) error {
+	if s.Nullable && val == nil {
+		put.AddNullableFloat32(nil)
+		return nil
+	}
+	val, err := coerceJSONNumber[float32](val)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaFloat16Name, "", -1, err)
+	}
+	var f float32
+	switch v := val.(type) {
+	case float32:
+		f = v
+	case float64:
+		f = float32(v)
+	default:
+		return NewSchemaError(ErrEncode, SchemaFloat16Name, "", -1, ErrTypeMisMatch)
+	}
+	if s.BFloat {
+		put.AddBFloat16(f)
+	} else {
+		put.AddFloat16(f)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package schema
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const SchemaGeoPointName = "SchemaGeoPoint"
+
+// Point is a WGS 84 position in degrees.
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// SchemaGeoPoint packs a Point as a tuple of its latitude and longitude,
+// two float64 values, or with Compact two int32 counts of micro-degrees,
+// about 11 cm at the equator. Latitudes must lie in [-90, 90] and
+// longitudes in [-180, 180] on encode, validation and decode. Decode
+// returns a Point.
+//
+// Encode accepts Point, *Point and maps with "lat" and "lon" keys, as
+// decoded from JSON.
+type SchemaGeoPoint struct {
+	Compact  bool
+	Nullable bool
+}
+
+var (
+	SGeoPoint        = SchemaGeoPoint{}
+	SGeoPointCompact = SchemaGeoPoint{Compact: true}
+)
+
+func (s SchemaGeoPoint) IsNullable() bool { return s.Nullable }
+
+func (s SchemaGeoPoint) width() int {
+	if s.Compact {
+		return 4
+	}
+	return 8
+}
+
+func (s SchemaGeoPoint) decodeValidate(seq *access.SeqGetAccess, decode bool) (any, error) {
+	pos := seq.CurrentIndex()
+	width, err := precheck(SchemaGeoPointName, pos, seq, typetags.TypeTuple, 0, s.Nullable)
+	if err != nil {
+		return nil, err
+	}
+	if width == 0 {
+		if !s.Nullable {
+			return nil, NewSchemaError(ErrConstraintViolated, SchemaGeoPointName, "", pos, ErrTypeMisMatch)
+		}
+		if err := seq.Advance(); err != nil {
+			return nil, NewSchemaError(ErrUnexpectedEOF, SchemaGeoPointName, "", pos, err)
+		}
+		return nil, nil
+	}
+	sub, err := seq.PeekNestedSeq()
+	if err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, SchemaGeoPointName, "", pos, err)
+	}
+	if sub.ArgCount() != 2 {
+		return nil, NewSchemaError(ErrConstraintViolated, SchemaGeoPointName, "", pos, SizeExact{2, sub.ArgCount()})
+	}
+	var coords [2]float64
+	for i := range coords {
+		if s.Compact {
+			payload, err := validatePrimitiveAndGetPayload(SchemaGeoPointName, sub, typetags.TypeInteger, 4, false)
+			if err != nil {
+				return nil, err
+			}
+			coords[i] = float64(int32(binary.LittleEndian.Uint32(payload))) / 1e6
+		} else {
+			payload, err := validatePrimitiveAndGetPayload(SchemaGeoPointName, sub, typetags.TypeFloating, 8, false)
+			if err != nil {
+				return nil, err
+			}
+			coords[i] = math.Float64frombits(binary.LittleEndian.Uint64(payload))
+		}
+	}
+	p := Point{Lat: coords[0], Lon: coords[1]}
+	if err := checkPoint(p, pos); err != nil {
+		return nil, err
+	}
+	if err := seq.Advance(); err != nil {
+		return nil, NewSchemaError(ErrUnexpectedEOF, SchemaGeoPointName, "", pos, err)
+	}
+	if !decode {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// checkPoint rejects coordinates off the globe, NaN included.
+func checkPoint(p Point, pos int) error {
+	for _, c := range []struct {
+		field string
+		val   float64
+		limit float64
+	}{{"lat", p.Lat, 90}, {"lon", p.Lon, 180}} {
+		if !(c.val >= -c.limit && c.val <= c.limit) {
+			min, max := -c.limit, c.limit
+			return NewSchemaError(ErrOutOfRange, SchemaGeoPointName, c.field, pos,
+				RangeErrorDetails[float64]{Min: &min, Max: &max, Actual: c.val})
+		}
+	}
+	return nil
+}
+
+func (s SchemaGeoPoint) Validate(seq *access.SeqGetAccess) error {
+	_, err := s.decodeValidate(seq, false)
+	return err
+}
+
+func (s SchemaGeoPoint) Decode(seq *access.SeqGetAccess) (any, error) {
+	return s.decodeValidate(seq, true)
+}
+
+func (s SchemaGeoPoint) Encode(put *access.PutAccess, val any) error {
+	if s.Nullable && val == nil {
+		put.AddAnyTuple(nil, false)
+		return nil
+	}
+	p, err := toPoint(val)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaGeoPointName, "", -1, err)
+	}
+	if err := checkPoint(p, -1); err != nil {
+		return err
+	}
+	nested := put.BeginTuple()
+	if s.Compact {
+		nested.AddInt32(int32(math.Round(p.Lat * 1e6)))
+		nested.AddInt32(int32(math.Round(p.Lon * 1e6)))
+	} else {
+		nested.AddFloat64(p.Lat)
+		nested.AddFloat64(p.Lon)
+	}
+	put.EndNested(nested)
+	return nil
+}
+
+func toPoint(val any) (Point, error) {
+	switch v := val.(type) {
+	case Point:
+		return v, nil
+	case *Point:
+		if v != nil {
+			return *v, nil
+		}
+	case map[string]any:
+		lat, okLat := convertToNumber[float64](v["lat"])
+		lon, okLon := convertToNumber[float64](v["lon"])
+		if okLat && okLon {
+			return Point{Lat: lat, Lon: lon}, nil
+		}
+	}
+	return Point{}, ErrTypeMisMatch
+}
@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSGeoPoint(t *testing.T) {
+	oslo := Point{Lat: 59.913868, Lon: 10.752245}
+	for _, s := range []SchemaGeoPoint{SGeoPoint, SGeoPointCompact} {
+		chain := SChain(s)
+		buf, err := EncodeValue(oslo, chain)
+		require.NoError(t, err)
+		require.NoError(t, ValidateBuffer(buf, chain))
+		assert.LessOrEqual(t, len(buf), chain.EstimateSize(oslo))
+		v, err := DecodeBuffer(buf, chain)
+		require.NoError(t, err)
+		p := v.(Point)
+		assert.InDelta(t, oslo.Lat, p.Lat, 1e-6)
+		assert.InDelta(t, oslo.Lon, p.Lon, 1e-6)
+
+		for _, bad := range []any{Point{90.5, 0}, Point{0, -180.1}, Point{math.NaN(), 0}, "59.9,10.7"} {
+			_, err := EncodeValue(bad, chain)
+			assert.Error(t, err, "%v", bad)
+		}
+	}
+
+	compact, err := EncodeValue(oslo, SChain(SGeoPointCompact))
+	require.NoError(t, err)
+	full, err := EncodeValue(oslo, SChain(SGeoPoint))
+	require.NoError(t, err)
+	assert.Less(t, len(compact), len(full))
+	assert.Error(t, ValidateBuffer(compact, SChain(SGeoPoint)))
+
+	// stored values are checked too
+	off, err := EncodeValue([]any{91.0, 0.0}, SChain(STuple(SFloat64, SFloat64)))
+	require.NoError(t, err)
+	var se *SchemaError
+	require.ErrorAs(t, ValidateBuffer(off, SChain(SGeoPoint)), &se)
+	assert.Equal(t, ErrOutOfRange, se.Code)
+	assert.Equal(t, "lat", se.Field)
+}
+
+func TestBuildSchema_GeoPoint(t *testing.T) {
+	chain := SchemaNamedChain{
+		SchemaChain: SChain(BuildSchema(&SchemaJSON{Type: "geoPointCompact", Nullable: true})),
+		FieldNames:  []string{"at"},
+	}
+	buf, err := EncodeJSON([]byte(`{"at": {"lat": -33.8688, "lon": 151.2093}}`), chain)
+	require.NoError(t, err)
+	v, err := DecodeBufferNamed(buf, chain)
+	require.NoError(t, err)
+	out, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"at": {"lat": -33.8688, "lon": 151.2093}}`, string(out))
+
+	buf, err = EncodeValueNamed(map[string]any{}, chain)
+	require.NoError(t, err)
+	v, err = DecodeBufferNamed(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"at": nil}, v)
+}
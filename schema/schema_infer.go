@@ -0,0 +1,471 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const (
+	// inferMaxEnum is the largest distinct-value count proposed as a string enum.
+	inferMaxEnum = 8
+	// inferMaxKeys is the largest key count inferred as a keyed map;
+	// wider maps are proposed as "mapRepeat".
+	inferMaxKeys = 32
+)
+
+// Infer walks sample buffers and proposes a SchemaJSON that accepts all of them.
+// It is meant to bootstrap schemas for existing producers and its output should
+// be reviewed before use.
+//
+//   - Integers keep their wire width and observed Min/Max (in Extra when nullable);
+//     mixed widths become "number".
+//   - Fields that were ever null, or map keys that were ever missing, are nullable.
+//   - Strings with at most 8 distinct values, each seen at least twice on average,
+//     get an anchored Pattern and the values in Extra["enum"].
+//   - Maps become "mapUnordered" keyed by the observed keys in first-seen order.
+//   - Tuples of a fixed length become positional "tuple"; variable lengths
+//     become a flattened "repeat" of the merged element schema, so values
+//     keep the plain []any shape.
+//   - Conflicting types fall back to "any".
+//
+// A single top-level field yields that field's schema. Buffers with several
+// top-level fields yield a "tuple" whose Schema lists one entry per field;
+// build those as SChain(buildSchemas(js.Schema)...) or set FieldNames.
+// Infer returns nil when no buffer could be read.
+func Infer(bufs [][]byte) *SchemaJSON {
+	return inferBuffers(bufs, false)
+}
+
+// InferJSON proposes a SchemaJSON from sample JSON documents, merging their
+// shapes the same way Infer does. Because EncodeJSON coerces numbers to the
+// declared width, integers widen to the widest observed type instead of
+// falling back to "number", integers mixed with fractions become "float64",
+// and null, {} and [] only make a field nullable; such maps and tuples get
+// the "emptyAsNull" sanitizer so EncodeJSON packs {} and [] as null again.
+// Each document should hold one JSON value; an invalid document fails with
+// its index.
+func InferJSON(docs [][]byte) (*SchemaJSON, error) {
+	bufs := make([][]byte, 0, len(docs))
+	for i, doc := range docs {
+		buf, err := access.FromJSON(bytes.NewReader(doc))
+		if err != nil {
+			return nil, fmt.Errorf("InferJSON: document %d: %w", i, err)
+		}
+		bufs = append(bufs, buf)
+	}
+	return inferBuffers(bufs, true), nil
+}
+
+func inferBuffers(bufs [][]byte, fromJSON bool) *SchemaJSON {
+	root := inferNode{fromJSON: fromJSON}
+	seen := 0
+	for _, buf := range bufs {
+		seq, err := access.NewSeqGetAccess(buf)
+		if err != nil {
+			continue
+		}
+		if err := root.observeTuple(seq); err != nil {
+			continue
+		}
+		seen++
+	}
+	if seen == 0 {
+		return nil
+	}
+	if root.minCount == 1 && root.maxCount == 1 {
+		js := root.elems[0].build()
+		return &js
+	}
+	js := root.build()
+	js.Nullable = false
+	return &js
+}
+
+type inferNode struct {
+	// fromJSON relaxes width and null-tag checks for JSON samples
+	fromJSON bool
+	samples  int
+	nulls    int
+	nullTags map[typetags.Type]bool
+	kind     string
+
+	// numbers
+	hasRange   bool
+	minF, maxF float64
+
+	// strings
+	values         map[string]int
+	tooMany        bool
+	minLen, maxLen int
+
+	// tuples
+	minCount, maxCount int
+	elems              []*inferNode
+	elem               *inferNode
+
+	// maps
+	mapSamples int
+	keys       []string
+	fields     map[string]*inferNode
+	present    map[string]int
+	value      *inferNode
+}
+
+func (n *inferNode) setKind(kind string) {
+	switch {
+	case n.kind == "" || n.kind == kind:
+		n.kind = kind
+	case isInferNumber(n.kind) && isInferNumber(kind) && n.fromJSON:
+		n.kind = widerJSONNumber(n.kind, kind)
+	case isInferNumber(n.kind) && isInferNumber(kind):
+		n.kind = "number"
+	case n.kind == "string" && kind == "bytes" || n.kind == "bytes" && kind == "string":
+		n.kind = "bytes"
+	default:
+		n.kind = "any"
+	}
+}
+
+func (n *inferNode) child() *inferNode {
+	return &inferNode{fromJSON: n.fromJSON}
+}
+
+// widerJSONNumber merges integer widths to the widest one; any float makes it float64.
+func widerJSONNumber(a, b string) string {
+	const order = "int8 int16 int32 int64"
+	ia, ib := strings.Index(order, a), strings.Index(order, b)
+	if ia < 0 || ib < 0 {
+		return "float64"
+	}
+	if ia > ib {
+		return a
+	}
+	return b
+}
+
+func isInferNumber(kind string) bool {
+	switch kind {
+	case "int8", "int16", "int32", "int64", "varint", "float16", "float32", "float64", "number":
+		return true
+	}
+	return false
+}
+
+func (n *inferNode) observeNull(tag typetags.Type) {
+	n.samples++
+	n.nulls++
+	if n.nullTags == nil {
+		n.nullTags = map[typetags.Type]bool{}
+	}
+	n.nullTags[tag] = true
+}
+
+func (n *inferNode) observeNumber(f float64) {
+	if !n.hasRange {
+		n.hasRange, n.minF, n.maxF = true, f, f
+		return
+	}
+	n.minF = min(n.minF, f)
+	n.maxF = max(n.maxF, f)
+}
+
+func (n *inferNode) observeString(s string) {
+	l := len(s)
+	if n.values == nil {
+		n.values = map[string]int{}
+		n.minLen, n.maxLen = l, l
+	}
+	n.minLen = min(n.minLen, l)
+	n.maxLen = max(n.maxLen, l)
+	if n.tooMany {
+		return
+	}
+	n.values[s]++
+	if len(n.values) > inferMaxEnum {
+		n.tooMany = true
+		n.values = map[string]int{}
+	}
+}
+
+// observeField reads the field at the current position of seq and advances past it.
+func (n *inferNode) observeField(seq *access.SeqGetAccess) error {
+	typ, width, err := seq.PeekTypeWidth()
+	if err != nil {
+		return err
+	}
+	if typ == typetags.TypeTuple || typ.IsMap() {
+		if width == 0 {
+			n.observeNull(typ)
+			return seq.Advance()
+		}
+		nested, err := seq.PeekNestedSeq()
+		if err != nil {
+			return err
+		}
+		if typ == typetags.TypeTuple {
+			err = n.observeTuple(nested)
+		} else {
+			err = n.observeMap(nested)
+		}
+		if err != nil {
+			return err
+		}
+		return seq.Advance()
+	}
+
+	payload, typ, err := seq.Next()
+	if err != nil {
+		return err
+	}
+	if typ == typetags.TypeString {
+		n.samples++
+		if utf8.Valid(payload) {
+			n.setKind("string")
+		} else {
+			n.setKind("bytes")
+		}
+		n.observeString(string(payload))
+		return nil
+	}
+	v, err := access.DecodePrimitive(typ, payload)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		n.observeNull(typ)
+		return nil
+	}
+	n.samples++
+	switch val := v.(type) {
+	case bool:
+		n.setKind("bool")
+	case time.Time:
+		n.setKind("time")
+	case int8:
+		n.setKind("int8")
+		n.observeNumber(float64(val))
+	case int16:
+		n.setKind("int16")
+		n.observeNumber(float64(val))
+	case int32:
+		n.setKind("int32")
+		n.observeNumber(float64(val))
+	case int64:
+		if access.IsVarintWidth(len(payload)) {
+			n.setKind("varint")
+		} else {
+			n.setKind("int64")
+		}
+		n.observeNumber(float64(val))
+	case float32:
+		if len(payload) == 2 {
+			n.setKind("float16")
+		} else {
+			n.setKind("float32")
+		}
+		n.observeNumber(float64(val))
+	case float64:
+		n.setKind("float64")
+		n.observeNumber(val)
+	default:
+		n.setKind("any")
+	}
+	return nil
+}
+
+func (n *inferNode) observeTuple(seq *access.SeqGetAccess) error {
+	n.samples++
+	n.setKind("tuple")
+	count := seq.ArgCount()
+	if n.elem == nil {
+		n.elem = n.child()
+		n.minCount, n.maxCount = count, count
+	}
+	n.minCount = min(n.minCount, count)
+	n.maxCount = max(n.maxCount, count)
+	for i := 0; i < count; i++ {
+		if i == len(n.elems) {
+			n.elems = append(n.elems, n.child())
+		}
+		// positional and merged views see the same field
+		mark := *seq
+		if err := n.elems[i].observeField(seq); err != nil {
+			return err
+		}
+		if err := n.elem.observeField(&mark); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *inferNode) observeMap(seq *access.SeqGetAccess) error {
+	n.samples++
+	n.mapSamples++
+	n.setKind("map")
+	if n.fields == nil {
+		n.fields = map[string]*inferNode{}
+		n.present = map[string]int{}
+		n.value = n.child()
+	}
+	for i := 0; i < seq.ArgCount(); i += 2 {
+		payload, typ, err := seq.Next()
+		if err != nil {
+			return err
+		}
+		if typ != typetags.TypeString {
+			return ErrTypeMisMatch
+		}
+		key := string(payload)
+		field, ok := n.fields[key]
+		if !ok {
+			field = n.child()
+			n.fields[key] = field
+			n.keys = append(n.keys, key)
+		}
+		n.present[key]++
+		mark := *seq
+		if err := field.observeField(seq); err != nil {
+			return err
+		}
+		if err := n.value.observeField(&mark); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nullable reports whether the node needs a nullable schema. missing counts
+// samples where the enclosing map did not carry the key.
+func (n *inferNode) nullable(missing int) bool {
+	return n.nulls > 0 || missing > 0
+}
+
+func (n *inferNode) build() SchemaJSON {
+	return n.buildWith(0)
+}
+
+func (n *inferNode) buildWith(missing int) SchemaJSON {
+	nullable := n.nullable(missing)
+	if n.kind == "" {
+		// only nulls were seen
+		return SchemaJSON{Type: "any"}
+	}
+	// JSON nulls carry no type; EncodeJSON writes them with the field's own tag
+	if !n.fromJSON {
+		for tag := range n.nullTags {
+			if !inferKindAccepts(n.kind, tag) {
+				return SchemaJSON{Type: "any"}
+			}
+		}
+	}
+
+	switch n.kind {
+	case "bool", "time", "float16", "float32", "float64":
+		return SchemaJSON{Type: n.kind, Nullable: nullable}
+	case "int8":
+		// int8 has no range support in BuildSchema
+		return SchemaJSON{Type: n.kind, Nullable: nullable}
+	case "int16", "int32", "int64", "varint":
+		lo, hi := int64(n.minF), int64(n.maxF)
+		if nullable {
+			// constrained integer schemas reject nulls; keep the range as a hint
+			return SchemaJSON{Type: n.kind, Nullable: true, Extra: map[string]any{"min": lo, "max": hi}}
+		}
+		return SchemaJSON{Type: n.kind, Min: &lo, Max: &hi}
+	case "number":
+		lo, hi := n.minF, n.maxF
+		return SchemaJSON{Type: "number", MinFloat: &lo, MaxFloat: &hi}
+	case "bytes":
+		return SchemaJSON{Type: "bytes"}
+	case "string":
+		js := SchemaJSON{Type: "string", Nullable: nullable, Extra: map[string]any{
+			"minLen": n.minLen,
+			"maxLen": n.maxLen,
+		}}
+		strSamples := n.samples - n.nulls
+		if !n.tooMany && len(n.values) > 0 && strSamples >= 2*len(n.values) {
+			enum := make([]string, 0, len(n.values))
+			for v := range n.values {
+				enum = append(enum, v)
+			}
+			slices.Sort(enum)
+			quoted := make([]string, len(enum))
+			for i, v := range enum {
+				quoted[i] = regexp.QuoteMeta(v)
+			}
+			js.Pattern = "^(?:" + strings.Join(quoted, "|") + ")$"
+			js.Extra["enum"] = enum
+		}
+		return js
+	case "tuple":
+		if n.minCount == n.maxCount {
+			js := SchemaJSON{Type: "tuple", Nullable: true}
+			for _, e := range n.elems {
+				js.Schema = append(js.Schema, e.build())
+			}
+			return n.emptyAsNull(js, nullable)
+		}
+		lo, hi := int64(n.minCount), int64(n.maxCount)
+		if nullable {
+			// an empty tuple is packed the same as null
+			lo = 0
+		}
+		return n.emptyAsNull(SchemaJSON{Type: "tuple", Nullable: true, VariableLength: true, Flatten: true, Schema: []SchemaJSON{{
+			Type:   "repeat",
+			Min:    &lo,
+			Max:    &hi,
+			Schema: []SchemaJSON{n.elem.build()},
+		}}}, nullable)
+	case "map":
+		if len(n.keys) > inferMaxKeys {
+			return SchemaJSON{Type: "mapRepeat", Schema: []SchemaJSON{{Type: "string"}, n.value.build()}}
+		}
+		js := SchemaJSON{Type: "mapUnordered", Nullable: nullable}
+		for _, key := range n.keys {
+			js.FieldNames = append(js.FieldNames, key)
+			js.Schema = append(js.Schema, n.fields[key].buildWith(n.mapSamples-n.present[key]))
+		}
+		return n.emptyAsNull(js, nullable)
+	}
+	return SchemaJSON{Type: "any"}
+}
+
+// emptyAsNull makes a nullable container inferred from JSON encode [] and {}
+// as null. access.FromJSON packed them that way in the samples, while the
+// tuple and map schemas would pack a nested list with no fields.
+func (n *inferNode) emptyAsNull(js SchemaJSON, nullable bool) SchemaJSON {
+	if n.fromJSON && nullable {
+		js.Sanitize = append(js.Sanitize, "emptyAsNull")
+	}
+	return js
+}
+
+// inferKindAccepts reports whether a null carrying tag is valid for kind.
+func inferKindAccepts(kind string, tag typetags.Type) bool {
+	switch kind {
+	case "bool":
+		return tag == typetags.TypeBool
+	case "int8", "int16", "int32", "int64", "varint", "time":
+		return tag == typetags.TypeInteger
+	case "float16", "float32", "float64":
+		return tag == typetags.TypeFloating
+	case "number":
+		return tag == typetags.TypeInteger || tag == typetags.TypeFloating
+	case "string", "bytes":
+		return tag == typetags.TypeString
+	case "tuple":
+		return tag == typetags.TypeTuple
+	case "map":
+		return tag.IsMap()
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func inferSamples(t *testing.T) [][]byte {
+	var bufs [][]byte
+	for i := 0; i < 6; i++ {
+		put := access.NewPutAccess()
+		fields := []typetags.PairAny{
+			typetags.OPAny("id", int32(100+i)),
+			typetags.OPAny("status", []string{"new", "done"}[i%2]),
+			typetags.OPAny("name", fmt.Sprintf("user-%d", i)),
+			typetags.OPAny("score", float64(i)/2),
+			typetags.OPAny("tags", []any{"a", "b", "c"}[:i%3+1]),
+		}
+		if i%3 == 0 {
+			fields = append(fields, typetags.OPAny("note", "optional"))
+		}
+		require.NoError(t, put.AddMapAnyOrdered(typetags.NewOrderedMapAny(fields...), false))
+		bufs = append(bufs, put.Pack())
+	}
+	return bufs
+}
+
+func TestInfer_MapFields(t *testing.T) {
+	bufs := inferSamples(t)
+	js := Infer(bufs)
+	require.NotNil(t, js)
+	assert.Equal(t, "mapUnordered", js.Type)
+	assert.Equal(t, []string{"id", "status", "name", "score", "tags", "note"}, js.FieldNames)
+
+	id := js.Schema[0]
+	assert.Equal(t, "int32", id.Type)
+	assert.Equal(t, int64(100), *id.Min)
+	assert.Equal(t, int64(105), *id.Max)
+	assert.False(t, id.Nullable)
+
+	status := js.Schema[1]
+	assert.Equal(t, []string{"done", "new"}, status.Extra["enum"])
+	assert.Equal(t, "^(?:done|new)$", status.Pattern)
+	assert.Empty(t, js.Schema[2].Pattern, "high-cardinality strings are not enums")
+
+	tags := js.Schema[4]
+	assert.True(t, tags.VariableLength)
+	assert.True(t, tags.Flatten)
+	assert.Equal(t, "repeat", tags.Schema[0].Type)
+	assert.Equal(t, int64(1), *tags.Schema[0].Min)
+	assert.Equal(t, int64(3), *tags.Schema[0].Max)
+
+	assert.True(t, js.Schema[5].Nullable, "keys missing in some samples are nullable")
+
+	chain := SChain(BuildSchema(js))
+	for i, buf := range bufs {
+		assert.NoError(t, ValidateBuffer(buf, chain), "sample %d", i)
+	}
+}
+
+func TestInfer_TopLevelFieldsAndWidening(t *testing.T) {
+	var bufs [][]byte
+	for i, v := range []int64{5, 300, 70000} {
+		put := access.NewPutAccess()
+		put.AddIntegerCompressed(v)
+		if i == 1 {
+			put.AddNullableInt16(nil)
+		} else {
+			put.AddInt16(int16(i))
+		}
+		put.AddBool(i%2 == 0)
+		bufs = append(bufs, put.Pack())
+	}
+
+	js := Infer(append(bufs, []byte{0x01}))
+	require.NotNil(t, js)
+	require.Equal(t, "tuple", js.Type)
+	require.Len(t, js.Schema, 3)
+	assert.Equal(t, "number", js.Schema[0].Type)
+	assert.Equal(t, 5.0, *js.Schema[0].MinFloat)
+	assert.Equal(t, 70000.0, *js.Schema[0].MaxFloat)
+	assert.Equal(t, "int16", js.Schema[1].Type)
+	assert.True(t, js.Schema[1].Nullable)
+	assert.Equal(t, "bool", js.Schema[2].Type)
+
+	chain := SChain(buildSchemas(js.Schema)...)
+	for i, buf := range bufs {
+		assert.NoError(t, ValidateBuffer(buf, chain), "sample %d", i)
+	}
+
+	assert.Nil(t, Infer(nil))
+}
+
+func TestInferJSON_MergesDocuments(t *testing.T) {
+	docs := [][]byte{
+		[]byte(`{"id": 1, "kind": "a", "price": 10, "tags": ["x"], "meta": {"v": 1}}`),
+		[]byte(`{"id": 70000, "kind": "b", "price": 2.5, "tags": [], "meta": null}`),
+		[]byte(`{"id": 3, "kind": "a", "price": 4, "tags": ["x", "y"], "meta": {}, "extra": true}`),
+		[]byte(`{"id": 4, "kind": "b", "price": null, "tags": ["z"], "meta": {"v": 300}}`),
+	}
+	js, err := InferJSON(docs)
+	require.NoError(t, err)
+	assert.Equal(t, "mapUnordered", js.Type)
+	assert.Equal(t, []string{"id", "kind", "price", "tags", "meta", "extra"}, js.FieldNames)
+
+	assert.Equal(t, "int32", js.Schema[0].Type, "integers widen to the widest width")
+	assert.Equal(t, int64(70000), *js.Schema[0].Max)
+	assert.Equal(t, "^(?:a|b)$", js.Schema[1].Pattern)
+	assert.Equal(t, "float64", js.Schema[2].Type)
+	assert.True(t, js.Schema[2].Nullable)
+	assert.Equal(t, "mapUnordered", js.Schema[4].Type)
+	assert.True(t, js.Schema[4].Nullable)
+	assert.Equal(t, "int16", js.Schema[4].Schema[0].Type)
+	assert.True(t, js.Schema[5].Nullable)
+	// [] and {} were read as nulls, so they are encoded as nulls too
+	assert.Equal(t, []string{"emptyAsNull"}, js.Schema[3].Sanitize)
+	assert.Equal(t, []string{"emptyAsNull"}, js.Schema[4].Sanitize)
+
+	// the inferred fields accept every sample as a top-level document
+	chain := SchemaNamedChain{SchemaChain: SChain(buildSchemas(js.Schema)...), FieldNames: js.FieldNames}
+	for i, doc := range docs {
+		buf, err := EncodeJSON(doc, chain)
+		require.NoError(t, err, "doc %d", i)
+		_, err = DecodeBufferNamed(buf, chain)
+		assert.NoError(t, err, "doc %d", i)
+	}
+
+	_, err = InferJSON([][]byte{[]byte(`{"a": }`)})
+	assert.ErrorContains(t, err, "document 0")
+}
+
+func TestInfer_CompactWidths(t *testing.T) {
+	var bufs [][]byte
+	for _, v := range []int64{40000, 1 << 34} {
+		put := access.NewPutAccess()
+		put.AddIntCompact(v)
+		put.AddFloat16(float32(v % 7))
+		bufs = append(bufs, put.Pack())
+	}
+
+	js := Infer(bufs)
+	require.NotNil(t, js)
+	require.Len(t, js.Schema, 2)
+	assert.Equal(t, "varint", js.Schema[0].Type)
+	assert.Equal(t, int64(40000), *js.Schema[0].Min)
+	assert.Equal(t, "float16", js.Schema[1].Type)
+
+	chain := SChain(buildSchemas(js.Schema)...)
+	for i, buf := range bufs {
+		assert.NoError(t, ValidateBuffer(buf, chain), "sample %d", i)
+	}
+}
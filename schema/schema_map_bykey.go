@@ -0,0 +1,175 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/quickwritereader/PackOS/utils"
+)
+
+// UnknownKeyErrorDetails reports a map key for which no value schema was selected.
+type UnknownKeyErrorDetails struct {
+	Key string
+}
+
+func (e UnknownKeyErrorDetails) Error() string {
+	return fmt.Sprintf("no schema for key '%s'", e.Key)
+}
+
+// KeyRule selects Schema for keys that start with Prefix and end with Suffix.
+// Empty Prefix and Suffix match every key.
+type KeyRule struct {
+	Prefix string
+	Suffix string
+	Schema Schema
+}
+
+// KeyPrefix is a rule for keys starting with prefix.
+func KeyPrefix(prefix string, schema Schema) KeyRule {
+	return KeyRule{Prefix: prefix, Schema: schema}
+}
+
+// KeySuffix is a rule for keys ending with suffix, e.g. "_count" or "_at".
+func KeySuffix(suffix string, schema Schema) KeyRule {
+	return KeyRule{Suffix: suffix, Schema: schema}
+}
+
+func (r KeyRule) matches(key string) bool {
+	return strings.HasPrefix(key, r.Prefix) && strings.HasSuffix(key, r.Suffix)
+}
+
+// SchemaMapByKey is a map whose value schema is chosen per key, for
+// semi-structured payloads where the key name implies the value type.
+// Keys without a schema are rejected.
+type SchemaMapByKey struct {
+	Select   func(key string) Schema
+	Nullable bool
+}
+
+// SMapByKey selects value schemas with fn; fn returns nil for unknown keys.
+func SMapByKey(fn func(key string) Schema) SchemaMapByKey {
+	return SchemaMapByKey{Select: fn}
+}
+
+// SMapByKeyRules selects the value schema from the first matching rule.
+func SMapByKeyRules(rules ...KeyRule) SchemaMapByKey {
+	return SMapByKey(func(key string) Schema {
+		for _, r := range rules {
+			if r.matches(key) {
+				return r.Schema
+			}
+		}
+		return nil
+	})
+}
+
+// WithDefault returns a copy that falls back to def for keys without a schema.
+func (s SchemaMapByKey) WithDefault(def Schema) SchemaMapByKey {
+	sel := s.Select
+	s.Select = func(key string) Schema {
+		if v := sel(key); v != nil {
+			return v
+		}
+		return def
+	}
+	return s
+}
+
+// Optional returns a copy that accepts a null map.
+func (s SchemaMapByKey) Optional() SchemaMapByKey {
+	s.Nullable = true
+	return s
+}
+
+func (s SchemaMapByKey) IsNullable() bool {
+	return s.Nullable
+}
+
+// walk resolves the value schema of every entry and hands it to fn.
+func (s SchemaMapByKey) walk(seq *access.SeqGetAccess, fn func(key string, value Schema, subseq *access.SeqGetAccess) error) error {
+	pos := seq.CurrentIndex()
+	w, err := precheck(SchemaMapByKeyName, pos, seq, typetags.TypeMap, 0, s.Nullable)
+	if err != nil {
+		return err
+	}
+	if w != 0 {
+		subseq, err := seq.PeekNestedSeq()
+		if err != nil {
+			return NewSchemaError(ErrInvalidFormat, SchemaMapByKeyName, "", pos, err)
+		}
+		for i := 0; i < subseq.ArgCount()/2; i++ {
+			payload, typ, err := subseq.Next()
+			if err != nil {
+				return NewSchemaError(ErrInvalidFormat, SchemaMapByKeyName, "", pos, err)
+			}
+			if typ != typetags.TypeString {
+				return NewSchemaError(ErrConstraintViolated, SchemaMapByKeyName, "", pos, ErrUnsupportedType)
+			}
+			key := string(payload)
+			value := s.Select(key)
+			if value == nil {
+				return NewSchemaError(ErrConstraintViolated, SchemaMapByKeyName, key, pos, UnknownKeyErrorDetails{Key: key})
+			}
+			if err := fn(key, value, subseq); err != nil {
+				return NewSchemaError(ErrInvalidFormat, SchemaMapByKeyName, key, pos, err)
+			}
+		}
+	}
+	if err := seq.Advance(); err != nil {
+		return NewSchemaError(ErrUnexpectedEOF, SchemaMapByKeyName, "", pos, err)
+	}
+	return nil
+}
+
+func (s SchemaMapByKey) Validate(seq *access.SeqGetAccess) error {
+	return s.walk(seq, func(_ string, value Schema, subseq *access.SeqGetAccess) error {
+		return value.Validate(subseq)
+	})
+}
+
+func (s SchemaMapByKey) Decode(seq *access.SeqGetAccess) (any, error) {
+	var out map[string]any
+	err := s.walk(seq, func(key string, value Schema, subseq *access.SeqGetAccess) error {
+		v, err := value.Decode(subseq)
+		if err != nil {
+			return err
+		}
+		if out == nil {
+			out = make(map[string]any)
+		}
+		out[key] = v
+		return nil
+	})
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Encode writes entries in sorted key order so equal maps produce equal buffers.
+func (s SchemaMapByKey) Encode(put *access.PutAccess, val any) error {
+	if s.Nullable && val == nil {
+		put.AddMap(nil)
+		return nil
+	}
+	mapKV, ok := val.(map[string]any)
+	if !ok {
+		return NewSchemaError(ErrEncode, SchemaMapByKeyName, "", -1, ErrTypeMisMatch)
+	}
+
+	nested := put.BeginMap()
+	defer put.EndNested(nested)
+	for _, key := range utils.SortKeys(mapKV) {
+		value := s.Select(key)
+		if value == nil {
+			return NewSchemaError(ErrEncode, SchemaMapByKeyName, key, -1, UnknownKeyErrorDetails{Key: key})
+		}
+		nested.AddString(key)
+		if err := value.Encode(nested, mapKV[key]); err != nil {
+			return NewSchemaError(ErrEncode, SchemaMapByKeyName, key, -1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	pack "github.com/quickwritereader/PackOS/packable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEventSchema() SchemaMapByKey {
+	return SMapByKeyRules(
+		KeySuffix("_count", SInt32),
+		KeySuffix("_at", SDateRange(false, nil, nil)),
+		KeyPrefix("is_", SBool),
+	)
+}
+
+func TestSMapByKey_RulesRoundTrip(t *testing.T) {
+	chain := SChain(newEventSchema())
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	buf, err := EncodeValue(map[string]any{
+		"retry_count": int32(3),
+		"created_at":  at,
+		"is_active":   true,
+	}, chain)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, chain))
+
+	decoded, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"retry_count": int32(3), "created_at": at, "is_active": true}, decoded)
+}
+
+func TestSMapByKey_Mismatches(t *testing.T) {
+	chain := SChain(newEventSchema())
+
+	wrongType := pack.Pack(pack.PackMapSorted{"retry_count": pack.PackString("3")})
+	require.Error(t, ValidateBuffer(wrongType, chain))
+
+	unknown := pack.Pack(pack.PackMapSorted{"note": pack.PackString("x")})
+	err := ValidateBuffer(unknown, chain)
+	var schemaErr *SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	assert.Equal(t, ErrConstraintViolated, schemaErr.Code)
+	assert.Equal(t, "note", schemaErr.Field)
+
+	_, err = EncodeValue(map[string]any{"note": "x"}, chain)
+	require.Error(t, err)
+
+	withDefault := SChain(newEventSchema().WithDefault(SString))
+	require.NoError(t, ValidateBuffer(unknown, withDefault))
+}
+
+func TestSMapByKey_SelectFunc(t *testing.T) {
+	chain := SChain(SMapByKey(func(key string) Schema {
+		if len(key) == 1 {
+			return SInt8
+		}
+		return nil
+	}).Optional())
+
+	require.NoError(t, ValidateBuffer(pack.Pack(pack.PackMapSorted{"a": pack.PackInt8(1)}), chain))
+	require.NoError(t, ValidateBuffer(pack.Pack(pack.PackMapSorted{}), chain))
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackMapSorted{"ab": pack.PackInt8(1)}), chain))
+}
@@ -0,0 +1,75 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/quickwritereader/PackOS/access"
+)
+
+const MatcherName = "Matcher"
+
+// ErrNoMatch is wrapped by the error FirstMatch returns when no candidate accepts a buffer.
+var ErrNoMatch = errors.New("no schema matched")
+
+// Matcher classifies untagged buffers by testing them against candidate
+// chains in name order. A candidate matches when it validates every top-level
+// field of the buffer, so a shorter chain does not claim a longer record.
+// The headers are parsed once per buffer and shared by all candidates.
+type Matcher struct {
+	names  []string
+	chains []SchemaChain
+}
+
+// NewMatcher prepares chains for repeated matching.
+func NewMatcher(chains map[string]SchemaChain) *Matcher {
+	m := &Matcher{names: make([]string, 0, len(chains))}
+	for name := range chains {
+		m.names = append(m.names, name)
+	}
+	slices.Sort(m.names)
+	for _, name := range m.names {
+		m.chains = append(m.chains, chains[name])
+	}
+	return m
+}
+
+// Match returns the name of the first chain, in name order, that accepts buf.
+// When none does, the error wraps ErrNoMatch and each candidate's failure.
+func (m *Matcher) Match(buf []byte) (string, error) {
+	head, err := access.NewSeqGetAccess(buf)
+	if err != nil {
+		return "", NewSchemaError(ErrInvalidFormat, MatcherName, "", -1, err)
+	}
+	errs := []error{ErrNoMatch}
+	for i, chain := range m.chains {
+		seq := *head
+		if err := validateSeq(&seq, chain); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.names[i], err))
+			continue
+		}
+		if seq.CurrentIndex() != head.ArgCount() {
+			errs = append(errs, fmt.Errorf("%s: %w", m.names[i],
+				SizeExact{Exact: head.ArgCount(), Actual: seq.CurrentIndex()}))
+			continue
+		}
+		return m.names[i], nil
+	}
+	return "", NewSchemaError(ErrConstraintViolated, MatcherName, "", -1, errors.Join(errs...))
+}
+
+// FirstMatch is NewMatcher(chains).Match(buf) for one-off classification.
+func FirstMatch(buf []byte, chains map[string]SchemaChain) (string, error) {
+	return NewMatcher(chains).Match(buf)
+}
+
+func validateSeq(seq *access.SeqGetAccess, chain SchemaChain) error {
+	for i, schema := range chain.Schemas {
+		if err := schema.Validate(seq); err != nil {
+			return prefixPath(err, schema, strconv.Itoa(i))
+		}
+	}
+	return nil
+}
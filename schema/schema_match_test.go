@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	pack "github.com/quickwritereader/PackOS/packable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirstMatch(t *testing.T) {
+	chains := map[string]SchemaChain{
+		"ping":    SChain(SInt16),
+		"login":   SChain(SInt16, SString.Pattern(`^[a-z]+$`)),
+		"message": SChain(SInt16, SString),
+	}
+	m := NewMatcher(chains)
+
+	name, err := m.Match(pack.Pack(pack.PackInt16(1)))
+	require.NoError(t, err)
+	assert.Equal(t, "ping", name)
+
+	// both login and message accept it; names are tried in order
+	name, err = m.Match(pack.Pack(pack.PackInt16(1), pack.PackString("bob")))
+	require.NoError(t, err)
+	assert.Equal(t, "login", name)
+
+	name, err = FirstMatch(pack.Pack(pack.PackInt16(1), pack.PackString("Hello, bob")), chains)
+	require.NoError(t, err)
+	assert.Equal(t, "message", name)
+
+	_, err = m.Match(pack.Pack(pack.PackBool(true)))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNoMatch))
+	assertSchemaError(t, err, ErrConstraintViolated)
+	assert.Contains(t, err.Error(), "ping:")
+
+	// trailing fields disqualify a shorter chain
+	_, err = m.Match(pack.Pack(pack.PackInt16(1), pack.PackString("a"), pack.PackBool(true)))
+	assert.ErrorIs(t, err, ErrNoMatch)
+
+	_, err = m.Match([]byte{0x01})
+	assertSchemaError(t, err, ErrInvalidFormat)
+}
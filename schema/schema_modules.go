@@ -0,0 +1,134 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+// SchemaModules loads SchemaJSON documents split across files. A module
+// lists the modules it uses in Imports, declares named schemas in Defs,
+// and uses them, or those of its direct imports, through "ref" nodes:
+//
+//	// address.json
+//	{"defs": {"Address": {"type": "mapUnordered", "fieldNames": ["city"], "schema": [{"type": "string"}]}}}
+//
+//	// user.json
+//	{"imports": ["address.json"], "type": "tuple", "fieldNames": ["name", "home"],
+//	 "schema": [{"type": "string"}, {"type": "ref", "ref": "Address"}]}
+//
+// Import paths are slash-separated and relative to the importing module.
+// Modules are read once, dependencies before the modules importing them,
+// in the order they are listed; import cycles and recursive definitions
+// are errors.
+type SchemaModules struct {
+	fsys    fs.FS
+	modules map[string]*SchemaJSON
+	order   []string
+}
+
+// NewSchemaModules returns a loader reading modules from fsys, such as
+// os.DirFS of the schema directory.
+func NewSchemaModules(fsys fs.FS) *SchemaModules {
+	return &SchemaModules{fsys: fsys, modules: map[string]*SchemaJSON{}}
+}
+
+// Load reads the module name with its imports and returns it with every
+// "ref" node replaced by the definition it names, ready for BuildSchema.
+func (m *SchemaModules) Load(name string) (*SchemaJSON, error) {
+	name = path.Clean(name)
+	if err := m.load(name, nil); err != nil {
+		return nil, err
+	}
+	js, err := m.resolve(name, *m.modules[name], nil)
+	if err != nil {
+		return nil, err
+	}
+	return &js, nil
+}
+
+// Order returns the modules read so far, each after the modules it imports.
+func (m *SchemaModules) Order() []string {
+	return slices.Clone(m.order)
+}
+
+func (m *SchemaModules) load(name string, stack []string) error {
+	if slices.Contains(stack, name) {
+		return fmt.Errorf("schema modules: import cycle %s", strings.Join(append(stack, name), " -> "))
+	}
+	if _, ok := m.modules[name]; ok {
+		return nil
+	}
+	data, err := fs.ReadFile(m.fsys, name)
+	if err != nil {
+		return fmt.Errorf("schema modules: %w", err)
+	}
+	var js SchemaJSON
+	if err := json.Unmarshal(data, &js); err != nil {
+		return fmt.Errorf("schema modules: %s: %w", name, err)
+	}
+	for i, imp := range js.Imports {
+		js.Imports[i] = path.Join(path.Dir(name), imp)
+		if err := m.load(js.Imports[i], append(stack, name)); err != nil {
+			return err
+		}
+	}
+	m.modules[name] = &js
+	m.order = append(m.order, name)
+	return nil
+}
+
+// resolve returns a copy of js, found in module, with its refs replaced.
+// refs holds the definitions being expanded, to catch recursion.
+func (m *SchemaModules) resolve(module string, js SchemaJSON, refs []string) (SchemaJSON, error) {
+	if js.Type == "ref" {
+		owner, def, err := m.lookup(module, js.Ref)
+		if err != nil {
+			return SchemaJSON{}, err
+		}
+		key := owner + "#" + js.Ref
+		if slices.Contains(refs, key) {
+			return SchemaJSON{}, fmt.Errorf("schema modules: recursive definition %s", strings.Join(append(refs, key), " -> "))
+		}
+		return m.resolve(owner, def, append(refs, key))
+	}
+	out := js
+	out.Imports, out.Defs = nil, nil
+	if js.Schema != nil {
+		out.Schema = make([]SchemaJSON, len(js.Schema))
+		for i := range js.Schema {
+			child, err := m.resolve(module, js.Schema[i], refs)
+			if err != nil {
+				return SchemaJSON{}, err
+			}
+			out.Schema[i] = child
+		}
+	}
+	return out, nil
+}
+
+// lookup finds the definition name in module or, failing that, in exactly
+// one of its imports.
+func (m *SchemaModules) lookup(module, name string) (string, SchemaJSON, error) {
+	js := m.modules[module]
+	if def, ok := js.Defs[name]; ok {
+		return module, def, nil
+	}
+	var owner string
+	var found SchemaJSON
+	for _, imp := range js.Imports {
+		if def, ok := m.modules[imp].Defs[name]; ok {
+			if owner != "" {
+				return "", SchemaJSON{}, fmt.Errorf("schema modules: %s: ref %q is defined in both %s and %s", module, name, owner, imp)
+			}
+			owner, found = imp, def
+		}
+	}
+	if owner == "" {
+		return "", SchemaJSON{}, fmt.Errorf("schema modules: %s: unknown ref %q", module, name)
+	}
+	return owner, found, nil
+}
@@ -0,0 +1,65 @@
+package schema
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaModules_Load(t *testing.T) {
+	fsys := fstest.MapFS{
+		"common/geo.json": {Data: []byte(`{"defs": {
+			"City": {"type": "string", "prefix": "c:"},
+			"Address": {"type": "mapUnordered", "fieldNames": ["city"], "schema": [{"type": "ref", "ref": "City"}]}}}`)},
+		"common/ids.json": {Data: []byte(`{"defs": {"Id": {"type": "int32", "min": 1}}}`)},
+		"user.json": {Data: []byte(`{"imports": ["common/ids.json", "common/geo.json"],
+			"defs": {"Name": {"type": "string"}},
+			"type": "tuple", "fieldNames": ["id", "name", "home"],
+			"schema": [{"type": "ref", "ref": "Id"}, {"type": "ref", "ref": "Name"}, {"type": "ref", "ref": "Address"}]}`)},
+	}
+	m := NewSchemaModules(fsys)
+	js, err := m.Load("user.json")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"common/ids.json", "common/geo.json", "user.json"}, m.Order())
+	assert.Equal(t, "int32", js.Schema[0].Type)
+	assert.Equal(t, "c:", js.Schema[2].Schema[0].Prefix)
+	assert.Nil(t, js.Imports)
+
+	chain := SChain(BuildSchema(js))
+	buf, err := EncodeValue(map[string]any{"id": int32(7), "name": "ann", "home": map[string]any{"city": "c:oslo"}}, chain)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, chain))
+	_, err = EncodeValue(map[string]any{"id": int32(0), "name": "ann", "home": map[string]any{"city": "c:oslo"}}, chain)
+	assert.Error(t, err)
+
+	p := NewSchemaProvider(SChain(SInt16))
+	_, err = p.ReloadModules(fsys, "user.json")
+	require.NoError(t, err)
+	require.NoError(t, p.Validate(buf))
+
+	assert.Panics(t, func() { BuildSchema(&SchemaJSON{Type: "ref", Ref: "Id"}) })
+}
+
+func TestSchemaModules_Errors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.json":    {Data: []byte(`{"imports": ["b.json"], "type": "int8"}`)},
+		"b.json":    {Data: []byte(`{"imports": ["a.json"]}`)},
+		"self.json": {Data: []byte(`{"defs": {"Node": {"type": "tuple", "schema": [{"type": "ref", "ref": "Node"}]}}, "type": "ref", "ref": "Node"}`)},
+		"x.json":    {Data: []byte(`{"defs": {"T": {"type": "int8"}}}`)},
+		"y.json":    {Data: []byte(`{"defs": {"T": {"type": "int16"}}}`)},
+		"both.json": {Data: []byte(`{"imports": ["x.json", "y.json"], "type": "ref", "ref": "T"}`)},
+		"miss.json": {Data: []byte(`{"type": "ref", "ref": "T"}`)},
+	}
+	_, err := NewSchemaModules(fsys).Load("a.json")
+	assert.ErrorContains(t, err, "import cycle a.json -> b.json -> a.json")
+	_, err = NewSchemaModules(fsys).Load("self.json")
+	assert.ErrorContains(t, err, "recursive definition")
+	_, err = NewSchemaModules(fsys).Load("both.json")
+	assert.ErrorContains(t, err, "defined in both x.json and y.json")
+	_, err = NewSchemaModules(fsys).Load("miss.json")
+	assert.ErrorContains(t, err, `unknown ref "T"`)
+	_, err = NewSchemaModules(fsys).Load("none.json")
+	assert.Error(t, err)
+}
@@ -0,0 +1,176 @@
+package schema
+
+import (
+	"encoding/binary"
+	"slices"
+	"strings"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const SchemaMoneyName = "SchemaMoney"
+
+// Money is an amount in the minor unit of its currency, such as cents for
+// "USD", so sums stay exact.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// SchemaMoney packs a Money as a tuple of its int64 amount and its
+// three-letter ISO 4217 currency code, and decodes to Money. Currencies
+// lists the accepted codes; when empty any three upper-case letters are
+// accepted. MinAmount and MaxAmount bound the amount in minor units.
+//
+// Encode accepts Money, *Money and maps with "amount" and "currency" keys,
+// as decoded from JSON.
+type SchemaMoney struct {
+	Currencies []string
+	MinAmount  *int64
+	MaxAmount  *int64
+	Nullable   bool
+}
+
+// SMoney accepts amounts in the given currencies, or in any when none are
+// given.
+func SMoney(currencies ...string) SchemaMoney {
+	return SchemaMoney{Currencies: currencies}
+}
+
+// NonNegative rejects amounts below zero.
+func (s SchemaMoney) NonNegative() SchemaMoney {
+	zero := int64(0)
+	s.MinAmount = &zero
+	return s
+}
+
+func (s SchemaMoney) IsNullable() bool { return s.Nullable }
+
+func (s SchemaMoney) decodeValidate(seq *access.SeqGetAccess, decode bool) (any, error) {
+	pos := seq.CurrentIndex()
+	width, err := precheck(SchemaMoneyName, pos, seq, typetags.TypeTuple, 0, s.Nullable)
+	if err != nil {
+		return nil, err
+	}
+	if width == 0 {
+		if !s.Nullable {
+			return nil, NewSchemaError(ErrConstraintViolated, SchemaMoneyName, "", pos, ErrTypeMisMatch)
+		}
+		if err := seq.Advance(); err != nil {
+			return nil, NewSchemaError(ErrUnexpectedEOF, SchemaMoneyName, "", pos, err)
+		}
+		return nil, nil
+	}
+	sub, err := seq.PeekNestedSeq()
+	if err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, SchemaMoneyName, "", pos, err)
+	}
+	if sub.ArgCount() != 2 {
+		return nil, NewSchemaError(ErrConstraintViolated, SchemaMoneyName, "", pos, SizeExact{2, sub.ArgCount()})
+	}
+	amount, err := validatePrimitiveAndGetPayload(SchemaMoneyName, sub, typetags.TypeInteger, 8, false)
+	if err != nil {
+		return nil, err
+	}
+	code, err := validatePrimitiveAndGetPayload(SchemaMoneyName, sub, typetags.TypeString, 3, false)
+	if err != nil {
+		return nil, err
+	}
+	m := Money{Amount: int64(binary.LittleEndian.Uint64(amount)), Currency: string(code)}
+	if err := s.check(m, pos); err != nil {
+		return nil, err
+	}
+	if err := seq.Advance(); err != nil {
+		return nil, NewSchemaError(ErrUnexpectedEOF, SchemaMoneyName, "", pos, err)
+	}
+	if !decode {
+		return nil, nil
+	}
+	return m, nil
+}
+
+// check applies the currency and amount constraints to m, read at pos.
+func (s SchemaMoney) check(m Money, pos int) error {
+	if !isCurrencyCode(m.Currency) {
+		return NewSchemaError(ErrConstraintViolated, SchemaMoneyName, "currency", pos,
+			StringErrorDetails{Actual: m.Currency, Expected: "ISO 4217 currency code"})
+	}
+	if len(s.Currencies) > 0 && !slices.Contains(s.Currencies, m.Currency) {
+		return NewSchemaError(ErrConstraintViolated, SchemaMoneyName, "currency", pos,
+			StringErrorDetails{Actual: m.Currency, Expected: strings.Join(s.Currencies, "|")})
+	}
+	if err := CheckRange(m.Amount, s.MinAmount, s.MaxAmount); err != nil {
+		return NewSchemaError(ErrOutOfRange, SchemaMoneyName, "amount", pos, err)
+	}
+	return nil
+}
+
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < 'A' || code[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func (s SchemaMoney) Validate(seq *access.SeqGetAccess) error {
+	_, err := s.decodeValidate(seq, false)
+	return err
+}
+
+func (s SchemaMoney) Decode(seq *access.SeqGetAccess) (any, error) {
+	return s.decodeValidate(seq, true)
+}
+
+func (s SchemaMoney) Encode(put *access.PutAccess, val any) error {
+	if s.Nullable && val == nil {
+		put.AddAnyTuple(nil, false)
+		return nil
+	}
+	m, err := toMoney(val)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaMoneyName, "", -1, err)
+	}
+	if err := s.check(m, -1); err != nil {
+		return err
+	}
+	nested := put.BeginTuple()
+	nested.AddInt64(m.Amount)
+	nested.AddString(m.Currency)
+	put.EndNested(nested)
+	return nil
+}
+
+func toMoney(val any) (Money, error) {
+	switch v := val.(type) {
+	case Money:
+		return v, nil
+	case *Money:
+		if v != nil {
+			return *v, nil
+		}
+	case map[string]any:
+		amount, err := coerceJSONNumber[int64](v["amount"])
+		if err != nil {
+			return Money{}, err
+		}
+		currency, ok := v["currency"].(string)
+		if !ok {
+			break
+		}
+		switch a := amount.(type) {
+		case int:
+			return Money{int64(a), currency}, nil
+		case int32:
+			return Money{int64(a), currency}, nil
+		case int64:
+			return Money{a, currency}, nil
+		}
+	}
+	return Money{}, ErrTypeMisMatch
+}
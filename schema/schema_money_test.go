@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMoney(t *testing.T) {
+	chain := SChain(SMoney("EUR", "USD").NonNegative(), SString)
+	buf, err := EncodeValue([]any{Money{Amount: 1999, Currency: "EUR"}, "x"}, chain)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, chain))
+	v, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, []any{Money{1999, "EUR"}, "x"}, v)
+	assert.LessOrEqual(t, len(buf), chain.EstimateSize([]any{Money{1999, "EUR"}, "x"}))
+
+	for _, bad := range []any{Money{-1, "EUR"}, Money{1, "GBP"}, Money{1, "eur"}, 19.99} {
+		_, err := EncodeValue([]any{bad, "x"}, chain)
+		assert.Error(t, err, "%v", bad)
+	}
+
+	// stored values are checked too
+	stored, err := EncodeValue(Money{-5, "GBP"}, SChain(SMoney()))
+	require.NoError(t, err)
+	var se *SchemaError
+	require.ErrorAs(t, ValidateBuffer(stored, SChain(SMoney("GBP").NonNegative())), &se)
+	assert.Equal(t, ErrOutOfRange, se.Code)
+	require.ErrorAs(t, ValidateBuffer(stored, SChain(SMoney("EUR"))), &se)
+	assert.Equal(t, "currency", se.Field)
+}
+
+func TestBuildSchema_Money(t *testing.T) {
+	var js SchemaJSON
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "money", "currencies": ["JPY"], "max": 100000, "nullable": true}`), &js))
+	chain := SChain(BuildSchema(&js))
+
+	buf, err := EncodeJSON([]byte(`{"price": {"amount": 500, "currency": "JPY"}}`),
+		SchemaNamedChain{SchemaChain: chain, FieldNames: []string{"price"}})
+	require.NoError(t, err)
+	v, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, Money{500, "JPY"}, v)
+
+	_, err = EncodeValue(map[string]any{"amount": json.Number("100001"), "currency": "JPY"}, chain)
+	assert.Error(t, err)
+	buf, err = EncodeValue(nil, chain)
+	require.NoError(t, err)
+	v, err = DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
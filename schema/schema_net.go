@@ -0,0 +1,140 @@
+package schema
+
+import (
+	"net"
+	"net/netip"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const SchemaIPName = "SchemaIP"
+
+// SIPv4 accepts dotted IPv4 addresses such as "192.0.2.1".
+func SIPv4(optional bool) Schema {
+	return netString(optional, "IPv4 address", func(str string) bool {
+		addr, err := netip.ParseAddr(str)
+		return err == nil && addr.Is4()
+	})
+}
+
+// SIPv6 accepts IPv6 addresses such as "2001:db8::1", with an optional zone.
+func SIPv6(optional bool) Schema {
+	return netString(optional, "IPv6 address", func(str string) bool {
+		addr, err := netip.ParseAddr(str)
+		return err == nil && addr.Is6()
+	})
+}
+
+// SCIDR accepts IPv4 or IPv6 prefixes such as "10.0.0.0/8".
+func SCIDR(optional bool) Schema {
+	return netString(optional, "CIDR prefix", func(str string) bool {
+		_, err := netip.ParsePrefix(str)
+		return err == nil
+	})
+}
+
+// SMAC accepts EUI-48 and EUI-64 hardware addresses in the forms
+// net.ParseMAC reads, such as "00:00:5e:00:53:01".
+func SMAC(optional bool) Schema {
+	return netString(optional, "MAC address", func(str string) bool {
+		hw, err := net.ParseMAC(str)
+		return err == nil && (len(hw) == 6 || len(hw) == 8)
+	})
+}
+
+func netString(optional bool, expected string, test func(string) bool) Schema {
+	s := SString
+	if optional {
+		s = s.Optional()
+	}
+	return s.CheckFunc(ErrStringNetAddr, expected, test)
+}
+
+// SchemaIP is an IP address packed as its 4 raw bytes, with Version 4, or
+// 16 raw bytes, with Version 6, instead of its text form. Decode returns
+// the text form, or a netip.Addr when AsAddr is set. Encode accepts either,
+// of the right version; zones cannot be packed.
+type SchemaIP struct {
+	Version  int
+	Nullable bool
+	AsAddr   bool
+}
+
+var (
+	SIPv4Packed = SchemaIP{Version: 4}
+	SIPv6Packed = SchemaIP{Version: 6}
+)
+
+func (s SchemaIP) IsNullable() bool { return s.Nullable }
+
+func (s SchemaIP) width() int {
+	if s.Version == 4 {
+		return 4
+	}
+	return 16
+}
+
+func (s SchemaIP) Validate(seq *access.SeqGetAccess) error {
+	_, err := s.payload(seq)
+	return err
+}
+
+func (s SchemaIP) Decode(seq *access.SeqGetAccess) (any, error) {
+	payload, err := s.payload(seq)
+	if err != nil || payload == nil {
+		return nil, err
+	}
+	addr, _ := netip.AddrFromSlice(payload)
+	if s.AsAddr {
+		return addr, nil
+	}
+	return addr.String(), nil
+}
+
+// payload checks the field and returns its bytes, or nil for a null.
+func (s SchemaIP) payload(seq *access.SeqGetAccess) ([]byte, error) {
+	pos := seq.CurrentIndex()
+	payload, err := validatePrimitiveAndGetPayload(SchemaIPName, seq, typetags.TypeString, s.width(), s.Nullable)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil && len(payload) != s.width() {
+		// nullable fields skip the width check in precheck
+		return nil, NewSchemaError(ErrConstraintViolated, SchemaIPName, "", pos, SizeExact{s.width(), len(payload)})
+	}
+	return payload, nil
+}
+
+func (s SchemaIP) Encode(put *access.PutAccess, val any) error {
+	if s.Nullable && val == nil {
+		put.AddBytes(nil)
+		return nil
+	}
+	var addr netip.Addr
+	switch v := val.(type) {
+	case netip.Addr:
+		addr = v
+	case string:
+		parsed, err := netip.ParseAddr(v)
+		if err != nil {
+			return NewSchemaError(ErrStringNetAddr, SchemaIPName, "", -1, err)
+		}
+		addr = parsed
+	default:
+		return NewSchemaError(ErrEncode, SchemaIPName, "", -1, ErrTypeMisMatch)
+	}
+	if addr.Zone() != "" || addr.Is4() != (s.Version == 4) {
+		return NewSchemaError(ErrStringNetAddr, SchemaIPName, "", -1,
+			StringErrorDetails{Actual: addr.String(), Expected: netVersion(s.Version)})
+	}
+	put.AddBytes(addr.AsSlice())
+	return nil
+}
+
+func netVersion(version int) string {
+	if version == 4 {
+		return "IPv4 address"
+	}
+	return "IPv6 address without zone"
+}
@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetAddressStrings(t *testing.T) {
+	cases := []struct {
+		schema    Schema
+		good, bad []string
+	}{
+		{SIPv4(false), []string{"192.0.2.1"}, []string{"2001:db8::1", "256.0.0.1", "host"}},
+		{SIPv6(false), []string{"2001:db8::1", "fe80::1%eth0"}, []string{"192.0.2.1", "2001:db8::g"}},
+		{SCIDR(false), []string{"10.0.0.0/8", "2001:db8::/32"}, []string{"10.0.0.0", "10.0.0.0/33"}},
+		{SMAC(false), []string{"00:00:5e:00:53:01", "02-00-5e-10-00-00-00-01"}, []string{"00:00:5e", "zz:00:5e:00:53:01"}},
+	}
+	for _, c := range cases {
+		chain := SChain(c.schema)
+		for _, in := range c.good {
+			buf, err := EncodeValue(in, chain)
+			require.NoError(t, err, in)
+			v, err := DecodeBuffer(buf, chain)
+			require.NoError(t, err, in)
+			assert.Equal(t, in, v)
+		}
+		for _, in := range c.bad {
+			_, err := EncodeValue(in, chain)
+			assert.Error(t, err, in)
+		}
+	}
+
+	raw, err := EncodeValue("host", SChain(SString))
+	require.NoError(t, err)
+	var se *SchemaError
+	require.ErrorAs(t, ValidateBuffer(raw, SChain(SIPv4(false))), &se)
+	assert.Equal(t, ErrStringNetAddr, se.Code)
+}
+
+func TestSchemaIP_Packed(t *testing.T) {
+	v4 := SChain(SIPv4Packed)
+	buf, err := EncodeValue("192.0.2.1", v4)
+	require.NoError(t, err)
+	text, err := EncodeValue("192.0.2.1", SChain(SIPv4(false)))
+	require.NoError(t, err)
+	assert.Less(t, len(buf), len(text))
+	v, err := DecodeBuffer(buf, v4)
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1", v)
+
+	v6 := SChain(SchemaIP{Version: 6, AsAddr: true})
+	addr := netip.MustParseAddr("2001:db8::1")
+	buf, err = EncodeValue(addr, v6)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, v6))
+	v, err = DecodeBuffer(buf, v6)
+	require.NoError(t, err)
+	assert.Equal(t, addr, v)
+	assert.Error(t, ValidateBuffer(buf, v4))
+
+	_, err = EncodeValue("2001:db8::1", v4)
+	assert.Error(t, err)
+	_, err = EncodeValue("fe80::1%eth0", v6)
+	assert.Error(t, err)
+	_, err = EncodeValue(nil, v4)
+	assert.Error(t, err)
+
+	null := SChain(BuildSchema(&SchemaJSON{Type: "ipv6Packed", Nullable: true}))
+	buf, err = EncodeValue(nil, null)
+	require.NoError(t, err)
+	v, err = DecodeBuffer(buf, null)
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
@@ -0,0 +1,136 @@
+package schema
+
+import (
+	"errors"
+
+	"github.com/quickwritereader/PackOS/access"
+)
+
+const (
+	SchemaNullableName = "SchemaNullable"
+	SchemaRequiredName = "SchemaRequired"
+)
+
+// ErrNullValue is the inner error of a SchemaRequired field that is null.
+var ErrNullValue = errors.New("null value")
+
+// SchemaNullable accepts nulls in front of any Schema. A field of width
+// zero, whatever its type, is skipped and decodes to nil; other fields are
+// handed to Schema. A nil value is encoded the way Schema encodes it if
+// Schema accepts nil, and as a zero-width field otherwise.
+type SchemaNullable struct {
+	Schema Schema
+}
+
+// SNullable makes s nullable, whatever its width and Nullable settings.
+func SNullable(s Schema) SchemaNullable {
+	return SchemaNullable{Schema: s}
+}
+
+func (s SchemaNullable) IsNullable() bool { return true }
+
+func (s SchemaNullable) Validate(seq *access.SeqGetAccess) error {
+	if null, err := skipNull(SchemaNullableName, seq); null || err != nil {
+		return err
+	}
+	return s.Schema.Validate(seq)
+}
+
+func (s SchemaNullable) Decode(seq *access.SeqGetAccess) (any, error) {
+	if null, err := skipNull(SchemaNullableName, seq); null || err != nil {
+		return nil, err
+	}
+	return s.Schema.Decode(seq)
+}
+
+func (s SchemaNullable) Encode(put *access.PutAccess, val any) error {
+	if val != nil {
+		return s.Schema.Encode(put, val)
+	}
+	scratch := access.NewPutAccessFromPool()
+	defer access.ReleasePutAccess(scratch)
+	if err := s.Schema.Encode(scratch, nil); err == nil {
+		return appendPackedFields(put, scratch.Pack())
+	}
+	put.AddNull(nil)
+	return nil
+}
+
+// skipNull advances past the current field and reports true if it is null.
+func skipNull(name string, seq *access.SeqGetAccess) (bool, error) {
+	pos := seq.CurrentIndex()
+	_, width, err := seq.PeekTypeWidth()
+	if err != nil {
+		return false, NewSchemaError(ErrUnexpectedEOF, name, "", pos, err)
+	}
+	if width != 0 {
+		return false, nil
+	}
+	if err := seq.Advance(); err != nil {
+		return true, NewSchemaError(ErrUnexpectedEOF, name, "", pos, err)
+	}
+	return true, nil
+}
+
+// SchemaRequired rejects nulls in front of any Schema: a field of width
+// zero fails with ErrNullValue before Schema sees it, and so does encoding
+// nil or a value that packs to one. Schemas that are nullable because of
+// their width, like SString, become required without giving up variable
+// widths; empty strings, maps and tuples are zero-width fields too and are
+// rejected as well.
+type SchemaRequired struct {
+	Schema Schema
+}
+
+// SRequired makes s reject nulls, whatever its width and Nullable settings.
+func SRequired(s Schema) SchemaRequired {
+	return SchemaRequired{Schema: s}
+}
+
+func (s SchemaRequired) IsNullable() bool { return false }
+
+func (s SchemaRequired) Validate(seq *access.SeqGetAccess) error {
+	if err := rejectNull(seq); err != nil {
+		return err
+	}
+	return s.Schema.Validate(seq)
+}
+
+func (s SchemaRequired) Decode(seq *access.SeqGetAccess) (any, error) {
+	if err := rejectNull(seq); err != nil {
+		return nil, err
+	}
+	return s.Schema.Decode(seq)
+}
+
+func (s SchemaRequired) Encode(put *access.PutAccess, val any) error {
+	if val == nil {
+		return NewSchemaError(ErrEncode, SchemaRequiredName, "", -1, ErrNullValue)
+	}
+	scratch := access.NewPutAccessFromPool()
+	defer access.ReleasePutAccess(scratch)
+	if err := s.Schema.Encode(scratch, val); err != nil {
+		return err
+	}
+	buf := scratch.Pack()
+	seq, err := access.NewSeqGetAccess(buf)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaRequiredName, "", -1, err)
+	}
+	if err := rejectNull(seq); err != nil {
+		return NewSchemaError(ErrEncode, SchemaRequiredName, "", -1, ErrNullValue)
+	}
+	return appendPackedFields(put, buf)
+}
+
+func rejectNull(seq *access.SeqGetAccess) error {
+	pos := seq.CurrentIndex()
+	_, width, err := seq.PeekTypeWidth()
+	if err != nil {
+		return NewSchemaError(ErrUnexpectedEOF, SchemaRequiredName, "", pos, err)
+	}
+	if width == 0 {
+		return NewSchemaError(ErrConstraintViolated, SchemaRequiredName, "", pos, ErrNullValue)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSNullable(t *testing.T) {
+	// SInt32 is not nullable on its own, SNullInt32 encodes its own null
+	chain := SChain(SNullable(SInt32), SNullable(SNullInt32), SNullable(STuple(SInt16, SString)))
+	assert.True(t, chain.Schemas[0].IsNullable())
+
+	for _, val := range [][]any{
+		{nil, nil, nil},
+		{int32(5), int32(6), []any{int16(1), "x"}},
+	} {
+		buf, err := EncodeValue(val, chain)
+		require.NoError(t, err)
+		require.NoError(t, ValidateBuffer(buf, chain))
+		got, err := DecodeBuffer(buf, chain)
+		require.NoError(t, err)
+		assert.Equal(t, val, got)
+	}
+
+	buf, err := EncodeValue([]any{nil, nil, nil}, chain)
+	require.NoError(t, err)
+	assert.Error(t, ValidateBuffer(buf, SChain(SInt32, SNullInt32, STuple(SInt16, SString))))
+
+	// missing named fields are written as nulls
+	named := SchemaNamedChain{SchemaChain: SChain(SInt16, SNullable(SInt64)), FieldNames: []string{"a", "b"}}
+	buf, err = EncodeValueNamed(map[string]any{"a": int16(1)}, named)
+	require.NoError(t, err)
+	got, err := DecodeBufferNamed(buf, named)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": int16(1), "b": nil}, got)
+}
+
+func TestSRequired(t *testing.T) {
+	chain := SChain(SRequired(SString), SRequired(SNullInt32))
+	assert.False(t, chain.Schemas[0].IsNullable())
+
+	val := []any{"ok", int32(3)}
+	buf, err := EncodeValue(val, chain)
+	require.NoError(t, err)
+	got, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, val, got)
+
+	_, err = EncodeValue([]any{"ok", nil}, chain)
+	assert.ErrorIs(t, err, ErrNullValue)
+	_, err = EncodeValue([]any{"", int32(3)}, chain)
+	assert.ErrorIs(t, err, ErrNullValue)
+
+	loose := SChain(SString, SNullInt32)
+	buf, err = EncodeValue([]any{"ok", nil}, loose)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, loose))
+	err = ValidateBuffer(buf, chain)
+	assert.ErrorIs(t, err, ErrNullValue)
+	_, err = DecodeBuffer(buf, chain)
+	assert.ErrorIs(t, err, ErrNullValue)
+}
+
+func TestBuildSchema_Required(t *testing.T) {
+	s := BuildSchema(&SchemaJSON{Type: "string", Required: true})
+	assert.IsType(t, SchemaRequired{}, s)
+	assert.False(t, s.IsNullable())
+}
+
+func TestNullTag_EmptyVersusNull(t *testing.T) {
+	chain := SChain(SVariableString().(SchemaString).WithNullTag(), SchemaBytes{Width: -1, NullTag: true}, SString.WithWidth(2).WithNullTag())
+	for _, val := range [][]any{
+		{nil, nil, nil},
+		{"", []byte{}, "ab"},
+	} {
+		buf, err := EncodeValue(val, chain)
+		require.NoError(t, err)
+		require.NoError(t, ValidateBuffer(buf, chain))
+		got, err := DecodeBuffer(buf, chain)
+		require.NoError(t, err)
+		assert.Equal(t, val, got)
+	}
+
+	// without NullTag both read back as ""
+	plain := SChain(SVariableString(), SVariableString())
+	buf, err := EncodeValue([]any{nil, ""}, plain)
+	require.NoError(t, err)
+	got, err := DecodeBuffer(buf, plain)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"", ""}, got)
+
+	// a fixed width string with NullTag still rejects ""
+	buf, err = EncodeValue([]any{"", nil}, SChain(SVariableString(), SNullInt8))
+	require.NoError(t, err)
+	assert.Error(t, ValidateBuffer(buf, SChain(SString.WithWidth(2).WithNullTag(), SNullInt8)))
+
+	s := BuildSchema(&SchemaJSON{Type: "string", NullTag: true, Pattern: "^a"})
+	chain = SChain(s, SString)
+	buf, err = EncodeValue([]any{nil, "x"}, chain)
+	require.NoError(t, err)
+	got, err = DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, []any{nil, "x"}, got)
+}
@@ -0,0 +1,96 @@
+package schema
+
+import (
+	"regexp"
+	"regexp/syntax"
+)
+
+// PatternLimits bounds the work of one regular expression check, since
+// Pattern and KeysMatch run configured expressions against untrusted input.
+// Go's regexp package is RE2: matching takes time linear in the input and
+// cannot backtrack, but it cannot be interrupted either, so the budget is
+// set in steps rather than wall time. A check over budget fails like a
+// mismatch, without running the expression.
+type PatternLimits struct {
+	// MaxInputLen is the longest input matched, in bytes. 0 means no limit.
+	MaxInputLen int
+	// MaxCost bounds the input length times the number of instructions in
+	// the compiled expression, which bounds the matching steps. 0 means no
+	// limit.
+	MaxCost int
+}
+
+// DefaultPatternLimits applies to Pattern and KeysMatch. Set it at start-up,
+// before schemas are used; PatternWithLimits overrides it per schema.
+var DefaultPatternLimits = PatternLimits{MaxInputLen: 64 << 10, MaxCost: 64 << 20}
+
+// boundedRegexp is a compiled expression with its program size.
+type boundedRegexp struct {
+	re    *regexp.Regexp
+	insts int
+}
+
+// mustCompileBounded compiles expr like regexp.MustCompile.
+func mustCompileBounded(expr string) boundedRegexp {
+	re := regexp.MustCompile(expr)
+	insts, err := patternInsts(expr)
+	if err != nil {
+		panic(err)
+	}
+	return boundedRegexp{re: re, insts: insts}
+}
+
+func patternInsts(expr string) (int, error) {
+	parsed, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return 0, err
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return 0, err
+	}
+	return len(prog.Inst), nil
+}
+
+// match reports whether s matches within limits, which default to
+// DefaultPatternLimits when nil.
+func (b boundedRegexp) match(s string, limits *PatternLimits) bool {
+	l := DefaultPatternLimits
+	if limits != nil {
+		l = *limits
+	}
+	if l.MaxInputLen > 0 && len(s) > l.MaxInputLen {
+		return false
+	}
+	if l.MaxCost > 0 && len(s)*b.insts > l.MaxCost {
+		return false
+	}
+	return b.re.MatchString(s)
+}
+
+// PatternAudit describes one expression found by AuditPatterns.
+type PatternAudit struct {
+	Path    string // as in FieldConstraint
+	Pattern string
+	Insts   int   // compiled program size, the per-byte matching cost
+	Err     error // set when the expression is not valid RE2 syntax
+}
+
+// AuditPatterns lists the string and key patterns declared in js with
+// their cost, so operators can review a schema before deploying it.
+// Expressions that need backtracking, such as backreferences or
+// lookarounds, are not RE2 and are reported with Err; BuildSchema would
+// panic on them.
+func AuditPatterns(js *SchemaJSON) []PatternAudit {
+	var out []PatternAudit
+	for _, fc := range ExtractConstraints(js) {
+		for _, expr := range []string{fc.Pattern, fc.KeyPattern} {
+			if expr == "" {
+				continue
+			}
+			insts, err := patternInsts(expr)
+			out = append(out, PatternAudit{Path: fc.Path, Pattern: expr, Insts: insts, Err: err})
+		}
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternLimits(t *testing.T) {
+	long := strings.Repeat("a", 100)
+	limited := SChain(SString.PatternWithLimits("^a+$", PatternLimits{MaxInputLen: 50}), SString)
+	buf, err := EncodeValue([]any{"aaa", "x"}, limited)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, limited))
+
+	_, err = EncodeValue([]any{long, "x"}, limited)
+	assert.Error(t, err)
+
+	plain := SChain(SString.Pattern("^a+$"), SString)
+	buf, err = EncodeValue([]any{long, "x"}, plain)
+	require.NoError(t, err)
+	err = ValidateBuffer(buf, limited)
+	assertSchemaError(t, err, ErrStringPattern)
+
+	costly := SChain(SString.PatternWithLimits("^a+$", PatternLimits{MaxCost: 100}), SString)
+	err = ValidateBuffer(buf, costly)
+	assertSchemaError(t, err, ErrStringPattern)
+}
+
+func TestPatternLimits_Default(t *testing.T) {
+	saved := DefaultPatternLimits
+	defer func() { DefaultPatternLimits = saved }()
+
+	keys := SChain(SMapRepeat(SString, SInt16).KeysMatch("^k[0-9]+$"), SString)
+	buf, err := EncodeValue([]any{map[string]any{"k1": int16(1), "k" + strings.Repeat("9", 40): int16(2)}, "x"}, keys)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, keys))
+
+	DefaultPatternLimits = PatternLimits{MaxInputLen: 10}
+	assert.Error(t, ValidateBuffer(buf, keys))
+}
+
+func TestAuditPatterns(t *testing.T) {
+	js := &SchemaJSON{Type: "tuple", FieldNames: []string{"code", "tags"}, Schema: []SchemaJSON{
+		{Type: "string", Pattern: "^[A-Z]{3}$"},
+		{Type: "mapRepeat", Pattern: `^(a)\1$`, Schema: []SchemaJSON{{Type: "string"}, {Type: "string"}}},
+	}}
+	audits := AuditPatterns(js)
+	require.Len(t, audits, 2)
+	assert.Equal(t, "code", audits[0].Path)
+	assert.NoError(t, audits[0].Err)
+	assert.Positive(t, audits[0].Insts)
+	assert.Equal(t, "tags", audits[1].Path)
+	assert.Error(t, audits[1].Err, "backreferences are not RE2")
+}
@@ -0,0 +1,300 @@
+package schema
+
+import (
+	"strings"
+)
+
+// SPhone accepts phone numbers in E.164 form, such as "+14155550100": a
+// plus sign, a country calling code and at most 15 digits in all. Encode
+// normalizes before checking: spaces, dashes, dots, slashes and parentheses
+// are dropped and a leading "00" becomes "+". With defaultRegion, an ISO
+// 3166 code such as "US" or "DE", numbers written without a country code
+// are taken as national numbers of that region; their trunk prefix, the
+// leading 0 in most regions or 1 in North America, is removed. Validate
+// and Decode check the stored form only. Panics if defaultRegion is not
+// known.
+func SPhone(optional bool, defaultRegion string) Schema {
+	region := strings.ToUpper(defaultRegion)
+	code, ok := callingCodes[region]
+	if region != "" && !ok {
+		panic("phone: unknown region " + defaultRegion)
+	}
+	s := SString
+	if optional {
+		s = s.Optional()
+	}
+	check := s.CheckFunc(ErrStringPhone, "E.164 phone number", isE164)
+	return SSanitized(check, stringSanitizer(func(str string) string {
+		return normalizePhone(str, region, code)
+	}))
+}
+
+func isE164(str string) bool {
+	if len(str) < 8 || len(str) > 16 || str[0] != '+' || str[1] == '0' {
+		return false
+	}
+	for i := 1; i < len(str); i++ {
+		if str[i] < '0' || str[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizePhone rewrites str in E.164 form as far as it can, and returns
+// it unchanged when it holds anything but digits and separators.
+func normalizePhone(str, region, code string) string {
+	trimmed := strings.TrimSpace(str)
+	var b strings.Builder
+	plus := false
+	for i, r := range trimmed {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			plus = true
+		case strings.ContainsRune(" -./()", r):
+		default:
+			return str
+		}
+	}
+	digits := b.String()
+	switch {
+	case digits == "":
+		return str
+	case plus:
+		return "+" + digits
+	case strings.HasPrefix(digits, "00"):
+		return "+" + digits[2:]
+	case code == "":
+		return str
+	case code == "1" && len(digits) == 11 && digits[0] == '1':
+		digits = digits[1:]
+	case digits[0] == '0' && !keepsTrunkZero[region]:
+		digits = digits[1:]
+	}
+	return "+" + code + digits
+}
+
+// keepsTrunkZero lists regions whose national numbers keep their leading 0
+// after the country code.
+var keepsTrunkZero = map[string]bool{"IT": true, "SM": true, "VA": true}
+
+// callingCodes maps ISO 3166 region codes to their country calling code.
+var callingCodes = regionsByCode(map[string]string{
+	"1":   "US CA AG AI AS BB BM BS DM DO GD GU JM KN KY LC MP MS PR SX TC TT VC VG VI",
+	"7":   "RU KZ",
+	"20":  "EG",
+	"27":  "ZA",
+	"30":  "GR",
+	"31":  "NL",
+	"32":  "BE",
+	"33":  "FR",
+	"34":  "ES",
+	"36":  "HU",
+	"39":  "IT VA",
+	"40":  "RO",
+	"41":  "CH",
+	"43":  "AT",
+	"44":  "GB GG IM JE",
+	"45":  "DK",
+	"46":  "SE",
+	"47":  "NO SJ",
+	"48":  "PL",
+	"49":  "DE",
+	"51":  "PE",
+	"52":  "MX",
+	"53":  "CU",
+	"54":  "AR",
+	"55":  "BR",
+	"56":  "CL",
+	"57":  "CO",
+	"58":  "VE",
+	"60":  "MY",
+	"61":  "AU CC CX",
+	"62":  "ID",
+	"63":  "PH",
+	"64":  "NZ",
+	"65":  "SG",
+	"66":  "TH",
+	"81":  "JP",
+	"82":  "KR",
+	"84":  "VN",
+	"86":  "CN",
+	"90":  "TR",
+	"91":  "IN",
+	"92":  "PK",
+	"93":  "AF",
+	"94":  "LK",
+	"95":  "MM",
+	"98":  "IR",
+	"211": "SS",
+	"212": "MA EH",
+	"213": "DZ",
+	"216": "TN",
+	"218": "LY",
+	"220": "GM",
+	"221": "SN",
+	"222": "MR",
+	"223": "ML",
+	"224": "GN",
+	"225": "CI",
+	"226": "BF",
+	"227": "NE",
+	"228": "TG",
+	"229": "BJ",
+	"230": "MU",
+	"231": "LR",
+	"232": "SL",
+	"233": "GH",
+	"234": "NG",
+	"235": "TD",
+	"236": "CF",
+	"237": "CM",
+	"238": "CV",
+	"239": "ST",
+	"240": "GQ",
+	"241": "GA",
+	"242": "CG",
+	"243": "CD",
+	"244": "AO",
+	"245": "GW",
+	"246": "IO",
+	"248": "SC",
+	"249": "SD",
+	"250": "RW",
+	"251": "ET",
+	"252": "SO",
+	"253": "DJ",
+	"254": "KE",
+	"255": "TZ",
+	"256": "UG",
+	"257": "BI",
+	"258": "MZ",
+	"260": "ZM",
+	"261": "MG",
+	"262": "RE YT",
+	"263": "ZW",
+	"264": "NA",
+	"265": "MW",
+	"266": "LS",
+	"267": "BW",
+	"268": "SZ",
+	"269": "KM",
+	"290": "SH",
+	"291": "ER",
+	"297": "AW",
+	"298": "FO",
+	"299": "GL",
+	"350": "GI",
+	"351": "PT",
+	"352": "LU",
+	"353": "IE",
+	"354": "IS",
+	"355": "AL",
+	"356": "MT",
+	"357": "CY",
+	"358": "FI AX",
+	"359": "BG",
+	"370": "LT",
+	"371": "LV",
+	"372": "EE",
+	"373": "MD",
+	"374": "AM",
+	"375": "BY",
+	"376": "AD",
+	"377": "MC",
+	"378": "SM",
+	"380": "UA",
+	"381": "RS",
+	"382": "ME",
+	"383": "XK",
+	"385": "HR",
+	"386": "SI",
+	"387": "BA",
+	"389": "MK",
+	"420": "CZ",
+	"421": "SK",
+	"423": "LI",
+	"500": "FK",
+	"501": "BZ",
+	"502": "GT",
+	"503": "SV",
+	"504": "HN",
+	"505": "NI",
+	"506": "CR",
+	"507": "PA",
+	"508": "PM",
+	"509": "HT",
+	"590": "GP BL MF",
+	"591": "BO",
+	"592": "GY",
+	"593": "EC",
+	"594": "GF",
+	"595": "PY",
+	"596": "MQ",
+	"597": "SR",
+	"598": "UY",
+	"599": "CW BQ",
+	"670": "TL",
+	"672": "NF",
+	"673": "BN",
+	"674": "NR",
+	"675": "PG",
+	"676": "TO",
+	"677": "SB",
+	"678": "VU",
+	"679": "FJ",
+	"680": "PW",
+	"681": "WF",
+	"682": "CK",
+	"683": "NU",
+	"685": "WS",
+	"686": "KI",
+	"687": "NC",
+	"688": "TV",
+	"689": "PF",
+	"690": "TK",
+	"691": "FM",
+	"692": "MH",
+	"850": "KP",
+	"852": "HK",
+	"853": "MO",
+	"855": "KH",
+	"856": "LA",
+	"880": "BD",
+	"886": "TW",
+	"960": "MV",
+	"961": "LB",
+	"962": "JO",
+	"963": "SY",
+	"964": "IQ",
+	"965": "KW",
+	"966": "SA",
+	"967": "YE",
+	"968": "OM",
+	"970": "PS",
+	"971": "AE",
+	"972": "IL",
+	"973": "BH",
+	"974": "QA",
+	"975": "BT",
+	"976": "MN",
+	"977": "NP",
+	"992": "TJ",
+	"993": "TM",
+	"994": "AZ",
+	"995": "GE",
+	"996": "KG",
+	"998": "UZ",
+})
+
+func regionsByCode(byCode map[string]string) map[string]string {
+	out := make(map[string]string, 256)
+	for code, regions := range byCode {
+		for _, region := range strings.Fields(regions) {
+			out[region] = code
+		}
+	}
+	return out
+}
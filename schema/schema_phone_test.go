@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSPhone(t *testing.T) {
+	cases := []struct {
+		region, in, want string
+	}{
+		{"", "+1 (415) 555-0100", "+14155550100"},
+		{"", "0049 30 1234567", "+49301234567"},
+		{"US", "415.555.0100", "+14155550100"},
+		{"US", "1-415-555-0100", "+14155550100"},
+		{"de", "030 1234567", "+49301234567"},
+		{"IT", "06 1234 5678", "+390612345678"},
+		{"GB", "+44 20 7946 0958", "+442079460958"},
+	}
+	for _, c := range cases {
+		chain := SChain(SPhone(false, c.region))
+		buf, err := EncodeValue(c.in, chain)
+		require.NoError(t, err, c.in)
+		v, err := DecodeBuffer(buf, chain)
+		require.NoError(t, err, c.in)
+		assert.Equal(t, c.want, v, c.in)
+	}
+
+	chain := SChain(SPhone(false, ""))
+	for _, in := range []string{"415 555 0100", "+0 415 555", "+1 415 CALL NOW", "+1234567890123456"} {
+		_, err := EncodeValue(in, chain)
+		assert.Error(t, err, in)
+	}
+
+	raw, err := EncodeValue("4155550100", SChain(SString))
+	require.NoError(t, err)
+	var se *SchemaError
+	require.ErrorAs(t, ValidateBuffer(raw, SChain(SPhone(false, "US"))), &se)
+	assert.Equal(t, ErrStringPhone, se.Code)
+
+	built := SChain(BuildSchema(&SchemaJSON{Type: "phone", Region: "FR"}))
+	buf, err := EncodeValue("01 23 45 67 89", built)
+	require.NoError(t, err)
+	v, err := DecodeBuffer(buf, built)
+	require.NoError(t, err)
+	assert.Equal(t, "+33123456789", v)
+
+	assert.Panics(t, func() { SPhone(false, "ZZ") })
+}
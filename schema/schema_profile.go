@@ -0,0 +1,212 @@
+package schema
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// FieldObserver is called once for every field a schema decodes. path names
+// the field within the record: chain field names or indexes, then tuple
+// indexes, tuple field names and map keys joined by dots, with "*" for the
+// elements of repeats and for the values of key-pattern maps. size is the
+// payload width in bytes.
+type FieldObserver interface {
+	ObserveField(path string, typ typetags.Type, size int, null bool)
+}
+
+// WithFieldObserver returns a copy of c whose schemas report every decoded
+// field, nested ones included, to obs while DecodeBuffer runs. Validate
+// and Encode are not observed.
+func (c SchemaChain) WithFieldObserver(obs FieldObserver) SchemaChain {
+	out := SchemaChain{Schemas: make([]Schema, len(c.Schemas))}
+	for i, s := range c.Schemas {
+		out.Schemas[i] = observeSchema(s, strconv.Itoa(i), obs)
+	}
+	return out
+}
+
+// WithFieldObserver is SchemaChain.WithFieldObserver with paths starting at
+// the field names.
+func (c SchemaNamedChain) WithFieldObserver(obs FieldObserver) SchemaNamedChain {
+	out := SchemaNamedChain{SchemaChain: SchemaChain{Schemas: make([]Schema, len(c.Schemas))}, FieldNames: c.FieldNames, Rules: c.Rules}
+	for i, s := range c.Schemas {
+		name := strconv.Itoa(i)
+		if i < len(c.FieldNames) {
+			name = c.FieldNames[i]
+		}
+		out.Schemas[i] = observeSchema(s, name, obs)
+	}
+	return out
+}
+
+// observeSchema wraps s and the schemas nested in it. Repeats are left
+// unwrapped, since tuples recognize them by type, and only their element
+// schemas report.
+func observeSchema(s Schema, path string, obs FieldObserver) Schema {
+	child := func(s Schema, name string) Schema {
+		if s == nil {
+			return nil
+		}
+		return observeSchema(s, path+"."+name, obs)
+	}
+	switch s := s.(type) {
+	case SRepeatSchema:
+		schemas := make([]Schema, len(s.Schemas))
+		for i, sch := range s.Schemas {
+			schemas[i] = observeSchema(sch, path+".*", obs)
+		}
+		s.Schemas = schemas
+		return s
+	case SchemaSanitized:
+		s.Schema = observeSchema(s.Schema, path, obs)
+		return s
+	case SchemaWidened:
+		s.Schema = observeSchema(s.Schema, path, obs)
+		return s
+	case SchemaFallback:
+		s.Primary = observeSchema(s.Primary, path, obs)
+		s.Secondary = observeSchema(s.Secondary, path, obs)
+		return s
+	case SchemaConditional:
+		// stay unwrapped, so that the enclosing container finds it
+		s.Then = observeSchema(s.Then, path, obs)
+		s.Else = observeSchema(s.Else, path, obs)
+		return s
+	case SchemaRequired:
+		s.Schema = observeSchema(s.Schema, path, obs)
+		return s
+	case SchemaRuled:
+		s.Schema = observeSchema(s.Schema, path, obs)
+		return s
+	case SchemaCached:
+		s.Schema = observeSchema(s.Schema, path, obs)
+		return s
+	case SchemaNullable:
+		// observe the wrapper, so that nulls it skips are reported too
+		inner := observeSchema(s.Schema, path, obs)
+		if o, ok := inner.(observedSchema); ok {
+			s.Schema = o.Schema
+			return observedSchema{s, path, obs}
+		}
+		s.Schema = inner
+		return s
+	case TupleSchema:
+		schemas := make([]Schema, len(s.Schemas))
+		for i, sch := range s.Schemas {
+			schemas[i] = child(sch, strconv.Itoa(i))
+		}
+		s.Schemas = schemas
+		return observedSchema{s, path, obs}
+	case TupleSchemaNamed:
+		schemas := make([]Schema, len(s.Schemas))
+		for i, sch := range s.Schemas {
+			name := strconv.Itoa(i)
+			if i < len(s.FieldNames) {
+				name = s.FieldNames[i]
+			}
+			schemas[i] = child(sch, name)
+		}
+		s.Schemas = schemas
+		return observedSchema{s, path, obs}
+	case SchemaMapUnordered:
+		fields := make(map[string]Schema, len(s.Fields))
+		for k, sch := range s.Fields {
+			fields[k] = child(sch, k)
+		}
+		s.Fields = fields
+		return observedSchema{s, path, obs}
+	case SchemaMapRepeat:
+		s.Value = child(s.Value, "*")
+		return observedSchema{s, path, obs}
+	case SchemaMapSortedKeys:
+		s.Value = child(s.Value, "*")
+		return observedSchema{s, path, obs}
+	}
+	return observedSchema{s, path, obs}
+}
+
+// observedSchema reports each successful Decode of Schema.
+type observedSchema struct {
+	Schema
+	path string
+	obs  FieldObserver
+}
+
+func (s observedSchema) Decode(seq *access.SeqGetAccess) (any, error) {
+	typ, width, peekErr := seq.PeekTypeWidth()
+	val, err := s.Schema.Decode(seq)
+	if err == nil && peekErr == nil {
+		s.obs.ObserveField(s.path, typ, width, val == nil)
+	}
+	return val, err
+}
+
+// FieldProfile aggregates the observations of one path.
+type FieldProfile struct {
+	Path    string
+	Count   int // fields decoded, nulls included
+	Nulls   int
+	Bytes   int // payload bytes summed over all fields
+	MinSize int
+	MaxSize int
+	Types   map[typetags.Type]int
+}
+
+// Profiler is a FieldObserver that aggregates observations per path. It is
+// safe for concurrent use, so one Profiler can collect the fields of many
+// decoding goroutines.
+type Profiler struct {
+	mu     sync.Mutex
+	fields map[string]*FieldProfile
+}
+
+// NewProfiler returns an empty Profiler.
+func NewProfiler() *Profiler {
+	return &Profiler{fields: map[string]*FieldProfile{}}
+}
+
+func (p *Profiler) ObserveField(path string, typ typetags.Type, size int, null bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fp, ok := p.fields[path]
+	if !ok {
+		fp = &FieldProfile{Path: path, MinSize: size, MaxSize: size, Types: map[typetags.Type]int{}}
+		p.fields[path] = fp
+	}
+	fp.Count++
+	if null {
+		fp.Nulls++
+	}
+	fp.Bytes += size
+	fp.MinSize = min(fp.MinSize, size)
+	fp.MaxSize = max(fp.MaxSize, size)
+	fp.Types[typ]++
+}
+
+// Profiles returns a copy of the aggregates sorted by path.
+func (p *Profiler) Profiles() []FieldProfile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]FieldProfile, 0, len(p.fields))
+	for _, fp := range p.fields {
+		cp := *fp
+		cp.Types = make(map[typetags.Type]int, len(fp.Types))
+		for t, n := range fp.Types {
+			cp.Types[t] = n
+		}
+		out = append(out, cp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// Reset discards all aggregates.
+func (p *Profiler) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fields = map[string]*FieldProfile{}
+}
@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfiler_NestedPaths(t *testing.T) {
+	chain := SchemaNamedChain{
+		SchemaChain: SChain(
+			SString,
+			SNullInt32,
+			STupleNamed([]string{"lat", "lon"}, SFloat64, SFloat64),
+			SMapUnordered(map[string]Schema{"k": SInt16}),
+			SRepeat(0, -1, SString),
+		),
+		FieldNames: []string{"name", "note", "pos", "attrs", "tags"},
+	}
+	records := []map[string]any{
+		{"name": "a", "note": int32(5), "pos": map[string]any{"lat": 1.0, "lon": 2.0}, "attrs": map[string]any{"k": int16(1)}, "tags": []any{"x", "yy"}},
+		{"name": "abc", "pos": map[string]any{"lat": 3.0, "lon": 4.0}, "attrs": map[string]any{"k": int16(2)}, "tags": []any{"zzz"}},
+	}
+
+	prof := NewProfiler()
+	observed := chain.WithFieldObserver(prof)
+	for _, rec := range records {
+		buf, err := EncodeValueNamed(rec, chain)
+		require.NoError(t, err)
+		got, err := DecodeBufferNamed(buf, observed)
+		require.NoError(t, err)
+		want, err := DecodeBufferNamed(buf, chain)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	byPath := map[string]FieldProfile{}
+	var paths []string
+	for _, fp := range prof.Profiles() {
+		byPath[fp.Path] = fp
+		paths = append(paths, fp.Path)
+	}
+	assert.Equal(t, []string{"attrs", "attrs.k", "name", "note", "pos", "pos.lat", "pos.lon", "tags.*"}, paths)
+
+	name := byPath["name"]
+	assert.Equal(t, 2, name.Count)
+	assert.Equal(t, 4, name.Bytes)
+	assert.Equal(t, 1, name.MinSize)
+	assert.Equal(t, 3, name.MaxSize)
+	assert.Equal(t, map[typetags.Type]int{typetags.TypeString: 2}, name.Types)
+
+	note := byPath["note"]
+	assert.Equal(t, 2, note.Count)
+	assert.Equal(t, 1, note.Nulls)
+
+	assert.Equal(t, 2, byPath["pos.lat"].Count)
+	assert.Equal(t, 16, byPath["pos.lon"].Bytes)
+	assert.Equal(t, map[typetags.Type]int{typetags.TypeMap: 2}, byPath["attrs"].Types)
+	assert.Equal(t, 3, byPath["tags.*"].Count)
+
+	prof.Reset()
+	assert.Empty(t, prof.Profiles())
+}
+
+func TestProfiler_Concurrent(t *testing.T) {
+	chain := SChain(SInt64, SString)
+	buf, err := EncodeValue([]any{int64(1), "v"}, chain)
+	require.NoError(t, err)
+
+	prof := NewProfiler()
+	observed := chain.WithFieldObserver(prof)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, err := DecodeBuffer(buf, observed)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	profiles := prof.Profiles()
+	require.Len(t, profiles, 2)
+	assert.Equal(t, "0", profiles[0].Path)
+	assert.Equal(t, 800, profiles[0].Count)
+	assert.Equal(t, 6400, profiles[0].Bytes)
+}
@@ -0,0 +1,128 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// SchemaSnapshot is an immutable view of the schema held by a SchemaProvider.
+// Validations that loaded a snapshot keep using it even if a reload happens meanwhile.
+type SchemaSnapshot struct {
+	Version uint64
+	Chain   SchemaChain
+	Source  *SchemaJSON // nil when the chain was stored directly
+}
+
+// SchemaProvider holds the active schema and swaps it atomically on reload,
+// so services can pick up new schema documents without restart and without
+// racing in-flight validations.
+type SchemaProvider struct {
+	current   atomic.Pointer[SchemaSnapshot]
+	mu        sync.Mutex // serializes writers and guards listeners
+	listeners map[int]func(old, new *SchemaSnapshot)
+	nextID    int
+}
+
+// NewSchemaProvider creates a provider serving chain as version 1.
+func NewSchemaProvider(chain SchemaChain) *SchemaProvider {
+	p := &SchemaProvider{listeners: make(map[int]func(old, new *SchemaSnapshot))}
+	p.current.Store(&SchemaSnapshot{Version: 1, Chain: chain})
+	return p
+}
+
+// Load returns the current snapshot.
+func (p *SchemaProvider) Load() *SchemaSnapshot {
+	return p.current.Load()
+}
+
+// Store swaps in chain and notifies listeners. It returns the new version.
+func (p *SchemaProvider) Store(chain SchemaChain) uint64 {
+	return p.swap(chain, nil)
+}
+
+func (p *SchemaProvider) swap(chain SchemaChain, source *SchemaJSON) uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	old := p.current.Load()
+	next := &SchemaSnapshot{Version: old.Version + 1, Chain: chain, Source: source}
+	p.current.Store(next)
+	for _, fn := range p.listeners {
+		fn(old, next)
+	}
+	return next.Version
+}
+
+// Reload builds a schema from js and swaps it in.
+// On a build failure the current schema stays active and the error is returned.
+func (p *SchemaProvider) Reload(js *SchemaJSON) (version uint64, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			version, err = 0, fmt.Errorf("schema reload: %v", r)
+		}
+	}()
+	built := BuildSchema(js)
+	return p.swap(SChain(built), js), nil
+}
+
+// ReloadJSON parses a SchemaJSON document and swaps it in.
+func (p *SchemaProvider) ReloadJSON(data []byte) (uint64, error) {
+	var js SchemaJSON
+	if err := json.Unmarshal(data, &js); err != nil {
+		return 0, fmt.Errorf("schema reload: %w", err)
+	}
+	return p.Reload(&js)
+}
+
+// ReloadFile reads a SchemaJSON document from path and swaps it in.
+func (p *SchemaProvider) ReloadFile(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("schema reload: %w", err)
+	}
+	return p.ReloadJSON(data)
+}
+
+// ReloadModules loads the module name and its imports from fsys with
+// SchemaModules and swaps the result in.
+func (p *SchemaProvider) ReloadModules(fsys fs.FS, name string) (uint64, error) {
+	js, err := NewSchemaModules(fsys).Load(name)
+	if err != nil {
+		return 0, fmt.Errorf("schema reload: %w", err)
+	}
+	return p.Reload(js)
+}
+
+// Subscribe registers fn to be called after every swap.
+// Listeners run synchronously while the writer lock is held, so they must not reload.
+// The returned function removes the listener.
+func (p *SchemaProvider) Subscribe(fn func(old, new *SchemaSnapshot)) (cancel func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := p.nextID
+	p.nextID++
+	p.listeners[id] = fn
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		delete(p.listeners, id)
+	}
+}
+
+// Validate validates buf against the current snapshot.
+func (p *SchemaProvider) Validate(buf []byte) error {
+	return ValidateBuffer(buf, p.Load().Chain)
+}
+
+// Decode decodes buf using the current snapshot.
+func (p *SchemaProvider) Decode(buf []byte) (any, error) {
+	return DecodeBuffer(buf, p.Load().Chain)
+}
+
+// Encode encodes val using the current snapshot.
+func (p *SchemaProvider) Encode(val any) ([]byte, error) {
+	return EncodeValue(val, p.Load().Chain)
+}
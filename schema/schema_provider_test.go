@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	pack "github.com/quickwritereader/PackOS/packable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaProvider_ReloadAndNotify(t *testing.T) {
+	p := NewSchemaProvider(SChain(SInt32))
+	assert.Equal(t, uint64(1), p.Load().Version)
+
+	buf32 := pack.Pack(pack.PackInt32(5))
+	buf16 := pack.Pack(pack.PackInt16(5))
+	require.NoError(t, p.Validate(buf32))
+	require.Error(t, p.Validate(buf16))
+
+	var seen []uint64
+	cancel := p.Subscribe(func(old, new *SchemaSnapshot) {
+		seen = append(seen, new.Version)
+		assert.Equal(t, old.Version+1, new.Version)
+	})
+
+	version, err := p.ReloadJSON([]byte(`{"type":"int16"}`))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), version)
+	require.NoError(t, p.Validate(buf16))
+	require.Error(t, p.Validate(buf32))
+	require.NotNil(t, p.Load().Source)
+
+	cancel()
+	p.Store(SChain(SInt32))
+	assert.Equal(t, []uint64{2}, seen, "cancelled listener must not be called")
+}
+
+func TestSchemaProvider_ReloadFailureKeepsCurrent(t *testing.T) {
+	p := NewSchemaProvider(SChain(SInt32))
+
+	_, err := p.ReloadJSON([]byte(`{"type":"no-such-type"}`))
+	require.Error(t, err)
+	_, err = p.ReloadJSON([]byte(`{`))
+	require.Error(t, err)
+
+	assert.Equal(t, uint64(1), p.Load().Version)
+	require.NoError(t, p.Validate(pack.Pack(pack.PackInt32(1))))
+}
+
+func TestSchemaProvider_ReloadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"type":"string","prefix":"id-"}`), 0o644))
+
+	p := NewSchemaProvider(SChain(SInt32))
+	_, err := p.ReloadFile(path)
+	require.NoError(t, err)
+
+	require.NoError(t, p.Validate(pack.Pack(pack.PackString("id-1"))))
+	require.Error(t, p.Validate(pack.Pack(pack.PackString("x-1"))))
+}
+
+func TestSchemaProvider_ConcurrentSwap(t *testing.T) {
+	p := NewSchemaProvider(SChain(SInt32))
+	buf := pack.Pack(pack.PackInt32(7))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				snap := p.Load()
+				assert.NoError(t, ValidateBuffer(buf, snap.Chain))
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		p.Store(SChain(SInt32))
+	}
+	wg.Wait()
+	assert.Equal(t, uint64(51), p.Load().Version)
+}
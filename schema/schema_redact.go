@@ -0,0 +1,47 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// RedactPlaceholder is written in place of redacted string and bytes fields.
+var RedactPlaceholder = "***"
+
+// Redact returns a copy of buf in which the string or bytes field at each
+// of fields is replaced with RedactPlaceholder. Paths are those of
+// UpdateField. Null fields stay null, so absence is still visible. Only the
+// containers along each path are rebuilt; no other field is decoded. The
+// placeholder is not checked against the field's constraints, so a pattern
+// or fixed width may no longer hold in the result.
+func Redact(buf []byte, chain SchemaNamedChain, fields []string) ([]byte, error) {
+	put := access.NewPutAccessFromPool()
+	defer access.ReleasePutAccess(put)
+	put.AddString(RedactPlaceholder)
+	placeholder := put.Pack()
+
+	out := append([]byte(nil), buf...)
+	for _, path := range fields {
+		_, wirePath, err := resolveUpdatePath(chain, path)
+		if err != nil {
+			return nil, err
+		}
+		tag, val, err := access.FieldAtPath(out, wirePath)
+		if err != nil {
+			return nil, NewSchemaError(ErrInvalidFormat, SchemaNamedChainName, path, -1, err)
+		}
+		if tag != typetags.TypeString {
+			return nil, NewSchemaError(ErrConstraintViolated, SchemaNamedChainName, path, -1,
+				fmt.Errorf("%w: cannot redact %v", ErrTypeMisMatch, tag))
+		}
+		if len(val) == 0 {
+			continue
+		}
+		if out, err = access.ReplacePath(out, wirePath, placeholder); err != nil {
+			return nil, NewSchemaError(ErrInvalidFormat, SchemaNamedChainName, path, -1, err)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	chain := SchemaNamedChain{
+		SchemaChain: SChain(
+			SInt32,
+			SString,
+			SMapUnorderedNamed([]string{"token", "user"}, SString, SString),
+			SVariableBytes(),
+			SNullInt32,
+			SString,
+		),
+		FieldNames: []string{"id", "password", "auth", "key", "n", "note"},
+	}
+	doc := map[string]any{
+		"id":       int32(7),
+		"password": "hunter2",
+		"auth":     map[string]any{"token": "tok-abcdef", "user": "ann"},
+		"key":      []byte{1, 2, 3, 4},
+		"note":     "",
+	}
+	buf, err := EncodeValueNamed(doc, chain)
+	require.NoError(t, err)
+	orig := bytes.Clone(buf)
+
+	out, err := Redact(buf, chain, []string{"password", "auth.token", "key", "note"})
+	require.NoError(t, err)
+	assert.Equal(t, orig, buf, "input is not modified")
+	assert.False(t, bytes.Contains(out, []byte("hunter2")))
+	assert.False(t, bytes.Contains(out, []byte("tok-abcdef")))
+
+	decoded, err := DecodeBufferNamed(out, SchemaNamedChain{
+		SchemaChain: SChain(SInt32, SString, SMapUnorderedNamed([]string{"token", "user"}, SString, SString), SString, SNullInt32, SString),
+		FieldNames:  chain.FieldNames,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":       int32(7),
+		"password": "***",
+		"auth":     map[string]any{"token": "***", "user": "ann"},
+		"key":      "***",
+		"n":        nil,
+		"note":     "",
+	}, decoded)
+
+	_, err = Redact(buf, chain, []string{"id"})
+	assertSchemaError(t, err, ErrConstraintViolated)
+	_, err = Redact(buf, chain, []string{"missing"})
+	assertSchemaError(t, err, ErrConstraintViolated)
+}
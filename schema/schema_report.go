@@ -0,0 +1,137 @@
+package schema
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Field statuses of a ValidationReport.
+const (
+	FieldValid     = "valid"
+	FieldInvalid   = "invalid"
+	FieldUnchecked = "unchecked" // inside a container that failed as a whole
+)
+
+// ValidationReport is the machine-readable outcome of validating one
+// buffer, for UIs that show feedback next to each form field. It
+// serializes to JSON as is.
+type ValidationReport struct {
+	Valid  bool          `json:"valid"`
+	Fields []FieldReport `json:"fields"`
+}
+
+// FieldReport is the outcome for one field declared in the schema
+// document, under the path used by ExtractConstraints. Repeated items and
+// map values share one report, which lists the errors of all of them.
+type FieldReport struct {
+	Path       string           `json:"path"`
+	Status     string           `json:"status"`
+	Constraint *FieldConstraint `json:"constraint,omitempty"`
+	Errors     []FieldError     `json:"errors,omitempty"`
+}
+
+// FieldError is one violation in a FieldReport.
+type FieldError struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Position int    `json:"position"`         // index of the field in its container
+	Actual   any    `json:"actual,omitempty"` // decoded value, for primitive fields
+}
+
+// NewValidationReport validates buf against the schema built from js, like
+// ValidateAll, and reports every field of js with its constraints. js must
+// be a document BuildSchema accepts. Errors at paths js does not declare,
+// such as a malformed buffer, are reported under their own path with no
+// constraint.
+func NewValidationReport(buf []byte, js *SchemaJSON) ValidationReport {
+	c := validateAll(buf, SChain(BuildSchema(js)))
+
+	constraints := ExtractConstraints(js)
+	paths := map[string]string{}
+	reportPaths(js, "0", "", paths)
+
+	report := ValidationReport{Valid: len(c.errs) == 0}
+	index := map[string]int{}
+	for i := range constraints {
+		index[constraints[i].Path] = len(report.Fields)
+		report.Fields = append(report.Fields, FieldReport{
+			Path:       constraints[i].Path,
+			Status:     FieldValid,
+			Constraint: &constraints[i],
+		})
+	}
+	for i, e := range c.errs {
+		path, ok := paths[e.Field]
+		if !ok {
+			path = e.Field
+		}
+		at, ok := index[path]
+		if !ok {
+			at = len(report.Fields)
+			index[path] = at
+			report.Fields = append(report.Fields, FieldReport{Path: path})
+		}
+		fr := &report.Fields[at]
+		fr.Status = FieldInvalid
+		fr.Errors = append(fr.Errors, FieldError{
+			Code:     specificCode(&e).String(),
+			Message:  e.Error(),
+			Position: e.Position,
+			Actual:   c.actual[i],
+		})
+	}
+	for i := range report.Fields {
+		fr := &report.Fields[i]
+		if fr.Status == FieldValid && invalidAncestor(report.Fields, fr.Path) {
+			fr.Status = FieldUnchecked
+		}
+	}
+	return report
+}
+
+// reportPaths maps the paths ValidateAll gives the fields of js to the
+// paths ExtractConstraints gives them.
+func reportPaths(js *SchemaJSON, vpath, cpath string, out map[string]string) {
+	out[vpath] = cpath
+	child := func(seg string) string {
+		if cpath == "" {
+			return seg
+		}
+		return cpath + "." + seg
+	}
+	switch js.Type {
+	case "tuple", "mapUnordered":
+		for i := range js.Schema {
+			seg := strconv.Itoa(i)
+			if i < len(js.FieldNames) {
+				seg = js.FieldNames[i]
+			}
+			reportPaths(&js.Schema[i], vpath+"."+seg, child(seg), out)
+		}
+	case "repeat":
+		// the elements of a repeat of several schemas share one path
+		if len(js.Schema) == 1 {
+			reportPaths(&js.Schema[0], vpath+".*", cpath+"[]", out)
+		}
+	case "mapRepeat":
+		if len(js.Schema) == 2 {
+			reportPaths(&js.Schema[1], vpath+".*", child("*"), out)
+		}
+	case "mapSortedKeys":
+		if len(js.Schema) > 0 {
+			reportPaths(&js.Schema[0], vpath+".*", child("*"), out)
+		}
+	}
+}
+
+func invalidAncestor(fields []FieldReport, path string) bool {
+	for _, f := range fields {
+		if f.Status != FieldInvalid || f.Path == path {
+			continue
+		}
+		if f.Path == "" || strings.HasPrefix(path, f.Path+".") || strings.HasPrefix(path, f.Path+"[") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewValidationReport(t *testing.T) {
+	var js SchemaJSON
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "tuple",
+		"variableLength": true,
+		"fieldNames": ["age", "code", "labels", "tags"],
+		"schema": [
+			{"type": "int16", "min": 0, "max": 150},
+			{"type": "string", "prefix": "c-"},
+			{"type": "mapRepeat", "schema": [{"type": "string"}, {"type": "string", "width": 2}]},
+			{"type": "repeat", "min": 1, "max": 5, "schema": [{"type": "string", "prefix": "#"}]}
+		]
+	}`), &js))
+
+	buf, err := EncodeValue(map[string]any{
+		"age":    int16(200),
+		"code":   "c-1",
+		"tags":   []any{"#a", "b"},
+		"labels": map[string]any{"x": "ok"},
+	}, SChain(STupleNamedVal([]string{"age", "code", "labels", "tags"},
+		SInt16, SString, SMapRepeat(SString, SString), SRepeat(0, -1, SString))))
+	require.NoError(t, err)
+
+	report := NewValidationReport(buf, &js)
+	assert.False(t, report.Valid)
+	byPath := map[string]FieldReport{}
+	for _, f := range report.Fields {
+		byPath[f.Path] = f
+	}
+
+	age := byPath["age"]
+	assert.Equal(t, FieldInvalid, age.Status)
+	require.Len(t, age.Errors, 1)
+	assert.Equal(t, "ErrOutOfRange", age.Errors[0].Code)
+	assert.Equal(t, int16(200), age.Errors[0].Actual)
+	assert.Equal(t, 150.0, *age.Constraint.Max)
+
+	assert.Equal(t, FieldValid, byPath["code"].Status)
+	assert.Equal(t, FieldValid, byPath["tags"].Status)
+	tag := byPath["tags[]"]
+	assert.Equal(t, FieldInvalid, tag.Status)
+	require.Len(t, tag.Errors, 1)
+	assert.Equal(t, "b", tag.Errors[0].Actual)
+	assert.Equal(t, 4, tag.Errors[0].Position)
+	assert.Equal(t, FieldValid, byPath["labels.*"].Status)
+
+	out, err := json.Marshal(age)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	assert.Equal(t, "invalid", decoded["status"])
+	assert.Equal(t, 200.0, decoded["errors"].([]any)[0].(map[string]any)["actual"])
+
+	good, err := EncodeValue(map[string]any{
+		"age": int16(20), "code": "c-1", "tags": []any{"#a"}, "labels": map[string]any{"y": "ok"},
+	}, SChain(BuildSchema(&js)))
+	require.NoError(t, err)
+	report = NewValidationReport(good, &js)
+	assert.True(t, report.Valid)
+	for _, f := range report.Fields {
+		assert.Equal(t, FieldValid, f.Status, f.Path)
+	}
+}
+
+func TestNewValidationReport_Unchecked(t *testing.T) {
+	js := SchemaJSON{Type: "tuple", FieldNames: []string{"user"}, Schema: []SchemaJSON{{
+		Type: "tuple", FieldNames: []string{"name"}, Schema: []SchemaJSON{{Type: "string"}},
+	}}}
+	buf, err := EncodeValue([]any{int32(5)}, SChain(STuple(SInt32)))
+	require.NoError(t, err)
+
+	report := NewValidationReport(buf, &js)
+	assert.False(t, report.Valid)
+	byPath := map[string]FieldReport{}
+	for _, f := range report.Fields {
+		byPath[f.Path] = f
+	}
+	assert.Equal(t, FieldInvalid, byPath["user"].Status)
+	assert.Equal(t, FieldUnchecked, byPath["user.name"].Status)
+}
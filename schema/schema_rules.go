@@ -0,0 +1,171 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/quickwritereader/PackOS/access"
+)
+
+const SchemaRuledName = "SchemaRuled"
+
+// Rule checks an invariant across the fields of a record, such as
+// start_date <= end_date or password == confirm_password. fields maps the
+// field names of a SchemaNamedChain to their values as DecodeBufferNamed
+// returns them.
+type Rule func(fields map[string]any) error
+
+// WithRule returns a copy of c that checks rule once all fields are read:
+// at the end of DecodeBufferNamed and ValidateBufferNamed, on the result
+// of EncodeValueNamed and UpdateField, and on envelope bodies read or
+// written by a SchemaDispatcher. Rules run in the order they were added and
+// the first error is returned with code ErrRuleViolated.
+func (c SchemaNamedChain) WithRule(rule Rule) SchemaNamedChain {
+	c.Rules = append(slices.Clip(c.Rules), rule)
+	return c
+}
+
+func (c SchemaNamedChain) checkRules(fields map[string]any) error {
+	for _, rule := range c.Rules {
+		if err := rule(fields); err != nil {
+			return NewSchemaError(ErrRuleViolated, SchemaNamedChainName, "", -1, err)
+		}
+	}
+	return nil
+}
+
+// ValidateBufferNamed is ValidateBuffer for named chains. When chain has
+// rules, buf is decoded as well to check them.
+func ValidateBufferNamed(buf []byte, chain SchemaNamedChain) error {
+	if err := ValidateBuffer(buf, chain.SchemaChain); err != nil {
+		return err
+	}
+	if len(chain.Rules) == 0 {
+		return nil
+	}
+	_, err := DecodeBufferNamed(buf, chain)
+	return err
+}
+
+// FieldRule checks one decoded field value, such as an IBAN checksum.
+type FieldRule func(v any) error
+
+// Registry of field rules referenced by SchemaJSON.Rules and SRules.
+var fieldRules = map[string]FieldRule{}
+
+// RegisterRule makes rule available under name to SRules and to
+// SchemaJSON.Rules, so rule libraries plug into programmatic and JSON
+// schemas alike:
+//
+//	schema.RegisterRule("iban", func(v any) error {
+//	    if s, ok := v.(string); ok && !ibanValid(s) {
+//	        return errors.New("invalid IBAN")
+//	    }
+//	    return nil
+//	})
+//
+//	{"type": "string", "rules": ["iban"]}
+//
+// Notes:
+//   - Names are case-sensitive.
+//   - Panics if the name is empty or already registered.
+//   - SRules and BuildSchema panic on names that are not registered.
+func RegisterRule(name string, rule FieldRule) {
+	if name == "" {
+		panic("cannot register empty rule name")
+	}
+	if _, exists := fieldRules[name]; exists {
+		panic("rule already registered: " + name)
+	}
+	fieldRules[name] = rule
+}
+
+// UnregisterRule removes a rule added with RegisterRule. Schemas built
+// before keep it. If the name is not found, the function does nothing.
+func UnregisterRule(name string) {
+	delete(fieldRules, name)
+}
+
+// SchemaRuled checks the fields Schema reads and writes against registered
+// rules, in order, after Schema's own constraints. Rules see values as
+// Decode returns them: Validate decodes the field to run them, and Encode
+// decodes what Schema wrote. Null fields are not checked. A rule's error is
+// returned with code ErrRuleViolated.
+type SchemaRuled struct {
+	Schema Schema
+	Names  []string
+	rules  []FieldRule
+}
+
+// SRules wraps s with the rules registered under names.
+func SRules(s Schema, names ...string) SchemaRuled {
+	rules := make([]FieldRule, len(names))
+	for i, name := range names {
+		rule, ok := fieldRules[name]
+		if !ok {
+			panic("unknown rule: " + name)
+		}
+		rules[i] = rule
+	}
+	return SchemaRuled{Schema: s, Names: names, rules: rules}
+}
+
+func (s SchemaRuled) IsNullable() bool { return s.Schema.IsNullable() }
+
+func (s SchemaRuled) Validate(seq *access.SeqGetAccess) error {
+	pos := seq.CurrentIndex()
+	cp := seq.Checkpoint()
+	if err := s.Schema.Validate(seq); err != nil {
+		return err
+	}
+	if err := seq.Restore(cp); err != nil {
+		return NewSchemaError(ErrInvalidFormat, SchemaRuledName, "", pos, err)
+	}
+	_, err := s.Decode(seq)
+	return err
+}
+
+func (s SchemaRuled) Decode(seq *access.SeqGetAccess) (any, error) {
+	pos := seq.CurrentIndex()
+	v, err := s.Schema.Decode(seq)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.check(v); err != nil {
+		return nil, NewSchemaError(ErrRuleViolated, SchemaRuledName, "", pos, err)
+	}
+	return v, nil
+}
+
+func (s SchemaRuled) Encode(put *access.PutAccess, val any) error {
+	scratch := access.NewPutAccessFromPool()
+	defer access.ReleasePutAccess(scratch)
+	if err := s.Schema.Encode(scratch, val); err != nil {
+		return err
+	}
+	buf := scratch.Pack()
+	seq, err := access.NewSeqGetAccess(buf)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaRuledName, "", -1, err)
+	}
+	v, err := s.Schema.Decode(seq)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaRuledName, "", -1, err)
+	}
+	if err := s.check(v); err != nil {
+		return NewSchemaError(ErrRuleViolated, SchemaRuledName, "", -1, err)
+	}
+	return appendPackedFields(put, buf)
+}
+
+func (s SchemaRuled) check(v any) error {
+	if v == nil {
+		return nil
+	}
+	for i, rule := range s.rules {
+		if err := rule(v); err != nil {
+			return fmt.Errorf("rule %s: %w", s.Names[i], err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaNamedChain_WithRule(t *testing.T) {
+	base := SchemaNamedChain{
+		SchemaChain: SChain(SDateRange(false, nil, nil), SDateRange(false, nil, nil), SString, SString),
+		FieldNames:  []string{"start_date", "end_date", "password", "confirm_password"},
+	}
+	chain := base.WithRule(func(f map[string]any) error {
+		if f["end_date"].(time.Time).Before(f["start_date"].(time.Time)) {
+			return errors.New("end_date is before start_date")
+		}
+		return nil
+	}).WithRule(func(f map[string]any) error {
+		if f["password"] != f["confirm_password"] {
+			return errors.New("passwords differ")
+		}
+		return nil
+	})
+	assert.Empty(t, base.Rules, "WithRule returns a copy")
+
+	jan, feb := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	good := map[string]any{"start_date": jan, "end_date": feb, "password": "pw", "confirm_password": "pw"}
+	buf, err := EncodeValueNamed(good, chain)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBufferNamed(buf, chain))
+	got, err := DecodeBufferNamed(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, "pw", got.(map[string]any)["password"])
+
+	swapped := map[string]any{"start_date": feb, "end_date": jan, "password": "pw", "confirm_password": "pw"}
+	_, err = EncodeValueNamed(swapped, chain)
+	assertSchemaError(t, err, ErrRuleViolated)
+	assert.ErrorContains(t, err, "end_date is before start_date")
+
+	buf, err = EncodeValueNamed(map[string]any{"start_date": jan, "end_date": feb, "password": "pw", "confirm_password": "px"}, base)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBufferNamed(buf, base))
+	err = ValidateBufferNamed(buf, chain)
+	assertSchemaError(t, err, ErrRuleViolated)
+	assert.ErrorContains(t, err, "passwords differ")
+	_, err = DecodeBufferNamed(buf, chain)
+	assertSchemaError(t, err, ErrRuleViolated)
+
+	observed := chain.WithFailureObserver(NewFailureStats())
+	assert.Len(t, observed.Rules, 2)
+}
+
+func TestSRules(t *testing.T) {
+	RegisterRule("even", func(v any) error {
+		if n, ok := v.(int16); ok && n%2 != 0 {
+			return errors.New("odd")
+		}
+		return nil
+	})
+	defer UnregisterRule("even")
+	assert.Panics(t, func() { RegisterRule("even", func(any) error { return nil }) })
+	assert.Panics(t, func() { SRules(SInt16, "missing") })
+
+	var js SchemaJSON
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "tuple", "fieldNames": ["n", "m"],
+		"schema": [{"type": "int16", "rules": ["even"]}, {"type": "int16", "nullable": true, "rules": ["even"]}]}`), &js))
+	chain := SChain(BuildSchema(&js))
+
+	buf, err := EncodeValue(map[string]any{"n": json.Number("4")}, chain)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, chain))
+	got, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"n": int16(4), "m": nil}, got)
+
+	_, err = EncodeValue(map[string]any{"n": json.Number("3")}, chain)
+	require.Error(t, err)
+	assert.Equal(t, ErrRuleViolated, innermostSchemaError(err).Code)
+
+	bad, err := EncodeValue(map[string]any{"n": int16(3)}, SChain(STupleNamed([]string{"n", "m"}, SInt16, SNullInt16)))
+	require.NoError(t, err)
+	err = ValidateBuffer(bad, chain)
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "rule even: odd")
+	assert.Equal(t, []string{"0", "n"}, ErrorPath(err))
+	_, err = DecodeBuffer(bad, chain)
+	assert.Error(t, err)
+
+	errs := ValidateAll(bad, chain)
+	require.Len(t, errs, 1)
+	assert.Equal(t, ErrRuleViolated, errs[0].Code)
+
+	fcs := ExtractConstraints(&js)
+	assert.Equal(t, []string{"even"}, fcs[1].Rules)
+}
@@ -0,0 +1,190 @@
+package schema
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/quickwritereader/PackOS/access"
+)
+
+// Sanitizer rewrites a value before it is encoded. Sanitizers leave values
+// of types they do not handle unchanged, so constraint checks still see them.
+type Sanitizer func(any) any
+
+// SchemaSanitized runs Sanitizers in order on every value passed to Encode,
+// then encodes the result with Schema, so constraints apply to the cleaned
+// value. Validate and Decode are those of Schema; stored data is not
+// rewritten.
+type SchemaSanitized struct {
+	Schema     Schema
+	Sanitizers []Sanitizer
+}
+
+// SSanitized wraps s with sanitizers applied on encode.
+func SSanitized(s Schema, sanitizers ...Sanitizer) SchemaSanitized {
+	return SchemaSanitized{Schema: s, Sanitizers: sanitizers}
+}
+
+func (s SchemaSanitized) IsNullable() bool { return s.Schema.IsNullable() }
+
+func (s SchemaSanitized) Validate(seq *access.SeqGetAccess) error {
+	return s.Schema.Validate(seq)
+}
+
+func (s SchemaSanitized) Decode(seq *access.SeqGetAccess) (any, error) {
+	return s.Schema.Decode(seq)
+}
+
+func (s SchemaSanitized) Encode(put *access.PutAccess, val any) error {
+	for _, fn := range s.Sanitizers {
+		val = fn(val)
+	}
+	return s.Schema.Encode(put, val)
+}
+
+// stringSanitizer lifts a string function to a Sanitizer.
+func stringSanitizer(fn func(string) string) Sanitizer {
+	return func(v any) any {
+		if str, ok := v.(string); ok {
+			return fn(str)
+		}
+		return v
+	}
+}
+
+var (
+	// SanitizeTrim removes leading and trailing white space.
+	SanitizeTrim = stringSanitizer(strings.TrimSpace)
+	// SanitizeCollapseSpace trims and replaces each run of white space with
+	// a single space.
+	SanitizeCollapseSpace = stringSanitizer(func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	})
+	// SanitizeStripControl removes control characters other than tab,
+	// newline and carriage return.
+	SanitizeStripControl = stringSanitizer(func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+				return -1
+			}
+			return r
+		}, s)
+	})
+	// SanitizeLower maps strings to lower case.
+	SanitizeLower = stringSanitizer(strings.ToLower)
+	// SanitizeUpper maps strings to upper case.
+	SanitizeUpper = stringSanitizer(strings.ToUpper)
+)
+
+// SanitizeEmptyAsNull maps an empty []any or map[string]any to nil, the way
+// access.FromJSON packs [] and {}.
+func SanitizeEmptyAsNull(v any) any {
+	switch c := v.(type) {
+	case []any:
+		if len(c) == 0 {
+			return nil
+		}
+	case map[string]any:
+		if len(c) == 0 {
+			return nil
+		}
+	}
+	return v
+}
+
+// SanitizeClamp limits numbers to [min, max], keeping their Go type. Either
+// bound may be nil. Integers are clamped to the integral part of the bounds.
+// Strings are left alone, since they may not be meant as numbers. Values
+// clamped onto an exclusive bound are still rejected by the schema.
+func SanitizeClamp(min, max *float64) Sanitizer {
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if min != nil {
+		lo = *min
+	}
+	if max != nil {
+		hi = *max
+	}
+	return func(v any) any {
+		switch n := v.(type) {
+		case int:
+			return clampInt(n, lo, hi)
+		case int8:
+			return clampInt(n, lo, hi)
+		case int16:
+			return clampInt(n, lo, hi)
+		case int32:
+			return clampInt(n, lo, hi)
+		case int64:
+			return clampInt(n, lo, hi)
+		case float32:
+			return float32(math.Min(math.Max(float64(n), lo), hi))
+		case float64:
+			return math.Min(math.Max(n, lo), hi)
+		case json.Number:
+			f, err := n.Float64()
+			if err != nil || (f >= lo && f <= hi) {
+				return v
+			}
+			return json.Number(strconv.FormatFloat(math.Min(math.Max(f, lo), hi), 'g', -1, 64))
+		}
+		return v
+	}
+}
+
+func clampInt[T int | int8 | int16 | int32 | int64](n T, lo, hi float64) T {
+	if f := float64(n); f < lo {
+		return T(math.Ceil(lo))
+	} else if f > hi {
+		return T(math.Floor(hi))
+	}
+	return n
+}
+
+// Registry of sanitizers referenced by SchemaJSON.Sanitize. "clamp" is not
+// listed because it takes its bounds from the node.
+var namedSanitizers = map[string]Sanitizer{
+	"trim":          SanitizeTrim,
+	"collapseSpace": SanitizeCollapseSpace,
+	"stripControl":  SanitizeStripControl,
+	"lower":         SanitizeLower,
+	"upper":         SanitizeUpper,
+	"emptyAsNull":   SanitizeEmptyAsNull,
+}
+
+// RegisterSanitizer makes fn available to SchemaJSON.Sanitize under name.
+// Panics if the name is empty, "clamp" or already registered.
+func RegisterSanitizer(name string, fn Sanitizer) {
+	if name == "" || name == "clamp" {
+		panic("invalid sanitizer name: " + name)
+	}
+	if _, exists := namedSanitizers[name]; exists {
+		panic("sanitizer already registered: " + name)
+	}
+	namedSanitizers[name] = fn
+}
+
+// UnregisterSanitizer removes a sanitizer added with RegisterSanitizer.
+func UnregisterSanitizer(name string) {
+	delete(namedSanitizers, name)
+}
+
+// buildSanitizers resolves js.Sanitize. "clamp" uses the node's bounds, the
+// same ones BuildSchema enforces. Unknown names panic.
+func buildSanitizers(js *SchemaJSON) []Sanitizer {
+	out := make([]Sanitizer, 0, len(js.Sanitize))
+	for _, name := range js.Sanitize {
+		if name == "clamp" {
+			out = append(out, SanitizeClamp(floatBounds(js)))
+			continue
+		}
+		fn, ok := namedSanitizers[name]
+		if !ok {
+			panic("unknown sanitizer: " + name)
+		}
+		out = append(out, fn)
+	}
+	return out
+}
@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	pack "github.com/quickwritereader/PackOS/packable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizers(t *testing.T) {
+	assert.Equal(t, "a b c", SanitizeCollapseSpace("  a \t b\n\nc "))
+	assert.Equal(t, "ab\tc", SanitizeStripControl("a\x00b\tc\x7f"))
+	assert.Equal(t, "x", SanitizeTrim(" x "))
+	assert.Equal(t, 5, SanitizeTrim(5), "other types pass through")
+	assert.Nil(t, SanitizeEmptyAsNull([]any{}))
+	assert.Nil(t, SanitizeEmptyAsNull(map[string]any{}))
+	assert.Equal(t, []any{1}, SanitizeEmptyAsNull([]any{1}))
+
+	lo, hi := 0.5, 10.0
+	clamp := SanitizeClamp(&lo, &hi)
+	assert.Equal(t, int16(1), clamp(int16(-3)))
+	assert.Equal(t, int64(10), clamp(int64(99)))
+	assert.Equal(t, 0.5, clamp(-1.0))
+	assert.Equal(t, float32(7), clamp(float32(7)))
+	assert.Equal(t, json.Number("10"), clamp(json.Number("1e3")))
+	assert.Equal(t, "99", clamp("99"))
+	assert.Equal(t, 3, SanitizeClamp(nil, &hi)(3))
+}
+
+func TestBuildSchema_Sanitize(t *testing.T) {
+	var js SchemaJSON
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "tuple",
+		"fieldNames": ["name", "code", "qty"],
+		"schema": [
+			{"type": "string", "width": 12, "sanitize": ["stripControl", "collapseSpace"]},
+			{"type": "string", "pattern": "^[A-Z]{3}$", "sanitize": ["trim", "upper"]},
+			{"type": "int16", "min": 1, "max": 100, "sanitize": ["clamp"]}
+		]
+	}`), &js))
+	chain := SChain(BuildSchema(&js))
+
+	buf, err := EncodeValue(map[string]any{"name": " Ada\x01  Lovelace", "code": " abc ", "qty": int16(500)}, chain)
+	require.NoError(t, err)
+	v, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "Ada Lovelace", "code": "ABC", "qty": int16(100)}, v)
+
+	// decoding does not sanitize stored data
+	raw := pack.Pack(pack.PackTuple(pack.PackString(" Ada Lovelace"), pack.PackString("ABC"), pack.PackInt16(5)))
+	require.Error(t, ValidateBuffer(raw, chain))
+
+	_, err = EncodeValue(map[string]any{"name": "Ada Lovelace", "code": "ab1", "qty": int16(5)}, chain)
+	require.Error(t, err, "constraints still apply after sanitizing")
+
+	RegisterSanitizer("digits", stringSanitizer(func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r < '0' || r > '9' {
+				return -1
+			}
+			return r
+		}, s)
+	}))
+	defer UnregisterSanitizer("digits")
+	phone := SChain(BuildSchema(&SchemaJSON{Type: "string", Sanitize: []string{"digits"}}))
+	buf, err = EncodeValue("+1 (555) 010-9999", phone)
+	require.NoError(t, err)
+	v, err = DecodeBuffer(buf, phone)
+	require.NoError(t, err)
+	assert.Equal(t, "15550109999", v)
+
+	assert.Panics(t, func() { BuildSchema(&SchemaJSON{Type: "string", Sanitize: []string{"nope"}}) })
+	assert.Panics(t, func() { RegisterSanitizer("trim", SanitizeTrim) })
+}
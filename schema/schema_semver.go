@@ -0,0 +1,211 @@
+package schema
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SSemVer accepts Semantic Versioning 2.0.0 versions, such as "1.4.2" or
+// "2.0.0-rc.1+build.5", without a leading "v".
+func SSemVer(optional bool) Schema {
+	s := SString
+	if optional {
+		s = s.Optional()
+	}
+	return s.CheckFunc(ErrStringVersion, "semantic version", func(str string) bool {
+		_, ok := parseSemVer(str)
+		return ok
+	})
+}
+
+// SSemVerRange accepts versions, as SSemVer does, within rng. A range is a
+// list of comparators that must all hold, such as ">=1.2.0 <2.0.0", with
+// the operators =, !=, <, <=, > and >=; a bare version means =. Ranges
+// joined by "||" accept versions within any of them. Versions compare by
+// SemVer precedence, so "2.0.0-rc.1" is below "2.0.0" and build metadata is
+// ignored. Panics if rng does not parse.
+func SSemVerRange(optional bool, rng string) Schema {
+	match := parseSemVerRange(rng)
+	s := SString
+	if optional {
+		s = s.Optional()
+	}
+	return s.CheckFunc(ErrStringVersion, "semantic version "+rng, func(str string) bool {
+		v, ok := parseSemVer(str)
+		return ok && match(v)
+	})
+}
+
+type semVer struct {
+	core [3]uint64
+	pre  []string
+}
+
+func parseSemVer(str string) (semVer, bool) {
+	var v semVer
+	if i := strings.IndexByte(str, '+'); i >= 0 {
+		for _, id := range strings.Split(str[i+1:], ".") {
+			if !semIdent(id) {
+				return v, false
+			}
+		}
+		str = str[:i]
+	}
+	if i := strings.IndexByte(str, '-'); i >= 0 {
+		v.pre = strings.Split(str[i+1:], ".")
+		for _, id := range v.pre {
+			if !semIdent(id) || semNumeric(id) && len(id) > 1 && id[0] == '0' {
+				return v, false
+			}
+		}
+		str = str[:i]
+	}
+	parts := strings.Split(str, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		if !semNumeric(p) || len(p) > 1 && p[0] == '0' {
+			return v, false
+		}
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return v, false
+		}
+		v.core[i] = n
+	}
+	return v, true
+}
+
+// semIdent reports whether id is a non-empty run of [0-9A-Za-z-].
+func semIdent(id string) bool {
+	if id == "" {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if !(c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func semNumeric(id string) bool {
+	if id == "" {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '0' || id[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// compareSemVer orders versions by SemVer precedence.
+func compareSemVer(a, b semVer) int {
+	for i := range a.core {
+		if a.core[i] != b.core[i] {
+			if a.core[i] < b.core[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(a.pre) == 0 && len(b.pre) == 0:
+		return 0
+	case len(a.pre) == 0:
+		return 1
+	case len(b.pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(a.pre) && i < len(b.pre); i++ {
+		if c := compareSemIdent(a.pre[i], b.pre[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(a.pre), len(b.pre))
+}
+
+// compareSemIdent orders pre-release identifiers: numeric ones numerically
+// and below alphanumeric ones, which compare as ASCII.
+func compareSemIdent(a, b string) int {
+	an, bn := semNumeric(a), semNumeric(b)
+	switch {
+	case an && bn:
+		if c := compareInts(len(a), len(b)); c != 0 {
+			return c
+		}
+	case an:
+		return -1
+	case bn:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// parseSemVerRange compiles rng into a predicate, panicking on bad input.
+func parseSemVerRange(rng string) func(semVer) bool {
+	var alternatives [][]func(semVer) bool
+	for _, alt := range strings.Split(rng, "||") {
+		fields := strings.Fields(alt)
+		if len(fields) == 0 {
+			panic("semver: empty range in " + strconv.Quote(rng))
+		}
+		comparators := make([]func(semVer) bool, 0, len(fields))
+		for _, f := range fields {
+			comparators = append(comparators, semComparator(f, rng))
+		}
+		alternatives = append(alternatives, comparators)
+	}
+	return func(v semVer) bool {
+	next:
+		for _, comparators := range alternatives {
+			for _, holds := range comparators {
+				if !holds(v) {
+					continue next
+				}
+			}
+			return true
+		}
+		return false
+	}
+}
+
+func semComparator(f, rng string) func(semVer) bool {
+	op := f[:len(f)-len(strings.TrimLeft(f, "<>=!"))]
+	bound, ok := parseSemVer(f[len(op):])
+	if !ok {
+		panic("semver: invalid version " + strconv.Quote(f) + " in range " + strconv.Quote(rng))
+	}
+	var holds func(int) bool
+	switch op {
+	case "", "=":
+		holds = func(c int) bool { return c == 0 }
+	case "!=":
+		holds = func(c int) bool { return c != 0 }
+	case "<":
+		holds = func(c int) bool { return c < 0 }
+	case "<=":
+		holds = func(c int) bool { return c <= 0 }
+	case ">":
+		holds = func(c int) bool { return c > 0 }
+	case ">=":
+		holds = func(c int) bool { return c >= 0 }
+	default:
+		panic("semver: invalid operator " + strconv.Quote(op) + " in range " + strconv.Quote(rng))
+	}
+	return func(v semVer) bool { return holds(compareSemVer(v, bound)) }
+}
@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSemVer(t *testing.T) {
+	chain := SChain(SSemVer(false))
+	for _, in := range []string{"0.0.0", "1.4.2", "2.0.0-rc.1+build.5", "1.0.0-alpha-1.0", "1.0.0+20130313144700"} {
+		buf, err := EncodeValue(in, chain)
+		require.NoError(t, err, in)
+		v, err := DecodeBuffer(buf, chain)
+		require.NoError(t, err, in)
+		assert.Equal(t, in, v)
+	}
+	for _, in := range []string{"v1.0.0", "1.0", "01.0.0", "1.0.0-01", "1.0.0-", "1.0.0+", "1.0.0-a..b", "1.0.0_x"} {
+		_, err := EncodeValue(in, chain)
+		assert.Error(t, err, in)
+	}
+
+	raw, err := EncodeValue("1.0", SChain(SString))
+	require.NoError(t, err)
+	var se *SchemaError
+	require.ErrorAs(t, ValidateBuffer(raw, chain), &se)
+	assert.Equal(t, ErrStringVersion, se.Code)
+}
+
+func TestSSemVerRange(t *testing.T) {
+	chain := SChain(SSemVerRange(false, ">=1.2.0 <2.0.0 || =3.0.0-beta.2"))
+	for in, ok := range map[string]bool{
+		"1.2.0":        true,
+		"1.10.3+build": true,
+		"1.1.9":        false,
+		"2.0.0-rc.1":   true,
+		"2.0.0":        false,
+		"3.0.0-beta.2": true,
+		"3.0.0-beta.3": false,
+		"3.0.0":        false,
+	} {
+		_, err := EncodeValue(in, chain)
+		assert.Equal(t, ok, err == nil, in)
+	}
+
+	// pre-release precedence from the SemVer specification
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0"}
+	for i := 1; i < len(ordered); i++ {
+		a, _ := parseSemVer(ordered[i-1])
+		b, _ := parseSemVer(ordered[i])
+		assert.Equal(t, -1, compareSemVer(a, b), "%s < %s", ordered[i-1], ordered[i])
+		assert.Equal(t, 1, compareSemVer(b, a))
+	}
+
+	built := SChain(BuildSchema(&SchemaJSON{Type: "semver", Range: "!=1.0.0"}))
+	_, err := EncodeValue("1.0.0", built)
+	assert.Error(t, err)
+	_, err = EncodeValue("1.0.1", built)
+	assert.NoError(t, err)
+
+	assert.Panics(t, func() { SSemVerRange(false, ">=1.2") })
+	assert.Panics(t, func() { SSemVerRange(false, "~1.2.0") })
+	assert.Panics(t, func() { SSemVerRange(false, ">=1.0.0 ||") })
+}
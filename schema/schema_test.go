@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -1392,3 +1393,530 @@ func TestSDate_SuccessAndNullable(t *testing.T) {
 	require.Error(t, err, "Decode should fail for out-of-range date")
 	require.Nil(t, decodedInvalid)
 }
+
+func TestSDateRangeWith_RelativeBounds(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	from, err := ParseDateBound("now-30d", nil)
+	require.NoError(t, err)
+	to, err := ParseDateBound("now+1h", nil)
+	require.NoError(t, err)
+
+	dateSchema := SChain(SDateRangeWith(false, DateRangeOptions{
+		From: &from,
+		To:   &to,
+		Now:  func() time.Time { return now },
+	}))
+
+	ok := pack.Pack(pack.PackInt64(now.AddDate(0, 0, -29).Unix()))
+	require.NoError(t, ValidateBuffer(ok, dateSchema))
+
+	tooOld := pack.Pack(pack.PackInt64(now.AddDate(0, 0, -31).Unix()))
+	require.Error(t, ValidateBuffer(tooOld, dateSchema))
+
+	future := pack.Pack(pack.PackInt64(now.Add(2 * time.Hour).Unix()))
+	require.Error(t, ValidateBuffer(future, dateSchema))
+
+	// bounds move with the clock
+	now = now.Add(3 * time.Hour)
+	require.NoError(t, ValidateBuffer(future, dateSchema))
+}
+
+func TestParseDateBound(t *testing.T) {
+	b, err := ParseDateBound("now-1w2d3h", nil)
+	require.NoError(t, err)
+	assert.Equal(t, RelativeDateBound(-9, -3*time.Hour), b)
+
+	b, err = ParseDateBound("now", nil)
+	require.NoError(t, err)
+	assert.Equal(t, RelativeDateBound(0, 0), b)
+
+	loc := time.FixedZone("UTC+2", 2*3600)
+	b, err = ParseDateBound("2024-03-01", loc)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 29, 22, 0, 0, 0, time.UTC).Unix(), b.At.Unix())
+
+	b, err = ParseDateBound("2024-03-01T00:00:00Z", loc)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC).Unix(), b.At.Unix())
+
+	for _, bad := range []string{"now-", "now*3d", "now-d", "now-3x", "yesterday"} {
+		_, err = ParseDateBound(bad, nil)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSMapSortedKeys_ValidateOrderAndPrefix(t *testing.T) {
+	chain := SChain(SMapSortedKeys("k", 1, 3).WithValue(SInt16))
+
+	put := access.NewPutAccess()
+	put.AddMapAnySortedKey(map[string]any{"ka": int16(1), "kb": int16(2)}, false)
+	require.NoError(t, ValidateBuffer(put.Pack(), chain))
+
+	unsorted := access.NewPutAccess()
+	unsorted.AddMapAnyOrdered(typetags.NewOrderedMapAny(
+		typetags.OPAny("kb", int16(1)),
+		typetags.OPAny("ka", int16(2)),
+	), false)
+	err := ValidateBuffer(unsorted.Pack(), chain)
+	var schemaErr *SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	assert.Equal(t, ErrKeyOrder, schemaErr.Code)
+
+	badPrefix := access.NewPutAccess()
+	badPrefix.AddMapAnySortedKey(map[string]any{"ka": int16(1), "xb": int16(2)}, false)
+	require.Error(t, ValidateBuffer(badPrefix.Pack(), chain))
+
+	tooMany := access.NewPutAccess()
+	tooMany.AddMapAnySortedKey(map[string]any{"k1": int16(1), "k2": int16(2), "k3": int16(3), "k4": int16(4)}, false)
+	require.Error(t, ValidateBuffer(tooMany.Pack(), chain))
+
+	// a trailing key without a value
+	odd := access.NewPutAccess()
+	m := odd.BeginMap()
+	m.AddString("ka")
+	m.AddInt16(1)
+	m.AddString("kb")
+	odd.EndNested(m)
+	require.ErrorAs(t, ValidateBuffer(odd.Pack(), chain), &schemaErr)
+	assert.Equal(t, ErrInvalidFormat, schemaErr.Code)
+}
+
+func TestSMapSortedKeys_EncodeDecode(t *testing.T) {
+	chain := SChain(SMapSortedKeys("", -1, -1))
+
+	buf, err := EncodeValue(map[string]any{"b": "x", "a": int64(1), "c": true}, chain)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, chain))
+
+	decoded, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": int64(1), "b": "x", "c": true}, decoded)
+
+	_, err = EncodeValue(typetags.NewOrderedMapAny(
+		typetags.OPAny("b", int64(1)),
+		typetags.OPAny("a", int64(2)),
+	), chain)
+	require.Error(t, err, "ordered input that breaks sorting must fail")
+
+	_, err = EncodeValue(typetags.NewOrderedMapAny(
+		typetags.OPAny("a", int64(1)),
+		typetags.OPAny("b", int64(2)),
+	), chain)
+	require.NoError(t, err)
+}
+
+func TestSMapRepeat_KeyRestrictions(t *testing.T) {
+	labels := SChain(SMapRepeat(SString, SString).KeysMatch(`^[a-z][a-z0-9_]*$`))
+
+	ok := pack.Pack(pack.PackMapSorted{"app": pack.PackString("web"), "tier_1": pack.PackString("x")})
+	require.NoError(t, ValidateBuffer(ok, labels))
+	_, err := DecodeBuffer(ok, labels)
+	require.NoError(t, err)
+
+	bad := pack.Pack(pack.PackMapSorted{"App": pack.PackString("web")})
+	err = ValidateBuffer(bad, labels)
+	var schemaErr *SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	assert.Equal(t, ErrStringPattern, schemaErr.Code)
+	_, err = DecodeBuffer(bad, labels)
+	require.Error(t, err)
+
+	_, err = EncodeValue(map[string]any{"Bad Key": "v"}, labels)
+	require.Error(t, err)
+
+	env := SChain(SMapRepeat(SString, SString).KeysOneOf("dev", "prod"))
+	require.NoError(t, ValidateBuffer(pack.Pack(pack.PackMapSorted{"dev": pack.PackString("1")}), env))
+	err = ValidateBuffer(pack.Pack(pack.PackMapSorted{"qa": pack.PackString("1")}), env)
+	require.ErrorAs(t, err, &schemaErr)
+	assert.Equal(t, ErrStringMatch, schemaErr.Code)
+
+	buf, err := EncodeValue(map[string]any{"prod": "2"}, env)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, env))
+}
+
+func TestSRepeat_StopsAtMax(t *testing.T) {
+	// the max bound is checked before each element, so the field after a
+	// full repeat is left to the next schema rather than validated as one
+	// more element
+	chain := SChain(SRepeat(1, 2, SInt16), SString)
+	buf := pack.Pack(pack.PackInt16(1), pack.PackInt16(2), pack.PackString("tail"))
+	require.NoError(t, ValidateBuffer(buf, chain))
+	v, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, []any{[]any{int16(1), int16(2)}, "tail"}, v)
+
+	// a third int16 is reported by the schema after the repeat
+	buf = pack.Pack(pack.PackInt16(1), pack.PackInt16(2), pack.PackInt16(3))
+	var se *SchemaError
+	require.ErrorAs(t, ValidateBuffer(buf, chain), &se)
+	assert.Equal(t, SchemaStringName, se.Name)
+}
+
+func TestValidateRepeatInsideTuple(t *testing.T) {
+	chain := SChain(STupleVal(SRepeat(1, 3, SString)))
+	for n := 1; n <= 3; n++ {
+		items := make([]access.Packable, n)
+		for i := range items {
+			items[i] = pack.PackString("a")
+		}
+		buf := pack.Pack(pack.PackTuple(items...))
+		assert.NoError(t, ValidateBuffer(buf, chain), "%d items", n)
+	}
+}
+
+func TestMapSchemasAcceptSortedMap(t *testing.T) {
+	buf := pack.Pack(pack.PackSortedMap{"a": pack.PackInt16(1), "b": pack.PackString("x")})
+	chain := SChain(SMapUnordered(map[string]Schema{"a": SInt16, "b": SString}))
+	require.NoError(t, ValidateBuffer(buf, chain))
+	decoded, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": int16(1), "b": "x"}, decoded)
+
+	require.NoError(t, ValidateBuffer(buf, SChain(SMapRepeat(SString, SAny))))
+}
+
+func TestMapEncodeStableOrder(t *testing.T) {
+	val := map[string]any{"zeta": "z", "id": int16(1), "alpha": "a", "mid": int16(2)}
+
+	named := SChain(SMapUnorderedNamed([]string{"zeta", "id", "alpha", "mid"}, SString, SInt16, SString, SInt16))
+	first, err := EncodeValue(val, named)
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		buf, err := EncodeValue(val, named)
+		require.NoError(t, err)
+		require.Equal(t, first, buf, "run %d", i)
+	}
+	decoded, err := access.DecodeOrdered(first)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zeta", "id", "alpha", "mid"}, decoded.(*typetags.OrderedMapAny).Keys())
+
+	// without a declared order keys are sorted
+	plain := SChain(SMapUnordered(map[string]Schema{"zeta": SString, "id": SInt16, "alpha": SString, "mid": SInt16}))
+	buf, err := EncodeValue(val, plain)
+	require.NoError(t, err)
+	decoded, err = access.DecodeOrdered(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alpha", "id", "mid", "zeta"}, decoded.(*typetags.OrderedMapAny).Keys())
+
+	repeat := SChain(SMapRepeat(SString, SAny))
+	buf, err = EncodeValue(val, repeat)
+	require.NoError(t, err)
+	decoded, err = access.DecodeOrdered(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alpha", "id", "mid", "zeta"}, decoded.(*typetags.OrderedMapAny).Keys())
+}
+
+func TestMapEncodePartial(t *testing.T) {
+	chain := SChain(SMapUnorderedNamed([]string{"id", "name", "age"},
+		SInt32, SString, SNullable(SInt16)))
+
+	buf, err := EncodeValue(map[string]any{"id": int32(7)}, chain)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, chain))
+	decoded, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": int32(7)}, decoded)
+
+	_, err = EncodeValue(map[string]any{"name": "ann"}, chain)
+	var details MissingKeyErrorDetails
+	require.ErrorAs(t, err, &details)
+	assert.Equal(t, "id", details.Key)
+}
+
+func TestDecodeBufferWithLimits(t *testing.T) {
+	chain := SChain(STuple(STuple(SString)), SString)
+	buf, err := EncodeValue([]any{[]any{[]any{"inner"}}, "outer"}, chain)
+	require.NoError(t, err)
+
+	got, err := DecodeBufferWithLimits(buf, chain, access.DecodeLimits{MaxDepth: 2, MaxStringLen: 5})
+	require.NoError(t, err)
+	assert.Equal(t, []any{[]any{[]any{"inner"}}, "outer"}, got)
+
+	_, err = DecodeBufferWithLimits(buf, chain, access.DecodeLimits{MaxDepth: 1})
+	assert.ErrorIs(t, err, access.ErrDecodeLimit)
+	_, err = DecodeBufferWithLimits(buf, chain, access.DecodeLimits{MaxStringLen: 4})
+	assert.ErrorIs(t, err, access.ErrDecodeLimit)
+}
+
+func TestSchemaError_Path(t *testing.T) {
+	settings := SMapUnordered(map[string]Schema{"theme": SString.Match("dark")})
+	user := SMapUnordered(map[string]Schema{"settings": settings})
+	strict := SChain(SInt8, SMapUnordered(map[string]Schema{"users": STupleVal(SRepeat(0, -1, user))}))
+
+	users := []any{}
+	for _, theme := range []string{"dark", "dark", "light"} {
+		users = append(users, map[string]any{"settings": map[string]any{"theme": theme}})
+	}
+	loose := SChain(SInt8, SMapUnordered(map[string]Schema{"users": STupleVal(SRepeat(0, -1,
+		SMapUnordered(map[string]Schema{"settings": SMapUnordered(map[string]Schema{"theme": SString})})))}))
+	buf, err := EncodeValue([]any{int8(1), map[string]any{"users": []any{users}}}, loose)
+	require.NoError(t, err)
+
+	want := []string{"1", "users", "2", "settings", "theme"}
+	err = ValidateBuffer(buf, strict)
+	require.Error(t, err)
+	assert.Equal(t, want, ErrorPath(err))
+	_, err = DecodeBuffer(buf, strict)
+	require.Error(t, err)
+	assert.Equal(t, want, ErrorPath(err))
+
+	js, err := json.Marshal(err)
+	require.NoError(t, err)
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(js, &out))
+	assert.Equal(t, SchemaMapUnorderedName, out["name"])
+	assert.Equal(t, []any{"1", "users", "2", "settings", "theme"}, out["path"])
+	inner := out["inner"].(map[string]any)
+	assert.Equal(t, []any{"2", "settings", "theme"}, inner["path"])
+
+	errs := ValidateAll(buf, strict)
+	require.Len(t, errs, 1)
+	assert.Equal(t, []string{"1", "users", "0", "*", "settings", "theme"}, errs[0].Path)
+}
+
+type testColor uint8
+
+const (
+	testRed testColor = iota + 1
+	testGreen
+)
+
+func TestEnum_SharedValues(t *testing.T) {
+	names := SEnum([]string{"red", "green"}, false)
+	colors := SEnumOf([]string{"red", "green"}, []testColor{testRed, testGreen}, false)
+
+	buf, err := EncodeValue([]any{"green", testRed, 1}, SChain(names, colors, colors))
+	require.NoError(t, err)
+	got, err := DecodeBuffer(buf, SChain(names, colors, colors))
+	require.NoError(t, err)
+	assert.Equal(t, []any{"green", testRed, testGreen}, got)
+
+	seq, err := access.NewSeqGetAccess(buf)
+	require.NoError(t, err)
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = seq.Seek(0)
+		_, _ = names.Decode(seq)
+		_, _ = colors.Decode(seq)
+	})
+	assert.Zero(t, allocs)
+
+	_, err = EncodeValue([]any{testColor(9)}, SChain(colors))
+	assert.Error(t, err)
+	assert.Panics(t, func() { SEnumOf([]string{"red"}, []testColor{}, false) })
+
+	// with int values a plain int is still an index
+	swapped := SChain(SEnumOf([]string{"a", "b"}, []int{1, 0}, false))
+	for in, want := range map[any]any{0: 1, 1: 0, "a": 1, "b": 0} {
+		buf, err := EncodeValue(in, swapped)
+		require.NoError(t, err)
+		v, err := DecodeBuffer(buf, swapped)
+		require.NoError(t, err)
+		assert.Equal(t, want, v, "%v", in)
+	}
+}
+
+func TestSString_LenRange(t *testing.T) {
+	bytesLen := SChain(SString.LenRange(2, 5))
+	runes := SChain(SString.LenRange(2, 5).WithRuneCount())
+
+	// "héllo" is 5 characters in 6 bytes
+	_, err := EncodeValue("héllo", bytesLen)
+	assert.Error(t, err)
+	buf, err := EncodeValue("héllo", runes)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, runes))
+	assert.Error(t, ValidateBuffer(buf, bytesLen))
+	v, err := DecodeBuffer(buf, runes)
+	require.NoError(t, err)
+	assert.Equal(t, "héllo", v)
+	_, err = DecodeBuffer(buf, bytesLen)
+	assert.Error(t, err)
+
+	_, err = EncodeValue("h", runes)
+	assert.Error(t, err)
+	_, err = EncodeValue(nil, runes)
+	assert.Error(t, err, "MinLen rejects the empty string")
+
+	open := SChain(SString.LenRange(0, 3).WithRuneCount().Prefix("é"))
+	buf, err = EncodeValue("éa", open)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, open))
+	_, err = EncodeValue("éabc", open)
+	assert.Error(t, err)
+}
+
+func TestSString_UTF8AndNFC(t *testing.T) {
+	valid := SChain(SString.UTF8())
+	_, err := EncodeValue("ok ✓", valid)
+	require.NoError(t, err)
+	_, err = EncodeValue("bad \xff", valid)
+	assert.Error(t, err)
+	raw, err := EncodeValue("bad \xff", SChain(SString))
+	require.NoError(t, err)
+	assert.Error(t, ValidateBuffer(raw, valid))
+
+	// "é" as e + combining acute, and precomposed
+	decomposed, composed := "cafe\u0301", "caf\u00e9"
+	nfc := SChain(SString.NormalizeNFC().Prefix("caf"))
+	buf, err := EncodeValue(decomposed, nfc)
+	require.NoError(t, err)
+	v, err := DecodeBuffer(buf, nfc)
+	require.NoError(t, err)
+	assert.Equal(t, composed, v)
+
+	raw, err = EncodeValue(decomposed, SChain(SString))
+	require.NoError(t, err)
+	assert.Error(t, ValidateBuffer(raw, SChain(SString.NormalizeNFC())))
+
+	keys := SChain(SMapRepeat(SString.NormalizeNFC(), SInt16))
+	buf, err = EncodeValue(map[string]any{decomposed: int16(1), "cafe": int16(2)}, keys)
+	require.NoError(t, err)
+	v, err = DecodeBuffer(buf, keys)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{composed: int16(1), "cafe": int16(2)}, v)
+	_, err = EncodeValue(map[string]any{decomposed: int16(1), composed: int16(2)}, keys)
+	assert.Error(t, err)
+}
+
+func TestSString_FoldAndTrim(t *testing.T) {
+	yes := SChain(SString.Trimmed().MatchFold("yes"))
+	for _, in := range []string{"yes", "YES", "  Yes\n"} {
+		buf, err := EncodeValue(in, yes)
+		require.NoError(t, err, in)
+		require.NoError(t, ValidateBuffer(buf, yes))
+		v, err := DecodeBuffer(buf, yes)
+		require.NoError(t, err)
+		assert.Equal(t, in, v, "stored as given")
+	}
+	_, err := EncodeValue("yes please", yes)
+	assert.Error(t, err)
+	_, err = EncodeValue(" yes", SChain(SString.MatchFold("yes")))
+	assert.Error(t, err)
+
+	// the Kelvin sign folds to k but is three bytes long
+	prefix := SChain(SString.IgnoreCase().Prefix("kg"))
+	_, err = EncodeValue("\u212AG net", prefix)
+	assert.NoError(t, err)
+	_, err = EncodeValue("lb", prefix)
+	assert.Error(t, err)
+
+	suffix := SChain(SString.IgnoreCase().Trimmed().Suffix(".PDF"))
+	_, err = EncodeValue("report.pdf ", suffix)
+	assert.NoError(t, err)
+	_, err = EncodeValue("pdf", suffix)
+	assert.Error(t, err)
+}
+
+func TestMultipleOf(t *testing.T) {
+	dozens := SChain(SInt32.MultipleOf(12))
+	buf, err := EncodeValue(int32(36), dozens)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, dozens))
+	_, err = EncodeValue(int32(30), dozens)
+	require.Error(t, err)
+	bad, err := EncodeValue(int32(30), SChain(SInt32))
+	require.NoError(t, err)
+	var se *SchemaError
+	require.ErrorAs(t, ValidateBuffer(bad, dozens), &se)
+	assert.Equal(t, ErrNotMultipleOf, se.Code)
+	_, err = DecodeBuffer(bad, dozens)
+	assert.Error(t, err)
+
+	prices := SChain(SchemaNumber{}.WithMultipleOf(0.05))
+	_, err = EncodeValue(19.95, prices)
+	require.NoError(t, err)
+	_, err = EncodeValue(19.99, prices)
+	require.Error(t, err)
+	// the tolerance scales with the quotient, so large prices still pass
+	_, err = EncodeValue(1234567.15, prices)
+	require.NoError(t, err)
+	_, err = EncodeValue(1234567.17, prices)
+	require.Error(t, err)
+
+	v := SVarint.Constrain(IntConstraints{Min: PtrToInt64(0)}).MultipleOf(5)
+	_, err = EncodeValue(int64(15), SChain(v))
+	require.NoError(t, err)
+	_, err = EncodeValue(int64(-5), SChain(v))
+	assert.Error(t, err, "bounds are kept")
+	_, err = EncodeValue(int64(7), SChain(v))
+	assert.Error(t, err)
+}
+
+func TestSBytes_MagicBase64ExactLen(t *testing.T) {
+	png := SChain(SchemaBytes{Width: -1}.Prefix([]byte("\x89PNG")))
+	buf, err := EncodeValue([]byte("\x89PNG\r\n..."), png)
+	require.NoError(t, err)
+	require.NoError(t, ValidateBuffer(buf, png))
+	_, err = EncodeValue([]byte("GIF89a"), png)
+	assert.Error(t, err)
+	gif, err := EncodeValue([]byte("GIF89a"), SChain(SVariableBytes()))
+	require.NoError(t, err)
+	assert.Error(t, ValidateBuffer(gif, png))
+	_, err = DecodeBuffer(gif, png)
+	assert.Error(t, err)
+
+	hash := SChain(SchemaBytes{}.ExactLen(4).Base64())
+	buf, err = EncodeValue("3q2+7w==", hash)
+	require.NoError(t, err)
+	v, err := DecodeBuffer(buf, hash)
+	require.NoError(t, err)
+	assert.Equal(t, "3q2+7w==", v)
+	_, err = EncodeValue([]byte{0xde, 0xad, 0xbe, 0xef}, hash)
+	require.NoError(t, err)
+	_, err = EncodeValue("3q2+", hash)
+	assert.Error(t, err, "3 bytes")
+	_, err = EncodeValue("not base64!", hash)
+	assert.Error(t, err)
+
+	var js SchemaJSON
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"bytes","prefix":"89504e47","base64":true}`), &js))
+	built := SChain(BuildSchema(&js))
+	_, err = EncodeValue("iVBORw0KGgo=", built)
+	require.NoError(t, err)
+	_, err = EncodeValue("R0lGODlh", built)
+	assert.Error(t, err)
+	assert.Panics(t, func() { BuildSchema(&SchemaJSON{Type: "bytes", Prefix: "png"}) })
+}
+
+func TestFlattenedRepeat_RoundTrip(t *testing.T) {
+	named := SChain(STupleNamedValFlattened([]string{"id", "tag", "name"},
+		SInt32, SRepeat(0, 2, SString), SString))
+	unnamed := SChain(STupleValFlatten(SInt32, SRepeat(0, 2, SString), SString))
+
+	want, err := EncodeValue(map[string]any{"id": int32(7), "tag_0": "a", "tag_1": "b", "name": "x"}, named)
+	require.NoError(t, err)
+
+	// one value for the repeat, in either tuple
+	buf, err := EncodeValue(map[string]any{"id": int32(7), "tag": FlattenedRepeat{"a", "b"}, "name": "x"}, named)
+	require.NoError(t, err)
+	assert.Equal(t, want, buf)
+	buf, err = EncodeValue([]any{int32(7), FlattenedRepeat{"a", "b"}, "x"}, unnamed)
+	require.NoError(t, err)
+	assert.Equal(t, want, buf)
+
+	// and the decoded forms encode back to the same bytes
+	v, err := DecodeBuffer(want, unnamed)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int32(7), "a", "b", "x"}, v)
+	buf, err = EncodeValue(v, unnamed)
+	require.NoError(t, err)
+	assert.Equal(t, want, buf)
+	v, err = DecodeBuffer(want, named)
+	require.NoError(t, err)
+	buf, err = EncodeValue(v, named)
+	require.NoError(t, err)
+	assert.Equal(t, want, buf)
+
+	// suffixed keys beyond max are not written
+	buf, err = EncodeValue(map[string]any{"id": int32(7), "tag_0": "a", "tag_1": "b", "tag_2": "c", "name": "x"}, named)
+	require.NoError(t, err)
+	assert.Equal(t, want, buf)
+
+	_, err = EncodeValue([]any{int32(7), "a"}, unnamed)
+	assert.Error(t, err, "too few values for the repeat")
+	// like the repeat itself, which keeps its first max values
+	buf, err = EncodeValue([]any{int32(7), FlattenedRepeat{"a", "b", "c"}, "x"}, unnamed)
+	require.NoError(t, err)
+	assert.Equal(t, want, buf)
+}
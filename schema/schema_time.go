@@ -0,0 +1,167 @@
+package schema
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const (
+	SchemaTimeName     = "SchemaTime"
+	SchemaDurationName = "SchemaDuration"
+)
+
+// SchemaTime is a timestamp packed with access.PutAccess.AddTime. It decodes
+// to time.Time in Location (UTC when nil); the location of encoded values is
+// not stored. Encode accepts time.Time, *time.Time and RFC 3339 strings.
+type SchemaTime struct {
+	Nullable bool
+	Location *time.Location
+}
+
+// SchemaDuration is an int64 nanosecond count that decodes to time.Duration.
+// Encode accepts time.Duration, Go integers, json.Number and strings in
+// time.ParseDuration syntax such as "1h30m". Min and Max, when set, bound
+// the duration on encode, validation and decode.
+type SchemaDuration struct {
+	Nullable bool
+	Min, Max *time.Duration
+}
+
+var (
+	STime         = SchemaTime{}
+	SNullTime     = SchemaTime{Nullable: true}
+	SDuration     = SchemaDuration{}
+	SNullDuration = SchemaDuration{Nullable: true}
+)
+
+func (s SchemaTime) IsNullable() bool { return s.Nullable }
+
+func (s SchemaTime) Validate(seq *access.SeqGetAccess) error {
+	_, err := s.Decode(seq)
+	return err
+}
+
+func (s SchemaTime) Decode(seq *access.SeqGetAccess) (any, error) {
+	pos := seq.CurrentIndex()
+	payload, err := validatePrimitiveAndGetPayload(SchemaTimeName, seq, typetags.TypeInteger, access.TimeWidth, s.Nullable)
+	if err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		return nil, nil
+	}
+	t, err := access.DecodeTime(payload)
+	if err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, SchemaTimeName, "", pos, err)
+	}
+	if s.Location != nil {
+		t = t.In(s.Location)
+	}
+	return t, nil
+}
+
+func (s SchemaTime) Encode(put *access.PutAccess, val any) error {
+	if s.Nullable && val == nil {
+		put.AddNullableTime(nil)
+		return nil
+	}
+	switch v := val.(type) {
+	case time.Time:
+		put.AddTime(v)
+	case *time.Time:
+		if v == nil {
+			return NewSchemaError(ErrEncode, SchemaTimeName, "", -1, ErrTypeMisMatch)
+		}
+		put.AddTime(*v)
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return NewSchemaError(ErrEncode, SchemaTimeName, "", -1, err)
+		}
+		put.AddTime(t)
+	default:
+		return NewSchemaError(ErrEncode, SchemaTimeName, "", -1, ErrTypeMisMatch)
+	}
+	return nil
+}
+
+// RangeValues bounds durations to [min, max].
+func (s SchemaDuration) RangeValues(min, max time.Duration) SchemaDuration {
+	return s.Range(&min, &max)
+}
+
+// Range bounds durations to [min, max]; a nil bound is open.
+func (s SchemaDuration) Range(min, max *time.Duration) SchemaDuration {
+	s.Min, s.Max = min, max
+	return s
+}
+
+func (s SchemaDuration) IsNullable() bool { return s.Nullable }
+
+func (s SchemaDuration) Validate(seq *access.SeqGetAccess) error {
+	if s.Min == nil && s.Max == nil {
+		return validatePrimitive(SchemaDurationName, seq, typetags.TypeInteger, 8, s.Nullable)
+	}
+	_, err := s.Decode(seq)
+	return err
+}
+
+func (s SchemaDuration) Decode(seq *access.SeqGetAccess) (any, error) {
+	pos := seq.CurrentIndex()
+	payload, err := validatePrimitiveAndGetPayload(SchemaDurationName, seq, typetags.TypeInteger, 8, s.Nullable)
+	if err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		return nil, nil
+	}
+	if len(payload) != 8 {
+		return nil, NewSchemaError(ErrConstraintViolated, SchemaDurationName, "", pos, SizeExact{8, len(payload)})
+	}
+	d := time.Duration(binary.LittleEndian.Uint64(payload))
+	if err := CheckRange(d, s.Min, s.Max); err != nil {
+		return nil, NewSchemaError(ErrOutOfRange, SchemaDurationName, "", pos, err)
+	}
+	return d, nil
+}
+
+func (s SchemaDuration) Encode(put *access.PutAccess, val any) error {
+	if s.Nullable && val == nil {
+		put.AddNullableInt64(nil)
+		return nil
+	}
+	d, err := toDuration(val)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaDurationName, "", -1, err)
+	}
+	if err := CheckRange(d, s.Min, s.Max); err != nil {
+		return NewSchemaError(ErrOutOfRange, SchemaDurationName, "", -1, err)
+	}
+	put.AddDuration(d)
+	return nil
+}
+
+func toDuration(val any) (time.Duration, error) {
+	switch v := val.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		return time.ParseDuration(v)
+	}
+	val, err := coerceJSONNumber[int64](val)
+	if err != nil {
+		return 0, err
+	}
+	switch v := val.(type) {
+	case int:
+		return time.Duration(v), nil
+	case int32:
+		return time.Duration(v), nil
+	case int64:
+		return time.Duration(v), nil
+	}
+	return 0, ErrTypeMisMatch
+}
@@ -0,0 +1,79 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	pack "github.com/quickwritereader/PackOS/packable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaTimeAndDuration(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	chain := SchemaNamedChain{
+		SchemaChain: SChain(STime, SchemaTime{Location: ny}, SDuration, SNullTime),
+		FieldNames:  []string{"created", "local", "ttl", "deleted"},
+	}
+	created := time.Date(2024, 3, 1, 8, 0, 0, 500, time.FixedZone("X", 3600))
+	buf, err := EncodeValueNamed(map[string]any{
+		"created": created,
+		"local":   "2024-03-01T12:00:00Z",
+		"ttl":     "1h30m",
+	}, chain)
+	require.NoError(t, err)
+
+	decoded, err := DecodeBufferNamed(buf, chain)
+	require.NoError(t, err)
+	m := decoded.(map[string]any)
+	assert.Equal(t, created.UTC(), m["created"])
+	assert.Equal(t, ny, m["local"].(time.Time).Location())
+	assert.Equal(t, 7, m["local"].(time.Time).Hour())
+	assert.Equal(t, 90*time.Minute, m["ttl"])
+	assert.Nil(t, m["deleted"])
+
+	// a plain int64 is not a time, and an int32 is not a duration
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackInt64(1)), SChain(STime)))
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackInt32(1)), SChain(SDuration)))
+	_, err = EncodeValue("yesterday", SChain(STime))
+	require.Error(t, err)
+	_, err = EncodeValue(3.5, SChain(SDuration))
+	require.Error(t, err)
+
+	js := SchemaJSON{Type: "time", Location: "America/New_York", Nullable: true}
+	assert.Equal(t, SchemaTime{Nullable: true, Location: ny}, BuildSchema(&js))
+	assert.Equal(t, SDuration, BuildSchema(&SchemaJSON{Type: "duration"}))
+}
+
+func TestSchemaDurationRange(t *testing.T) {
+	chain := SChain(SDuration.RangeValues(time.Second, time.Hour))
+	for _, in := range []any{"1s", "59m59s", time.Hour, int64(time.Second)} {
+		buf, err := EncodeValue(in, chain)
+		require.NoError(t, err, in)
+		require.NoError(t, ValidateBuffer(buf, chain), in)
+	}
+	for _, in := range []any{"999ms", "1h0m1s", -time.Minute} {
+		_, err := EncodeValue(in, chain)
+		assert.Error(t, err, in)
+	}
+
+	long, err := EncodeValue("2h", SChain(SDuration))
+	require.NoError(t, err)
+	var se *SchemaError
+	require.ErrorAs(t, ValidateBuffer(long, chain), &se)
+	assert.Equal(t, ErrOutOfRange, se.Code)
+	assert.Contains(t, se.Error(), "2h0m0s")
+	_, err = DecodeBuffer(long, chain)
+	assert.Error(t, err)
+
+	built := SChain(BuildSchema(&SchemaJSON{Type: "duration", MinDuration: "90s"}))
+	_, err = EncodeValue("1m", built)
+	assert.Error(t, err)
+	buf, err := EncodeValue("2m", built)
+	require.NoError(t, err)
+	v, err := DecodeBuffer(buf, built)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, v)
+	assert.Panics(t, func() { BuildSchema(&SchemaJSON{Type: "duration", MaxDuration: "soon"}) })
+}
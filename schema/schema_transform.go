@@ -0,0 +1,195 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+	"golang.org/x/exp/constraints"
+)
+
+const TransformerName = "Transformer"
+
+// Mapping declares how records of one named chain become records of another.
+// Fields are matched by name unless renamed. Every source field must reach a
+// destination field or be dropped, and every destination field needs a
+// source, a default, or a nullable schema.
+type Mapping struct {
+	// Rename maps source field names to destination field names.
+	Rename map[string]string
+	// Convert rewrites the decoded value of a destination field before it is
+	// encoded. Keyed by destination name.
+	Convert map[string]func(any) (any, error)
+	// Drop lists source fields that are not carried over.
+	Drop []string
+	// Default supplies values for destination fields that have no source.
+	Default map[string]any
+}
+
+// Transformer converts packed records from a source chain to a destination
+// chain. Fields whose schema is unchanged and that have no conversion are
+// copied as raw bytes after validation; the rest are decoded, converted and
+// re-encoded. A Transformer is safe for concurrent use.
+type Transformer struct {
+	src, dst SchemaNamedChain
+	// srcTarget[i] is the destination index fed by source field i, or -1.
+	srcTarget []int
+	steps     []transformStep
+}
+
+type transformStep struct {
+	name    string
+	src     int // source index, -1 when the field has no source
+	raw     bool
+	convert func(any) (any, error)
+	def     any
+	hasDef  bool
+}
+
+// Transform compiles mapping into a Transformer. It fails when the mapping
+// leaves a source field unaccounted for or a destination field unfilled.
+func Transform(src, dst SchemaNamedChain, mapping Mapping) (*Transformer, error) {
+	for _, c := range []SchemaNamedChain{src, dst} {
+		if len(c.FieldNames) != len(c.Schemas) {
+			return nil, NewSchemaError(ErrConstraintViolated, TransformerName, "", -1,
+				SizeExact{Actual: len(c.FieldNames), Exact: len(c.Schemas)})
+		}
+	}
+	t := &Transformer{
+		src:       src,
+		dst:       dst,
+		srcTarget: make([]int, len(src.FieldNames)),
+		steps:     make([]transformStep, len(dst.FieldNames)),
+	}
+	for i, name := range dst.FieldNames {
+		def, hasDef := mapping.Default[name]
+		t.steps[i] = transformStep{name: name, src: -1, convert: mapping.Convert[name], def: def, hasDef: hasDef}
+	}
+	for i, name := range src.FieldNames {
+		t.srcTarget[i] = -1
+		if slices.Contains(mapping.Drop, name) {
+			continue
+		}
+		to := name
+		if r, ok := mapping.Rename[name]; ok {
+			to = r
+		}
+		j := slices.Index(dst.FieldNames, to)
+		if j < 0 {
+			return nil, NewSchemaError(ErrConstraintViolated, TransformerName, name, -1,
+				fmt.Errorf("source field %q has no destination %q; drop it or rename it", name, to))
+		}
+		if t.steps[j].src >= 0 {
+			return nil, NewSchemaError(ErrConstraintViolated, TransformerName, to, -1,
+				fmt.Errorf("destination field %q is fed by %q and %q", to, src.FieldNames[t.steps[j].src], name))
+		}
+		t.srcTarget[i] = j
+		t.steps[j].src = i
+		t.steps[j].raw = t.steps[j].convert == nil && sameSchema(src.Schemas[i], dst.Schemas[j])
+	}
+	for j, st := range t.steps {
+		if st.src < 0 && !st.hasDef && !dst.Schemas[j].IsNullable() {
+			return nil, NewSchemaError(ErrConstraintViolated, TransformerName, st.name, -1,
+				MissingKeyErrorDetails{Key: st.name})
+		}
+	}
+	return t, nil
+}
+
+// sameSchema reports whether a and b are known to accept and produce the same
+// bytes. Schemas built from closures never compare equal and are re-encoded.
+func sameSchema(a, b Schema) bool {
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && reflect.DeepEqual(a, b)
+}
+
+type transformField struct {
+	tag     typetags.Type
+	payload []byte
+	val     any
+}
+
+// Apply converts one source record into a destination record.
+func (t *Transformer) Apply(buf []byte) ([]byte, error) {
+	seq, err := access.NewSeqGetAccess(buf)
+	if err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, TransformerName, "", -1, err)
+	}
+	fields := make([]transformField, len(t.steps))
+	for i, sch := range t.src.Schemas {
+		name := t.src.FieldNames[i]
+		j := t.srcTarget[i]
+		if j < 0 {
+			if err := sch.Validate(seq); err != nil {
+				return nil, NewSchemaError(ErrInvalidFormat, TransformerName, name, i, err)
+			}
+			continue
+		}
+		if t.steps[j].raw {
+			typ, width, err := seq.PeekTypeWidth()
+			if err != nil {
+				return nil, NewSchemaError(ErrUnexpectedEOF, TransformerName, name, i, err)
+			}
+			payload, err := seq.GetPayload(width)
+			if err != nil {
+				return nil, NewSchemaError(ErrInvalidFormat, TransformerName, name, i, err)
+			}
+			if err := sch.Validate(seq); err != nil {
+				return nil, NewSchemaError(ErrInvalidFormat, TransformerName, name, i, err)
+			}
+			fields[j] = transformField{tag: typ, payload: payload}
+			continue
+		}
+		val, err := sch.Decode(seq)
+		if err != nil {
+			return nil, NewSchemaError(ErrInvalidFormat, TransformerName, name, i, err)
+		}
+		fields[j].val = val
+	}
+
+	put := access.NewPutAccessFromPool()
+	defer access.ReleasePutAccess(put)
+	for j, st := range t.steps {
+		if st.raw {
+			put.AppendTagAndValue(fields[j].tag, fields[j].payload)
+			continue
+		}
+		val := fields[j].val
+		if st.src < 0 && st.hasDef {
+			val = st.def
+		}
+		if st.convert != nil {
+			if val, err = st.convert(val); err != nil {
+				return nil, NewSchemaError(ErrEncode, TransformerName, st.name, -1, err)
+			}
+		}
+		if err := t.dst.Schemas[j].Encode(put, val); err != nil {
+			return nil, NewSchemaError(ErrEncode, TransformerName, st.name, -1, err)
+		}
+	}
+	return put.Pack(), nil
+}
+
+// ConvertNumber returns a Convert function that turns any decoded number into
+// T. Integer targets reject fractions and values outside T. Nil passes through.
+func ConvertNumber[T constraints.Integer | constraints.Float]() func(any) (any, error) {
+	return func(v any) (any, error) {
+		if v == nil {
+			return nil, nil
+		}
+		out, ok := convertToNumber[T](v)
+		if !ok {
+			return nil, fmt.Errorf("%T is not a number: %w", v, ErrTypeMisMatch)
+		}
+		f, _ := convertToNumber[float64](v)
+		if back := float64(out); back != f && !(math.IsNaN(back) && math.IsNaN(f)) {
+			var zero T
+			if _, isFloat := any(zero).(float32); !isFloat {
+				return nil, fmt.Errorf("%v does not fit %T", v, zero)
+			}
+		}
+		return out, nil
+	}
+}
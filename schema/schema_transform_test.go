@@ -0,0 +1,78 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransform_RenameConvertDrop(t *testing.T) {
+	v1 := SchemaNamedChain{
+		SchemaChain: SChain(SInt16, SString, SString, SMapUnorderedNamed([]string{"theme"}, SString)),
+		FieldNames:  []string{"id", "name", "legacy", "settings"},
+	}
+	v2 := SchemaNamedChain{
+		SchemaChain: SChain(SInt32, SString, SMapUnorderedNamed([]string{"theme"}, SString), SBool, SNullInt64),
+		FieldNames:  []string{"id", "displayName", "settings", "active", "deletedAt"},
+	}
+	tr, err := Transform(v1, v2, Mapping{
+		Rename:  map[string]string{"name": "displayName"},
+		Convert: map[string]func(any) (any, error){"id": ConvertNumber[int32]()},
+		Drop:    []string{"legacy"},
+		Default: map[string]any{"active": true},
+	})
+	require.NoError(t, err)
+	assert.True(t, tr.steps[1].raw, "unchanged string is copied")
+	assert.False(t, tr.steps[0].raw, "converted field is re-encoded")
+
+	src, err := EncodeValueNamed(map[string]any{
+		"id": int16(42), "name": "ann", "legacy": "x", "settings": map[string]any{"theme": "dark"},
+	}, v1)
+	require.NoError(t, err)
+
+	out, err := tr.Apply(src)
+	require.NoError(t, err)
+	decoded, err := DecodeBufferNamed(out, v2)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id": int32(42), "displayName": "ann", "settings": map[string]any{"theme": "dark"},
+		"active": true, "deletedAt": nil,
+	}, decoded)
+
+	_, err = tr.Apply(src[:len(src)-3])
+	assert.Error(t, err)
+}
+
+func TestTransform_CompileErrors(t *testing.T) {
+	src := SchemaNamedChain{SchemaChain: SChain(SInt16, SString), FieldNames: []string{"a", "b"}}
+	dst := SchemaNamedChain{SchemaChain: SChain(SInt16, SBool), FieldNames: []string{"a", "c"}}
+
+	_, err := Transform(src, dst, Mapping{Drop: []string{"b"}})
+	assertSchemaError(t, err, ErrConstraintViolated) // c has no source and is not nullable
+
+	_, err = Transform(src, dst, Mapping{Default: map[string]any{"c": false}})
+	assertSchemaError(t, err, ErrConstraintViolated) // b is unaccounted for
+
+	_, err = Transform(src, dst, Mapping{Rename: map[string]string{"b": "a"}, Default: map[string]any{"c": false}})
+	assertSchemaError(t, err, ErrConstraintViolated) // a is fed twice
+
+	_, err = Transform(src, dst, Mapping{Drop: []string{"b"}, Default: map[string]any{"c": false}})
+	require.NoError(t, err)
+}
+
+func TestConvertNumber(t *testing.T) {
+	v, err := ConvertNumber[int8]()(int64(100))
+	require.NoError(t, err)
+	assert.Equal(t, int8(100), v)
+	_, err = ConvertNumber[int8]()(int64(300))
+	assert.Error(t, err)
+	_, err = ConvertNumber[int32]()(2.5)
+	assert.Error(t, err)
+	v, err = ConvertNumber[float32]()(0.1)
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.1), v)
+	v, err = ConvertNumber[int16]()(nil)
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
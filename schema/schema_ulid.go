@@ -0,0 +1,63 @@
+package schema
+
+import "strings"
+
+const (
+	crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	base62Digits    = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	// maxKSUID is the largest KSUID, 2^160-1 in base 62.
+	maxKSUID = "aWgEPTl1tmebfsQzFP4bxwgy80V"
+)
+
+// SULID accepts ULIDs: 26 Crockford base32 characters, in either case,
+// encoding 128 bits, such as "01ARZ3NDEKTSV4RRFFQ69G5FAV".
+func SULID(optional bool) Schema {
+	s := SString
+	if optional {
+		s = s.Optional()
+	}
+	return s.CheckFunc(ErrStringID, "ULID", isULID)
+}
+
+// SKSUID accepts KSUIDs: 27 base62 characters encoding 160 bits, such as
+// "0ujtsYcgvSTl8PAuAdqWYSMnLOv".
+func SKSUID(optional bool) Schema {
+	s := SString
+	if optional {
+		s = s.Optional()
+	}
+	return s.CheckFunc(ErrStringID, "KSUID", isKSUID)
+}
+
+func isULID(str string) bool {
+	if len(str) != 26 || str[0] > '7' {
+		// 26 characters hold 130 bits; the first may only use three
+		return false
+	}
+	for i := 0; i < len(str); i++ {
+		if !strings.ContainsRune(crockfordBase32, rune(upperASCII(str[i]))) {
+			return false
+		}
+	}
+	return true
+}
+
+func isKSUID(str string) bool {
+	if len(str) != len(maxKSUID) || str > maxKSUID {
+		// base62Digits is in ASCII order, so fixed-width values compare as strings
+		return false
+	}
+	for i := 0; i < len(str); i++ {
+		if strings.IndexByte(base62Digits, str[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func upperASCII(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
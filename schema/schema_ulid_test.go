@@ -0,0 +1,42 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSULIDAndKSUID(t *testing.T) {
+	cases := []struct {
+		schema    Schema
+		good, bad []string
+	}{
+		{SULID(false),
+			[]string{"01ARZ3NDEKTSV4RRFFQ69G5FAV", "01arz3ndektsv4rrffq69g5fav", "7ZZZZZZZZZZZZZZZZZZZZZZZZZ"},
+			[]string{"8ZZZZZZZZZZZZZZZZZZZZZZZZZ", "01ARZ3NDEKTSV4RRFFQ69G5FA", "01ARZ3NDEKTSV4RRFFQ69G5FAU"}},
+		{SKSUID(false),
+			[]string{"0ujtsYcgvSTl8PAuAdqWYSMnLOv", maxKSUID, "000000000000000000000000000"},
+			[]string{"aWgEPTl1tmebfsQzFP4bxwgy80W", "0ujtsYcgvSTl8PAuAdqWYSMnLO", "0ujtsYcgvSTl8PAuAdqWYSMnLO-"}},
+	}
+	for _, c := range cases {
+		chain := SChain(c.schema)
+		for _, in := range c.good {
+			_, err := EncodeValue(in, chain)
+			assert.NoError(t, err, in)
+		}
+		for _, in := range c.bad {
+			_, err := EncodeValue(in, chain)
+			assert.Error(t, err, in)
+			raw, err := EncodeValue(in, SChain(SString))
+			require.NoError(t, err)
+			var se *SchemaError
+			require.ErrorAs(t, ValidateBuffer(raw, chain), &se, in)
+			assert.Equal(t, ErrStringID, se.Code, in)
+		}
+	}
+
+	built := SChain(BuildSchema(&SchemaJSON{Type: "ksuid"}))
+	_, err := EncodeValue("0ujtsYcgvSTl8PAuAdqWYSMnLOv", built)
+	assert.NoError(t, err)
+}
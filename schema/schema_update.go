@@ -0,0 +1,113 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/quickwritereader/PackOS/access"
+)
+
+// UpdateField replaces the field at path with val and returns the new buffer.
+// The first path segment names a field of chain; later segments are keys of
+// map schemas, names of named tuples or indexes of positional tuples, so
+// "settings.theme" is field "settings", then key "theme". val is encoded and
+// validated with the schema found at path, and only the containers along the
+// path are rebuilt with access.ReplacePath; the rest of buf is not decoded
+// unless chain has rules, which are checked on the updated record.
+func UpdateField(buf []byte, chain SchemaNamedChain, path string, val any) ([]byte, error) {
+	sch, wirePath, err := resolveUpdatePath(chain, path)
+	if err != nil {
+		return nil, err
+	}
+
+	put := access.NewPutAccessFromPool()
+	defer access.ReleasePutAccess(put)
+	if err := sch.Encode(put, val); err != nil {
+		return nil, NewSchemaError(ErrEncode, SchemaNamedChainName, path, -1, err)
+	}
+	field := put.Pack()
+	seq, err := access.NewSeqGetAccess(field)
+	if err != nil {
+		return nil, NewSchemaError(ErrEncode, SchemaNamedChainName, path, -1, err)
+	}
+	if err := sch.Validate(seq); err != nil {
+		return nil, NewSchemaError(ErrEncode, SchemaNamedChainName, path, -1, err)
+	}
+
+	out, err := access.ReplacePath(buf, wirePath, field)
+	if err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, SchemaNamedChainName, path, -1, err)
+	}
+	if len(chain.Rules) > 0 {
+		if _, err := DecodeBufferNamed(out, chain); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// resolveUpdatePath walks path through the schemas and returns the schema of
+// the target field together with the matching access.ReplacePath path.
+func resolveUpdatePath(chain SchemaNamedChain, path string) (Schema, string, error) {
+	segments := strings.Split(path, ".")
+	idx := slices.Index(chain.FieldNames, segments[0])
+	if idx < 0 || idx >= len(chain.Schemas) {
+		return nil, "", NewSchemaError(ErrConstraintViolated, SchemaNamedChainName, path, -1,
+			MissingKeyErrorDetails{Key: segments[0]})
+	}
+	sch := chain.Schemas[idx]
+	wire := make([]string, 0, len(segments))
+	wire = append(wire, strconv.Itoa(idx))
+
+	for _, seg := range segments[1:] {
+		next, wireSeg, ok := childSchema(sch, seg)
+		if !ok {
+			return nil, "", NewSchemaError(ErrConstraintViolated, SchemaNamedChainName, path, -1,
+				fmt.Errorf("cannot address %q inside %T", seg, sch))
+		}
+		sch = next
+		wire = append(wire, wireSeg)
+	}
+	return sch, strings.Join(wire, "."), nil
+}
+
+// childSchema returns the schema addressed by seg inside a container schema.
+// Variable-length and flattened tuples have no fixed positions and are not addressable.
+func childSchema(sch Schema, seg string) (Schema, string, bool) {
+	switch s := sch.(type) {
+	case SchemaMapUnordered:
+		child, ok := s.Fields[seg]
+		return child, seg, ok
+	case SchemaMapRepeat:
+		if s.checkKey(seg, -1) != nil {
+			return nil, "", false
+		}
+		return s.Value, seg, true
+	case SchemaMapSortedKeys:
+		if s.Value == nil {
+			return SchemaAny{}, seg, true
+		}
+		return s.Value, seg, true
+	case TupleSchemaNamed:
+		if s.VariableLength || s.Flatten {
+			return nil, "", false
+		}
+		i := slices.Index(s.FieldNames, seg)
+		if i < 0 || i >= len(s.Schemas) {
+			return nil, "", false
+		}
+		return s.Schemas[i], strconv.Itoa(i), true
+	case TupleSchema:
+		if s.VariableLength || s.Flatten {
+			return nil, "", false
+		}
+		i, err := strconv.Atoi(seg)
+		if err != nil || i < 0 || i >= len(s.Schemas) {
+			return nil, "", false
+		}
+		return s.Schemas[i], seg, true
+	}
+	return nil, "", false
+}
@@ -0,0 +1,91 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateField(t *testing.T) {
+	chain := SchemaNamedChain{
+		SchemaChain: SChain(
+			SInt32,
+			SMapUnorderedNamed([]string{"theme", "volume"},
+				SString.Pattern(`^[a-z]+$`),
+				SInt16.RangeValues(0, 10)),
+			STupleNamed([]string{"x", "y"}, SInt16, SInt16),
+			SMapRepeat(SString, SString),
+		),
+		FieldNames: []string{"id", "settings", "pos", "labels"},
+	}
+	doc := map[string]any{
+		"id":       int32(7),
+		"settings": map[string]any{"theme": "dark", "volume": int16(4)},
+		"pos":      map[string]any{"x": int16(1), "y": int16(2)},
+		"labels":   map[string]any{"env": "dev"},
+	}
+	buf, err := EncodeValueNamed(doc, chain)
+	require.NoError(t, err)
+
+	out, err := UpdateField(buf, chain, "settings.theme", "light")
+	require.NoError(t, err)
+	out, err = UpdateField(out, chain, "pos.y", int16(-5))
+	require.NoError(t, err)
+	out, err = UpdateField(out, chain, "labels.env", "prod")
+	require.NoError(t, err)
+	out, err = UpdateField(out, chain, "id", int32(8))
+	require.NoError(t, err)
+
+	decoded, err := DecodeBufferNamed(out, chain)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":       int32(8),
+		"settings": map[string]any{"theme": "light", "volume": int16(4)},
+		"pos":      map[string]any{"x": int16(1), "y": int16(-5)},
+		"labels":   map[string]any{"env": "prod"},
+	}, decoded)
+
+	_, err = UpdateField(buf, chain, "settings.theme", "Not Valid")
+	assertSchemaError(t, err, ErrEncode)
+	_, err = UpdateField(buf, chain, "settings.volume", int16(11))
+	assertSchemaError(t, err, ErrEncode)
+	_, err = UpdateField(buf, chain, "settings.missing", "x")
+	assertSchemaError(t, err, ErrConstraintViolated)
+	_, err = UpdateField(buf, chain, "nope", "x")
+	assertSchemaError(t, err, ErrConstraintViolated)
+	_, err = UpdateField(buf, chain, "labels.other", "x")
+	assertSchemaError(t, err, ErrInvalidFormat)
+}
+
+func assertSchemaError(t *testing.T, err error, code ErrorCode) {
+	t.Helper()
+	var schemaErr *SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	assert.Equal(t, code, schemaErr.Code)
+}
+
+func TestUpdateField_Rules(t *testing.T) {
+	chain := SchemaNamedChain{
+		SchemaChain: SChain(SInt32, SInt32),
+		FieldNames:  []string{"lo", "hi"},
+	}.WithRule(func(f map[string]any) error {
+		if f["lo"].(int32) > f["hi"].(int32) {
+			return errors.New("lo is above hi")
+		}
+		return nil
+	})
+	buf, err := EncodeValueNamed(map[string]any{"lo": int32(1), "hi": int32(5)}, chain)
+	require.NoError(t, err)
+
+	out, err := UpdateField(buf, chain, "lo", int32(4))
+	require.NoError(t, err)
+	decoded, err := DecodeBufferNamed(out, chain)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"lo": int32(4), "hi": int32(5)}, decoded)
+
+	_, err = UpdateField(buf, chain, "lo", int32(6))
+	assertSchemaError(t, err, ErrRuleViolated)
+	assert.ErrorContains(t, err, "lo is above hi")
+}
@@ -0,0 +1,122 @@
+package schema
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const SchemaUUIDName = "SchemaUUID"
+
+// SchemaUUID is a UUID packed as its 16 raw bytes in a string field.
+// Decode returns the canonical lowercase "8-4-4-4-12" string, or a [16]byte
+// when AsBytes is set. Encode accepts either form, 32 hex digits without
+// dashes, and a 16-byte slice.
+type SchemaUUID struct {
+	Nullable bool
+	AsBytes  bool
+}
+
+var (
+	SUUID      = SchemaUUID{}
+	SNullUUID  = SchemaUUID{Nullable: true}
+	SUUIDBytes = SchemaUUID{AsBytes: true}
+)
+
+func (s SchemaUUID) IsNullable() bool { return s.Nullable }
+
+func (s SchemaUUID) Validate(seq *access.SeqGetAccess) error {
+	_, err := s.payload(seq)
+	return err
+}
+
+func (s SchemaUUID) Decode(seq *access.SeqGetAccess) (any, error) {
+	payload, err := s.payload(seq)
+	if err != nil || payload == nil {
+		return nil, err
+	}
+	var id [16]byte
+	copy(id[:], payload)
+	if s.AsBytes {
+		return id, nil
+	}
+	return FormatUUID(id), nil
+}
+
+// payload checks the field and returns its 16 bytes, or nil for a null.
+func (s SchemaUUID) payload(seq *access.SeqGetAccess) ([]byte, error) {
+	pos := seq.CurrentIndex()
+	payload, err := validatePrimitiveAndGetPayload(SchemaUUIDName, seq, typetags.TypeString, 16, s.Nullable)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil && len(payload) != 16 {
+		// nullable fields skip the width check in precheck
+		return nil, NewSchemaError(ErrConstraintViolated, SchemaUUIDName, "", pos, SizeExact{16, len(payload)})
+	}
+	return payload, nil
+}
+
+func (s SchemaUUID) Encode(put *access.PutAccess, val any) error {
+	if s.Nullable && val == nil {
+		put.AddBytes(nil)
+		return nil
+	}
+	var id [16]byte
+	switch v := val.(type) {
+	case [16]byte:
+		id = v
+	case []byte:
+		if len(v) != 16 {
+			return NewSchemaError(ErrEncode, SchemaUUIDName, "", -1, SizeExact{16, len(v)})
+		}
+		copy(id[:], v)
+	case string:
+		parsed, err := ParseUUID(v)
+		if err != nil {
+			return NewSchemaError(ErrEncode, SchemaUUIDName, "", -1, err)
+		}
+		id = parsed
+	default:
+		return NewSchemaError(ErrEncode, SchemaUUIDName, "", -1, ErrTypeMisMatch)
+	}
+	put.AddBytes(id[:])
+	return nil
+}
+
+// ParseUUID parses the canonical "8-4-4-4-12" form or 32 hex digits.
+func ParseUUID(s string) ([16]byte, error) {
+	var id [16]byte
+	h := s
+	if len(s) == 36 {
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return id, fmt.Errorf("invalid UUID %q", s)
+		}
+		h = strings.ReplaceAll(s, "-", "")
+	}
+	if len(h) != 32 {
+		return id, fmt.Errorf("invalid UUID %q", s)
+	}
+	if _, err := hex.Decode(id[:], []byte(h)); err != nil {
+		return id, fmt.Errorf("invalid UUID %q: %w", s, err)
+	}
+	return id, nil
+}
+
+// FormatUUID returns the canonical lowercase form of id.
+func FormatUUID(id [16]byte) string {
+	var out [36]byte
+	hex.Encode(out[0:8], id[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], id[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], id[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], id[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], id[10:])
+	return string(out[:])
+}
@@ -0,0 +1,43 @@
+package schema
+
+import (
+	"testing"
+
+	pack "github.com/quickwritereader/PackOS/packable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaUUID(t *testing.T) {
+	const canonical = "123e4567-e89b-12d3-a456-426614174000"
+	raw := [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+
+	chain := SChain(BuildSchema(&SchemaJSON{Type: "uuid"}))
+	for _, in := range []any{canonical, "123E4567E89B12D3A456426614174000", raw, raw[:]} {
+		buf, err := EncodeValue(in, chain)
+		require.NoError(t, err, "%v", in)
+		assert.Equal(t, pack.Pack(pack.PackByteArray(raw[:])), buf)
+		v, err := DecodeBuffer(buf, chain)
+		require.NoError(t, err)
+		assert.Equal(t, canonical, v)
+	}
+
+	v, err := DecodeBuffer(pack.Pack(pack.PackByteArray(raw[:])), SChain(SUUIDBytes))
+	require.NoError(t, err)
+	assert.Equal(t, raw, v)
+
+	for _, bad := range []any{"123e4567-e89b-12d3-a456", "123e4567e89b-12d3-a456-426614174000-", "zz3e4567-e89b-12d3-a456-426614174000", []byte{1, 2}, 42} {
+		_, err := EncodeValue(bad, chain)
+		assertSchemaError(t, err, ErrEncode)
+	}
+	assertSchemaError(t, ValidateBuffer(pack.Pack(pack.PackString("short")), chain), ErrConstraintViolated)
+	assertSchemaError(t, ValidateBuffer(pack.Pack(pack.PackString("0123456789abcdefX")), SChain(SNullUUID)), ErrConstraintViolated)
+
+	nullable := SChain(SNullUUID)
+	buf, err := EncodeValue(nil, nullable)
+	require.NoError(t, err)
+	v, err = DecodeBuffer(buf, nullable)
+	require.NoError(t, err)
+	assert.Nil(t, v)
+	require.Error(t, ValidateBuffer(buf, chain))
+}
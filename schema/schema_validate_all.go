@@ -0,0 +1,183 @@
+package schema
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+// ValidateAll validates buf like ValidateBuffer but does not stop at the
+// first failure: a field that fails is recorded and skipped, and validation
+// goes on with the next one, so that a form can show every problem at once.
+// Each error's Field is set to the path of the failing field, named as in
+// WithFieldObserver, and Position is its index within its container; Path
+// is Field split at the dots, followed by the error's own Path.
+// Failures of tuple and map fields are reported at the deepest field that
+// failed; a container whose own rules fail, such as a missing map key, is
+// reported and skipped as a whole. A failure that leaves no field to skip,
+// such as a truncated buffer or a repeat with too few elements, ends
+// validation. ValidateAll returns nil when buf is valid.
+func ValidateAll(buf []byte, chain SchemaChain) []SchemaError {
+	return validateAll(buf, chain).errs
+}
+
+func validateAll(buf []byte, chain SchemaChain) *errorCollector {
+	c := &errorCollector{}
+	seq, err := access.NewSeqGetAccess(buf)
+	if err != nil {
+		c.errs = []SchemaError{*NewSchemaError(ErrInvalidFormat, ChainName, "", -1, err)}
+		c.actual = []any{nil}
+		return c
+	}
+	for i, s := range chain.Schemas {
+		if err := collectSchema(s, strconv.Itoa(i), c).Validate(seq); err != nil {
+			c.add(err, strconv.Itoa(i), seq.CurrentIndex(), nil)
+			break
+		}
+	}
+	return c
+}
+
+// errorCollector gathers the failures of one ValidateAll, with the value
+// of each failing primitive field.
+type errorCollector struct {
+	errs   []SchemaError
+	actual []any
+}
+
+func (c *errorCollector) add(err error, path string, pos int, actual any) {
+	c.actual = append(c.actual, actual)
+	var se *SchemaError
+	if errors.As(err, &se) {
+		e := *se
+		e.Field = path
+		e.Path = append(strings.Split(path, "."), se.Path...)
+		c.errs = append(c.errs, e)
+		return
+	}
+	e := NewSchemaError(ErrUnknown, "", path, pos, err)
+	e.Path = strings.Split(path, ".")
+	c.errs = append(c.errs, *e)
+}
+
+// fieldValue decodes the primitive field at pos, or returns nil for
+// containers and unreadable fields.
+func fieldValue(seq *access.SeqGetAccess, pos int) any {
+	if seq.Seek(pos) != nil {
+		return nil
+	}
+	typ, width, err := seq.PeekTypeWidth()
+	if err != nil || typ.IsMap() || typ == typetags.TypeTuple {
+		return nil
+	}
+	payload, err := seq.GetPayload(width)
+	if err != nil {
+		return nil
+	}
+	v, err := access.DecodePrimitive(typ, payload)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// collectSchema wraps s and the schemas nested in it so that their failures
+// are recorded in c instead of returned. Repeats stay unwrapped, since
+// tuples recognize them by type, and wrappers that only change decoding are
+// dropped; caches are dropped too, so that their entries are not filled
+// with fields that failed.
+func collectSchema(s Schema, path string, c *errorCollector) Schema {
+	child := func(s Schema, name string) Schema {
+		if s == nil {
+			return nil
+		}
+		return collectSchema(s, path+"."+name, c)
+	}
+	switch s := s.(type) {
+	case SRepeatSchema:
+		schemas := make([]Schema, len(s.Schemas))
+		for i, sch := range s.Schemas {
+			schemas[i] = collectSchema(sch, path+".*", c)
+		}
+		s.Schemas = schemas
+		return s
+	case SchemaSanitized:
+		return collectSchema(s.Schema, path, c)
+	case SchemaWidened:
+		return collectSchema(s.Schema, path, c)
+	case SchemaCached:
+		return collectSchema(s.Schema, path, c)
+	case SchemaConditional:
+		// stay unwrapped, so that the enclosing container finds it
+		s.Then = collectSchema(s.Then, path, c)
+		s.Else = collectSchema(s.Else, path, c)
+		return s
+	case SchemaRequired:
+		s.Schema = collectSchema(s.Schema, path, c)
+	case SchemaRuled:
+		// rules decode the whole field, so it is reported as one
+		return collectedSchema{s, path, c}
+	case SchemaNullable:
+		s.Schema = collectSchema(s.Schema, path, c)
+	case TupleSchema:
+		schemas := make([]Schema, len(s.Schemas))
+		for i, sch := range s.Schemas {
+			schemas[i] = child(sch, strconv.Itoa(i))
+		}
+		s.Schemas = schemas
+		return collectedSchema{s, path, c}
+	case TupleSchemaNamed:
+		schemas := make([]Schema, len(s.Schemas))
+		for i, sch := range s.Schemas {
+			name := strconv.Itoa(i)
+			if i < len(s.FieldNames) {
+				name = s.FieldNames[i]
+			}
+			schemas[i] = child(sch, name)
+		}
+		s.Schemas = schemas
+		return collectedSchema{s, path, c}
+	case SchemaMapUnordered:
+		fields := make(map[string]Schema, len(s.Fields))
+		for k, sch := range s.Fields {
+			fields[k] = child(sch, k)
+		}
+		s.Fields = fields
+		return collectedSchema{s, path, c}
+	case SchemaMapRepeat:
+		s.Value = child(s.Value, "*")
+		return collectedSchema{s, path, c}
+	case SchemaMapSortedKeys:
+		s.Value = child(s.Value, "*")
+		return collectedSchema{s, path, c}
+	}
+	return collectedSchema{s, path, c}
+}
+
+// collectedSchema records a failed Validate of Schema and skips its field.
+type collectedSchema struct {
+	Schema
+	path string
+	c    *errorCollector
+}
+
+func (s collectedSchema) Validate(seq *access.SeqGetAccess) error {
+	pos := seq.CurrentIndex()
+	err := s.Schema.Validate(seq)
+	if err == nil {
+		return nil
+	}
+	if pos >= seq.ArgCount() {
+		// nothing to skip, the caller ends validation
+		return err
+	}
+	actual := fieldValue(seq, pos)
+	if seq.Seek(pos+1) != nil {
+		return err
+	}
+	s.c.add(err, s.path, pos, actual)
+	return nil
+}
@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAll_ReportsEveryViolation(t *testing.T) {
+	loose := SChain(
+		STupleNamedVal([]string{"id", "age", "tags"}, SString, SInt16, SRepeat(0, -1, SString)),
+		SMapUnordered(map[string]Schema{"email": SString, "zip": SString}),
+	)
+	strict := SChain(
+		STupleNamedVal([]string{"id", "age", "tags"},
+			SString.Prefix("u-"),
+			SInt16.RangeValues(0, 150),
+			SRepeat(0, -1, SString.WithWidth(2)),
+		),
+		SMapUnordered(map[string]Schema{"email": SString.Prefix("mailto:"), "zip": SString.WithWidth(5)}),
+	)
+	buf, err := EncodeValue([]any{
+		map[string]any{"id": "x-1", "age": int16(200), "tags": []any{"ok", "bad", "no"}},
+		map[string]any{"email": "ann", "zip": "12345"},
+	}, loose)
+	require.NoError(t, err)
+
+	require.Error(t, ValidateBuffer(buf, strict))
+	errs := ValidateAll(buf, strict)
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Field+" "+e.Code.String())
+	}
+	assert.Equal(t, []string{
+		"0.id ErrStringPrefix",
+		"0.age ErrOutOfRange",
+		"0.tags.* ErrConstraintViolated",
+		"1.email ErrStringPrefix",
+	}, got)
+	assert.Equal(t, 3, errs[2].Position)
+
+	good, err := EncodeValue([]any{
+		map[string]any{"id": "u-1", "age": int16(20), "tags": []any{"ok"}},
+		map[string]any{"email": "mailto:ann", "zip": "12345"},
+	}, loose)
+	require.NoError(t, err)
+	assert.Nil(t, ValidateAll(good, strict))
+}
+
+func TestValidateAll_StopsWhenNothingToSkip(t *testing.T) {
+	buf, err := EncodeValue([]any{"a", "b"}, SChain(SString, SString))
+	require.NoError(t, err)
+	errs := ValidateAll(buf, SChain(SString.Prefix("x"), SString, SString))
+	require.Len(t, errs, 2)
+	assert.Equal(t, "0", errs[0].Field)
+	assert.Equal(t, ErrStringPrefix, errs[0].Code)
+	assert.Equal(t, "2", errs[1].Field)
+}
@@ -0,0 +1,103 @@
+package schema
+
+import (
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const SchemaVarintName = "SchemaVarint"
+
+// SchemaVarint is an integer field written with access.PutAccess.AddIntCompact.
+// It accepts any integer payload width, fixed or varint, and decodes to int64.
+type SchemaVarint struct {
+	Nullable    bool
+	Constraints *IntConstraints
+}
+
+var SVarint = SchemaVarint{}
+
+// Constrain returns a copy of s that checks c on validate, decode and encode.
+func (s SchemaVarint) Constrain(c IntConstraints) SchemaVarint {
+	s.Constraints = &c
+	return s
+}
+
+// MultipleOf returns a copy of s that also requires values to be multiples
+// of n, keeping the constraints already set.
+func (s SchemaVarint) MultipleOf(n int64) SchemaVarint {
+	var c IntConstraints
+	if s.Constraints != nil {
+		c = *s.Constraints
+	}
+	c.MultipleOf = &n
+	return s.Constrain(c)
+}
+
+func (s SchemaVarint) IsNullable() bool { return s.Nullable }
+
+func (s SchemaVarint) decodeValidate(seq *access.SeqGetAccess) (any, error) {
+	pos := seq.CurrentIndex()
+	payload, err := validatePrimitiveAndGetPayload(SchemaVarintName, seq, typetags.TypeInteger, 0, s.Nullable)
+	if err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		if !s.Nullable {
+			return nil, NewSchemaError(ErrConstraintViolated, SchemaVarintName, "", pos, ErrTypeMisMatch)
+		}
+		return nil, nil
+	}
+	v, err := access.DecodePrimitive(typetags.TypeInteger, payload)
+	if err != nil {
+		return nil, NewSchemaError(ErrInvalidFormat, SchemaVarintName, "", pos, err)
+	}
+	i, _ := convertToNumber[int64](v)
+	if s.Constraints != nil {
+		if err := s.Constraints.Check(i); err != nil {
+			return nil, NewSchemaError(numericErrorCode(err), SchemaVarintName, "", pos, err)
+		}
+	}
+	return i, nil
+}
+
+func (s SchemaVarint) Validate(seq *access.SeqGetAccess) error {
+	_, err := s.decodeValidate(seq)
+	return err
+}
+
+func (s SchemaVarint) Decode(seq *access.SeqGetAccess) (any, error) {
+	return s.decodeValidate(seq)
+}
+
+func (s SchemaVarint) Encode(put *access.PutAccess, val any) error {
+	if s.Nullable && val == nil {
+		put.AddNullableInt64(nil)
+		return nil
+	}
+	val, err := coerceJSONNumber[int64](val)
+	if err != nil {
+		return NewSchemaError(ErrEncode, SchemaVarintName, "", -1, err)
+	}
+	var i int64
+	switch v := val.(type) {
+	case int:
+		i = int64(v)
+	case int8:
+		i = int64(v)
+	case int16:
+		i = int64(v)
+	case int32:
+		i = int64(v)
+	case int64:
+		i = v
+	default:
+		return NewSchemaError(ErrEncode, SchemaVarintName, "", -1, ErrTypeMisMatch)
+	}
+	if s.Constraints != nil {
+		if err := s.Constraints.Check(i); err != nil {
+			return NewSchemaError(numericErrorCode(err), SchemaVarintName, "", -1, err)
+		}
+	}
+	put.AddIntCompact(i)
+	return nil
+}
@@ -0,0 +1,37 @@
+package schema
+
+import (
+	"github.com/quickwritereader/PackOS/access"
+)
+
+// SchemaWidened decodes with Schema and widens the numbers in the result
+// with access.WidenNumbers: integers of any width become int64 and floats
+// float64, also inside the tuples and maps SchemaAny and SchemaTypeOnly
+// return. Downstream code then needs a single case per kind whatever width
+// the writer chose. Validate and Encode are those of Schema.
+type SchemaWidened struct {
+	Schema Schema
+}
+
+// SWiden wraps s so that it decodes to int64 and float64.
+func SWiden(s Schema) SchemaWidened {
+	return SchemaWidened{Schema: s}
+}
+
+func (s SchemaWidened) IsNullable() bool { return s.Schema.IsNullable() }
+
+func (s SchemaWidened) Validate(seq *access.SeqGetAccess) error {
+	return s.Schema.Validate(seq)
+}
+
+func (s SchemaWidened) Decode(seq *access.SeqGetAccess) (any, error) {
+	v, err := s.Schema.Decode(seq)
+	if err != nil {
+		return nil, err
+	}
+	return access.WidenNumbers(v), nil
+}
+
+func (s SchemaWidened) Encode(put *access.PutAccess, val any) error {
+	return s.Schema.Encode(put, val)
+}
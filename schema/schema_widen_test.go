@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSWiden_TypeOnlyAndPrimitives(t *testing.T) {
+	put := access.NewPutAccess()
+	put.AddInt8(1)
+	put.AddInt16(2)
+	put.AddFloat32(1.5)
+	require.NoError(t, put.AddAnyTuple([]any{int32(3), map[string]any{"f": float32(0.25), "n": int8(-4)}}, false))
+	put.AddNullableInt32(nil)
+	buf := put.Pack()
+
+	tuple := SchemaTypeOnly{Tag: typetags.TypeTuple}
+	plain, err := DecodeBuffer(buf, SChain(SchemaTypeOnly{Tag: typetags.TypeInteger}, SInt16, SFloat32, tuple, SNullInt32))
+	require.NoError(t, err)
+	assert.Equal(t, []any{int8(1), int16(2), float32(1.5), []any{int32(3), map[string]any{"f": float32(0.25), "n": int8(-4)}}, nil}, plain)
+
+	chain := SChain(SWiden(SchemaTypeOnly{Tag: typetags.TypeInteger}), SWiden(SInt16), SWiden(SFloat32), SWiden(tuple), SWiden(SNullInt32))
+	got, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(1), int64(2), 1.5, []any{int64(3), map[string]any{"f": 0.25, "n": int64(-4)}}, nil}, got)
+}
+
+func TestSWiden_AnyValues(t *testing.T) {
+	chain := SChain(SWiden(SMapRepeat(SString, SAny)))
+	buf, err := EncodeValue(map[string]any{"a": []any{int16(7), float32(2)}}, chain)
+	require.NoError(t, err)
+
+	got, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": []any{int64(7), 2.0}}, got)
+
+	put := access.NewPutAccess()
+	put.AddInt8(1)
+	require.NoError(t, put.AddAnyTuple([]any{int16(2), float32(0.5)}, false))
+	put.AddInt32(3)
+	got, err = DecodeBuffer(put.Pack(), SChain(SWiden(SAny), SWiden(SAny), SAny))
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(1), []any{int64(2), 0.5}, int32(3)}, got)
+}
+
+func TestWidenNumbers_OrderedMap(t *testing.T) {
+	om := typetags.NewOrderedMapAny(typetags.OPAny("b", int16(7)), typetags.OPAny("a", float32(2)))
+	access.WidenNumbers(om)
+	assert.Equal(t, []string{"b", "a"}, om.Keys())
+	assert.Equal(t, []any{int64(7), 2.0}, om.Values())
+}
+
+func TestBuildSchema_Widen(t *testing.T) {
+	s := BuildSchema(&SchemaJSON{Type: "int16", Widen: true})
+	buf, err := EncodeValue(int16(9), SChain(s))
+	require.NoError(t, err)
+	got, err := DecodeBuffer(buf, SChain(s))
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), got)
+}
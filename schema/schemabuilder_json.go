@@ -1,8 +1,13 @@
 package schema
 
 import (
+	"encoding/hex"
 	"fmt"
+	"math"
+	"slices"
 	"time"
+
+	"golang.org/x/exp/constraints"
 )
 
 type SchemaJSON struct {
@@ -14,19 +19,78 @@ type SchemaJSON struct {
 	Flatten        bool         `json:"flatten,omitempty"`
 
 	// Constraint helpers
-	Width         int    `json:"width,omitempty"`
-	Min           *int64 `json:"min,omitempty"`
-	Max           *int64 `json:"max,omitempty"`
-	Exact         string `json:"exact,omitempty"`
-	Prefix        string `json:"prefix,omitempty"`
-	Suffix        string `json:"suffix,omitempty"`
-	Pattern       string `json:"pattern,omitempty"`
-	DateFrom      string `json:"dateFrom,omitempty"`
-	DateTo        string `json:"dateTo,omitempty"`
-	DecodeDefault string `json:"decodeDefault,omitempty"`
+	Width int    `json:"width,omitempty"`
+	Min   *int64 `json:"min,omitempty"`
+	Max   *int64 `json:"max,omitempty"`
+	// Float bounds for "number"/"numberString"; take precedence over Min/Max.
+	MinFloat      *float64 `json:"minFloat,omitempty"`
+	MaxFloat      *float64 `json:"maxFloat,omitempty"`
+	ExclusiveMin  bool     `json:"exclusiveMin,omitempty"`
+	ExclusiveMax  bool     `json:"exclusiveMax,omitempty"`
+	MultipleOf    *float64 `json:"multipleOf,omitempty"`
+	Epsilon       float64  `json:"epsilon,omitempty"` // tolerance of "number" bounds
+	Exact         string   `json:"exact,omitempty"`
+	Prefix        string   `json:"prefix,omitempty"`
+	Suffix        string   `json:"suffix,omitempty"`
+	Pattern       string   `json:"pattern,omitempty"`
+	Check         string   `json:"check,omitempty"`    // name given to RegisterStringCheck
+	EnumType      string   `json:"enumType,omitempty"` // name given to RegisterEnumType
+	DateFrom      string   `json:"dateFrom,omitempty"`
+	DateTo        string   `json:"dateTo,omitempty"`
+	MinDuration   string   `json:"minDuration,omitempty"` // "duration" bounds, as in "90s"
+	MaxDuration   string   `json:"maxDuration,omitempty"`
+	Location      string   `json:"location,omitempty"`
+	DecodeDefault string   `json:"decodeDefault,omitempty"`
+	// Sanitizers applied in order on encode, before constraint checks:
+	// "trim", "collapseSpace", "stripControl", "lower", "upper",
+	// "emptyAsNull", "clamp" (to this node's bounds) or a name given to
+	// RegisterSanitizer.
+	Sanitize []string `json:"sanitize,omitempty"`
+	// Widen decodes integers to int64 and floats to float64 at any depth.
+	Widen bool `json:"widen,omitempty"`
+	// Required rejects nulls, including zero-width values, on any type.
+	Required bool `json:"required,omitempty"`
+	// Rules names rules given to RegisterRule, checked in order on any type.
+	Rules []string `json:"rules,omitempty"`
+	// NullTag keeps null and empty "string" and "bytes" values apart.
+	NullTag bool `json:"nullTag,omitempty"`
+	// CountRunes measures the Min/Max length of a "string" in UTF-8
+	// characters rather than bytes.
+	CountRunes bool `json:"countRunes,omitempty"`
+	// UTF8 rejects malformed "string" payloads; NFC also normalizes them
+	// to Unicode Normalization Form C on encode.
+	UTF8 bool `json:"utf8,omitempty"`
+	NFC  bool `json:"nfc,omitempty"`
+	// IgnoreCase and Trimmed make "exact", "prefix" and "suffix" ignore
+	// case and surrounding white space.
+	IgnoreCase bool `json:"ignoreCase,omitempty"`
+	Trimmed    bool `json:"trimmed,omitempty"`
+	// Base64 carries "bytes" values as base64 strings.
+	Base64 bool `json:"base64,omitempty"`
+	// Region is the ISO 3166 region of "phone" numbers written without
+	// a country code.
+	Region string `json:"region,omitempty"`
+	// Range limits "semver" values, as in ">=1.2.0 <2.0.0"; see SSemVerRange.
+	Range string `json:"range,omitempty"`
+	// Currencies lists the ISO 4217 codes a "money" node accepts.
+	Currencies []string `json:"currencies,omitempty"`
+	// Codes maps the names of an "enumValues" node to their codes; Aliases
+	// maps extra names to names in Codes, Deprecated lists names refused on
+	// encode and DecodeCode decodes to the code instead of the name.
+	Codes      map[string]int64  `json:"codes,omitempty"`
+	Aliases    map[string]string `json:"aliases,omitempty"`
+	Deprecated []string          `json:"deprecated,omitempty"`
+	DecodeCode bool              `json:"decodeCode,omitempty"`
 
 	// Extra metadata for UI or other purposes
 	Extra map[string]any `json:"extra,omitempty"`
+
+	// Modules, resolved by SchemaModules: Imports lists the modules whose
+	// Defs this document uses, Defs holds named schemas, and a "ref" node
+	// stands for the definition named by Ref.
+	Imports []string              `json:"imports,omitempty"`
+	Defs    map[string]SchemaJSON `json:"defs,omitempty"`
+	Ref     string                `json:"ref,omitempty"`
 }
 
 // Registry of custom schema builders.
@@ -70,6 +134,83 @@ func UnregisterSchemaType(typeName string) {
 	delete(customSchemaBuilders, typeName)
 }
 
+// Registry of named string checks referenced by SchemaJSON.Check.
+var stringChecks = map[string]func(string) bool{}
+
+// RegisterStringCheck registers a named validation function for "string"
+// nodes, so organization-specific rules can be referenced from JSON-defined
+// schemas without a custom type per rule:
+//
+//	schema.RegisterStringCheck("employeeId", func(s string) bool {
+//	    return len(s) == 7 && strings.HasPrefix(s, "E")
+//	})
+//
+//	{"type": "string", "check": "employeeId"}
+//
+// Notes:
+//   - Names are case-sensitive.
+//   - Panics if the name is already registered.
+//   - Like exact, prefix, suffix and pattern, a check is applied only when
+//     none of the rules before it is set.
+//   - BuildSchema panics if a node names a check that is not registered.
+func RegisterStringCheck(name string, check func(string) bool) {
+	if name == "" {
+		panic("cannot register empty check name")
+	}
+	if _, exists := stringChecks[name]; exists {
+		panic("string check already registered: " + name)
+	}
+	stringChecks[name] = check
+}
+
+// UnregisterStringCheck removes a previously registered string check.
+// If the name is not found, the function does nothing.
+func UnregisterStringCheck(name string) {
+	delete(stringChecks, name)
+}
+
+// Registry of Go enum types referenced by SchemaJSON.EnumType.
+var enumTypes = map[string]enumType{}
+
+type enumType struct {
+	names []string
+	build func(nullable bool) Schema
+}
+
+// RegisterEnumType registers the integer type T under name, so that "enum"
+// nodes naming it decode to T constants, as SEnumInt does, instead of name
+// strings:
+//
+//	type Role int8
+//	schema.RegisterEnumType[Role]("role", []string{"admin", "user"})
+//
+//	{"type": "enum", "enumType": "role"}
+//
+// Notes:
+//   - Names are case-sensitive.
+//   - Panics if the name is already registered.
+//   - A node may omit fieldNames; when it lists them, they must equal
+//     fieldNames here.
+//   - BuildSchema panics if a node names an enum type that is not registered.
+func RegisterEnumType[T constraints.Integer](name string, fieldNames []string) {
+	if name == "" {
+		panic("cannot register empty enum type name")
+	}
+	if _, exists := enumTypes[name]; exists {
+		panic("enum type already registered: " + name)
+	}
+	enumTypes[name] = enumType{
+		names: fieldNames,
+		build: func(nullable bool) Schema { return SEnumInt[T](fieldNames, nullable) },
+	}
+}
+
+// UnregisterEnumType removes a previously registered enum type.
+// If the name is not found, the function does nothing.
+func UnregisterEnumType(name string) {
+	delete(enumTypes, name)
+}
+
 // BuildSchema constructs a Schema instance from a SchemaJSON definition.
 //
 // It inspects the `Type` field of the provided SchemaJSON and returns the
@@ -80,22 +221,44 @@ func UnregisterSchemaType(typeName string) {
 //   - "int16"      → SInt16 with optional Range
 //   - "int32"      → SInt32 with optional Range
 //   - "int64"      → SInt64 with optional Range
+//   - "varint"     → SVarint (any integer width, decodes to int64) with optional Range
+//   - "time"       → STime / SNullTime, decoded in Location (default UTC)
+//   - "duration"   → SDuration / SNullDuration, bounded by minDuration/maxDuration
 //   - "date"       → SDate with optional DateFrom/DateTo
+//   - "float16"    → SFloat16 / SNullFloat16
+//   - "bfloat16"   → SBFloat16 / SNullBFloat16
 //   - "float32"    → SFloat32 / SNullFloat32
 //   - "float64"    → SFloat64 / SNullFloat64
-//   - "string"     → SString with optional width, exact, prefix, suffix, pattern
+//   - "string"     → SString with optional width, exact, prefix, suffix, pattern, check, nullTag
+//   - "uuid"       → SUUID / SNullUUID (16 raw bytes, decoded as a string)
 //   - "email"      → SEmail
 //   - "uri"        → SURI
 //   - "lang"       → SLang
-//   - "bytes"      → SBytes / SVariableBytes
+//   - "ipv4", "ipv6", "cidr", "mac" → SIPv4, SIPv6, SCIDR, SMAC
+//   - "ipv4Packed", "ipv6Packed" → SIPv4Packed / SIPv6Packed (4 or 16 raw bytes)
+//   - "phone"      → SPhone, normalized to E.164 with region as the default
+//   - "semver"     → SSemVer, or SSemVerRange when range is set
+//   - "ulid", "ksuid" → SULID, SKSUID
+//   - "bytes"      → SBytes / SVariableBytes; nullTag keeps null and empty apart,
+//     prefix is hex magic bytes and base64 carries values as base64 strings
+//   - "bitset"     → SBitset / SBitsetLen(width), compressed for sparse sets
+//   - "decimal"    → SDecimal; "decimalString" decodes to the plain string form
+//   - "money"      → SMoney(currencies...) with min/max bounding the amount in
+//     minor units
+//   - "geoPoint"   → SGeoPoint; "geoPointCompact" packs int32 micro-degrees
 //   - "any"        → SAny
 //   - "tuple"      → STuple / STupleNamed / STupleVal (with flatten/variableLength)
 //   - "repeat"     → SRepeat
+//   - "array"      → SArray of the numeric element Schema[0], Min/Max elements
+//   - "fallback"   → SFallback(Schema[0], Schema[1])
 //   - "map"        → SMap
-//   - "mapUnordered" → SMapUnordered / SMapUnorderedOptional
-//   - "mapRepeat"  → SMapRepeatRange
+//   - "mapUnordered" → SMapUnorderedNamed; Encode follows FieldNames order
+//   - "mapRepeat"  → SMapRepeatRange; Pattern → KeysMatch, FieldNames → KeysOneOf
+//   - "mapSortedKeys" → SMapSortedKeys (Prefix, Min/Max entries, optional value Schema[0])
 //   - "multicheck" → SMultiCheckNames
-//   - "enum"       → SEnum
+//   - "enum"       → SEnum, or SEnumInt for a registered EnumType
+//   - "enumValues" → SEnumValues(codes) with aliases, deprecated names and
+//     decodeCode
 //   - "color"      → SColor
 //
 // If the type is not recognized, BuildSchema checks the custom registry
@@ -118,14 +281,44 @@ func UnregisterSchemaType(typeName string) {
 //   - Type names are case-sensitive.
 //   - Nullable fields are respected where applicable.
 //   - Min/Max apply to numeric typetags.
-//   - DateFrom/DateTo must be RFC3339 strings.
+//   - MinFloat/MaxFloat apply to "number" and "numberString".
+//   - ExclusiveMin/ExclusiveMax and MultipleOf apply to int16/int32/int64 and number typetags;
+//     MultipleOf must be integral for integer typetags.
+//   - Epsilon widens the float bound comparisons of "number" and "numberString".
+//   - DateFrom/DateTo accept RFC3339, zone-less "2006-01-02[T15:04:05]" values read in
+//     Location (an IANA name, default UTC), or relative bounds like "now-30d" and "now+1h".
 //   - For "mapUnordered", FieldNames and Schema must align in length.
 //   - For "mapRepeat", Schema must contain exactly two entries.
+//   - Sanitize wraps any type in SSanitized; values are cleaned on encode only.
+//   - Widen wraps any type in SWiden; decoded numbers become int64 and float64.
+//   - Required wraps any type in SRequired; Nullable then only affects the
+//     inner schema.
+//   - Rules wraps any type in SRules, inside the wrappers above.
 func BuildSchema(js *SchemaJSON) Schema {
 	if js == nil {
 		panic("nil schema")
 
 	}
+	if len(js.Sanitize) > 0 {
+		inner := *js
+		inner.Sanitize = nil
+		return SSanitized(BuildSchema(&inner), buildSanitizers(js)...)
+	}
+	if js.Widen {
+		inner := *js
+		inner.Widen = false
+		return SWiden(BuildSchema(&inner))
+	}
+	if js.Required {
+		inner := *js
+		inner.Required = false
+		return SRequired(BuildSchema(&inner))
+	}
+	if len(js.Rules) > 0 {
+		inner := *js
+		inner.Rules = nil
+		return SRules(BuildSchema(&inner), js.Rules...)
+	}
 	switch js.Type {
 	case "bool":
 		if js.Nullable {
@@ -142,8 +335,8 @@ func BuildSchema(js *SchemaJSON) Schema {
 		if js.Nullable {
 			s.Nullable = true
 		}
-		if js.Min != nil || js.Max != nil {
-			return s.Range(js.Min, js.Max)
+		if c, ok := intConstraints(js); ok {
+			return s.Constrain(c)
 		}
 		return s
 	case "int32":
@@ -151,8 +344,8 @@ func BuildSchema(js *SchemaJSON) Schema {
 		if js.Nullable {
 			s.Nullable = true
 		}
-		if js.Min != nil || js.Max != nil {
-			return s.Range(js.Min, js.Max)
+		if c, ok := intConstraints(js); ok {
+			return s.Constrain(c)
 		}
 		return s
 	case "int64":
@@ -160,17 +353,35 @@ func BuildSchema(js *SchemaJSON) Schema {
 		if js.Nullable {
 			s.Nullable = true
 		}
-		if js.Min != nil || js.Max != nil {
-			return s.Range(js.Min, js.Max)
+		if c, ok := intConstraints(js); ok {
+			return s.Constrain(c)
 		}
 		return s
-	case "date":
-		if js.DateFrom != "" && js.DateTo != "" {
-			from, _ := time.Parse(time.RFC3339, js.DateFrom)
-			to, _ := time.Parse(time.RFC3339, js.DateTo)
-			return SDateRange(js.Nullable, &from, &to)
+	case "varint":
+		s := SVarint
+		s.Nullable = js.Nullable
+		if c, ok := intConstraints(js); ok {
+			return s.Constrain(c)
+		}
+		return s
+	case "time":
+		s := SchemaTime{Nullable: js.Nullable}
+		if js.Location != "" {
+			loc, err := time.LoadLocation(js.Location)
+			if err != nil {
+				panic(fmt.Sprintf("time: invalid location %q: %v", js.Location, err))
+			}
+			s.Location = loc
 		}
-		return SDateRange(js.Nullable, nil, nil)
+		return s
+	case "duration":
+		return SchemaDuration{Nullable: js.Nullable}.Range(durationBound(js.MinDuration), durationBound(js.MaxDuration))
+	case "date":
+		return SDateRangeWith(js.Nullable, dateRangeOptions(js))
+	case "float16":
+		return SchemaFloat16{Nullable: js.Nullable}
+	case "bfloat16":
+		return SchemaFloat16{Nullable: js.Nullable, BFloat: true}
 	case "float32":
 		if js.Nullable {
 			return SNullFloat32
@@ -190,9 +401,30 @@ func BuildSchema(js *SchemaJSON) Schema {
 		} else if js.Width > 0 {
 			s = s.WithWidth(js.Width)
 		}
+		if js.NullTag {
+			s = s.WithNullTag()
+		}
 		if js.DecodeDefault != "" {
 			s = s.DefaultDecodeValue(js.DecodeDefault)
 		}
+		if js.Min != nil || js.Max != nil {
+			// Min/Max bound the length, with CountRunes in characters
+			var min, max int
+			if js.Min != nil {
+				min = int(*js.Min)
+			}
+			if js.Max != nil {
+				max = int(*js.Max)
+			}
+			s = s.LenRange(min, max)
+			s.CountRunes = js.CountRunes
+		}
+		if js.NFC {
+			s = s.NormalizeNFC()
+		} else if js.UTF8 {
+			s = s.UTF8()
+		}
+		s.FoldCase, s.Trim = js.IgnoreCase, js.Trimmed
 		if js.Exact != "" {
 			return s.Match(js.Exact)
 		}
@@ -205,40 +437,73 @@ func BuildSchema(js *SchemaJSON) Schema {
 		if js.Pattern != "" {
 			return s.Pattern(js.Pattern)
 		}
+		if js.Check != "" {
+			return s.Check(js.Check)
+		}
 		return s
+	case "uuid":
+		return SchemaUUID{Nullable: js.Nullable}
 	case "email":
 		return SEmail(js.Nullable)
 	case "uri":
 		return SURI(js.Nullable)
 	case "lang":
 		return SLang(js.Nullable)
+	case "phone":
+		return SPhone(js.Nullable, js.Region)
+	case "semver":
+		if js.Range != "" {
+			return SSemVerRange(js.Nullable, js.Range)
+		}
+		return SSemVer(js.Nullable)
+	case "ulid":
+		return SULID(js.Nullable)
+	case "ksuid":
+		return SKSUID(js.Nullable)
+	case "ipv4":
+		return SIPv4(js.Nullable)
+	case "ipv6":
+		return SIPv6(js.Nullable)
+	case "cidr":
+		return SCIDR(js.Nullable)
+	case "mac":
+		return SMAC(js.Nullable)
+	case "ipv4Packed":
+		return SchemaIP{Version: 4, Nullable: js.Nullable}
+	case "ipv6Packed":
+		return SchemaIP{Version: 6, Nullable: js.Nullable}
+	case "bitset":
+		return SchemaBitset{Nullable: js.Nullable, Len: js.Width}
 	case "bytes":
+		s := SchemaBytes{Width: -1, NullTag: js.NullTag, AsBase64: js.Base64}
 		if js.Width > 0 {
-			return SBytes(js.Width)
-		}
-		return SVariableBytes()
-	case "number":
-		var xmin, xmax *float64
-		if js.Min != nil {
-			xret := float64(*js.Min)
-			xmin = &xret
+			s.Width = js.Width
 		}
-		if js.Max != nil {
-			xret := float64(*js.Max)
-			xmax = &xret
-		}
-		return SchemaNumber{false, xmin, xmax}
-	case "numberString":
-		var xmin, xmax *float64
-		if js.Min != nil {
-			xret := float64(*js.Min)
-			xmin = &xret
+		if js.Prefix != "" {
+			magic, err := hex.DecodeString(js.Prefix)
+			if err != nil {
+				panic(fmt.Sprintf("bytes: prefix %q is not hex: %v", js.Prefix, err))
+			}
+			s.Magic = magic
 		}
-		if js.Max != nil {
-			xret := float64(*js.Max)
-			xmax = &xret
+		return s
+	case "number", "numberString":
+		xmin, xmax := floatBounds(js)
+		return SchemaNumber{
+			DecodeAsString: js.Type == "numberString",
+			Min:            xmin,
+			Max:            xmax,
+			ExclusiveMin:   js.ExclusiveMin,
+			ExclusiveMax:   js.ExclusiveMax,
+			MultipleOf:     js.MultipleOf,
+			Epsilon:        js.Epsilon,
 		}
-		return SchemaNumber{true, xmin, xmax}
+	case "decimal", "decimalString":
+		return SchemaDecimal{Nullable: js.Nullable, AsString: js.Type == "decimalString"}
+	case "money":
+		return SchemaMoney{Currencies: js.Currencies, MinAmount: js.Min, MaxAmount: js.Max, Nullable: js.Nullable}
+	case "geoPoint", "geoPointCompact":
+		return SchemaGeoPoint{Compact: js.Type == "geoPointCompact", Nullable: js.Nullable}
 	case "any":
 		return SchemaAny{}
 	case "tuple":
@@ -260,36 +525,89 @@ func BuildSchema(js *SchemaJSON) Schema {
 		return STuple(buildSchemas(js.Schema)...)
 	case "repeat":
 		return SRepeatRange(js.Min, js.Max, buildSchemas(js.Schema)...)
+	case "array":
+		if len(js.Schema) != 1 {
+			panic(fmt.Sprintf("array needs 1 element schema, got %d", len(js.Schema)))
+		}
+		s := SArray(BuildSchema(&js.Schema[0]), -1, -1)
+		if js.Min != nil {
+			s.Min = int(*js.Min)
+		}
+		if js.Max != nil {
+			s.Max = int(*js.Max)
+		}
+		s.Nullable = js.Nullable
+		return s
+	case "fallback":
+		if len(js.Schema) != 2 {
+			panic(fmt.Sprintf("fallback needs 2 schemas, got %d", len(js.Schema)))
+		}
+		return SFallback(BuildSchema(&js.Schema[0]), BuildSchema(&js.Schema[1]))
 
 	case "map":
 		return SMap(buildSchemas(js.Schema)...)
 	case "mapUnordered":
-		mapped := make(map[string]Schema)
-		for i := range js.Schema {
-			mapped[js.FieldNames[i]] = BuildSchema(&js.Schema[i])
-		}
-		if js.Nullable {
-			return SMapUnorderedOptional(mapped)
-		}
-		return SMapUnordered(mapped)
+		s := SMapUnorderedNamed(js.FieldNames, buildSchemas(js.Schema)...)
+		s.Nullable = js.Nullable
+		return s
 	case "mapRepeat":
 		if len(js.Schema) == 2 {
-			return SMapRepeatRange(BuildSchema(&js.Schema[0]), BuildSchema(&js.Schema[1]), js.Min, js.Max)
+			s := SMapRepeatRange(BuildSchema(&js.Schema[0]), BuildSchema(&js.Schema[1]), js.Min, js.Max)
+			if js.Pattern != "" {
+				s = s.KeysMatch(js.Pattern)
+			}
+			if len(js.FieldNames) > 0 {
+				s = s.KeysOneOf(js.FieldNames...)
+			}
+			return s
 		} else {
 			panic(fmt.Sprintf("should be 2 schemas %v", len(js.FieldNames)))
 		}
+	case "mapSortedKeys":
+		min, max := -1, -1
+		if js.Min != nil {
+			min = int(*js.Min)
+		}
+		if js.Max != nil {
+			max = int(*js.Max)
+		}
+		s := SMapSortedKeys(js.Prefix, min, max)
+		if len(js.Schema) > 0 {
+			s = s.WithValue(BuildSchema(&js.Schema[0]))
+		}
+		return s
 	case "multicheck":
 		if len(js.FieldNames) > 0 {
 			return SMultiCheckNames(js.FieldNames)
 		}
 		return SMultiCheckNames([]string{})
 	case "enum":
+		if js.EnumType != "" {
+			et, ok := enumTypes[js.EnumType]
+			if !ok {
+				panic("unknown enum type: " + js.EnumType)
+			}
+			if len(js.FieldNames) > 0 && !slices.Equal(js.FieldNames, et.names) {
+				panic("enum names differ from enum type " + js.EnumType)
+			}
+			return et.build(js.Nullable)
+		}
 		if len(js.FieldNames) > 0 {
 			return SEnum(js.FieldNames, js.Nullable)
 		}
 		return SEnum([]string{}, js.Nullable)
+	case "enumValues":
+		s := SEnumValues(js.Codes).Deprecate(js.Deprecated...)
+		for alias, name := range js.Aliases {
+			s = s.Alias(alias, name)
+		}
+		s.DecodeCode = js.DecodeCode
+		s.Nullable = js.Nullable
+		return s
 	case "color":
 		return SColor(js.Nullable)
+	case "ref":
+		panic("unresolved ref " + js.Ref + ": load the document with SchemaModules")
 	default:
 		// Check custom registry before panicking
 		if builder, ok := customSchemaBuilders[js.Type]; ok {
@@ -299,6 +617,44 @@ func BuildSchema(js *SchemaJSON) Schema {
 	}
 }
 
+// floatBounds resolves numeric bounds for float-based schemas.
+// MinFloat/MaxFloat win over the integer Min/Max when both are given.
+func floatBounds(js *SchemaJSON) (min, max *float64) {
+	if js.MinFloat != nil {
+		min = js.MinFloat
+	} else if js.Min != nil {
+		v := float64(*js.Min)
+		min = &v
+	}
+	if js.MaxFloat != nil {
+		max = js.MaxFloat
+	} else if js.Max != nil {
+		v := float64(*js.Max)
+		max = &v
+	}
+	return min, max
+}
+
+// intConstraints collects integer constraints from js.
+// It reports false when js declares none, so plain schemas stay plain.
+func intConstraints(js *SchemaJSON) (IntConstraints, bool) {
+	c := IntConstraints{
+		Min:          js.Min,
+		Max:          js.Max,
+		ExclusiveMin: js.ExclusiveMin,
+		ExclusiveMax: js.ExclusiveMax,
+	}
+	if js.MultipleOf != nil {
+		m := *js.MultipleOf
+		if m != math.Trunc(m) {
+			panic(fmt.Sprintf("multipleOf must be integral for %s: %v", js.Type, m))
+		}
+		c.MultipleOf = PtrToInt64(int64(m))
+	}
+	ok := c.Min != nil || c.Max != nil || c.MultipleOf != nil
+	return c, ok
+}
+
 // buildSchemas is an internal helper that converts a slice of SchemaJSON
 // definitions into a slice of Schema instances by delegating to BuildSchema.
 // It preserves the order of the input list and is primarily used by composite
@@ -310,3 +666,39 @@ func buildSchemas(list []SchemaJSON) []Schema {
 	}
 	return out
 }
+
+// durationBound parses a "duration" bound, nil when str is empty.
+func durationBound(str string) *time.Duration {
+	if str == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(str)
+	if err != nil {
+		panic(fmt.Sprintf("duration: invalid bound %q: %v", str, err))
+	}
+	return &d
+}
+
+func dateRangeOptions(js *SchemaJSON) DateRangeOptions {
+	opts := DateRangeOptions{Location: time.UTC}
+	if js.Location != "" {
+		loc, err := time.LoadLocation(js.Location)
+		if err != nil {
+			panic(fmt.Sprintf("date: invalid location %q: %v", js.Location, err))
+		}
+		opts.Location = loc
+	}
+	parse := func(s string) *DateBound {
+		if s == "" {
+			return nil
+		}
+		b, err := ParseDateBound(s, opts.Location)
+		if err != nil {
+			panic("date: " + err.Error())
+		}
+		return &b
+	}
+	opts.From = parse(js.DateFrom)
+	opts.To = parse(js.DateTo)
+	return opts
+}
@@ -1,9 +1,16 @@
 package schema
 
 import (
+	"encoding/json"
+	"errors"
+	"math/big"
 	"testing"
+	"time"
 
+	pack "github.com/quickwritereader/PackOS/packable"
+	"github.com/quickwritereader/PackOS/typetags"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBuildSchema_WithRepeatTuples(t *testing.T) {
@@ -78,3 +85,310 @@ func TestBuildSchema_NamedTuple(t *testing.T) {
 	assert.EqualValues(t, expected, built,
 		"Built schema from JSON should equal manually constructed named tuple")
 }
+
+func TestBuildSchema_NumberFloatExclusiveBounds(t *testing.T) {
+	var js SchemaJSON
+	require.NoError(t, json.Unmarshal([]byte(
+		`{"type":"number","minFloat":0.0,"maxFloat":1.0,"exclusiveMin":true,"exclusiveMax":true}`), &js))
+
+	chain := SChain(BuildSchema(&js))
+
+	require.NoError(t, ValidateBuffer(pack.Pack(pack.PackFloat64(0.5)), chain))
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackFloat64(0.0)), chain), "0.0 excluded")
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackFloat64(1.0)), chain), "1.0 excluded")
+
+	_, err := EncodeValue(1.0, chain)
+	require.Error(t, err)
+	_, err = EncodeValue(0.25, chain)
+	require.NoError(t, err)
+}
+
+func TestBuildSchema_NumberEpsilon(t *testing.T) {
+	var js SchemaJSON
+	require.NoError(t, json.Unmarshal([]byte(
+		`{"type":"number","minFloat":0.3,"maxFloat":1.0,"exclusiveMax":true,"epsilon":1e-9}`), &js))
+	chain := SChain(BuildSchema(&js))
+
+	// 0.1+0.2 is 0.30000000000000004 but within epsilon of the inclusive bound
+	require.NoError(t, ValidateBuffer(pack.Pack(pack.PackFloat64(0.1+0.2)), chain))
+	require.NoError(t, ValidateBuffer(pack.Pack(pack.PackFloat64(0.3-1e-12)), chain))
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackFloat64(0.29)), chain))
+	// and close to the exclusive one counts as reaching it
+	_, err := EncodeValue(1.0-1e-12, chain)
+	require.Error(t, err)
+	_, err = EncodeValue(0.99, chain)
+	require.NoError(t, err)
+
+	fcs := ExtractConstraints(&js)
+	require.Len(t, fcs, 1)
+	assert.Equal(t, 1e-9, fcs[0].Epsilon)
+
+	exact := SChain(SchemaNumber{Min: PtrToFloat64(0.3)})
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackFloat64(0.3-1e-12)), exact))
+}
+
+func TestBuildSchema_NumberNegativeFloatBounds(t *testing.T) {
+	js := SchemaJSON{Type: "numberString", MinFloat: PtrToFloat64(-2.5), MaxFloat: PtrToFloat64(-0.5)}
+	chain := SChain(BuildSchema(&js))
+
+	decoded, err := DecodeBuffer(pack.Pack(pack.PackFloat64(-1.25)), chain)
+	require.NoError(t, err)
+	assert.Equal(t, "-1.25", decoded)
+
+	// inclusive by default
+	require.NoError(t, ValidateBuffer(pack.Pack(pack.PackFloat64(-2.5)), chain))
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackFloat64(-3)), chain))
+
+	// float bounds take precedence over integer ones
+	js.Min = PtrToInt64(-100)
+	chain = SChain(BuildSchema(&js))
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackFloat64(-3)), chain))
+}
+
+func TestBuildSchema_IntExclusiveAndMultipleOf(t *testing.T) {
+	var js SchemaJSON
+	require.NoError(t, json.Unmarshal([]byte(
+		`{"type":"int32","min":0,"max":100,"exclusiveMin":true,"multipleOf":5}`), &js))
+	chain := SChain(BuildSchema(&js))
+
+	require.NoError(t, ValidateBuffer(pack.Pack(pack.PackInt32(100)), chain))
+	require.NoError(t, ValidateBuffer(pack.Pack(pack.PackInt32(5)), chain))
+
+	err := ValidateBuffer(pack.Pack(pack.PackInt32(0)), chain)
+	require.Error(t, err, "0 is excluded")
+	var se *SchemaError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, ErrOutOfRange, se.Code)
+
+	err = ValidateBuffer(pack.Pack(pack.PackInt32(12)), chain)
+	require.Error(t, err)
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, ErrNotMultipleOf, se.Code)
+
+	_, err = EncodeValue(int32(7), chain)
+	require.Error(t, err)
+	_, err = EncodeValue(int32(45), chain)
+	require.NoError(t, err)
+
+	// multipleOf alone still produces a constrained schema
+	chain = SChain(BuildSchema(&SchemaJSON{Type: "int16", MultipleOf: PtrToFloat64(2)}))
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackInt16(3)), chain))
+
+	assert.Panics(t, func() {
+		BuildSchema(&SchemaJSON{Type: "int64", MultipleOf: PtrToFloat64(0.5)})
+	})
+}
+
+func TestBuildSchema_NumberMultipleOf(t *testing.T) {
+	chain := SChain(BuildSchema(&SchemaJSON{Type: "number", MultipleOf: PtrToFloat64(0.1)}))
+
+	require.NoError(t, ValidateBuffer(pack.Pack(pack.PackFloat64(0.3)), chain), "rounding is tolerated")
+	require.NoError(t, ValidateBuffer(pack.Pack(pack.PackInt8(2)), chain))
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackFloat64(0.35)), chain))
+
+	_, err := EncodeValue(0.15, chain)
+	require.Error(t, err)
+}
+
+func TestBuildSchema_DateLocationAndRelative(t *testing.T) {
+	var js SchemaJSON
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"date","location":"Asia/Tokyo","dateFrom":"2024-01-01","dateTo":"now+1h"}`), &js))
+	chain := SChain(BuildSchema(&js))
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, tokyo)
+
+	require.NoError(t, ValidateBuffer(pack.Pack(pack.PackInt64(start.Unix())), chain))
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackInt64(start.Unix()-1)), chain))
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackInt64(time.Now().Add(2*time.Hour).Unix())), chain))
+
+	decoded, err := DecodeBuffer(pack.Pack(pack.PackInt64(start.Unix())), chain)
+	require.NoError(t, err)
+	assert.Equal(t, tokyo, decoded.(time.Time).Location())
+
+	assert.Panics(t, func() { BuildSchema(&SchemaJSON{Type: "date", Location: "Nowhere/City"}) })
+	assert.Panics(t, func() { BuildSchema(&SchemaJSON{Type: "date", DateFrom: "now~1d"}) })
+}
+
+func TestBuildSchema_Varint(t *testing.T) {
+	chain := SChain(BuildSchema(&SchemaJSON{Type: "varint", Min: PtrToInt64(0)}))
+
+	buf, err := EncodeValue(int64(40000), chain)
+	require.NoError(t, err)
+	assert.Len(t, buf, 4+3, "two headers and a 3 byte varint")
+	v, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, int64(40000), v)
+
+	// fixed-width integers are accepted too
+	v, err = DecodeBuffer(pack.Pack(pack.PackInt16(7)), chain)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), v)
+
+	err = ValidateBuffer(pack.Pack(pack.PackInt32(-1)), chain)
+	var se *SchemaError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, ErrOutOfRange, se.Code)
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackNullableInt64(nil)), chain))
+
+	chain = SChain(BuildSchema(&SchemaJSON{Type: "varint", Nullable: true}))
+	buf, err = EncodeValue(nil, chain)
+	require.NoError(t, err)
+	v, err = DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestBuildSchema_Float16(t *testing.T) {
+	for _, typ := range []string{"float16", "bfloat16"} {
+		chain := SChain(BuildSchema(&SchemaJSON{Type: typ}))
+		buf, err := EncodeValue(0.75, chain)
+		require.NoError(t, err, typ)
+		assert.Len(t, buf, 4+2, typ)
+		v, err := DecodeBuffer(buf, chain)
+		require.NoError(t, err, typ)
+		assert.Equal(t, float32(0.75), v, typ)
+
+		require.Error(t, ValidateBuffer(pack.Pack(pack.PackFloat32(0.75)), chain), typ)
+		_, err = EncodeValue("x", chain)
+		require.Error(t, err, typ)
+	}
+
+	chain := SChain(BuildSchema(&SchemaJSON{Type: "float16", Nullable: true}))
+	buf, err := EncodeValue(nil, chain)
+	require.NoError(t, err)
+	v, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestBuildSchema_MapUnorderedEncodeOrder(t *testing.T) {
+	js := SchemaJSON{
+		Type:       "mapUnordered",
+		FieldNames: []string{"name", "age"},
+		Schema:     []SchemaJSON{{Type: "string"}, {Type: "int16"}},
+	}
+	chain := SChain(BuildSchema(&js))
+	buf, err := EncodeValue(map[string]any{"age": int16(30), "name": "bob"}, chain)
+	require.NoError(t, err)
+	assert.Equal(t, pack.Pack(pack.PackMapOrdered(pack.PP("name", pack.PackString("bob")), pack.PP("age", pack.PackInt16(30)))), buf)
+}
+
+func TestBuildSchema_Decimal(t *testing.T) {
+	chain := SChain(BuildSchema(&SchemaJSON{Type: "decimal"}))
+	for in, want := range map[any]string{
+		"19.990":           "19.990",
+		json.Number("1e3"): "1000",
+		0.1:                "0.1",
+		int32(-7):          "-7",
+	} {
+		buf, err := EncodeValue(in, chain)
+		require.NoError(t, err, in)
+		v, err := DecodeBuffer(buf, chain)
+		require.NoError(t, err, in)
+		require.IsType(t, typetags.Decimal{}, v)
+		assert.Equal(t, want, v.(typetags.Decimal).String(), in)
+	}
+
+	buf, err := EncodeValue(big.NewRat(1, 4), chain)
+	require.NoError(t, err)
+	asString := SChain(BuildSchema(&SchemaJSON{Type: "decimalString"}))
+	v, err := DecodeBuffer(buf, asString)
+	require.NoError(t, err)
+	assert.Equal(t, "0.25", v)
+
+	_, err = EncodeValue(big.NewRat(1, 3), chain)
+	require.Error(t, err)
+	_, err = EncodeValue("abc", chain)
+	require.Error(t, err)
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackInt16(1)), chain))
+	require.Error(t, ValidateBuffer(pack.Pack(pack.PackTuple(pack.PackString("x"))), chain))
+
+	nullable := SChain(BuildSchema(&SchemaJSON{Type: "decimal", Nullable: true}))
+	buf, err = EncodeValue(nil, nullable)
+	require.NoError(t, err)
+	v, err = DecodeBuffer(buf, nullable)
+	require.NoError(t, err)
+	assert.Nil(t, v)
+	require.Error(t, ValidateBuffer(buf, chain))
+}
+
+func TestBuildSchema_StringCheck(t *testing.T) {
+	RegisterStringCheck("employeeId", func(s string) bool {
+		return len(s) == 7 && s[0] == 'E'
+	})
+	defer UnregisterStringCheck("employeeId")
+	assert.Panics(t, func() { RegisterStringCheck("employeeId", func(string) bool { return true }) })
+
+	var js SchemaJSON
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"string","check":"employeeId"}`), &js))
+	chain := SChain(BuildSchema(&js))
+
+	buf, err := EncodeValue("E123456", chain)
+	require.NoError(t, err)
+	v, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, "E123456", v)
+
+	err = ValidateBuffer(pack.Pack(pack.PackString("X123456")), chain)
+	assertSchemaError(t, err, ErrStringCheck)
+	_, err = EncodeValue("E12", chain)
+	require.Error(t, err)
+
+	fcs := ExtractConstraints(&SchemaJSON{Type: "tuple", FieldNames: []string{"id"}, Schema: []SchemaJSON{js}})
+	require.Len(t, fcs, 1)
+	assert.Equal(t, "employeeId", fcs[0].Check)
+	assert.Panics(t, func() { BuildSchema(&SchemaJSON{Type: "string", Check: "missing"}) })
+}
+
+type testRole int8
+
+const (
+	testAdmin testRole = iota
+	testUser
+)
+
+func TestBuildSchema_EnumType(t *testing.T) {
+	RegisterEnumType[testRole]("role", []string{"admin", "user"})
+	defer UnregisterEnumType("role")
+	assert.Panics(t, func() { RegisterEnumType[testRole]("role", nil) })
+
+	var js SchemaJSON
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"enum","enumType":"role"}`), &js))
+	chain := SChain(BuildSchema(&js), BuildSchema(&js))
+
+	buf, err := EncodeValue([]any{testUser, "admin"}, chain)
+	require.NoError(t, err)
+	v, err := DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, []any{testUser, testAdmin}, v)
+
+	_, err = EncodeValue([]any{testRole(5), testUser}, chain)
+	require.Error(t, err)
+
+	fcs := ExtractConstraints(&js)
+	require.Len(t, fcs, 1)
+	assert.Equal(t, []string{"admin", "user"}, fcs[0].Enum)
+	assert.Panics(t, func() { BuildSchema(&SchemaJSON{Type: "enum", EnumType: "role", FieldNames: []string{"user"}}) })
+	assert.Panics(t, func() { BuildSchema(&SchemaJSON{Type: "enum", EnumType: "missing"}) })
+}
+
+func TestBuildSchema_StringLength(t *testing.T) {
+	var js SchemaJSON
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"string","min":1,"max":3,"countRunes":true}`), &js))
+	chain := SChain(BuildSchema(&js))
+
+	_, err := EncodeValue("äöü", chain)
+	require.NoError(t, err)
+	_, err = EncodeValue("äöüß", chain)
+	assert.Error(t, err)
+	_, err = EncodeValue("", chain)
+	assert.Error(t, err)
+
+	fcs := ExtractConstraints(&js)
+	require.Len(t, fcs, 1)
+	assert.Equal(t, 1, *fcs[0].MinLen)
+	assert.Equal(t, 3, *fcs[0].MaxLen)
+	assert.True(t, fcs[0].CountRunes)
+}
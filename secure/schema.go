@@ -0,0 +1,108 @@
+package secure
+
+import (
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/schema"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+const SchemaEncryptedName = "SchemaEncrypted"
+
+// SchemaEncrypted stores a field described by Schema as an encryption
+// envelope, the same byte array EncryptFields writes. Encode encodes with
+// Schema and seals the result; Validate and Decode open the envelope and
+// hand the field to Schema. Without the key the field is an opaque byte
+// array, which schema.SBytes(-1) accepts.
+//
+// Path and Context bind envelopes to the field as FieldAD describes; use
+// the path EncryptFields would be given for the field to share envelopes
+// with it.
+type SchemaEncrypted struct {
+	Schema   schema.Schema
+	Envelope *Envelope
+	Path     string
+	Context  []byte
+}
+
+// SEncrypted wraps s so that its field, found at path, is encrypted with
+// env.
+func SEncrypted(s schema.Schema, env *Envelope, path string) SchemaEncrypted {
+	return SchemaEncrypted{Schema: s, Envelope: env, Path: path}
+}
+
+// WithContext returns a copy of s whose envelopes are also bound to
+// context.
+func (s SchemaEncrypted) WithContext(context []byte) SchemaEncrypted {
+	s.Context = context
+	return s
+}
+
+func (s SchemaEncrypted) IsNullable() bool { return s.Schema.IsNullable() }
+
+func (s SchemaEncrypted) Validate(seq *access.SeqGetAccess) error {
+	pos := seq.CurrentIndex()
+	inner, err := s.open(seq)
+	if err != nil {
+		return err
+	}
+	if err := s.Schema.Validate(inner); err != nil {
+		return schema.NewSchemaError(schema.ErrInvalidFormat, SchemaEncryptedName, "", pos, err)
+	}
+	return nil
+}
+
+func (s SchemaEncrypted) Decode(seq *access.SeqGetAccess) (any, error) {
+	pos := seq.CurrentIndex()
+	inner, err := s.open(seq)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.Schema.Decode(inner)
+	if err != nil {
+		return nil, schema.NewSchemaError(schema.ErrInvalidFormat, SchemaEncryptedName, "", pos, err)
+	}
+	return v, nil
+}
+
+// open decrypts the current field, advances seq past it and returns a
+// reader positioned on the decrypted field.
+func (s SchemaEncrypted) open(seq *access.SeqGetAccess) (*access.SeqGetAccess, error) {
+	pos := seq.CurrentIndex()
+	typ, width, err := seq.PeekTypeWidth()
+	if err != nil {
+		return nil, schema.NewSchemaError(schema.ErrUnexpectedEOF, SchemaEncryptedName, "", pos, err)
+	}
+	if typ != typetags.TypeByteArray {
+		return nil, schema.NewSchemaError(schema.ErrConstraintViolated, SchemaEncryptedName, "", pos, schema.ErrTypeMisMatch)
+	}
+	payload, err := seq.GetPayload(width)
+	if err != nil {
+		return nil, schema.NewSchemaError(schema.ErrUnexpectedEOF, SchemaEncryptedName, "", pos, err)
+	}
+	field, err := s.Envelope.Open(payload, FieldAD(s.Path, s.Context))
+	if err != nil {
+		return nil, schema.NewSchemaError(schema.ErrInvalidFormat, SchemaEncryptedName, "", pos, err)
+	}
+	if err := seq.Advance(); err != nil {
+		return nil, schema.NewSchemaError(schema.ErrUnexpectedEOF, SchemaEncryptedName, "", pos, err)
+	}
+	inner, err := access.NewSeqGetAccess(field)
+	if err != nil {
+		return nil, schema.NewSchemaError(schema.ErrInvalidFormat, SchemaEncryptedName, "", pos, err)
+	}
+	return inner, nil
+}
+
+func (s SchemaEncrypted) Encode(put *access.PutAccess, val any) error {
+	scratch := access.NewPutAccessFromPool()
+	defer access.ReleasePutAccess(scratch)
+	if err := s.Schema.Encode(scratch, val); err != nil {
+		return schema.NewSchemaError(schema.ErrEncode, SchemaEncryptedName, "", -1, err)
+	}
+	sealed, err := s.Envelope.Seal(scratch.Pack(), FieldAD(s.Path, s.Context))
+	if err != nil {
+		return schema.NewSchemaError(schema.ErrEncode, SchemaEncryptedName, "", -1, err)
+	}
+	put.AddBytes(sealed)
+	return nil
+}
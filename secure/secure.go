@@ -0,0 +1,170 @@
+// Package secure encrypts individual fields of packed buffers.
+//
+// An encrypted field is replaced by a byte array holding an envelope: a
+// three byte prefix ("PE" and a version), the AES-GCM nonce and the sealed
+// field. The sealed plaintext is the field packed on its own, tag included,
+// so maps and tuples come back exactly as they were. Field positions and
+// every other payload are unchanged, so readers without the key can still
+// walk the buffer and decode the fields they are allowed to see.
+//
+//	out, err := secure.EncryptFields(buf, []string{"1.contact", "2"}, key)
+//	...
+//	plain, err := secure.DecryptFields(out, []string{"1.contact", "2"}, key)
+//
+// Paths follow access.Patcher.FieldPath: indexes inside tuples and keys
+// inside maps. Each envelope is bound to its path, and to an optional
+// caller context such as a record ID given to EncryptFieldsContext, so an
+// envelope copied to another field or record does not open. SchemaEncrypted
+// reads and writes the same envelopes as part of a schema chain.
+package secure
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/typetags"
+)
+
+var envelopeMagic = []byte{'P', 'E', 1}
+
+// ErrNotSealed is returned when a field expected to be encrypted is not.
+var ErrNotSealed = errors.New("field is not an encryption envelope")
+
+// Envelope seals and opens packed fields with AES-GCM. It is safe for
+// concurrent use.
+type Envelope struct {
+	aead cipher.AEAD
+}
+
+// NewEnvelope creates an Envelope. key must be 16, 24 or 32 bytes long.
+func NewEnvelope(key []byte) (*Envelope, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{aead: aead}, nil
+}
+
+// Seal encrypts a packed buffer holding one field and returns the envelope.
+// additional is authenticated but not stored; Open must be given the same.
+func (e *Envelope) Seal(field, additional []byte) ([]byte, error) {
+	n := e.aead.NonceSize()
+	out := make([]byte, len(envelopeMagic)+n, len(envelopeMagic)+n+len(field)+e.aead.Overhead())
+	copy(out, envelopeMagic)
+	nonce := out[len(envelopeMagic):]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return e.aead.Seal(out, nonce, field, append(slices.Clip(envelopeMagic), additional...)), nil
+}
+
+// Open decrypts an envelope made by Seal with the same additional data and
+// returns the packed field.
+func (e *Envelope) Open(env, additional []byte) ([]byte, error) {
+	if !IsSealed(env) {
+		return nil, ErrNotSealed
+	}
+	rest := env[len(envelopeMagic):]
+	n := e.aead.NonceSize()
+	if len(rest) < n {
+		return nil, ErrNotSealed
+	}
+	return e.aead.Open(nil, rest[:n], rest[n:], append(slices.Clip(envelopeMagic), additional...))
+}
+
+// FieldAD returns the additional data binding an envelope to path and
+// context, as EncryptFieldsContext and SchemaEncrypted use it.
+func FieldAD(path string, context []byte) []byte {
+	ad := binary.AppendUvarint(nil, uint64(len(path)))
+	ad = append(ad, path...)
+	return append(ad, context...)
+}
+
+// IsSealed reports whether a byte array payload starts like an envelope.
+func IsSealed(payload []byte) bool {
+	return bytes.HasPrefix(payload, envelopeMagic)
+}
+
+// EncryptFields replaces the field at each path, typically a nested map or
+// tuple holding personal data, with its encrypted envelope. Paths are
+// applied in order; a path inside a field encrypted before it fails.
+func EncryptFields(buf []byte, paths []string, key []byte) ([]byte, error) {
+	return EncryptFieldsContext(buf, paths, key, nil)
+}
+
+// EncryptFieldsContext is EncryptFields with envelopes also bound to
+// context, such as the ID of the record buf holds.
+func EncryptFieldsContext(buf []byte, paths []string, key, context []byte) ([]byte, error) {
+	env, err := NewEnvelope(key)
+	if err != nil {
+		return nil, fmt.Errorf("EncryptFields: %w", err)
+	}
+	for _, path := range paths {
+		tag, val, err := access.FieldAtPath(buf, path)
+		if err != nil {
+			return nil, fmt.Errorf("EncryptFields: %w", err)
+		}
+		if tag == typetags.TypeByteArray && IsSealed(val) {
+			return nil, fmt.Errorf("EncryptFields: path %q is already encrypted", path)
+		}
+		sealed, err := env.Seal(packField(tag, val), FieldAD(path, context))
+		if err != nil {
+			return nil, fmt.Errorf("EncryptFields: path %q: %w", path, err)
+		}
+		if buf, err = access.ReplacePath(buf, path, packField(typetags.TypeByteArray, sealed)); err != nil {
+			return nil, fmt.Errorf("EncryptFields: %w", err)
+		}
+	}
+	return buf, nil
+}
+
+// DecryptFields restores the fields EncryptFields encrypted at paths. It
+// fails if a field is not an envelope or does not open with key.
+func DecryptFields(buf []byte, paths []string, key []byte) ([]byte, error) {
+	return DecryptFieldsContext(buf, paths, key, nil)
+}
+
+// DecryptFieldsContext restores the fields EncryptFieldsContext encrypted
+// with the same context.
+func DecryptFieldsContext(buf []byte, paths []string, key, context []byte) ([]byte, error) {
+	env, err := NewEnvelope(key)
+	if err != nil {
+		return nil, fmt.Errorf("DecryptFields: %w", err)
+	}
+	for _, path := range paths {
+		tag, val, err := access.FieldAtPath(buf, path)
+		if err != nil {
+			return nil, fmt.Errorf("DecryptFields: %w", err)
+		}
+		if tag != typetags.TypeByteArray {
+			return nil, fmt.Errorf("DecryptFields: path %q: %w", path, ErrNotSealed)
+		}
+		field, err := env.Open(val, FieldAD(path, context))
+		if err != nil {
+			return nil, fmt.Errorf("DecryptFields: path %q: %w", path, err)
+		}
+		if buf, err = access.ReplacePath(buf, path, field); err != nil {
+			return nil, fmt.Errorf("DecryptFields: %w", err)
+		}
+	}
+	return buf, nil
+}
+
+// packField packs a single field.
+func packField(tag typetags.Type, val []byte) []byte {
+	put := access.NewPutAccessFromPool()
+	defer access.ReleasePutAccess(put)
+	put.AppendTagAndValue(tag, val)
+	return put.Pack()
+}
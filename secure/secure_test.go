@@ -0,0 +1,136 @@
+package secure
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/quickwritereader/PackOS/schema"
+	"github.com/quickwritereader/PackOS/typetags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = bytes.Repeat([]byte{7}, 32)
+
+func customerBuf(t *testing.T) []byte {
+	put := access.NewPutAccess()
+	put.SetDeterministic(true)
+	put.AddInt64(42)
+	require.NoError(t, put.AddMapAny(map[string]any{
+		"plan":    "pro",
+		"contact": map[string]any{"email": "ann@example.com", "phone": "555-0100"},
+	}, false))
+	put.AddString("ssn-123-45-6789")
+	return put.Pack()
+}
+
+func TestEncryptFields_RoundTrip(t *testing.T) {
+	buf := customerBuf(t)
+	paths := []string{"1.contact", "2"}
+
+	enc, err := EncryptFields(buf, paths, testKey)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(enc, []byte("ann@example.com")))
+	assert.False(t, bytes.Contains(enc, []byte("ssn-123")))
+
+	// the rest of the buffer stays readable
+	decoded, err := access.Decode(enc)
+	require.NoError(t, err)
+	fields := decoded.([]any)
+	assert.Equal(t, int64(42), fields[0])
+	assert.Equal(t, "pro", fields[1].(map[string]any)["plan"])
+
+	_, err = EncryptFields(enc, []string{"2"}, testKey)
+	assert.Error(t, err, "already encrypted")
+
+	plain, err := DecryptFields(enc, paths, testKey)
+	require.NoError(t, err)
+	assert.Equal(t, buf, plain)
+}
+
+func TestDecryptFields_Failures(t *testing.T) {
+	buf := customerBuf(t)
+	enc, err := EncryptFields(buf, []string{"2"}, testKey)
+	require.NoError(t, err)
+
+	_, err = DecryptFields(enc, []string{"2"}, bytes.Repeat([]byte{8}, 32))
+	assert.Error(t, err)
+	_, err = DecryptFields(enc, []string{"0"}, testKey)
+	assert.ErrorIs(t, err, ErrNotSealed)
+	_, err = DecryptFields(enc, []string{"2"}, []byte("short"))
+	assert.Error(t, err)
+
+	// a tampered byte fails authentication
+	tampered := bytes.Clone(enc)
+	tampered[len(tampered)-1] ^= 1
+	_, err = DecryptFields(tampered, []string{"2"}, testKey)
+	assert.Error(t, err)
+}
+
+func TestSchemaEncrypted(t *testing.T) {
+	env, err := NewEnvelope(testKey)
+	require.NoError(t, err)
+	contact := schema.SMapUnordered(map[string]schema.Schema{"email": schema.SString, "phone": schema.SString})
+	chain := schema.SChain(schema.SInt64, SEncrypted(contact, env, "1"))
+	val := []any{int64(1), map[string]any{"email": "bob@example.com", "phone": "555-0199"}}
+
+	buf, err := schema.EncodeValue(val, chain)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(buf, []byte("bob@example.com")))
+	require.NoError(t, schema.ValidateBuffer(buf, chain))
+	got, err := schema.DecodeBuffer(buf, chain)
+	require.NoError(t, err)
+	assert.Equal(t, val, got)
+
+	// readers without the key see an opaque byte array
+	require.NoError(t, schema.ValidateBuffer(buf, schema.SChain(schema.SInt64, schema.SBytes(-1))))
+
+	// EncryptFields and SchemaEncrypted share the envelope format
+	plainBuf, err := schema.EncodeValue(val, schema.SChain(schema.SInt64, contact))
+	require.NoError(t, err)
+	enc, err := EncryptFields(plainBuf, []string{"1"}, testKey)
+	require.NoError(t, err)
+	got, err = schema.DecodeBuffer(enc, chain)
+	require.NoError(t, err)
+	assert.Equal(t, val, got)
+
+	_, err = schema.DecodeBuffer(plainBuf, chain)
+	assert.Error(t, err)
+}
+
+func TestEncryptFields_EnvelopeBoundToPathAndContext(t *testing.T) {
+	put := access.NewPutAccess()
+	put.AddString("alice-ssn")
+	put.AddString("bob-ssn")
+	buf := put.Pack()
+
+	enc, err := EncryptFieldsContext(buf, []string{"0", "1"}, testKey, []byte("record-1"))
+	require.NoError(t, err)
+	_, err = DecryptFieldsContext(enc, []string{"0", "1"}, testKey, []byte("record-1"))
+	require.NoError(t, err)
+
+	// paste field 0's envelope over field 1
+	_, first, err := access.FieldAtPath(enc, "0")
+	require.NoError(t, err)
+	swapped, err := access.ReplacePath(enc, "1", packField(typetags.TypeByteArray, first))
+	require.NoError(t, err)
+	_, err = DecryptFieldsContext(swapped, []string{"1"}, testKey, []byte("record-1"))
+	assert.Error(t, err)
+
+	// the same envelope under another record
+	_, err = DecryptFieldsContext(enc, []string{"0"}, testKey, []byte("record-2"))
+	assert.Error(t, err)
+	_, err = DecryptFields(enc, []string{"0"}, testKey)
+	assert.Error(t, err)
+
+	env, err := NewEnvelope(testKey)
+	require.NoError(t, err)
+	chain := schema.SChain(SEncrypted(schema.SString, env, "0").WithContext([]byte("record-1")), schema.SBytes(-1))
+	v, err := schema.DecodeBuffer(enc, chain)
+	require.NoError(t, err)
+	assert.Equal(t, "alice-ssn", v.([]any)[0])
+	chain = schema.SChain(SEncrypted(schema.SString, env, "1").WithContext([]byte("record-1")), schema.SBytes(-1))
+	_, err = schema.DecodeBuffer(enc, chain)
+	assert.Error(t, err)
+}
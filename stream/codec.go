@@ -0,0 +1,179 @@
+package stream
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Codec transforms a frame body. Its ID is written into the frame header so
+// the reader can pick the same codec to undo the transformation.
+type Codec interface {
+	ID() byte
+	Encode(src []byte) ([]byte, error)
+	Decode(src []byte) ([]byte, error)
+}
+
+// Registry maps codec IDs to compression and encryption codecs.
+type Registry struct {
+	mu         sync.RWMutex
+	compressor map[byte]Codec
+	encryptor  map[byte]Codec
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{compressor: map[byte]Codec{}, encryptor: map[byte]Codec{}}
+}
+
+// DefaultRegistry is used by readers that were not given a registry.
+// It holds GzipCodec.
+var DefaultRegistry = func() *Registry {
+	r := NewRegistry()
+	r.RegisterCompression(GzipCodec{})
+	return r
+}()
+
+// RegisterCompression adds or replaces the compression codec for c.ID().
+func (r *Registry) RegisterCompression(c Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.compressor[c.ID()] = c
+}
+
+// RegisterEncryption adds or replaces the encryption codec for c.ID().
+func (r *Registry) RegisterEncryption(c Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.encryptor[c.ID()] = c
+}
+
+func (r *Registry) compression(id byte) (Codec, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if c, ok := r.compressor[id]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("unknown compression codec %d", id)
+}
+
+func (r *Registry) encryption(id byte) (Codec, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if c, ok := r.encryptor[id]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("unknown encryption codec %d", id)
+}
+
+// GzipCodecID is the compression ID of GzipCodec.
+const GzipCodecID byte = 1
+
+// ErrInflateTooLarge is returned by GzipCodec.Decode for bodies that
+// inflate beyond MaxSize.
+var ErrInflateTooLarge = errors.New("stream: decompressed body too large")
+
+// GzipCodec compresses frame bodies with gzip. MaxSize bounds the size a
+// body may inflate to; zero means DefaultMaxFrameSize.
+type GzipCodec struct {
+	MaxSize int
+}
+
+func (GzipCodec) ID() byte { return GzipCodecID }
+
+func (GzipCodec) Encode(src []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(src); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (g GzipCodec) Decode(src []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	limit := g.MaxSize
+	if limit <= 0 {
+		limit = DefaultMaxFrameSize
+	}
+	out, err := io.ReadAll(io.LimitReader(zr, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > limit {
+		return nil, ErrInflateTooLarge
+	}
+	return out, nil
+}
+
+// AuthCodec is an encryption Codec that also authenticates data it does
+// not encrypt. FrameWriter and FrameReader pass it the frame header, so
+// the flags, codec IDs and schema ID cannot be altered in transit.
+type AuthCodec interface {
+	Codec
+	EncodeAuth(src, additional []byte) ([]byte, error)
+	DecodeAuth(src, additional []byte) ([]byte, error)
+}
+
+// AESGCMCodec encrypts frame bodies with AES-GCM. The random nonce is
+// prepended to the ciphertext.
+type AESGCMCodec struct {
+	id   byte
+	aead cipher.AEAD
+}
+
+// NewAESGCMCodec creates an encryption codec registered under id.
+// key must be 16, 24 or 32 bytes long.
+func NewAESGCMCodec(id byte, key []byte) (*AESGCMCodec, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESGCMCodec{id: id, aead: aead}, nil
+}
+
+func (c *AESGCMCodec) ID() byte { return c.id }
+
+func (c *AESGCMCodec) Encode(src []byte) ([]byte, error) {
+	return c.EncodeAuth(src, nil)
+}
+
+func (c *AESGCMCodec) Decode(src []byte) ([]byte, error) {
+	return c.DecodeAuth(src, nil)
+}
+
+// EncodeAuth encrypts src and authenticates additional with it.
+func (c *AESGCMCodec) EncodeAuth(src, additional []byte) ([]byte, error) {
+	out := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(src)+c.aead.Overhead())
+	if _, err := rand.Read(out); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(out, out, src, additional), nil
+}
+
+// DecodeAuth decrypts src, failing unless additional matches what was
+// passed to EncodeAuth.
+func (c *AESGCMCodec) DecodeAuth(src, additional []byte) ([]byte, error) {
+	n := c.aead.NonceSize()
+	if len(src) < n {
+		return nil, errors.New("ciphertext shorter than nonce")
+	}
+	return c.aead.Open(nil, src[:n], src[n:], additional)
+}
@@ -0,0 +1,263 @@
+// Package stream frames PackOS buffers for byte streams such as TCP or
+// WebSocket connections.
+//
+// Frame layout:
+//
+//	uint32 LE   length of everything after this field
+//	byte        flags
+//	[byte]      compression codec ID, present when FlagCompressed is set
+//	[byte]      encryption codec ID, present when FlagEncrypted is set
+//	[uvarint]   schema ID, present when FlagSchemaID is set
+//	...         body
+//
+// Bodies are compressed before they are encrypted. Readers undo both steps with
+// the codecs found in their Registry, so a stream may mix plain, compressed and
+// encrypted frames; FrameReader.RequireEncryption refuses plain frames on
+// streams that must be encrypted. The optional schema ID stays outside the
+// encryption so receivers can route frames with a SchemaRouter before
+// touching the payload; an AuthCodec still authenticates it, with the flags
+// and codec IDs.
+package stream
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Flags describe the transformations applied to a frame body.
+type Flags byte
+
+const (
+	FlagCompressed Flags = 1 << iota
+	FlagEncrypted
+	FlagSchemaID
+)
+
+// DefaultMaxFrameSize bounds the frames a FrameReader accepts.
+const DefaultMaxFrameSize = 16 << 20
+
+var ErrFrameTooLarge = errors.New("stream: frame too large")
+
+// ErrFrameNotEncrypted is returned by a FrameReader that requires
+// encryption when a frame is plain or uses another codec.
+var ErrFrameNotEncrypted = errors.New("stream: frame is not encrypted with a required codec")
+
+// Frame is a decoded frame. Payload is the unwrapped PackOS buffer.
+// SchemaID is meaningful only when Flags has FlagSchemaID.
+type Frame struct {
+	Flags    Flags
+	SchemaID uint64
+	Payload  []byte
+}
+
+// HasSchemaID reports whether the frame carries a schema ID.
+func (f Frame) HasSchemaID() bool {
+	return f.Flags&FlagSchemaID != 0
+}
+
+// FrameWriter writes length-prefixed frames to w.
+type FrameWriter struct {
+	w        io.Writer
+	compress Codec
+	encrypt  Codec
+	// MinCompressSize skips compression for smaller payloads, where it rarely pays off.
+	MinCompressSize int
+	hdr             []byte
+}
+
+// NewFrameWriter creates a writer that emits plain frames.
+func NewFrameWriter(w io.Writer) *FrameWriter {
+	return &FrameWriter{w: w}
+}
+
+// Compress enables compression with c for subsequent frames; nil disables it.
+func (fw *FrameWriter) Compress(c Codec) *FrameWriter {
+	fw.compress = c
+	return fw
+}
+
+// Encrypt enables encryption with c for subsequent frames; nil disables it.
+func (fw *FrameWriter) Encrypt(c Codec) *FrameWriter {
+	fw.encrypt = c
+	return fw
+}
+
+// WriteFrame wraps payload according to the writer settings and writes one frame.
+func (fw *FrameWriter) WriteFrame(payload []byte) error {
+	return fw.writeFrame(payload, 0, false)
+}
+
+// WriteFrameSchema is WriteFrame with a schema ID the receiver can dispatch on.
+func (fw *FrameWriter) WriteFrameSchema(schemaID uint64, payload []byte) error {
+	return fw.writeFrame(payload, schemaID, true)
+}
+
+func (fw *FrameWriter) writeFrame(payload []byte, schemaID uint64, withID bool) error {
+	var flags Flags
+	ids := make([]byte, 0, 2+binary.MaxVarintLen64)
+	body := payload
+	if fw.compress != nil && len(payload) >= fw.MinCompressSize {
+		b, err := fw.compress.Encode(body)
+		if err != nil {
+			return fmt.Errorf("stream: compress: %w", err)
+		}
+		body, flags = b, flags|FlagCompressed
+		ids = append(ids, fw.compress.ID())
+	}
+	if fw.encrypt != nil {
+		flags |= FlagEncrypted
+		ids = append(ids, fw.encrypt.ID())
+	}
+	if withID {
+		flags |= FlagSchemaID
+		ids = binary.AppendUvarint(ids, schemaID)
+	}
+	fw.hdr = append(fw.hdr[:0], 0, 0, 0, 0, byte(flags))
+	fw.hdr = append(fw.hdr, ids...)
+	if fw.encrypt != nil {
+		b, err := encodeFrameBody(fw.encrypt, body, fw.hdr[4:])
+		if err != nil {
+			return fmt.Errorf("stream: encrypt: %w", err)
+		}
+		body = b
+	}
+
+	size := len(fw.hdr) - 4 + len(body)
+	if uint64(size) > 1<<32-1 {
+		return ErrFrameTooLarge
+	}
+	binary.LittleEndian.PutUint32(fw.hdr, uint32(size))
+	if _, err := fw.w.Write(fw.hdr); err != nil {
+		return err
+	}
+	_, err := fw.w.Write(body)
+	return err
+}
+
+// FrameReader reads frames written by FrameWriter.
+type FrameReader struct {
+	r        io.Reader
+	registry *Registry
+	// MaxFrameSize rejects larger frames before allocating them.
+	MaxFrameSize int
+	lenBuf       [4]byte
+	encryptIDs   []byte
+}
+
+// NewFrameReader creates a reader that unwraps frames with DefaultRegistry.
+func NewFrameReader(r io.Reader) *FrameReader {
+	return &FrameReader{r: r, registry: DefaultRegistry, MaxFrameSize: DefaultMaxFrameSize}
+}
+
+// WithRegistry makes the reader resolve codecs in reg.
+func (fr *FrameReader) WithRegistry(reg *Registry) *FrameReader {
+	fr.registry = reg
+	return fr
+}
+
+// RequireEncryption makes the reader reject, with ErrFrameNotEncrypted,
+// frames that are not encrypted with one of the codecs ids. Without it a
+// peer that strips FlagEncrypted and the codec ID can pass plaintext. No
+// ids lifts the requirement.
+func (fr *FrameReader) RequireEncryption(ids ...byte) *FrameReader {
+	fr.encryptIDs = ids
+	return fr
+}
+
+// ReadFrame reads and unwraps the next frame. It returns io.EOF at a clean
+// end of stream and io.ErrUnexpectedEOF when a frame is cut short.
+func (fr *FrameReader) ReadFrame() (Frame, error) {
+	if _, err := io.ReadFull(fr.r, fr.lenBuf[:]); err != nil {
+		return Frame{}, err
+	}
+	size := binary.LittleEndian.Uint32(fr.lenBuf[:])
+	if size == 0 {
+		return Frame{}, errors.New("stream: empty frame")
+	}
+	if fr.MaxFrameSize > 0 && uint64(size) > uint64(fr.MaxFrameSize) {
+		return Frame{}, ErrFrameTooLarge
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(fr.r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return Frame{}, err
+	}
+	return fr.unwrap(data)
+}
+
+func (fr *FrameReader) unwrap(data []byte) (Frame, error) {
+	f := Frame{Flags: Flags(data[0])}
+	header := data
+	data = data[1:]
+	var compressID, encryptID byte
+	var err error
+	if f.Flags&FlagCompressed != 0 {
+		if compressID, data, err = takeByte(data); err != nil {
+			return Frame{}, err
+		}
+	}
+	if f.Flags&FlagEncrypted != 0 {
+		if encryptID, data, err = takeByte(data); err != nil {
+			return Frame{}, err
+		}
+	}
+	if f.HasSchemaID() {
+		id, n := binary.Uvarint(data)
+		if n <= 0 {
+			return Frame{}, errors.New("stream: invalid schema ID")
+		}
+		f.SchemaID, data = id, data[n:]
+	}
+	if fr.encryptIDs != nil && (f.Flags&FlagEncrypted == 0 || !slices.Contains(fr.encryptIDs, encryptID)) {
+		return Frame{}, ErrFrameNotEncrypted
+	}
+	header = header[:len(header)-len(data)]
+	if f.Flags&FlagEncrypted != 0 {
+		c, err := fr.registry.encryption(encryptID)
+		if err != nil {
+			return Frame{}, fmt.Errorf("stream: %w", err)
+		}
+		if data, err = decodeFrameBody(c, data, header); err != nil {
+			return Frame{}, fmt.Errorf("stream: decrypt: %w", err)
+		}
+	}
+	if f.Flags&FlagCompressed != 0 {
+		c, err := fr.registry.compression(compressID)
+		if err != nil {
+			return Frame{}, fmt.Errorf("stream: %w", err)
+		}
+		if data, err = c.Decode(data); err != nil {
+			return Frame{}, fmt.Errorf("stream: decompress: %w", err)
+		}
+	}
+	f.Payload = data
+	return f, nil
+}
+
+// encodeFrameBody encrypts body, authenticating header when c is an
+// AuthCodec.
+func encodeFrameBody(c Codec, body, header []byte) ([]byte, error) {
+	if ac, ok := c.(AuthCodec); ok {
+		return ac.EncodeAuth(body, header)
+	}
+	return c.Encode(body)
+}
+
+func decodeFrameBody(c Codec, body, header []byte) ([]byte, error) {
+	if ac, ok := c.(AuthCodec); ok {
+		return ac.DecodeAuth(body, header)
+	}
+	return c.Decode(body)
+}
+
+func takeByte(data []byte) (byte, []byte, error) {
+	if len(data) == 0 {
+		return 0, nil, errors.New("stream: truncated frame header")
+	}
+	return data[0], data[1:], nil
+}
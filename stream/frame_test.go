@@ -0,0 +1,148 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func packed(s string) []byte {
+	put := access.NewPutAccess()
+	put.AddString(s)
+	return put.Pack()
+}
+
+func TestFrames_MixedCompressionAndEncryption(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	aead, err := NewAESGCMCodec(9, key)
+	require.NoError(t, err)
+
+	var wire bytes.Buffer
+	fw := NewFrameWriter(&wire)
+	fw.MinCompressSize = 64
+	fw.Compress(GzipCodec{})
+
+	small := packed("tiny")
+	large := packed(string(bytes.Repeat([]byte("abc"), 200)))
+	require.NoError(t, fw.WriteFrame(small))
+	require.NoError(t, fw.WriteFrame(large))
+	fw.Encrypt(aead)
+	require.NoError(t, fw.WriteFrame(large))
+	fw.Compress(nil)
+	require.NoError(t, fw.WriteFrame(small))
+
+	reg := NewRegistry()
+	reg.RegisterCompression(GzipCodec{})
+	reg.RegisterEncryption(aead)
+	fr := NewFrameReader(&wire).WithRegistry(reg)
+
+	expect := []struct {
+		flags   Flags
+		payload []byte
+	}{
+		{0, small},
+		{FlagCompressed, large},
+		{FlagCompressed | FlagEncrypted, large},
+		{FlagEncrypted, small},
+	}
+	for i, e := range expect {
+		f, err := fr.ReadFrame()
+		require.NoError(t, err, "frame %d", i)
+		assert.Equal(t, e.flags, f.Flags, "frame %d", i)
+		assert.Equal(t, e.payload, f.Payload, "frame %d", i)
+	}
+	_, err = fr.ReadFrame()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestFrameReader_Errors(t *testing.T) {
+	var wire bytes.Buffer
+	aead, err := NewAESGCMCodec(3, bytes.Repeat([]byte{1}, 16))
+	require.NoError(t, err)
+	require.NoError(t, NewFrameWriter(&wire).Encrypt(aead).WriteFrame(packed("secret")))
+
+	// default registry has no encryption codecs
+	_, err = NewFrameReader(bytes.NewReader(wire.Bytes())).ReadFrame()
+	assert.Error(t, err)
+
+	_, err = NewFrameReader(bytes.NewReader(wire.Bytes()[:wire.Len()-1])).ReadFrame()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	fr := NewFrameReader(bytes.NewReader(wire.Bytes()))
+	fr.MaxFrameSize = 8
+	_, err = fr.ReadFrame()
+	assert.ErrorIs(t, err, ErrFrameTooLarge)
+}
+
+func TestFrameReader_HeaderIsAuthenticated(t *testing.T) {
+	aead, err := NewAESGCMCodec(3, bytes.Repeat([]byte{1}, 16))
+	require.NoError(t, err)
+	reg := NewRegistry()
+	reg.RegisterEncryption(aead)
+
+	var wire bytes.Buffer
+	require.NoError(t, NewFrameWriter(&wire).Encrypt(aead).WriteFrameSchema(5, packed("secret")))
+	f, err := NewFrameReader(bytes.NewReader(wire.Bytes())).WithRegistry(reg).ReadFrame()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), f.SchemaID)
+
+	// route the payload to another schema
+	forged := bytes.Clone(wire.Bytes())
+	forged[4+1+1] = 6
+	_, err = NewFrameReader(bytes.NewReader(forged)).WithRegistry(reg).ReadFrame()
+	assert.ErrorContains(t, err, "decrypt")
+}
+
+func TestFrameReader_RequireEncryption(t *testing.T) {
+	aead, err := NewAESGCMCodec(3, bytes.Repeat([]byte{1}, 16))
+	require.NoError(t, err)
+	reg := NewRegistry()
+	reg.RegisterEncryption(aead)
+	read := func(wire []byte) (Frame, error) {
+		return NewFrameReader(bytes.NewReader(wire)).WithRegistry(reg).RequireEncryption(3).ReadFrame()
+	}
+
+	var wire bytes.Buffer
+	require.NoError(t, NewFrameWriter(&wire).Encrypt(aead).WriteFrame(packed("secret")))
+	_, err = read(wire.Bytes())
+	require.NoError(t, err)
+
+	// strip the encrypted flag and codec ID and put plaintext in the body
+	plain := packed("forged")
+	stripped := binary.LittleEndian.AppendUint32(nil, uint32(1+len(plain)))
+	stripped = append(stripped, wire.Bytes()[4]&^byte(FlagEncrypted))
+	stripped = append(stripped, plain...)
+	f, err := NewFrameReader(bytes.NewReader(stripped)).WithRegistry(reg).ReadFrame()
+	require.NoError(t, err, "accepted without the requirement")
+	assert.Equal(t, plain, f.Payload)
+	_, err = read(stripped)
+	assert.ErrorIs(t, err, ErrFrameNotEncrypted)
+
+	// another registered codec does not satisfy the requirement
+	other, err := NewAESGCMCodec(4, bytes.Repeat([]byte{2}, 16))
+	require.NoError(t, err)
+	reg.RegisterEncryption(other)
+	wire.Reset()
+	require.NoError(t, NewFrameWriter(&wire).Encrypt(other).WriteFrame(packed("secret")))
+	_, err = read(wire.Bytes())
+	assert.ErrorIs(t, err, ErrFrameNotEncrypted)
+}
+
+func TestGzipCodec_MaxSize(t *testing.T) {
+	bomb, err := GzipCodec{}.Encode(make([]byte, 1<<20))
+	require.NoError(t, err)
+
+	out, err := GzipCodec{}.Decode(bomb)
+	require.NoError(t, err)
+	assert.Len(t, out, 1<<20)
+
+	_, err = GzipCodec{MaxSize: 1 << 19}.Decode(bomb)
+	assert.ErrorIs(t, err, ErrInflateTooLarge)
+	_, err = GzipCodec{MaxSize: 1 << 20}.Decode(bomb)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,104 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/quickwritereader/PackOS/schema"
+)
+
+// DecodeStream reads frames from r until the stream ends and hands each
+// payload, decoded with DecodeBufferNamed, to into as a T. T may be
+// map[string]any, which receives the decoded fields as they are, or a type
+// the fields are mapped onto through encoding/json, so struct fields follow
+// their json tags. Frames are unwrapped with DefaultRegistry.
+//
+// DecodeStream returns nil at a clean end of stream, the first error of
+// into unchanged, and any other error with the index of the frame.
+func DecodeStream[T any](r io.Reader, chain schema.SchemaNamedChain, into func(T) error) error {
+	fr := NewFrameReader(r)
+	for n := 0; ; n++ {
+		f, err := fr.ReadFrame()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("stream: frame %d: %w", n, err)
+		}
+		val, err := schema.DecodeBufferNamed(f.Payload, chain)
+		if err != nil {
+			return fmt.Errorf("stream: frame %d: %w", n, err)
+		}
+		rec, err := mapRecord[T](val.(map[string]any))
+		if err != nil {
+			return fmt.Errorf("stream: frame %d: %w", n, err)
+		}
+		if err := into(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// mapRecord converts decoded fields to T.
+func mapRecord[T any](fields map[string]any) (T, error) {
+	var rec T
+	if m, ok := any(fields).(T); ok {
+		return m, nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return rec, err
+	}
+	err = json.Unmarshal(data, &rec)
+	return rec, err
+}
+
+// EncodeStream encodes each record of src with chain, as EncodeValueNamed
+// does, and writes it to w as one plain frame, the stream DecodeStream
+// reads. Records of type map[string]any are encoded as they are; others are
+// marshaled with encoding/json first and encoded as EncodeJSON does, so
+// struct fields follow their json tags and numbers are narrowed to the
+// widths of their schemas. Frames are buffered and w is written in large
+// chunks.
+//
+// EncodeStream stops at the first record that fails, returning its error
+// with the index of the record; the frames before it are written.
+func EncodeStream[T any](w io.Writer, chain schema.SchemaNamedChain, src iter.Seq[T]) error {
+	bw := bufio.NewWriter(w)
+	fw := NewFrameWriter(bw)
+	var js bytes.Buffer
+	enc := json.NewEncoder(&js)
+	n := 0
+	for rec := range src {
+		buf, err := encodeRecord(rec, chain, &js, enc)
+		if err == nil {
+			err = fw.WriteFrame(buf)
+		}
+		if err != nil {
+			if ferr := bw.Flush(); ferr != nil {
+				return ferr
+			}
+			return fmt.Errorf("stream: record %d: %w", n, err)
+		}
+		n++
+	}
+	return bw.Flush()
+}
+
+// encodeRecord packs rec, reusing js and enc for records that go through
+// encoding/json.
+func encodeRecord[T any](rec T, chain schema.SchemaNamedChain, js *bytes.Buffer, enc *json.Encoder) ([]byte, error) {
+	if m, ok := any(rec).(map[string]any); ok {
+		return schema.EncodeValueNamed(m, chain)
+	}
+	js.Reset()
+	if err := enc.Encode(rec); err != nil {
+		return nil, err
+	}
+	return schema.EncodeJSON(js.Bytes(), chain)
+}
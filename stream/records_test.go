@@ -0,0 +1,99 @@
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/quickwritereader/PackOS/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reading struct {
+	Sensor string  `json:"sensor"`
+	Value  float64 `json:"value"`
+	Seq    int32   `json:"seq"`
+}
+
+var readingChain = schema.SchemaNamedChain{
+	SchemaChain: schema.SChain(schema.SString, schema.SFloat64, schema.SInt32),
+	FieldNames:  []string{"sensor", "value", "seq"},
+}
+
+func writeReadings(t *testing.T, w io.Writer, recs ...map[string]any) {
+	t.Helper()
+	fw := NewFrameWriter(w).Compress(GzipCodec{})
+	for _, rec := range recs {
+		buf, err := schema.EncodeValueNamed(rec, readingChain)
+		require.NoError(t, err)
+		require.NoError(t, fw.WriteFrame(buf))
+	}
+}
+
+func TestDecodeStream(t *testing.T) {
+	var wire bytes.Buffer
+	writeReadings(t, &wire,
+		map[string]any{"sensor": "t1", "value": 21.5, "seq": int32(1)},
+		map[string]any{"sensor": "t2", "value": -3.25, "seq": int32(2)})
+	data := wire.Bytes()
+
+	var got []reading
+	err := DecodeStream(bytes.NewReader(data), readingChain, func(r reading) error {
+		got = append(got, r)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []reading{{"t1", 21.5, 1}, {"t2", -3.25, 2}}, got)
+
+	var maps []map[string]any
+	err = DecodeStream(bytes.NewReader(data), readingChain, func(m map[string]any) error {
+		maps = append(maps, m)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, maps, 2)
+	assert.Equal(t, int32(2), maps[1]["seq"])
+
+	stop := errors.New("stop")
+	calls := 0
+	err = DecodeStream(bytes.NewReader(data), readingChain, func(reading) error {
+		calls++
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, calls)
+
+	err = DecodeStream(bytes.NewReader(data[:len(data)-1]), readingChain, func(reading) error { return nil })
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Contains(t, err.Error(), "frame 1")
+}
+
+func TestEncodeStream(t *testing.T) {
+	recs := []reading{{"t1", 21.5, 1}, {"t2", -3.25, 2}, {"t3", 0, 3}}
+	var wire bytes.Buffer
+	require.NoError(t, EncodeStream(&wire, readingChain, slices.Values(recs)))
+
+	var got []reading
+	require.NoError(t, DecodeStream(&wire, readingChain, func(r reading) error {
+		got = append(got, r)
+		return nil
+	}))
+	assert.Equal(t, recs, got)
+
+	maps := []map[string]any{
+		{"sensor": "t1", "value": 1.0, "seq": int32(1)},
+		{"sensor": "t2", "value": 2.0},
+	}
+	wire.Reset()
+	err := EncodeStream(&wire, readingChain, slices.Values(maps))
+	assert.ErrorContains(t, err, "record 1")
+	var seqs []int32
+	require.NoError(t, DecodeStream(&wire, readingChain, func(m map[string]any) error {
+		seqs = append(seqs, m["seq"].(int32))
+		return nil
+	}))
+	assert.Equal(t, []int32{1}, seqs)
+}
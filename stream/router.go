@@ -0,0 +1,80 @@
+package stream
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/quickwritereader/PackOS/schema"
+)
+
+// UnknownSchemaIDError is returned for frames whose schema ID has no registered chain.
+type UnknownSchemaIDError struct {
+	ID uint64
+}
+
+func (e UnknownSchemaIDError) Error() string {
+	return fmt.Sprintf("stream: unknown schema ID %d", e.ID)
+}
+
+// SchemaRouter maps frame schema IDs to schema chains.
+// Frames without an ID use the fallback chain when one is set.
+type SchemaRouter struct {
+	mu          sync.RWMutex
+	chains      map[uint64]schema.SchemaChain
+	fallback    schema.SchemaChain
+	hasFallback bool
+}
+
+// NewSchemaRouter returns an empty router.
+func NewSchemaRouter() *SchemaRouter {
+	return &SchemaRouter{chains: map[uint64]schema.SchemaChain{}}
+}
+
+// Register adds or replaces the chain for id.
+func (r *SchemaRouter) Register(id uint64, chain schema.SchemaChain) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.chains[id] = chain
+}
+
+// SetFallback sets the chain used for frames without a schema ID.
+func (r *SchemaRouter) SetFallback(chain schema.SchemaChain) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback, r.hasFallback = chain, true
+}
+
+// Chain returns the chain for f.
+func (r *SchemaRouter) Chain(f Frame) (schema.SchemaChain, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !f.HasSchemaID() {
+		if r.hasFallback {
+			return r.fallback, nil
+		}
+		return schema.SchemaChain{}, fmt.Errorf("stream: frame has no schema ID")
+	}
+	chain, ok := r.chains[f.SchemaID]
+	if !ok {
+		return schema.SchemaChain{}, UnknownSchemaIDError{ID: f.SchemaID}
+	}
+	return chain, nil
+}
+
+// Validate validates the frame payload against its chain.
+func (r *SchemaRouter) Validate(f Frame) error {
+	chain, err := r.Chain(f)
+	if err != nil {
+		return err
+	}
+	return schema.ValidateBuffer(f.Payload, chain)
+}
+
+// Decode decodes the frame payload with its chain.
+func (r *SchemaRouter) Decode(f Frame) (any, error) {
+	chain, err := r.Chain(f)
+	if err != nil {
+		return nil, err
+	}
+	return schema.DecodeBuffer(f.Payload, chain)
+}
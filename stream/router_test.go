@@ -0,0 +1,54 @@
+package stream
+
+import (
+	"bytes"
+	"testing"
+
+	pack "github.com/quickwritereader/PackOS/packable"
+	"github.com/quickwritereader/PackOS/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaRouter_DispatchesBySchemaID(t *testing.T) {
+	router := NewSchemaRouter()
+	router.Register(1, schema.SChain(schema.SInt32))
+	router.Register(300, schema.SChain(schema.SString, schema.SBool))
+
+	var wire bytes.Buffer
+	fw := NewFrameWriter(&wire)
+	require.NoError(t, fw.WriteFrameSchema(1, pack.Pack(pack.PackInt32(42))))
+	fw.Compress(GzipCodec{})
+	require.NoError(t, fw.WriteFrameSchema(300, pack.Pack(pack.PackString("on"), pack.PackBool(true))))
+	require.NoError(t, fw.WriteFrameSchema(7, pack.Pack(pack.PackInt32(1))))
+	require.NoError(t, fw.WriteFrame(pack.Pack(pack.PackInt32(1))))
+
+	fr := NewFrameReader(&wire)
+
+	f, err := fr.ReadFrame()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), f.SchemaID)
+	v, err := router.Decode(f)
+	require.NoError(t, err)
+	assert.Equal(t, int32(42), v)
+
+	f, err = fr.ReadFrame()
+	require.NoError(t, err)
+	assert.Equal(t, FlagCompressed|FlagSchemaID, f.Flags)
+	v, err = router.Decode(f)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"on", true}, v)
+
+	f, err = fr.ReadFrame()
+	require.NoError(t, err)
+	var unknown UnknownSchemaIDError
+	assert.ErrorAs(t, router.Validate(f), &unknown)
+	assert.Equal(t, uint64(7), unknown.ID)
+
+	f, err = fr.ReadFrame()
+	require.NoError(t, err)
+	assert.False(t, f.HasSchemaID())
+	assert.Error(t, router.Validate(f))
+	router.SetFallback(schema.SChain(schema.SInt32))
+	assert.NoError(t, router.Validate(f))
+}
@@ -0,0 +1,96 @@
+package typetags
+
+import "math/bits"
+
+// Bitset is a growable set of bit positions with a fixed logical length.
+// The zero value is an empty bitset of length 0.
+type Bitset struct {
+	words []uint64
+	n     int
+}
+
+// NewBitset returns an empty bitset of n bits.
+func NewBitset(n int) *Bitset {
+	return &Bitset{words: make([]uint64, (n+63)/64), n: n}
+}
+
+// BitsetOf returns a bitset of length n with the given positions set.
+// Positions at or beyond n extend the length.
+func BitsetOf(n int, positions ...int) *Bitset {
+	b := NewBitset(n)
+	for _, i := range positions {
+		b.Set(i)
+	}
+	return b
+}
+
+// Len returns the length in bits.
+func (b *Bitset) Len() int { return b.n }
+
+// Set sets bit i, growing the bitset when i is beyond its length.
+func (b *Bitset) Set(i int) {
+	if i < 0 {
+		panic("bitset: negative index")
+	}
+	if i >= b.n {
+		b.Grow(i + 1)
+	}
+	b.words[i>>6] |= 1 << (i & 63)
+}
+
+// Clear clears bit i. Bits beyond the length are already clear.
+func (b *Bitset) Clear(i int) {
+	if i >= 0 && i < b.n {
+		b.words[i>>6] &^= 1 << (i & 63)
+	}
+}
+
+// Test reports whether bit i is set.
+func (b *Bitset) Test(i int) bool {
+	return i >= 0 && i < b.n && b.words[i>>6]&(1<<(i&63)) != 0
+}
+
+// Grow extends the length to n bits. It never shrinks the bitset.
+func (b *Bitset) Grow(n int) {
+	if n <= b.n {
+		return
+	}
+	for len(b.words) < (n+63)/64 {
+		b.words = append(b.words, 0)
+	}
+	b.n = n
+}
+
+// Count returns the number of set bits.
+func (b *Bitset) Count() int {
+	c := 0
+	for _, w := range b.words {
+		c += bits.OnesCount64(w)
+	}
+	return c
+}
+
+// Ones returns the set positions in ascending order.
+func (b *Bitset) Ones() []int {
+	out := make([]int, 0, b.Count())
+	for wi, w := range b.words {
+		for w != 0 {
+			out = append(out, wi<<6+bits.TrailingZeros64(w))
+			w &= w - 1
+		}
+	}
+	return out
+}
+
+// Equal reports whether b and o have the same length and bits.
+func (b *Bitset) Equal(o *Bitset) bool {
+	if b.n != o.n {
+		return false
+	}
+	for i, w := range b.words {
+		if w != o.words[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,31 @@
+package typetags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitset_SetTestClear(t *testing.T) {
+	b := NewBitset(10)
+	b.Set(3)
+	b.Set(130) // grows
+	assert.Equal(t, 131, b.Len())
+	assert.True(t, b.Test(3))
+	assert.True(t, b.Test(130))
+	assert.False(t, b.Test(4))
+	assert.False(t, b.Test(1000))
+	assert.Equal(t, []int{3, 130}, b.Ones())
+
+	b.Clear(3)
+	b.Clear(5000)
+	assert.Equal(t, 1, b.Count())
+	assert.True(t, b.Equal(BitsetOf(131, 130)))
+	assert.False(t, b.Equal(BitsetOf(132, 130)))
+	assert.Panics(t, func() { b.Set(-1) })
+
+	var zero Bitset
+	assert.Equal(t, 0, zero.Len())
+	zero.Set(0)
+	assert.True(t, zero.Test(0))
+}
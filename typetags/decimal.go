@@ -0,0 +1,135 @@
+package typetags
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an arbitrary-precision decimal number Coef × 10^Exp.
+// The scale is kept as written, so 1.50 and 1.5 are different values that
+// compare equal with Cmp.
+type Decimal struct {
+	Coef *big.Int
+	Exp  int32
+}
+
+// NewDecimal returns coef × 10^exp. A nil coef is zero.
+func NewDecimal(coef *big.Int, exp int32) Decimal {
+	if coef == nil {
+		coef = new(big.Int)
+	}
+	return Decimal{Coef: coef, Exp: exp}
+}
+
+// NewDecimalInt64 returns coef × 10^exp.
+func NewDecimalInt64(coef int64, exp int32) Decimal {
+	return Decimal{Coef: big.NewInt(coef), Exp: exp}
+}
+
+var ErrInvalidDecimal = errors.New("invalid decimal")
+
+// ParseDecimal parses a decimal literal such as "-12.340" or "1.5e-3".
+// The number of fraction digits becomes the scale.
+func ParseDecimal(s string) (Decimal, error) {
+	mant, exp := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+		}
+		mant, exp = s[:i], e
+	}
+	digits := mant
+	if i := strings.IndexByte(mant, '.'); i >= 0 {
+		digits = mant[:i] + mant[i+1:]
+		exp -= int64(len(mant) - i - 1)
+	}
+	body := strings.TrimLeft(digits, "+-")
+	if len(digits)-len(body) > 1 || body == "" || strings.Trim(body, "0123456789") != "" || exp < -1<<31 || exp > 1<<31-1 {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+	coef, _ := new(big.Int).SetString(digits, 10)
+	return Decimal{Coef: coef, Exp: int32(exp)}, nil
+}
+
+// DecimalFromRat converts r exactly. It fails when r has no finite decimal
+// expansion, such as 1/3.
+func DecimalFromRat(r *big.Rat) (Decimal, error) {
+	num := new(big.Int).Set(r.Num())
+	den := new(big.Int).Set(r.Denom())
+	two, five := big.NewInt(2), big.NewInt(5)
+	var twos, fives int32
+	rem := new(big.Int)
+	for {
+		if q, m := new(big.Int).QuoRem(den, two, rem); m.Sign() == 0 {
+			den, twos = q, twos+1
+			continue
+		}
+		if q, m := new(big.Int).QuoRem(den, five, rem); m.Sign() == 0 {
+			den, fives = q, fives+1
+			continue
+		}
+		break
+	}
+	if !den.IsInt64() || den.Int64() != 1 {
+		return Decimal{}, fmt.Errorf("%w: %s has no finite decimal expansion", ErrInvalidDecimal, r.String())
+	}
+	// scale num so the denominator becomes 10^n
+	n := max(twos, fives)
+	num.Mul(num, new(big.Int).Exp(two, big.NewInt(int64(n-twos)), nil))
+	num.Mul(num, new(big.Int).Exp(five, big.NewInt(int64(n-fives)), nil))
+	return Decimal{Coef: num, Exp: -n}, nil
+}
+
+// Rat returns d as an exact rational number.
+func (d Decimal) Rat() *big.Rat {
+	r := new(big.Rat).SetInt(d.coef())
+	p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs32(d.Exp))), nil)
+	if d.Exp >= 0 {
+		return r.Mul(r, new(big.Rat).SetInt(p))
+	}
+	return r.Quo(r, new(big.Rat).SetInt(p))
+}
+
+// Cmp compares d and o by value, ignoring scale.
+func (d Decimal) Cmp(o Decimal) int {
+	return d.Rat().Cmp(o.Rat())
+}
+
+// String formats d in plain notation, keeping trailing fraction zeros.
+func (d Decimal) String() string {
+	s := d.coef().String()
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	switch {
+	case d.Exp > 0:
+		s += strings.Repeat("0", int(d.Exp))
+	case d.Exp < 0:
+		scale := int(-d.Exp)
+		if len(s) <= scale {
+			s = strings.Repeat("0", scale-len(s)+1) + s
+		}
+		s = s[:len(s)-scale] + "." + s[len(s)-scale:]
+	}
+	if neg {
+		return "-" + s
+	}
+	return s
+}
+
+func (d Decimal) coef() *big.Int {
+	if d.Coef == nil {
+		return new(big.Int)
+	}
+	return d.Coef
+}
+
+func abs32(v int32) int64 {
+	if v < 0 {
+		return -int64(v)
+	}
+	return int64(v)
+}
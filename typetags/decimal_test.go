@@ -0,0 +1,51 @@
+package typetags
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDecimal(t *testing.T) {
+	cases := []struct {
+		in   string
+		coef string
+		exp  int32
+		out  string
+	}{
+		{"12.340", "12340", -3, "12.340"},
+		{"-0.05", "-5", -2, "-0.05"},
+		{"+7", "7", 0, "7"},
+		{"1.5e-3", "15", -4, "0.0015"},
+		{"25e2", "25", 2, "2500"},
+		{".5", "5", -1, "0.5"},
+		{"123456789012345678901234567890.01", "12345678901234567890123456789001", -2, "123456789012345678901234567890.01"},
+	}
+	for _, c := range cases {
+		d, err := ParseDecimal(c.in)
+		require.NoError(t, err, c.in)
+		assert.Equal(t, c.coef, d.Coef.String(), c.in)
+		assert.Equal(t, c.exp, d.Exp, c.in)
+		assert.Equal(t, c.out, d.String(), c.in)
+	}
+	for _, bad := range []string{"", "-", "1.2.3", "1e", "--1", "0x10", "1e99999999999"} {
+		_, err := ParseDecimal(bad)
+		assert.ErrorIs(t, err, ErrInvalidDecimal, bad)
+	}
+}
+
+func TestDecimalFromRatAndCmp(t *testing.T) {
+	d, err := DecimalFromRat(big.NewRat(-3, 8))
+	require.NoError(t, err)
+	assert.Equal(t, "-0.375", d.String())
+	assert.Equal(t, 0, d.Rat().Cmp(big.NewRat(-3, 8)))
+
+	_, err = DecimalFromRat(big.NewRat(1, 3))
+	assert.ErrorIs(t, err, ErrInvalidDecimal)
+
+	assert.Equal(t, 0, NewDecimalInt64(150, -2).Cmp(NewDecimalInt64(15, -1)))
+	assert.Equal(t, -1, NewDecimalInt64(1, 0).Cmp(NewDecimalInt64(2, 3)))
+	assert.Equal(t, "0", Decimal{}.String())
+}
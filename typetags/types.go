@@ -4,21 +4,28 @@ package typetags
 type Type uint16
 
 const (
-	TypeInvalid              Type = 0
-	TypeEnd                  Type = 0
-	TypeUnk                  Type = 0 // actually, can be used as arg position is not determined by it
-	TypeInteger              Type = 1
-	TypeExtendedTagContainer Type = 2
-	TypeFloating             Type = 3
-	TypeTuple                Type = 4
-	TypeNull                 Type = 4
-	TypeBool                 Type = 5
-	TypeString               Type = 6 // used for both string and []byte small chunks
-	TypeByteArray            Type = 6
-	TypeSlice                Type = 6
-	TypeMap                  Type = 7
+	TypeInvalid   Type = 0
+	TypeEnd       Type = 0
+	TypeUnk       Type = 0 // actually, can be used as arg position is not determined by it
+	TypeInteger   Type = 1
+	TypeSortedMap Type = 2 // map whose string keys are strictly ascending
+	TypeFloating  Type = 3
+	TypeTuple     Type = 4
+	TypeNull      Type = 4
+	TypeBool      Type = 5
+	TypeString    Type = 6 // used for both string and []byte small chunks
+	TypeByteArray Type = 6
+	TypeSlice     Type = 6
+	TypeMap       Type = 7
 )
 
+// TypeExtendedTagContainer is the former name of tag 2.
+//
+// Deprecated: tag 2, once reserved for extensions, now marks TypeSortedMap.
+// Buffers escape to other encodings with an offset-0 header instead, which
+// no packed list starts with; see access.IsCompressed and access.IsInterned.
+const TypeExtendedTagContainer = TypeSortedMap
+
 // String returns the human-readable name of the type
 func (t Type) String() string {
 	switch t {
@@ -30,8 +37,8 @@ func (t Type) String() string {
 		return "bool"
 	case TypeString:
 		return "string"
-	case TypeExtendedTagContainer:
-		return "extended_container"
+	case TypeSortedMap:
+		return "sorted_map"
 	case TypeTuple:
 		return "tuple"
 	case TypeMap:
@@ -41,6 +48,11 @@ func (t Type) String() string {
 	}
 }
 
+// IsMap reports whether t is a map container, sorted or not
+func (t Type) IsMap() bool {
+	return t == TypeMap || t == TypeSortedMap
+}
+
 func EncodeHeader(offset int, typeID Type) uint16 {
 	return uint16(offset<<3) | (uint16(typeID) & 0x07)
 }
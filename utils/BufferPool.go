@@ -3,6 +3,7 @@ package utils
 import (
 	"math/bits"
 	"sync"
+	"unsafe"
 )
 
 var BufferSizeClass = [...]int{64, 128, 256, 512, 1024, 2048, 4096, 8192, 16384, 32768}
@@ -23,6 +24,10 @@ func SizeIndex(n int) int {
 
 type BufferPool struct {
 	pools [len(BufferSizeClass)]sync.Pool
+	// ZeroOnRelease makes Release clear whole buffers, up to their
+	// capacity, so keys, tokens and other secrets do not linger in pooled
+	// or collected memory. Set it before the pool is used.
+	ZeroOnRelease bool
 }
 
 func NewBufferPool() *BufferPool {
@@ -58,8 +63,39 @@ func (bp *BufferPool) AcquireZeroed(n int) []byte {
 	return buf
 }
 
-// Release returns the buffer to its pool if size matches a class.
+// AcquireAligned returns a buffer of n bytes whose first byte sits at an
+// address that is a multiple of align, a power of two, as casting it to a
+// slice of wider integers or floats with unsafe requires. Pooled buffers are
+// used when they happen to be aligned; otherwise a larger buffer is
+// allocated and a slice of it returned, which Release drops unless its
+// capacity matches a class. Panics if align is not a power of two.
+func (bp *BufferPool) AcquireAligned(n, align int) []byte {
+	if align <= 0 || align&(align-1) != 0 {
+		panic("utils: alignment must be a power of two")
+	}
+	buf := bp.Acquire(n)
+	if n == 0 || alignOffset(buf, align) == 0 {
+		return buf
+	}
+	bp.Release(buf)
+	buf = make([]byte, n+align-1)
+	off := alignOffset(buf, align)
+	return buf[off : off+n : off+n]
+}
+
+// alignOffset returns how many bytes past the start of buf the next
+// address aligned to align is.
+func alignOffset(buf []byte, align int) int {
+	addr := uintptr(unsafe.Pointer(unsafe.SliceData(buf)))
+	return int(-addr & uintptr(align-1))
+}
+
+// Release returns the buffer to its pool if size matches a class. With
+// ZeroOnRelease, the buffer is cleared first whether it is pooled or not.
 func (bp *BufferPool) Release(buf []byte) {
+	if bp.ZeroOnRelease {
+		clear(buf[:cap(buf)])
+	}
 	c := cap(buf)
 	if c&(c-1) != 0 || c < 64 || c > 32768 {
 		return // not a valid class
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"runtime"
 	"testing"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -77,6 +78,38 @@ func TestBufferPool_ExactSizeReuse(t *testing.T) {
 	}
 }
 
+func TestBufferPool_AcquireAligned(t *testing.T) {
+	bp := NewBufferPool()
+	for _, align := range []int{1, 8, 64, 4096} {
+		for _, n := range []int{1, 24, 100, 5000, 40000} {
+			buf := bp.AcquireAligned(n, align)
+			assert.Len(t, buf, n)
+			assert.Zero(t, uintptr(unsafe.Pointer(&buf[0]))%uintptr(align), "n=%d align=%d", n, align)
+			bp.Release(buf)
+		}
+	}
+	assert.Empty(t, bp.AcquireAligned(0, 16))
+	assert.Panics(t, func() { bp.AcquireAligned(8, 12) })
+	assert.Panics(t, func() { bp.AcquireAligned(8, 0) })
+}
+
+func TestBufferPool_ZeroOnRelease(t *testing.T) {
+	bp := NewBufferPool()
+	bp.ZeroOnRelease = true
+	buf := bp.Acquire(100)
+	full := buf[:cap(buf)]
+	for i := range full {
+		full[i] = 0xAA
+	}
+	bp.Release(buf[:10])
+	assert.Equal(t, make([]byte, cap(buf)), full)
+
+	big := bp.Acquire(40000)
+	big[len(big)-1] = 1
+	bp.Release(big)
+	assert.Zero(t, big[len(big)-1])
+}
+
 var retained [][]byte
 
 func BenchmarkGCPressureSafe(b *testing.B) {
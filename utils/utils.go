@@ -13,6 +13,68 @@ func SortKeys[T any](m map[string]T) []string {
 	return keys
 }
 
+// SortKeysFunc returns the keys of m ordered by less.
+func SortKeysFunc[T any](m map[string]T, less func(a, b string) bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	return keys
+}
+
+// NaturalLess orders strings the way people read them: runs of ASCII
+// digits compare by numeric value, so "item2" sorts before "item10", and
+// everything else compares byte by byte. Equal numbers with more leading
+// zeros sort first, so distinct strings never compare equal.
+func NaturalLess(a, b string) bool {
+	zerosA, zerosB := 0, 0
+	for a != "" && b != "" {
+		if !isDigit(a[0]) || !isDigit(b[0]) {
+			if a[0] != b[0] {
+				return a[0] < b[0]
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		var numA, numB string
+		numA, a = digitRun(a)
+		numB, b = digitRun(b)
+		trimA, trimB := trimZeros(numA), trimZeros(numB)
+		if len(trimA) != len(trimB) {
+			return len(trimA) < len(trimB)
+		}
+		if trimA != trimB {
+			return trimA < trimB
+		}
+		if zerosA == zerosB {
+			zerosA, zerosB = len(numA)-len(trimA), len(numB)-len(trimB)
+		}
+	}
+	if a != "" || b != "" {
+		return a == ""
+	}
+	return zerosA > zerosB
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// digitRun splits the leading digits off s.
+func digitRun(s string) (run, rest string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func trimZeros(num string) string {
+	for len(num) > 1 && num[0] == '0' {
+		num = num[1:]
+	}
+	return num
+}
+
 func HasPrefix(b []byte, prefix string) bool {
 	return len(b) >= len(prefix) && string(b[:len(prefix)]) == prefix
 }
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNaturalLess(t *testing.T) {
+	input := []string{"item7x", "file10b10", "file2", "item007x", "file100", "",
+		"file02", "file10", "item7", "file1", "file2a", "file", "file10b2"}
+	want := []string{"", "file", "file1", "file02", "file2", "file2a", "file10",
+		"file10b2", "file10b10", "file100", "item7", "item007x", "item7x"}
+	sort.Slice(input, func(i, j int) bool { return NaturalLess(input[i], input[j]) })
+	assert.Equal(t, want, input)
+
+	assert.False(t, NaturalLess("a1", "a1"))
+	assert.True(t, NaturalLess("007", "07"))
+	assert.False(t, NaturalLess("07", "007"))
+	assert.True(t, NaturalLess("99999999999999999999998", "99999999999999999999999"))
+}
+
+func TestSortKeysFunc(t *testing.T) {
+	m := map[string]int{"item10": 10, "item2": 2, "item1": 1, "Item3": 3}
+	assert.Equal(t, []string{"Item3", "item1", "item2", "item10"}, SortKeysFunc(m, NaturalLess))
+	assert.Equal(t, []string{"item10", "Item3", "item2", "item1"},
+		SortKeysFunc(m, func(a, b string) bool { return m[a] > m[b] }))
+	assert.Empty(t, SortKeysFunc(map[string]int{}, NaturalLess))
+}